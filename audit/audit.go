@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// Record is a full-fidelity audit entry for a single request
+type Record struct {
+	Timestamp time.Time                      `json:"timestamp"`
+	RequestID string                         `json:"request_id"`
+	TenantID  string                         `json:"tenant_id,omitempty"`
+	Model     string                         `json:"model"`    // Model requested by the client
+	Provider  string                         `json:"provider"` // Provider that served the request
+	Endpoint  string                         `json:"endpoint"`
+	Stream    bool                           `json:"stream"`
+	Request   *openai.ChatCompletionRequest  `json:"request"`
+	Response  *openai.ChatCompletionResponse `json:"response"` // Accumulated response for streams
+}
+
+// Sink receives audit records
+type Sink interface {
+	// Write persists a record. Errors are reported but never fail the request.
+	Write(ctx context.Context, record *Record) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(ctx context.Context, record *Record) error
+
+// Write implements Sink
+func (f SinkFunc) Write(ctx context.Context, record *Record) error {
+	return f(ctx, record)
+}
+
+// JSONSink writes records as JSON lines to a writer
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONSink creates a sink writing one JSON record per line
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+// Write implements Sink
+func (s *JSONSink) Write(ctx context.Context, record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(data); err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(&buf)
+
+	record := &Record{
+		Timestamp: time.Unix(1700000000, 0).UTC(),
+		RequestID: "req-1",
+		TenantID:  "tenant-1",
+		Model:     "gpt-4",
+		Request:   &openai.ChatCompletionRequest{Model: "gpt-4"},
+		Response:  &openai.ChatCompletionResponse{ID: "chatcmpl-1", Usage: openai.Usage{TotalTokens: 15}},
+	}
+	if err := sink.Write(context.Background(), record); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	sink.Write(context.Background(), record)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+
+	var decoded Record
+	if err := json.Unmarshal(lines[0], &decoded); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+	if decoded.RequestID != "req-1" || decoded.TenantID != "tenant-1" || decoded.Response.Usage.TotalTokens != 15 {
+		t.Errorf("unexpected record: %+v", decoded)
+	}
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"log/slog"
+	"math"
+)
+
+// Sampler decides which requests are fully audited. Decisions are deterministic
+// per request ID, so every component consulting the sampler agrees.
+type Sampler struct {
+	// Rate is the default fraction of requests sampled, from 0 to 1
+	Rate float64
+	// TenantRates overrides Rate for specific tenants
+	TenantRates map[string]float64
+	// ModelRates overrides Rate and TenantRates for specific models
+	ModelRates map[string]float64
+}
+
+// NewSampler creates a sampler with the given default rate
+func NewSampler(rate float64) *Sampler {
+	return &Sampler{
+		Rate:        rate,
+		TenantRates: make(map[string]float64),
+		ModelRates:  make(map[string]float64),
+	}
+}
+
+// WithTenantRate sets the sampling rate for a tenant
+func (s *Sampler) WithTenantRate(tenantID string, rate float64) *Sampler {
+	s.TenantRates[tenantID] = rate
+	return s
+}
+
+// WithModelRate sets the sampling rate for a model
+func (s *Sampler) WithModelRate(model string, rate float64) *Sampler {
+	s.ModelRates[model] = rate
+	return s
+}
+
+// RateFor returns the sampling rate for a tenant and model.
+// Model rates take precedence over tenant rates, which take precedence over Rate.
+func (s *Sampler) RateFor(tenantID, model string) float64 {
+	if rate, ok := s.ModelRates[model]; ok {
+		return rate
+	}
+	if rate, ok := s.TenantRates[tenantID]; ok {
+		return rate
+	}
+	return s.Rate
+}
+
+// Sampled reports whether the request should be fully audited
+func (s *Sampler) Sampled(requestID, tenantID, model string) bool {
+	rate := s.RateFor(tenantID, model)
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+
+	sum := sha256.Sum256([]byte(requestID))
+	return float64(binary.BigEndian.Uint64(sum[:8]))/math.MaxUint64 < rate
+}
+
+// SampledSink forwards only sampled records to the wrapped sink. Every record,
+// sampled or not, produces a lightweight access log entry.
+type SampledSink struct {
+	sink    Sink
+	sampler *Sampler
+}
+
+// NewSampledSink creates a sink that fully audits only sampled requests
+func NewSampledSink(sink Sink, sampler *Sampler) *SampledSink {
+	return &SampledSink{sink: sink, sampler: sampler}
+}
+
+// Write implements Sink
+func (s *SampledSink) Write(ctx context.Context, record *Record) error {
+	sampled := s.sampler.Sampled(record.RequestID, record.TenantID, record.Model)
+
+	attrs := []any{
+		"request_id", record.RequestID,
+		"tenant_id", record.TenantID,
+		"model", record.Model,
+		"provider", record.Provider,
+		"endpoint", record.Endpoint,
+		"stream", record.Stream,
+		"sampled", sampled,
+	}
+	if record.Response != nil {
+		attrs = append(attrs, "total_tokens", record.Response.Usage.TotalTokens)
+	}
+	slog.InfoContext(ctx, "access", attrs...)
+
+	if !sampled {
+		return nil
+	}
+	return s.sink.Write(ctx, record)
+}
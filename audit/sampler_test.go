@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestSampler_Fraction(t *testing.T) {
+	sampler := NewSampler(0.25)
+
+	const total = 10000
+	sampled := 0
+	for i := 0; i < total; i++ {
+		if sampler.Sampled(fmt.Sprintf("req-%d", i), "", "") {
+			sampled++
+		}
+	}
+
+	fraction := float64(sampled) / total
+	if fraction < 0.22 || fraction > 0.28 {
+		t.Errorf("expected about 25%% sampled, got %.3f", fraction)
+	}
+}
+
+func TestSampler_Deterministic(t *testing.T) {
+	sampler := NewSampler(0.5)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("req-%d", i)
+		if sampler.Sampled(id, "", "") != sampler.Sampled(id, "", "") {
+			t.Fatalf("expected stable decision for %s", id)
+		}
+	}
+}
+
+func TestSampler_Overrides(t *testing.T) {
+	sampler := NewSampler(0).
+		WithTenantRate("tenant-1", 1).
+		WithModelRate("gpt-4", 0)
+
+	if sampler.Sampled("req-1", "tenant-2", "gpt-3.5") {
+		t.Error("expected default rate 0 to skip")
+	}
+	if !sampler.Sampled("req-1", "tenant-1", "gpt-3.5") {
+		t.Error("expected tenant rate 1 to sample")
+	}
+	if sampler.Sampled("req-1", "tenant-1", "gpt-4") {
+		t.Error("expected model rate to take precedence over tenant rate")
+	}
+}
+
+func TestSampledSink(t *testing.T) {
+	var written []*Record
+	inner := SinkFunc(func(ctx context.Context, record *Record) error {
+		written = append(written, record)
+		return nil
+	})
+	sink := NewSampledSink(inner, NewSampler(0).WithTenantRate("audited", 1))
+
+	sink.Write(context.Background(), &Record{RequestID: "req-1", TenantID: "audited"})
+	sink.Write(context.Background(), &Record{RequestID: "req-2", TenantID: "other"})
+
+	if len(written) != 1 || written[0].RequestID != "req-1" {
+		t.Errorf("expected only the sampled record to be written, got %d", len(written))
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Recorder receives response cache outcomes, e.g. to export them as metrics
+type Recorder interface {
+	// RecordHit records a request served from the cache and what it saved
+	RecordHit(endpoint, model string, saved Savings)
+
+	// RecordMiss records a request that was not found in the cache
+	RecordMiss(endpoint, model string)
+}
+
+// Savings estimates the upstream usage avoided by serving a request without calling the provider
+type Savings struct {
+	PromptTokens     int
+	CompletionTokens int
+
+	// Cost is the estimated cost avoided, or 0 when no pricing is configured
+	Cost float64
+}
+
+// Price is the cost of a model in dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Pricing maps model names to their prices, for estimating the cost saved by the cache
+type Pricing map[string]Price
+
+// Cost returns the dollar cost of the given token counts on model, or false
+// if model has no price
+func (p Pricing) Cost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := p[model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*price.Input + float64(completionTokens)*price.Output) / 1e6, true
+}
+
+// Estimate returns the savings for the given token counts, costed at the model's price
+func (p Pricing) Estimate(model string, promptTokens, completionTokens int) Savings {
+	cost, _ := p.Cost(model, promptTokens, completionTokens)
+	return Savings{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		Cost:             cost,
+	}
+}
+
+// RecorderStats is a snapshot of the totals kept by Counters
+type RecorderStats struct {
+	Hits               uint64  `json:"hits"`
+	Misses             uint64  `json:"misses"`
+	HitRate            float64 `json:"hit_rate"`
+	TokensSaved        uint64  `json:"tokens_saved"`
+	EstimatedCostSaved float64 `json:"estimated_cost_saved"`
+}
+
+// Counters is a Recorder keeping running totals, e.g. for a stats endpoint
+type Counters struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	tokensSaved atomic.Uint64
+
+	mu        sync.Mutex
+	costSaved float64
+}
+
+// NewCounters creates a new Counters recorder
+func NewCounters() *Counters {
+	return &Counters{}
+}
+
+// RecordHit implements Recorder
+func (c *Counters) RecordHit(endpoint, model string, saved Savings) {
+	c.hits.Add(1)
+	c.addSavings(saved)
+}
+
+// RecordMiss implements Recorder
+func (c *Counters) RecordMiss(endpoint, model string) {
+	c.misses.Add(1)
+}
+
+// addSavings adds the tokens and cost saved by a request
+func (c *Counters) addSavings(saved Savings) {
+	c.tokensSaved.Add(uint64(saved.PromptTokens + saved.CompletionTokens))
+	c.mu.Lock()
+	c.costSaved += saved.Cost
+	c.mu.Unlock()
+}
+
+// Stats returns a snapshot of the totals
+func (c *Counters) Stats() RecorderStats {
+	stats := RecorderStats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		TokensSaved: c.tokensSaved.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	c.mu.Lock()
+	stats.EstimatedCostSaved = c.costSaved
+	c.mu.Unlock()
+	return stats
+}
+
+// multiRecorder fans outcomes out to several recorders
+type multiRecorder []Recorder
+
+// MultiRecorder returns a Recorder that forwards to every non-nil recorder
+func MultiRecorder(recorders ...Recorder) Recorder {
+	var multi multiRecorder
+	for _, r := range recorders {
+		if r != nil {
+			multi = append(multi, r)
+		}
+	}
+	return multi
+}
+
+func (m multiRecorder) RecordHit(endpoint, model string, saved Savings) {
+	for _, r := range m {
+		r.RecordHit(endpoint, model, saved)
+	}
+}
+
+func (m multiRecorder) RecordMiss(endpoint, model string) {
+	for _, r := range m {
+		r.RecordMiss(endpoint, model)
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestCounters(t *testing.T) {
+	counters := NewCounters()
+	pricing := Pricing{"gpt-4": {Input: 30, Output: 60}}
+
+	counters.RecordMiss("/v1/chat/completions", "gpt-4")
+	counters.RecordHit("/v1/chat/completions", "gpt-4", pricing.Estimate("gpt-4", 1000, 500))
+	counters.RecordHit("/v1/chat/completions", "gpt-4", pricing.Estimate("gpt-4", 1000, 500))
+
+	stats := counters.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if stats.TokensSaved != 3000 {
+		t.Errorf("expected 3000 tokens saved, got %d", stats.TokensSaved)
+	}
+	// 2 * (1000*30 + 500*60)/1e6
+	if math.Abs(stats.EstimatedCostSaved-0.12) > 1e-9 {
+		t.Errorf("expected cost saved 0.12, got %f", stats.EstimatedCostSaved)
+	}
+	if math.Abs(stats.HitRate-2.0/3.0) > 1e-9 {
+		t.Errorf("expected hit rate 2/3, got %f", stats.HitRate)
+	}
+}
+
+func TestPricing_EstimateUnknownModel(t *testing.T) {
+	saved := Pricing{}.Estimate("unknown", 10, 20)
+	if saved.PromptTokens != 10 || saved.CompletionTokens != 20 || saved.Cost != 0 {
+		t.Errorf("expected tokens without cost, got %+v", saved)
+	}
+}
+
+func TestMultiRecorder(t *testing.T) {
+	a, b := NewCounters(), NewCounters()
+	recorder := MultiRecorder(a, nil, b)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder.RecordHit("/v1/chat/completions", "gpt-4", Savings{PromptTokens: 1})
+		}()
+	}
+	wg.Wait()
+	recorder.RecordMiss("/v1/chat/completions", "gpt-4")
+
+	for _, c := range []*Counters{a, b} {
+		if stats := c.Stats(); stats.Hits != 10 || stats.Misses != 1 || stats.TokensSaved != 10 {
+			t.Errorf("unexpected counts: %+v", stats)
+		}
+	}
+}
+
+func TestPricing_Cost(t *testing.T) {
+	pricing := Pricing{"gpt-4": {Input: 30, Output: 60}}
+	cost, ok := pricing.Cost("gpt-4", 1000, 500)
+	if !ok || math.Abs(cost-0.06) > 1e-9 {
+		t.Errorf("expected $0.06, got %v (ok=%v)", cost, ok)
+	}
+	if _, ok := pricing.Cost("unknown", 1000, 0); ok {
+		t.Error("expected an unpriced model to have no cost")
+	}
+}
@@ -0,0 +1,462 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// DefaultSimilarityThreshold is the cosine similarity a prompt needs to reuse a cached response
+const DefaultSimilarityThreshold = 0.95
+
+// Prompt describes the request behind a cache key, for caches that match on meaning
+// rather than exact keys. Callers attach it to the context with WithPrompt.
+type Prompt struct {
+	// Text is the prompt to compare, e.g. the last user message
+	Text string
+
+	// Model is the model the request was sent to
+	Model string
+
+	// Tenant is the tenant making the request; cached responses are never shared across tenants
+	Tenant string
+
+	// Context identifies everything but Text (earlier messages, parameters).
+	// Only prompts with identical context are compared.
+	Context string
+}
+
+type promptKey struct{}
+
+// WithPrompt attaches the prompt behind a cache lookup to ctx
+func WithPrompt(ctx context.Context, prompt Prompt) context.Context {
+	return context.WithValue(ctx, promptKey{}, prompt)
+}
+
+// PromptFromContext returns the prompt attached with WithPrompt
+func PromptFromContext(ctx context.Context) (Prompt, bool) {
+	prompt, ok := ctx.Value(promptKey{}).(Prompt)
+	return prompt, ok
+}
+
+// Embedder computes embedding vectors for prompts
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface
+type EmbedderFunc func(ctx context.Context, text string) ([]float32, error)
+
+// Embed implements Embedder
+func (f EmbedderFunc) Embed(ctx context.Context, text string) ([]float32, error) {
+	return f(ctx, text)
+}
+
+// resolver is the subset of the model registry used to find the embedding provider
+type resolver interface {
+	Resolve(model string) (provider.Provider, string)
+}
+
+// NewProviderEmbedder returns an Embedder calling the embedding model registered as model
+func NewProviderEmbedder(registry resolver, model string) Embedder {
+	return EmbedderFunc(func(ctx context.Context, text string) ([]float32, error) {
+		prov, rewrite := registry.Resolve(model)
+		if prov == nil {
+			return nil, fmt.Errorf("embedding model not found: %s", model)
+		}
+		upstreamModel := model
+		if rewrite != "" {
+			upstreamModel = rewrite
+		}
+
+		resp, err := prov.SendRequest(ctx, provider.NewEmbeddingsRequest(upstreamModel, text))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Close()
+
+		embedding, err := resp.GetEmbedding()
+		if err != nil {
+			return nil, err
+		}
+		if len(embedding.Data) == 0 {
+			return nil, fmt.Errorf("empty embedding response")
+		}
+		return embedding.Data[0].Embedding, nil
+	})
+}
+
+// VectorStore indexes prompt embeddings by cache key
+type VectorStore interface {
+	// Add indexes vector for key within scope
+	Add(ctx context.Context, scope, key string, vector []float32) error
+
+	// Nearest returns the key in scope most similar to vector and its cosine similarity.
+	// ok is false if the scope is empty.
+	Nearest(ctx context.Context, scope string, vector []float32) (key string, similarity float64, ok bool, err error)
+
+	// Remove drops key from the index
+	Remove(ctx context.Context, key string) error
+
+	// Clear drops every key from the index
+	Clear(ctx context.Context) error
+}
+
+// SemanticScope controls which prompts are compared with each other
+type SemanticScope int
+
+const (
+	// ScopePerModel only compares prompts sent to the same model
+	ScopePerModel SemanticScope = iota
+	// ScopeGlobal compares prompts across all models
+	ScopeGlobal
+)
+
+// SemanticConfig configures a SemanticCache
+type SemanticConfig struct {
+	// Threshold is the minimum cosine similarity for a hit (default DefaultSimilarityThreshold).
+	// Set it high: near-misses in embedding space can be different questions.
+	Threshold float64
+
+	// Scope controls which prompts are compared (default ScopePerModel)
+	Scope SemanticScope
+
+	// DefaultTTL is how long values stored with ttl 0 live, after which their
+	// embeddings are dropped. Match it to the underlying cache's default
+	// (default 5 minutes, as in DefaultConfig).
+	DefaultTTL time.Duration
+}
+
+// embeddingTTL is how long an embedding computed by a missed lookup is kept
+// for the Set that usually follows it
+const embeddingTTL = time.Minute
+
+// pendingEmbedding is the prompt embedding of a missed lookup
+type pendingEmbedding struct {
+	vector  []float32
+	expires time.Time
+}
+
+// SemanticCache serves cached values for prompts that are similar in meaning to
+// earlier ones. Values live in an underlying cache; exact key matches are served
+// from it directly and other lookups fall back to the nearest prompt embedding.
+// Lookups without a Prompt in the context behave like the underlying cache.
+// Embeddings are dropped when their values expire, are deleted or cleared.
+type SemanticCache struct {
+	store    Cache
+	vectors  VectorStore
+	embedder Embedder
+	config   SemanticConfig
+
+	mu         sync.Mutex
+	expires    map[string]time.Time        // When each indexed key's value expires
+	nextExpiry time.Time                   // Earliest of expires and pending
+	pending    map[string]pendingEmbedding // Embeddings of missed lookups, by key
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// Ensure SemanticCache implements Cache
+var _ Cache = (*SemanticCache)(nil)
+
+// NewSemanticCache creates a semantic cache storing values in store and prompt embeddings in vectors
+func NewSemanticCache(store Cache, vectors VectorStore, embedder Embedder, config SemanticConfig) *SemanticCache {
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultSimilarityThreshold
+	}
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = DefaultConfig().DefaultTTL
+	}
+	return &SemanticCache{
+		store:    store,
+		vectors:  vectors,
+		embedder: embedder,
+		config:   config,
+		expires:  make(map[string]time.Time),
+		pending:  make(map[string]pendingEmbedding),
+	}
+}
+
+// Get retrieves the value for key, or for the most similar cached prompt
+func (c *SemanticCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	if value, ok := c.store.Get(ctx, key); ok {
+		c.hits.Add(1)
+		return value, true
+	}
+
+	if value, ok := c.getSimilar(ctx, key); ok {
+		c.hits.Add(1)
+		return value, true
+	}
+	c.misses.Add(1)
+	return nil, false
+}
+
+// getSimilar looks up the value of the nearest prompt above the threshold. On
+// a miss the prompt's embedding is kept for a Set of missKey.
+func (c *SemanticCache) getSimilar(ctx context.Context, missKey string) ([]byte, bool) {
+	prompt, ok := PromptFromContext(ctx)
+	if !ok || prompt.Text == "" {
+		return nil, false
+	}
+	c.dropExpired(ctx)
+
+	// Embedding failures fall back to a miss
+	vector, err := c.embedder.Embed(ctx, prompt.Text)
+	if err != nil {
+		slog.WarnContext(ctx, "semantic cache embedding failed", "error", err)
+		return nil, false
+	}
+
+	key, similarity, ok, err := c.vectors.Nearest(ctx, c.scope(prompt), vector)
+	if err != nil || !ok || similarity < c.config.Threshold {
+		c.keepEmbedding(missKey, vector)
+		return nil, false
+	}
+
+	value, ok := c.store.Get(ctx, key)
+	if !ok {
+		// The value expired or was evicted; drop its stale embedding
+		c.vectors.Remove(ctx, key)
+		c.forget(key)
+		c.keepEmbedding(missKey, vector)
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores value under key and indexes the prompt in the context, if any,
+// reusing the embedding computed by a missed Get of key
+func (c *SemanticCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.store.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	vector, embedded := c.takeEmbedding(key)
+
+	prompt, ok := PromptFromContext(ctx)
+	if !ok || prompt.Text == "" {
+		return nil
+	}
+	c.dropExpired(ctx)
+	if !embedded {
+		var err error
+		if vector, err = c.embedder.Embed(ctx, prompt.Text); err != nil {
+			// The value is still served for exact matches
+			slog.WarnContext(ctx, "semantic cache embedding failed", "error", err)
+			return nil
+		}
+	}
+	if err := c.vectors.Add(ctx, c.scope(prompt), key, vector); err != nil {
+		return err
+	}
+
+	if ttl <= 0 {
+		ttl = c.config.DefaultTTL
+	}
+	c.mu.Lock()
+	c.expires[key] = time.Now().Add(ttl)
+	c.updateNextExpiry(c.expires[key])
+	c.mu.Unlock()
+	return nil
+}
+
+// Delete removes a value from the cache
+func (c *SemanticCache) Delete(ctx context.Context, key string) error {
+	c.vectors.Remove(ctx, key)
+	c.forget(key)
+	return c.store.Delete(ctx, key)
+}
+
+// Clear removes all values and embeddings from the cache
+func (c *SemanticCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	clear(c.expires)
+	clear(c.pending)
+	c.nextExpiry = time.Time{}
+	c.mu.Unlock()
+	return errors.Join(c.vectors.Clear(ctx), c.store.Clear(ctx))
+}
+
+// keepEmbedding keeps the embedding of a missed lookup of key for its Set
+func (c *SemanticCache) keepEmbedding(key string, vector []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(embeddingTTL)
+	c.pending[key] = pendingEmbedding{vector: vector, expires: expires}
+	c.updateNextExpiry(expires)
+}
+
+// takeEmbedding removes and returns the kept embedding of key, if any
+func (c *SemanticCache) takeEmbedding(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	embedding, ok := c.pending[key]
+	delete(c.pending, key)
+	return embedding.vector, ok && time.Now().Before(embedding.expires)
+}
+
+// forget stops tracking the expiry of key
+func (c *SemanticCache) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.expires, key)
+	delete(c.pending, key)
+}
+
+// updateNextExpiry moves the next expiry up to t if it is earlier. The caller
+// holds the lock.
+func (c *SemanticCache) updateNextExpiry(t time.Time) {
+	if c.nextExpiry.IsZero() || t.Before(c.nextExpiry) {
+		c.nextExpiry = t
+	}
+}
+
+// dropExpired removes the embeddings whose values have expired, once the
+// earliest tracked expiry has passed
+func (c *SemanticCache) dropExpired(ctx context.Context) {
+	now := time.Now()
+	c.mu.Lock()
+	if c.nextExpiry.IsZero() || now.Before(c.nextExpiry) {
+		c.mu.Unlock()
+		return
+	}
+	var expired []string
+	c.nextExpiry = time.Time{}
+	for key, expires := range c.expires {
+		if now.Before(expires) {
+			c.updateNextExpiry(expires)
+			continue
+		}
+		expired = append(expired, key)
+		delete(c.expires, key)
+	}
+	for key, embedding := range c.pending {
+		if now.Before(embedding.expires) {
+			c.updateNextExpiry(embedding.expires)
+			continue
+		}
+		delete(c.pending, key)
+	}
+	c.mu.Unlock()
+
+	for _, key := range expired {
+		c.vectors.Remove(ctx, key)
+	}
+}
+
+// Stats returns cache statistics, counting semantic matches as hits
+func (c *SemanticCache) Stats() CacheStats {
+	stats := c.store.Stats()
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	return stats
+}
+
+// scope returns the vector store scope for prompt
+func (c *SemanticCache) scope(prompt Prompt) string {
+	if c.config.Scope == ScopeGlobal {
+		return prompt.Tenant + "\x00" + prompt.Context
+	}
+	return prompt.Tenant + "\x00" + prompt.Model + "\x00" + prompt.Context
+}
+
+// memoryVector is an indexed embedding
+type memoryVector struct {
+	key    string
+	vector []float32
+}
+
+// MemoryVectorStore is an in-memory VectorStore using exhaustive search
+type MemoryVectorStore struct {
+	mu     sync.RWMutex
+	scopes map[string][]memoryVector
+}
+
+// NewMemoryVectorStore creates a new in-memory vector store
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{scopes: make(map[string][]memoryVector)}
+}
+
+// Add implements VectorStore
+func (s *MemoryVectorStore) Add(ctx context.Context, scope, key string, vector []float32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(key)
+	s.scopes[scope] = append(s.scopes[scope], memoryVector{key: key, vector: vector})
+	return nil
+}
+
+// Nearest implements VectorStore
+func (s *MemoryVectorStore) Nearest(ctx context.Context, scope string, vector []float32) (string, float64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var best string
+	bestSimilarity := math.Inf(-1)
+	for _, v := range s.scopes[scope] {
+		if similarity := CosineSimilarity(vector, v.vector); similarity > bestSimilarity {
+			best, bestSimilarity = v.key, similarity
+		}
+	}
+	if best == "" {
+		return "", 0, false, nil
+	}
+	return best, bestSimilarity, true, nil
+}
+
+// Remove implements VectorStore
+func (s *MemoryVectorStore) Remove(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeLocked(key)
+	return nil
+}
+
+// Clear implements VectorStore
+func (s *MemoryVectorStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.scopes)
+	return nil
+}
+
+// removeLocked removes key from every scope (must be called with lock held)
+func (s *MemoryVectorStore) removeLocked(key string) {
+	for scope, vectors := range s.scopes {
+		for i, v := range vectors {
+			if v.key == key {
+				s.scopes[scope] = append(vectors[:i:i], vectors[i+1:]...)
+				break
+			}
+		}
+		if len(s.scopes[scope]) == 0 {
+			delete(s.scopes, scope)
+		}
+	}
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, or 0 if they
+// differ in length or either is zero
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
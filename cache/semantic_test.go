@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wordEmbedder embeds text as a bag of lowercased words hashed into 64 dimensions
+var wordEmbedder = EmbedderFunc(func(ctx context.Context, text string) ([]float32, error) {
+	vector := make([]float32, 64)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || r == '\'')
+	}) {
+		h := fnv.New32a()
+		h.Write([]byte(word))
+		vector[h.Sum32()%64]++
+	}
+	return vector, nil
+})
+
+func newTestSemanticCache(config SemanticConfig) *SemanticCache {
+	return NewSemanticCache(NewLRUCache(DefaultConfig()), NewMemoryVectorStore(), wordEmbedder, config)
+}
+
+func promptContext(text, model string) context.Context {
+	return WithPrompt(context.Background(), Prompt{Text: text, Model: model, Context: "ctx"})
+}
+
+func TestSemanticCache_NearIdenticalPromptHits(t *testing.T) {
+	c := newTestSemanticCache(SemanticConfig{})
+	c.Set(promptContext("What is the capital of France?", "gpt-4"), "key-1", []byte("Paris"), time.Minute)
+
+	value, ok := c.Get(promptContext("what is the capital of france", "gpt-4"), "key-2")
+	if !ok || string(value) != "Paris" {
+		t.Errorf("expected near-identical prompt to hit, got %q, %v", value, ok)
+	}
+
+	if _, ok := c.Get(promptContext("How do I bake sourdough bread?", "gpt-4"), "key-3"); ok {
+		t.Error("expected distinct prompt to miss")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Items != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSemanticCache_Threshold(t *testing.T) {
+	ctx := promptContext("What is the capital of France?", "gpt-4")
+	similar := promptContext("What's the capital of France?", "gpt-4")
+
+	strict := newTestSemanticCache(SemanticConfig{})
+	strict.Set(ctx, "key-1", []byte("Paris"), time.Minute)
+	if _, ok := strict.Get(similar, "key-2"); ok {
+		t.Error("expected reworded prompt to miss at the default threshold")
+	}
+
+	loose := newTestSemanticCache(SemanticConfig{Threshold: 0.7})
+	loose.Set(ctx, "key-1", []byte("Paris"), time.Minute)
+	if _, ok := loose.Get(similar, "key-2"); !ok {
+		t.Error("expected reworded prompt to hit at a lower threshold")
+	}
+}
+
+func TestSemanticCache_Scope(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		scope SemanticScope
+		hit   bool
+	}{
+		{"per model", ScopePerModel, false},
+		{"global", ScopeGlobal, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestSemanticCache(SemanticConfig{Scope: tt.scope})
+			c.Set(promptContext("What is the capital of France?", "gpt-4"), "key-1", []byte("Paris"), time.Minute)
+
+			if _, ok := c.Get(promptContext("What is the capital of France?", "gpt-3.5"), "key-2"); ok != tt.hit {
+				t.Errorf("expected hit=%v for another model, got %v", tt.hit, ok)
+			}
+		})
+	}
+}
+
+func TestSemanticCache_DifferentContextOrTenantMisses(t *testing.T) {
+	c := newTestSemanticCache(SemanticConfig{})
+	c.Set(promptContext("What is the capital of France?", "gpt-4"), "key-1", []byte("Paris"), time.Minute)
+
+	for _, prompt := range []Prompt{
+		{Text: "What is the capital of France?", Model: "gpt-4", Context: "other"},
+		{Text: "What is the capital of France?", Model: "gpt-4", Context: "ctx", Tenant: "tenant-b"},
+	} {
+		if _, ok := c.Get(WithPrompt(context.Background(), prompt), "key-2"); ok {
+			t.Errorf("expected miss for %+v", prompt)
+		}
+	}
+}
+
+func TestSemanticCache_EmbeddingErrorMisses(t *testing.T) {
+	failing := EmbedderFunc(func(ctx context.Context, text string) ([]float32, error) {
+		return nil, errors.New("embedding provider unavailable")
+	})
+	c := NewSemanticCache(NewLRUCache(DefaultConfig()), NewMemoryVectorStore(), failing, SemanticConfig{})
+
+	ctx := promptContext("What is the capital of France?", "gpt-4")
+	if err := c.Set(ctx, "key-1", []byte("Paris"), time.Minute); err != nil {
+		t.Fatalf("expected Set to succeed without an embedding, got %v", err)
+	}
+	if _, ok := c.Get(promptContext("what is the capital of france", "gpt-4"), "key-2"); ok {
+		t.Error("expected a miss when embedding fails")
+	}
+	// Exact keys are still served
+	if value, ok := c.Get(ctx, "key-1"); !ok || string(value) != "Paris" {
+		t.Errorf("expected exact key to hit, got %q, %v", value, ok)
+	}
+}
+
+func TestSemanticCache_ExpiredEntryMisses(t *testing.T) {
+	c := newTestSemanticCache(SemanticConfig{})
+	c.Set(promptContext("What is the capital of France?", "gpt-4"), "key-1", []byte("Paris"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get(promptContext("what is the capital of france", "gpt-4"), "key-2"); ok {
+		t.Error("expected expired entry to miss")
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{2, 0}); got != 1 {
+		t.Errorf("expected 1 for parallel vectors, got %f", got)
+	}
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("expected 0 for orthogonal vectors, got %f", got)
+	}
+	if got := CosineSimilarity([]float32{1}, []float32{1, 0}); got != 0 {
+		t.Errorf("expected 0 for mismatched lengths, got %f", got)
+	}
+}
+
+// countingEmbedder wraps wordEmbedder, counting its calls
+type countingEmbedder struct {
+	calls int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	return wordEmbedder(ctx, text)
+}
+
+func TestSemanticCache_MissEmbedsOnce(t *testing.T) {
+	embedder := &countingEmbedder{}
+	c := NewSemanticCache(NewLRUCache(DefaultConfig()), NewMemoryVectorStore(), embedder, SemanticConfig{})
+
+	ctx := promptContext("What is the capital of France?", "gpt-4")
+	if _, ok := c.Get(ctx, "key-1"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	c.Set(ctx, "key-1", []byte("Paris"), time.Minute)
+	if embedder.calls != 1 {
+		t.Errorf("expected the lookup's embedding to be reused, embedded %d times", embedder.calls)
+	}
+	if value, ok := c.Get(promptContext("what is the capital of france", "gpt-4"), "key-2"); !ok || string(value) != "Paris" {
+		t.Errorf("expected a semantic hit, got %q, %v", value, ok)
+	}
+}
+
+func TestSemanticCache_ClearDropsEmbeddings(t *testing.T) {
+	vectors := NewMemoryVectorStore()
+	c := NewSemanticCache(NewLRUCache(DefaultConfig()), vectors, wordEmbedder, SemanticConfig{})
+	c.Set(promptContext("What is the capital of France?", "gpt-4"), "key-1", []byte("Paris"), time.Minute)
+
+	if err := c.Clear(context.Background()); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if len(vectors.scopes) != 0 {
+		t.Errorf("expected no embeddings after Clear, got %v", vectors.scopes)
+	}
+}
+
+func TestSemanticCache_ExpiryDropsEmbeddings(t *testing.T) {
+	vectors := NewMemoryVectorStore()
+	c := NewSemanticCache(NewLRUCache(DefaultConfig()), vectors, wordEmbedder, SemanticConfig{})
+	c.Set(promptContext("What is the capital of France?", "gpt-4"), "key-1", []byte("Paris"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	// Any later write sweeps the expired embeddings, whatever its scope
+	c.Set(promptContext("How tall is Everest?", "other-model"), "key-2", []byte("8849m"), time.Minute)
+	if len(vectors.scopes) != 1 {
+		t.Errorf("expected only the live embedding, got %v", vectors.scopes)
+	}
+	if _, ok := c.Get(promptContext("what is the capital of france", "gpt-4"), "key-3"); ok {
+		t.Error("expected expired entry to miss")
+	}
+}
@@ -1,6 +1,7 @@
 package aigateway
 
 import (
+	"context"
 	"net/http"
 	"sync"
 	"time"
@@ -65,3 +66,93 @@ func (c *Context) Get(key string) any {
 	defer c.mu.RUnlock()
 	return c.Metadata[key]
 }
+
+// contextKey is the type of context keys defined by this package
+type contextKey int
+
+const (
+	routeInfoKey contextKey = iota
+	requestIDKey
+	requestSummaryKey
+	clientIPKey
+)
+
+// RouteInfo describes how a request was routed to a provider
+type RouteInfo struct {
+	Provider      string // Name of the provider serving the request
+	OriginalModel string // Model requested by the client
+	Model         string // Model sent upstream, after any rewrite
+}
+
+// Rewritten reports whether the model was rewritten
+func (ri RouteInfo) Rewritten() bool {
+	return ri.Model != ri.OriginalModel
+}
+
+// WithRouteInfo returns a copy of ctx carrying the route info
+func WithRouteInfo(ctx context.Context, info RouteInfo) context.Context {
+	return context.WithValue(ctx, routeInfoKey, info)
+}
+
+// RouteInfoFromContext returns the route info stored in ctx
+func RouteInfoFromContext(ctx context.Context) (RouteInfo, bool) {
+	info, ok := ctx.Value(routeInfoKey).(RouteInfo)
+	return info, ok
+}
+
+// ProviderNameFromContext returns the name of the provider serving the request,
+// or an empty string if the request hasn't been routed yet
+func ProviderNameFromContext(ctx context.Context) string {
+	info, _ := RouteInfoFromContext(ctx)
+	return info.Provider
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithClientIP returns a copy of ctx carrying the client's IP address, as
+// resolved from the connection and any trusted proxy headers
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIPFromContext returns the client IP address stored in ctx, or an empty string
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// TenantIDFromContext returns the tenant ID set by authentication hooks,
+// or an empty string if the request is unauthenticated
+func TenantIDFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value("tenant_id").(string)
+	return tenantID
+}
+
+// RequestSummary is filled in by the handlers with what they learn about a
+// request, for middleware wrapping them such as access logging
+type RequestSummary struct {
+	RequestID string
+	TenantID  string
+	Model     string // Model requested by the client
+	Provider  string
+}
+
+// WithRequestSummary returns a copy of ctx carrying summary for the handlers to fill in
+func WithRequestSummary(ctx context.Context, summary *RequestSummary) context.Context {
+	return context.WithValue(ctx, requestSummaryKey, summary)
+}
+
+// RequestSummaryFromContext returns the summary stored in ctx, or nil
+func RequestSummaryFromContext(ctx context.Context) *RequestSummary {
+	summary, _ := ctx.Value(requestSummaryKey).(*RequestSummary)
+	return summary
+}
@@ -1,6 +1,7 @@
 package aigateway
 
 import (
+	"context"
 	"net/http"
 	"testing"
 )
@@ -35,3 +36,26 @@ func TestContextSetGet(t *testing.T) {
 		t.Errorf("expected nil, got '%v'", val)
 	}
 }
+
+func TestRouteInfoContext(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := RouteInfoFromContext(ctx); ok {
+		t.Error("expected no route info in empty context")
+	}
+	if name := ProviderNameFromContext(ctx); name != "" {
+		t.Errorf("expected empty provider name, got '%s'", name)
+	}
+
+	ctx = WithRouteInfo(ctx, RouteInfo{Provider: "openai", OriginalModel: "gpt-4", Model: "gpt-4o"})
+
+	info, ok := RouteInfoFromContext(ctx)
+	if !ok {
+		t.Fatal("expected route info in context")
+	}
+	if info.OriginalModel != "gpt-4" || info.Model != "gpt-4o" || !info.Rewritten() {
+		t.Errorf("unexpected route info %+v", info)
+	}
+	if name := ProviderNameFromContext(ctx); name != "openai" {
+		t.Errorf("expected provider 'openai', got '%s'", name)
+	}
+}
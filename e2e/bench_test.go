@@ -0,0 +1,166 @@
+//go:build bench
+
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/gateway"
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/mock"
+)
+
+// Run with: go test -tags bench -run '^$' -bench . ./e2e
+
+// benchConcurrency is the number of concurrent clients for each benchmark
+var benchConcurrency = []int{1, 8, 64}
+
+// Upstream latency knobs. Both default to zero so the gateway hot path dominates,
+// e.g. -bench.ttft=200ms -bench.token-rate=50 simulates a realistic upstream.
+var (
+	benchTTFT      = flag.Duration("bench.ttft", 0, "simulated time to first token")
+	benchTokenRate = flag.Float64("bench.token-rate", 0, "simulated tokens per second (0 = no delay)")
+)
+
+// newBenchServer starts a gateway backed by the simulated mock provider
+func newBenchServer(b *testing.B) *httptest.Server {
+	b.Helper()
+
+	prov := mock.NewProvider(
+		mock.WithTTFT(*benchTTFT),
+		mock.WithTokenRate(*benchTokenRate),
+		mock.WithResponseText("The quick brown fox jumps over the lazy dog and keeps on running through the field."),
+	)
+
+	registry := model.NewMapModelRegistry()
+	registry.RegisterWithOptions("gpt-4", prov, model.WithPreferredAPI(provider.APITypeChatCompletions))
+
+	gw := gateway.New(
+		gateway.WithModelRegistry(registry),
+		gateway.WithHooks(hook.NewRegistry()),
+	)
+	server := httptest.NewServer(gw)
+	b.Cleanup(server.Close)
+	return server
+}
+
+// runLoad issues b.N requests from concurrency workers and reports
+// throughput and latency percentiles
+func runLoad(b *testing.B, concurrency int, do func() error) {
+	b.Helper()
+	b.ReportAllocs()
+
+	var (
+		next      atomic.Int64
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, b.N)
+		wg        sync.WaitGroup
+	)
+
+	b.ResetTimer()
+	start := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next.Add(1) <= int64(b.N) {
+				reqStart := time.Now()
+				if err := do(); err != nil {
+					b.Error(err)
+					return
+				}
+				elapsed := time.Since(reqStart)
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	b.StopTimer()
+
+	if len(latencies) == 0 {
+		return
+	}
+	slices.Sort(latencies)
+	b.ReportMetric(float64(len(latencies))/time.Since(start).Seconds(), "req/s")
+	b.ReportMetric(percentile(latencies, 0.50), "p50-ms")
+	b.ReportMetric(percentile(latencies, 0.95), "p95-ms")
+	b.ReportMetric(percentile(latencies, 0.99), "p99-ms")
+}
+
+// percentile returns the p-th percentile of sorted latencies in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	i := int(float64(len(sorted)-1) * p)
+	return float64(sorted[i]) / float64(time.Millisecond)
+}
+
+// postChat sends a chat completion request and drains the response body
+func postChat(client *http.Client, url string, body []byte, stream bool) error {
+	resp, err := client.Post(url+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, data)
+	}
+
+	if !stream {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	// Read SSE frames until the terminating [DONE] event
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if scanner.Text() == "data: [DONE]" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream ended without [DONE]")
+}
+
+func benchmarkChat(b *testing.B, stream bool) {
+	server := newBenchServer(b)
+	body := []byte(fmt.Sprintf(`{"model":"gpt-4","stream":%t,"messages":[{"role":"user","content":"Tell me a story"}]}`, stream))
+
+	for _, concurrency := range benchConcurrency {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: concurrency}}
+			defer client.CloseIdleConnections()
+
+			runLoad(b, concurrency, func() error {
+				return postChat(client, server.URL, body, stream)
+			})
+		})
+	}
+}
+
+// BenchmarkGateway_ChatCompletions measures the non-streaming chat path
+func BenchmarkGateway_ChatCompletions(b *testing.B) {
+	benchmarkChat(b, false)
+}
+
+// BenchmarkGateway_ChatCompletionsStream measures the streaming chat path including SSE framing
+func BenchmarkGateway_ChatCompletionsStream(b *testing.B) {
+	benchmarkChat(b, true)
+}
@@ -265,6 +265,50 @@ func TestE2E_ChatCompletions_StreamingFinishReason(t *testing.T) {
 	assert.Equal(t, "stop", lastFinishReason, "should receive finish_reason 'stop'")
 }
 
+// TestE2E_ChatCompletions_StreamingIncludeUsage tests the final usage chunk
+func TestE2E_ChatCompletions_StreamingIncludeUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	env := NewTestEnvironment(t)
+
+	stream, err := env.Client.CreateChatCompletionStream(
+		context.Background(),
+		openailib.ChatCompletionRequest{
+			Model: "gpt-4",
+			Messages: []openailib.ChatCompletionMessage{
+				{
+					Role:    openailib.ChatMessageRoleUser,
+					Content: "Hello",
+				},
+			},
+			StreamOptions: &openailib.StreamOptions{IncludeUsage: true},
+		},
+	)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var usage *openailib.Usage
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		if chunk.Usage != nil {
+			assert.Empty(t, chunk.Choices, "usage chunk should have no choices")
+			usage = chunk.Usage
+		}
+	}
+
+	require.NotNil(t, usage, "should receive a usage chunk before [DONE]")
+	assert.Greater(t, usage.PromptTokens, 0)
+	assert.Greater(t, usage.CompletionTokens, 0)
+	assert.Equal(t, usage.PromptTokens+usage.CompletionTokens, usage.TotalTokens)
+}
+
 // TestE2E_ChatCompletions_StreamingContextCancel tests context cancellation during streaming
 func TestE2E_ChatCompletions_StreamingContextCancel(t *testing.T) {
 	if testing.Short() {
@@ -10,6 +10,7 @@ import (
 	"github.com/deeplooplabs/ai-gateway/gateway"
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/openresponses"
 	"github.com/deeplooplabs/ai-gateway/provider"
 	openai "github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/require"
@@ -61,6 +62,7 @@ func NewTestEnvironment(t *testing.T) *TestEnvironment {
 	gw := gateway.New(
 		gateway.WithModelRegistry(registry),
 		gateway.WithHooks(hooks),
+		gateway.WithResponseStore(openresponses.NewMemoryResponseStore()),
 	)
 
 	// Start test server
@@ -175,16 +177,19 @@ func ValidateStreamingResponse(t *testing.T, stream *openai.ChatCompletionStream
 		require.NotEmpty(t, chunk.ID, "chunk should have ID")
 		require.Equal(t, "chat.completion.chunk", chunk.Object, "chunk object should be chat.completion.chunk")
 		require.NotEmpty(t, chunk.Model, "chunk should have model")
-		require.NotEmpty(t, chunk.Choices, "chunk should have choices")
+
+		// The final usage chunk of a stream has no choices
+		if len(chunk.Choices) == 0 {
+			require.NotNil(t, chunk.Usage, "chunk without choices should carry usage")
+			continue
+		}
 
 		// Accumulate content
-		if len(chunk.Choices) > 0 {
-			choice := chunk.Choices[0]
-			accumulated.WriteString(choice.Delta.Content)
+		choice := chunk.Choices[0]
+		accumulated.WriteString(choice.Delta.Content)
 
-			if choice.FinishReason != "" {
-				finishReason = string(choice.FinishReason)
-			}
+		if choice.FinishReason != "" {
+			finishReason = string(choice.FinishReason)
 		}
 	}
 
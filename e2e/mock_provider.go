@@ -25,6 +25,9 @@ type E2EMockProvider struct {
 	streamChunks      [][]byte
 	streamDelay       time.Duration
 
+	// Last request received
+	lastRequest *provider.Request
+
 	// Error simulation
 	shouldError bool
 	errorMsg    string
@@ -53,6 +56,8 @@ func (m *E2EMockProvider) SendRequest(ctx context.Context, req *provider.Request
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.lastRequest = req
+
 	// Check for error simulation
 	if m.shouldError {
 		return nil, fmt.Errorf("%s", m.errorMsg)
@@ -253,6 +258,13 @@ func (m *E2EMockProvider) getDefaultStreamChunks() [][]byte {
 
 // Configuration methods (thread-safe)
 
+// LastRequest returns the last request received
+func (m *E2EMockProvider) LastRequest() *provider.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastRequest
+}
+
 // SetChatResponse sets the chat completion response
 func (m *E2EMockProvider) SetChatResponse(resp *openai.ChatCompletionResponse) {
 	m.mu.Lock()
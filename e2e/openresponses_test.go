@@ -220,6 +220,72 @@ func TestE2E_OpenResponses_ErrorResponse(t *testing.T) {
 	assert.Equal(t, "not_found", errorObj["type"])
 }
 
+// TestE2E_OpenResponses_PreviousResponseID tests continuing a stored response
+func TestE2E_OpenResponses_PreviousResponseID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	env := NewTestEnvironment(t)
+
+	postResponse := func(req openresponses.CreateRequest) *http.Response {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		resp, err := http.Post(env.Server.URL+"/v1/responses", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	// First turn, stored
+	store := true
+	resp := postResponse(openresponses.CreateRequest{
+		Model: "gpt-4",
+		Input: "My name is Alice.",
+		Store: &store,
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var first openresponses.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&first))
+	require.NotEmpty(t, first.ID)
+	assert.True(t, first.Store, "stored response should report store: true")
+
+	// Second turn continues the first
+	resp2 := postResponse(openresponses.CreateRequest{
+		Model:              "gpt-4",
+		Input:              "What's my name?",
+		PreviousResponseID: first.ID,
+	})
+	defer resp2.Body.Close()
+	require.Equal(t, http.StatusOK, resp2.StatusCode)
+
+	var second openresponses.Response
+	require.NoError(t, json.NewDecoder(resp2.Body).Decode(&second))
+	require.NotNil(t, second.PreviousResponseID)
+	assert.Equal(t, first.ID, *second.PreviousResponseID)
+
+	// The prior turn was prepended to the converted messages
+	lastReq := env.MockProvider.LastRequest()
+	require.NotNil(t, lastReq)
+	require.Len(t, lastReq.Messages, 3)
+	assert.Equal(t, "user", lastReq.Messages[0].Role)
+	assert.Equal(t, "My name is Alice.", lastReq.Messages[0].Content)
+	assert.Equal(t, "assistant", lastReq.Messages[1].Role)
+	assert.NotEmpty(t, lastReq.Messages[1].Content)
+	assert.Equal(t, "user", lastReq.Messages[2].Role)
+	assert.Equal(t, "What's my name?", lastReq.Messages[2].Content)
+
+	// Unknown previous responses are rejected
+	resp3 := postResponse(openresponses.CreateRequest{
+		Model:              "gpt-4",
+		Input:              "Hello",
+		PreviousResponseID: "resp_missing",
+	})
+	defer resp3.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp3.StatusCode)
+}
+
 // ========================================
 // Helper Functions
 // ========================================
@@ -0,0 +1,184 @@
+// Package ensemble provides a provider that sends each chat request to several
+// providers concurrently and merges their completions.
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// Selection defines how the members' completions are combined
+type Selection int
+
+const (
+	// All returns every member's completions as separate choices
+	All Selection = iota
+	// Longest returns the single choice with the longest content
+	Longest
+	// First returns the completion of the first member to succeed and
+	// cancels the rest
+	First
+)
+
+// Config holds ensemble configuration
+type Config struct {
+	Name      string
+	Providers []provider.Provider
+	// Models are optional per-provider model names sent instead of the
+	// requested model, for members that name the model differently
+	Models    []string
+	Selection Selection
+}
+
+// EnsembleProvider fans chat requests out to several providers
+type EnsembleProvider struct {
+	name      string
+	providers []provider.Provider
+	models    []string
+	selection Selection
+}
+
+// New creates a new ensemble provider
+func New(config *Config) (*EnsembleProvider, error) {
+	if config == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+	if len(config.Providers) == 0 {
+		return nil, errors.New("at least one provider is required")
+	}
+
+	models := make([]string, len(config.Providers))
+	copy(models, config.Models)
+	return &EnsembleProvider{
+		name:      config.Name,
+		providers: config.Providers,
+		models:    models,
+		selection: config.Selection,
+	}, nil
+}
+
+// Name returns the provider name
+func (e *EnsembleProvider) Name() string {
+	return e.name
+}
+
+// SupportedAPIs returns Chat Completions, the only API the ensemble merges
+func (e *EnsembleProvider) SupportedAPIs() provider.APIType {
+	return provider.APITypeChatCompletions
+}
+
+// result is one member's outcome
+type result struct {
+	index int
+	resp  *openai.ChatCompletionResponse
+	err   error
+}
+
+// SendRequest sends req to every member concurrently and merges the
+// completions of those that succeed. It fails only if every member fails.
+func (e *EnsembleProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	if req.Stream {
+		return nil, errors.New("ensemble: streaming is not supported")
+	}
+	if req.APIType != provider.APITypeChatCompletions {
+		return nil, fmt.Errorf("ensemble: unsupported API type %v", req.APIType)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result, len(e.providers))
+	for i, p := range e.providers {
+		go func() {
+			resp, err := e.send(ctx, i, p, req)
+			results <- result{index: i, resp: resp, err: err}
+		}()
+	}
+
+	// Collect in member order so merged choices are deterministic
+	responses := make([]*openai.ChatCompletionResponse, len(e.providers))
+	var errs []error
+	for range e.providers {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.providers[r.index].Name(), r.err))
+			continue
+		}
+		if e.selection == First {
+			return provider.NewChatCompletionResponse(r.resp), nil
+		}
+		responses[r.index] = r.resp
+	}
+
+	merged := merge(responses, e.selection)
+	if merged == nil {
+		return nil, fmt.Errorf("ensemble: every provider failed: %w", errors.Join(errs...))
+	}
+	return provider.NewChatCompletionResponse(merged), nil
+}
+
+// send sends a copy of req to member i
+func (e *EnsembleProvider) send(ctx context.Context, i int, p provider.Provider, req *provider.Request) (*openai.ChatCompletionResponse, error) {
+	member, err := req.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("clone request: %w", err)
+	}
+	if e.models[i] != "" {
+		member.Model = e.models[i]
+	}
+
+	resp, err := p.SendRequest(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+
+	chatResp, err := resp.GetChatCompletion()
+	if err != nil {
+		return nil, err
+	}
+	if chatResp == nil {
+		return nil, errors.New("nil response")
+	}
+	return chatResp, nil
+}
+
+// merge combines the successful responses, skipping nil ones, and returns
+// nil if there are none. Usage is summed over every member that answered.
+func merge(responses []*openai.ChatCompletionResponse, selection Selection) *openai.ChatCompletionResponse {
+	var merged *openai.ChatCompletionResponse
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if merged == nil {
+			first := *resp
+			first.Choices = nil
+			first.Usage = openai.Usage{}
+			merged = &first
+		}
+		for _, choice := range resp.Choices {
+			choice.Index = len(merged.Choices)
+			merged.Choices = append(merged.Choices, choice)
+		}
+		merged.Usage.PromptTokens += resp.Usage.PromptTokens
+		merged.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		merged.Usage.TotalTokens += resp.Usage.TotalTokens
+	}
+
+	if merged != nil && selection == Longest && len(merged.Choices) > 1 {
+		longest := merged.Choices[0]
+		for _, choice := range merged.Choices[1:] {
+			if len(choice.Message.Content) > len(longest.Message.Content) {
+				longest = choice
+			}
+		}
+		longest.Index = 0
+		merged.Choices = []openai.Choice{longest}
+	}
+	return merged
+}
@@ -0,0 +1,146 @@
+package ensemble
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// stubProvider answers with content after delay, or fails with err
+type stubProvider struct {
+	name    string
+	content string
+	delay   time.Duration
+	err     error
+	model   string
+}
+
+func (s *stubProvider) Name() string {
+	return s.name
+}
+
+func (s *stubProvider) SupportedAPIs() provider.APIType {
+	return provider.APITypeChatCompletions
+}
+
+func (s *stubProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	s.model = req.Model
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return provider.NewChatCompletionResponse(&openai.ChatCompletionResponse{
+		ID:      "chatcmpl-" + s.name,
+		Object:  "chat.completion",
+		Model:   req.Model,
+		Choices: []openai.Choice{{Message: openai.Message{Role: "assistant", Content: s.content}, FinishReason: "stop"}},
+		Usage:   openai.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+	}), nil
+}
+
+func sendEnsemble(t *testing.T, config *Config) (*openai.ChatCompletionResponse, error) {
+	t.Helper()
+	e, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create ensemble: %v", err)
+	}
+	resp, err := e.SendRequest(context.Background(), provider.NewChatCompletionsRequest("gpt-4", []openai.Message{{Role: "user", Content: "Hello"}}))
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetChatCompletion()
+}
+
+func TestEnsemble_MergesChoices(t *testing.T) {
+	a := &stubProvider{name: "a", content: "from a", delay: 20 * time.Millisecond}
+	b := &stubProvider{name: "b", content: "from b"}
+	c := &stubProvider{name: "c", content: "from c", delay: 10 * time.Millisecond}
+
+	resp, err := sendEnsemble(t, &Config{
+		Name:      "ensemble",
+		Providers: []provider.Provider{a, b, c},
+		Models:    []string{"", "claude-3", ""},
+	})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+
+	if len(resp.Choices) != 3 {
+		t.Fatalf("expected 3 choices, got %d", len(resp.Choices))
+	}
+	for i, want := range []string{"from a", "from b", "from c"} {
+		if resp.Choices[i].Index != i || resp.Choices[i].Message.Content != want {
+			t.Errorf("choice %d: expected %q, got %+v", i, want, resp.Choices[i])
+		}
+	}
+	if resp.Usage.TotalTokens != 21 {
+		t.Errorf("expected summed usage of 21 tokens, got %d", resp.Usage.TotalTokens)
+	}
+	if a.model != "gpt-4" || b.model != "claude-3" {
+		t.Errorf("expected per-provider models, got %q and %q", a.model, b.model)
+	}
+}
+
+func TestEnsemble_PartialFailure(t *testing.T) {
+	resp, err := sendEnsemble(t, &Config{
+		Name: "ensemble",
+		Providers: []provider.Provider{
+			&stubProvider{name: "a", content: "from a"},
+			&stubProvider{name: "b", err: errors.New("upstream down")},
+			&stubProvider{name: "c", content: "from c"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected the successful members' choices, got %v", err)
+	}
+	if len(resp.Choices) != 2 || resp.Choices[1].Message.Content != "from c" {
+		t.Errorf("expected 2 choices, got %+v", resp.Choices)
+	}
+
+	_, err = sendEnsemble(t, &Config{
+		Name: "ensemble",
+		Providers: []provider.Provider{
+			&stubProvider{name: "a", err: errors.New("upstream down")},
+			&stubProvider{name: "b", err: errors.New("rate limited")},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error when every provider fails")
+	}
+}
+
+func TestEnsemble_Selection(t *testing.T) {
+	providers := func() []provider.Provider {
+		return []provider.Provider{
+			&stubProvider{name: "slow", content: "a much longer answer", delay: 50 * time.Millisecond},
+			&stubProvider{name: "fast", content: "short"},
+		}
+	}
+
+	resp, err := sendEnsemble(t, &Config{Name: "ensemble", Providers: providers(), Selection: Longest})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "a much longer answer" || resp.Choices[0].Index != 0 {
+		t.Errorf("expected the longest choice, got %+v", resp.Choices)
+	}
+	if resp.Usage.TotalTokens != 14 {
+		t.Errorf("expected usage summed over both members, got %d", resp.Usage.TotalTokens)
+	}
+
+	resp, err = sendEnsemble(t, &Config{Name: "ensemble", Providers: providers(), Selection: First})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "short" {
+		t.Errorf("expected the first completion, got %+v", resp.Choices)
+	}
+}
@@ -22,6 +22,11 @@ func (e *GatewayError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Type, e.Message)
 }
 
+// Unwrap returns the inner error
+func (e *GatewayError) Unwrap() error {
+	return e.InnerError
+}
+
 // OpenAIErrorResponse represents the error response format compatible with OpenAI
 type OpenAIErrorResponse struct {
 	Error *OpenAIErrorDetail `json:"error"`
@@ -102,3 +107,13 @@ func NewServerError(message string, inner error) *GatewayError {
 		InnerError: inner,
 	}
 }
+
+// NewTimeoutError creates a new gateway timeout error (504)
+func NewTimeoutError(message string, inner error) *GatewayError {
+	return &GatewayError{
+		Code:       http.StatusGatewayTimeout,
+		Message:    message,
+		Type:       "timeout_error",
+		InnerError: inner,
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/deeplooplabs/ai-gateway/gateway"
@@ -100,8 +102,10 @@ func main() {
 	// )
 
 	// Create hooks
-	hooks := hook.NewRegistry()
-	hooks.Register(&LoggingHook{}, &AuthenticateHook{}, &ErrorHook{})
+	hooks := hook.NewRegistry(hook.WithAsync(2))
+	hooks.Register(&AuthenticateHook{})
+	// Logging is slow and can't fail a request, so keep it off the request path
+	hooks.RegisterAsync(&LoggingHook{}, &ErrorHook{})
 
 	// Create gateway
 	gw := gateway.New(
@@ -111,8 +115,25 @@ func main() {
 	)
 
 	// Start server
-	slog.Info("AI Gateway listening on :8083")
-	log.Fatal(http.ListenAndServe(":8083", gw))
+	server := gateway.NewServer(":8083", gw)
+	go func() {
+		slog.Info("AI Gateway listening on :8083")
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+
+	// Drain in-flight requests on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	slog.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Shutdown failed", "error", err)
+	}
 }
 
 type AuthenticateHook struct{}
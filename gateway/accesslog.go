@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+)
+
+// maxPeekBody is the largest request body read up front to find the model
+const maxPeekBody = 64 << 10
+
+// AccessLog wraps next to log every request to logger once it has been served,
+// with its method, path, model, status, bytes written, duration and tenant.
+// Streams are logged when they end, noting whether the client disconnected.
+func AccessLog(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		summary := &ai_gateway.RequestSummary{Model: peekModel(r)}
+		r = r.WithContext(ai_gateway.WithRequestSummary(r.Context(), summary))
+		lw := &accessLogWriter{ResponseWriter: w}
+
+		next.ServeHTTP(lw, r)
+
+		status := lw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("model", summary.Model),
+			slog.Int("status", status),
+			slog.Int64("bytes", lw.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("tenant_id", summary.TenantID),
+		}
+		if summary.Provider != "" {
+			attrs = append(attrs, slog.String("provider", summary.Provider))
+		}
+		if summary.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", summary.RequestID))
+		}
+		if strings.HasPrefix(lw.Header().Get("Content-Type"), "text/event-stream") {
+			attrs = append(attrs,
+				slog.Bool("stream", true),
+				slog.Bool("client_disconnected", r.Context().Err() != nil),
+			)
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+	})
+}
+
+// peekModel returns the model named in a small JSON request body, leaving the
+// body intact for the handler. Large, chunked or non-JSON bodies are not read.
+func peekModel(r *http.Request) string {
+	if r.Body == nil || r.ContentLength <= 0 || r.ContentLength > maxPeekBody {
+		return ""
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return ""
+	}
+
+	data, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	var body struct {
+		Model string `json:"model"`
+	}
+	json.Unmarshal(data, &body)
+	return body.Model
+}
+
+// accessLogWriter records the status and size of a response
+type accessLogWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *accessLogWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for streaming handlers
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer does
+func (w *accessLogWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// tenantHook authenticates every request as tenant
+type tenantHook struct {
+	tenant string
+}
+
+func (h tenantHook) Name() string { return "tenant" }
+
+func (h tenantHook) Authenticate(ctx context.Context, apiKey string) (bool, string, error) {
+	return true, h.tenant, nil
+}
+
+// decodeAccessLog returns the single log line written to buf
+func decodeAccessLog(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("expected one access log line, got %d: %s", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(lines[0], &entry); err != nil {
+		t.Fatalf("failed to decode log line: %v", err)
+	}
+	return entry
+}
+
+func TestGateway_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	hooks := hook.NewRegistry()
+	hooks.Register(tenantHook{tenant: "acme"})
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithHooks(hooks),
+		WithAccessLog(slog.New(slog.NewJSONHandler(&buf, nil))),
+	)
+
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	entry := decodeAccessLog(t, &buf)
+	if entry["status"] != float64(http.StatusOK) {
+		t.Errorf("expected status 200, got %v", entry["status"])
+	}
+	if d, _ := entry["duration"].(float64); d <= 0 {
+		t.Errorf("expected a non-zero duration, got %v", entry["duration"])
+	}
+	if entry["method"] != "POST" || entry["path"] != "/v1/chat/completions" {
+		t.Errorf("unexpected method or path: %v", entry)
+	}
+	if entry["model"] != "gpt-4" || entry["tenant_id"] != "acme" || entry["provider"] != "mock" {
+		t.Errorf("expected model, tenant and provider from the handler, got %v", entry)
+	}
+	if entry["bytes"] != float64(w.Body.Len()) {
+		t.Errorf("expected %d bytes, got %v", w.Body.Len(), entry["bytes"])
+	}
+}
+
+func TestGateway_AccessLogError(t *testing.T) {
+	var buf bytes.Buffer
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithAccessLog(slog.New(slog.NewJSONHandler(&buf, nil))),
+	)
+
+	// The model is peeked from the body even though the request fails before routing
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader([]byte(`{"model":"gpt-4"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	entry := decodeAccessLog(t, &buf)
+	if entry["status"] != float64(http.StatusBadRequest) {
+		t.Errorf("expected status 400, got %v", entry["status"])
+	}
+	if entry["model"] != "gpt-4" {
+		t.Errorf("expected the model from the body, got %v", entry["model"])
+	}
+}
@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/handler"
+	"github.com/deeplooplabs/ai-gateway/model"
+)
+
+// requireAdmin wraps an admin endpoint so it needs the admin token, when one is configured
+func (g *Gateway) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.adminToken != "" && !validAdminToken(r, g.adminToken) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAdminError(w, &handler.GatewayError{Code: http.StatusUnauthorized, Message: "invalid admin token", Type: "authentication_error"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// validAdminToken reports whether r carries token as a bearer token
+func validAdminToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// modelsReloadResponse is the body of the admin models reload endpoint
+type modelsReloadResponse struct {
+	Models int `json:"models"`
+}
+
+func (g *Gateway) handleModelsReload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error":{"message":"only POST method is allowed","type":"invalid_request_error"}}`))
+		return
+	}
+
+	reloadable := g.modelRegistry.(model.Reloadable)
+	if err := reloadable.Reload(r.Context()); err != nil {
+		writeAdminError(w, fmt.Errorf("failed to reload models: %w", err))
+		return
+	}
+	json.NewEncoder(w).Encode(modelsReloadResponse{Models: len(g.modelRegistry.ListModels())})
+}
@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// reloadableRegistry registers another model on each reload
+type reloadableRegistry struct {
+	*model.MapModelRegistry
+	provider provider.Provider
+	reloads  int
+	err      error
+}
+
+func (r *reloadableRegistry) Reload(ctx context.Context) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.reloads++
+	r.Register(fmt.Sprintf("reloaded-%d", r.reloads), r.provider)
+	return nil
+}
+
+func newReloadableRegistry() *reloadableRegistry {
+	registry := &reloadableRegistry{MapModelRegistry: model.NewMapModelRegistry(), provider: &mockProvider{}}
+	registry.Register("gpt-4", registry.provider)
+	return registry
+}
+
+func postReload(gw *Gateway, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/admin/models/reload", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+	return w
+}
+
+func TestGateway_AdminModelsReload(t *testing.T) {
+	registry := newReloadableRegistry()
+	gw := New(WithModelRegistry(registry), WithAdminToken("admin-secret"))
+
+	w := postReload(gw, "admin-secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if registry.reloads != 1 {
+		t.Errorf("expected 1 reload, got %d", registry.reloads)
+	}
+	var resp modelsReloadResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Models != 2 {
+		t.Errorf("expected 2 models after reload, got %d", resp.Models)
+	}
+
+	// Failures are reported without a count
+	registry.err = errors.New("file not found")
+	if w := postReload(gw, "admin-secret"); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a failed reload, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_AdminModelsReloadAuth(t *testing.T) {
+	registry := newReloadableRegistry()
+	gw := New(WithModelRegistry(registry), WithAdminToken("admin-secret"))
+
+	for _, token := range []string{"", "wrong"} {
+		if w := postReload(gw, token); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for token %q, got %d: %s", token, w.Code, w.Body.String())
+		}
+	}
+	if registry.reloads != 0 {
+		t.Errorf("expected no reloads, got %d", registry.reloads)
+	}
+
+	// Other admin endpoints need the token too
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for stats without a token, got %d", w.Code)
+	}
+
+	// Without an admin token the endpoint isn't served
+	gw = New(WithModelRegistry(registry))
+	if w := postReload(gw, ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without admin auth, got %d", w.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package gateway
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// probeCapabilities probes the provider of every registered model
+// concurrently, caching the results in g.capabilities
+func (g *Gateway) probeCapabilities() {
+	models := make(map[provider.Provider]string)
+	for _, name := range g.modelRegistry.ListModels() {
+		prov, _ := g.modelRegistry.Resolve(name)
+		if prov == nil {
+			continue
+		}
+		if _, ok := models[prov]; !ok {
+			models[prov] = name
+		}
+	}
+
+	var wg sync.WaitGroup
+	for prov, name := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), g.probeTimeout)
+			defer cancel()
+			caps := g.capabilities.Probe(ctx, prov)
+			slog.Debug("probed provider capabilities", "model", name, "provider", prov.Name(),
+				"tools", caps.Tools, "json_mode", caps.JSONMode, "streaming", caps.Streaming)
+		}()
+	}
+	wg.Wait()
+}
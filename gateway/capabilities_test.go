@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/handler"
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// probedProvider reports capabilities through a probe
+type probedProvider struct {
+	mockProvider
+	caps   provider.Capabilities
+	err    error
+	probes int
+}
+
+func (m *probedProvider) ProbeCapabilities(ctx context.Context) (provider.Capabilities, error) {
+	m.probes++
+	return m.caps, m.err
+}
+
+func sendCapabilityRequest(gw *Gateway, body map[string]any) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+	return w
+}
+
+func TestGateway_CapabilityProbe(t *testing.T) {
+	prov := &probedProvider{caps: provider.Capabilities{JSONMode: true}}
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", prov)
+	registry.Register("gpt-4-alias", prov)
+	gw := New(
+		WithModelRegistry(registry),
+		WithCapabilityProbe(time.Second),
+		WithToolsFallback(handler.ToolsReject),
+	)
+
+	if prov.probes != 1 {
+		t.Errorf("expected one probe per provider, got %d", prov.probes)
+	}
+
+	messages := []map[string]string{{"role": "user", "content": "Hello"}}
+	w := sendCapabilityRequest(gw, map[string]any{
+		"model":    "gpt-4",
+		"messages": messages,
+		"tools":    []map[string]any{{"type": "function", "function": map[string]any{"name": "get_weather"}}},
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "does not support tools") {
+		t.Errorf("expected tools to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = sendCapabilityRequest(gw, map[string]any{"model": "gpt-4", "messages": messages, "stream": true})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "does not support streaming") {
+		t.Errorf("expected streaming to be rejected, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = sendCapabilityRequest(gw, map[string]any{"model": "gpt-4", "messages": messages})
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_CapabilityProbeFailure(t *testing.T) {
+	prov := &probedProvider{err: errors.New("connection refused")}
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", prov)
+	gw := New(
+		WithModelRegistry(registry),
+		WithCapabilityProbe(time.Second),
+		WithToolsFallback(handler.ToolsReject),
+	)
+
+	w := sendCapabilityRequest(gw, map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"tools":    []map[string]any{{"type": "function", "function": map[string]any{"name": "get_weather"}}},
+	})
+	if w.Code != http.StatusOK {
+		t.Errorf("expected a failed probe to be permissive, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIP returns the address of the client behind r. Requests from a
+// trusted proxy are attributed to the last X-Forwarded-For address that isn't
+// itself a trusted proxy, so clients sharing a proxy don't share its address.
+func (g *Gateway) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if len(g.proxies) == 0 || !g.trustedProxy(host) {
+		return host
+	}
+
+	// Proxies append the address they received the request from, so the
+	// entries are read from the right, where the trusted proxies added them
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if _, err := netip.ParseAddr(addr); err != nil {
+			break
+		}
+		host = addr
+		if !g.trustedProxy(addr) {
+			break
+		}
+	}
+	return host
+}
+
+// trustedProxy reports whether addr belongs to a trusted proxy
+func (g *Gateway) trustedProxy(addr string) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range g.proxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
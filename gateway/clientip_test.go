@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
+)
+
+func TestGateway_ClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name      string
+		proxies   []netip.Prefix
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{"direct client", proxies, "203.0.113.7:1234", nil, "203.0.113.7"},
+		{"header ignored without trusted proxies", nil, "10.0.0.1:1234", []string{"203.0.113.7"}, "10.0.0.1"},
+		{"header ignored from untrusted peer", proxies, "198.51.100.1:1234", []string{"203.0.113.7"}, "198.51.100.1"},
+		{"client behind proxy", proxies, "10.0.0.1:1234", []string{"203.0.113.7"}, "203.0.113.7"},
+		{"spoofed entries left of the client", proxies, "10.0.0.1:1234", []string{"192.0.2.99, 203.0.113.7"}, "203.0.113.7"},
+		{"chained proxies", proxies, "10.0.0.1:1234", []string{"203.0.113.7, 10.0.0.2"}, "203.0.113.7"},
+		{"split headers", proxies, "10.0.0.1:1234", []string{"203.0.113.7", "10.0.0.2"}, "203.0.113.7"},
+		{"invalid entry", proxies, "10.0.0.1:1234", []string{"203.0.113.7, bogus"}, "10.0.0.1"},
+		{"only proxies", proxies, "10.0.0.1:1234", []string{"10.0.0.3"}, "10.0.0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := New(WithTrustedProxies(tt.proxies))
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := gw.clientIP(req); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGateway_RateLimitsClientsBehindProxy(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithRateLimiter(ratelimit.NewTokenBucket(ratelimit.PerMinute(1))),
+		WithTrustedProxies([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}),
+	)
+
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	send := func(client string) int {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", client)
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	codes := []int{send("203.0.113.7"), send("203.0.113.8"), send("203.0.113.7")}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("expected each client its own bucket (200, 200, 429), got %v", codes)
+	}
+}
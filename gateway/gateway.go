@@ -1,17 +1,27 @@
 package gateway
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
+	"sync"
 	"time"
 
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/audit"
 	"github.com/deeplooplabs/ai-gateway/cache"
 	"github.com/deeplooplabs/ai-gateway/handler"
 	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/loadbalancer"
 	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/openresponses"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/quota"
 	"github.com/deeplooplabs/ai-gateway/ratelimit"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Gateway is the main HTTP handler
@@ -19,10 +29,47 @@ type Gateway struct {
 	modelRegistry model.ModelRegistry
 	hooks         *hook.Registry
 	mux           *http.ServeMux
+	root          http.Handler
 	cors          *CORSConfig
 	metrics       *Metrics
 	cache         cache.Cache
+	cacheTTL      time.Duration
+	cachePricing  cache.Pricing
+	cacheCounters *cache.Counters
 	rateLimiter   ratelimit.Limiter
+	proxies       []netip.Prefix
+	quota         quota.Manager
+	audit         audit.Sink
+	responseStore openresponses.ResponseStore
+	responseHooks []hook.Hook
+	strictConvert bool
+	messageIDs    openresponses.MessageIDFunc
+	sse           handler.SSEConfig
+	maxTokens     handler.OutputTokenDefaults
+	tenantLabel   func(tenantID string) string
+	choices       handler.ChoicesFallback
+	tools         handler.ToolsFallback
+	streams       handler.StreamFallback
+	accessLog     *slog.Logger
+	probeTimeout  time.Duration
+	maxTimeout    time.Duration
+	requestIDHdr  string
+	finishCalls   bool
+	adminToken    string
+	attemptLog    *slog.Logger
+	defaultProv   provider.Provider
+	defaultModel  string
+	degraded      bool
+	schemaCheck   bool
+	capabilities  *provider.CapabilityCache
+	chatHandler   *handler.ChatHandler
+
+	// Graceful shutdown state
+	shutdownMu   sync.Mutex
+	shuttingDown bool
+	inFlight     sync.WaitGroup
+	closeOnce    sync.Once
+	closeErr     error
 }
 
 // New creates a new gateway with default options
@@ -39,8 +86,30 @@ func New(opts ...Option) *Gateway {
 		opt(g)
 	}
 
+	if g.defaultProv != nil {
+		if registry, ok := g.modelRegistry.(model.Defaulter); ok {
+			registry.SetDefault(g.defaultProv, g.defaultModel)
+		} else {
+			slog.Warn("model registry does not support a default model", "provider", g.defaultProv.Name())
+		}
+	}
+	if g.cache != nil {
+		g.cacheCounters = cache.NewCounters()
+	}
+	if g.metrics != nil {
+		g.metrics.TenantLabel = g.tenantLabel
+	}
+	if g.probeTimeout > 0 {
+		g.capabilities = provider.NewCapabilityCache()
+		g.probeCapabilities()
+	}
+
 	// Setup routes
 	g.setupRoutes()
+	g.root = Recover(g.hooks, g.sse, g.mux)
+	if g.accessLog != nil {
+		g.root = AccessLog(g.accessLog, g.root)
+	}
 
 	return g
 }
@@ -48,20 +117,96 @@ func New(opts ...Option) *Gateway {
 func (g *Gateway) setupRoutes() {
 	// OpenResponses endpoint
 	responsesHandler := handler.NewResponsesHandler(g.modelRegistry, g.hooks)
+	if g.audit != nil {
+		responsesHandler.SetAuditSink(g.audit)
+	}
+	if g.responseStore != nil {
+		responsesHandler.SetResponseStore(g.responseStore)
+	}
+	responsesHandler.SetSSEConfig(g.sse)
+	responsesHandler.SetOutputTokenDefaults(g.maxTokens)
+	responsesHandler.SetRateLimiter(g.rateLimiter)
+	responsesHandler.SetMetricsRecorder(g.metricsRecorder())
+	responsesHandler.SetMaxRequestTimeout(g.maxTimeout)
+	responsesHandler.SetStrictConversion(g.strictConvert)
+	responsesHandler.SetSchemaValidation(g.schemaCheck)
+	responsesHandler.SetMessageIDFunc(g.messageIDs)
+	responsesHandler.SetRequestIDHeader(g.requestIDHdr)
+	responsesHandler.SetCompleteOnDisconnect(g.finishCalls)
+	responsesHandler.SetStreamFallback(g.streams)
+	responsesHandler.SetCapabilityCache(g.capabilities)
+	responsesHandler.SetToolsFallback(g.tools)
+	// Background responses are drained on shutdown like requests
+	responsesHandler.SetBackgroundWaitGroup(&g.inFlight)
+	if len(g.responseHooks) > 0 {
+		orHooks := openresponses.NewRegistry(g.hooks)
+		orHooks.Register(g.responseHooks...)
+		responsesHandler.SetOpenResponsesHooks(orHooks)
+	}
 	g.mux.HandleFunc("/v1/responses", responsesHandler.ServeHTTP)
+	g.mux.HandleFunc("/v1/responses/", responsesHandler.ServeResponseByID)
 
 	// Chat Completions (OpenAI-compatible)
 	chatHandler := handler.NewChatHandler(g.modelRegistry, g.hooks)
+	if g.quota != nil {
+		chatHandler.SetQuotaManager(g.quota)
+	}
+	if g.audit != nil {
+		chatHandler.SetAuditSink(g.audit)
+	}
+	chatHandler.SetSSEConfig(g.sse)
+	chatHandler.SetRateLimiter(g.rateLimiter)
+	chatHandler.SetMetricsRecorder(g.metricsRecorder())
+	chatHandler.SetMaxRequestTimeout(g.maxTimeout)
+	chatHandler.SetRequestIDHeader(g.requestIDHdr)
+	chatHandler.SetCompleteOnDisconnect(g.finishCalls)
+	chatHandler.SetReportDegraded(g.degraded)
+	chatHandler.SetChoicesFallback(g.choices)
+	chatHandler.SetToolsFallback(g.tools)
+	chatHandler.SetSchemaValidation(g.schemaCheck)
+	chatHandler.SetCapabilityCache(g.capabilities)
+	if g.cache != nil {
+		chatHandler.SetCache(g.cache, g.cacheTTL)
+		chatHandler.SetCacheRecorder(g.cacheRecorder(), g.cachePricing)
+	}
 	g.mux.HandleFunc("/v1/chat/completions", chatHandler.ServeHTTP)
+	g.chatHandler = chatHandler
 
 	// Embeddings
 	embeddingsHandler := handler.NewEmbeddingsHandler(g.modelRegistry, g.hooks)
+	embeddingsHandler.SetRateLimiter(g.rateLimiter)
+	embeddingsHandler.SetMetricsRecorder(g.metricsRecorder())
+	embeddingsHandler.SetMaxRequestTimeout(g.maxTimeout)
 	g.mux.HandleFunc("/v1/embeddings", embeddingsHandler.ServeHTTP)
 
 	// Images
 	imagesHandler := handler.NewImagesHandler(g.modelRegistry, g.hooks)
+	imagesHandler.SetRateLimiter(g.rateLimiter)
+	imagesHandler.SetMetricsRecorder(g.metricsRecorder())
+	imagesHandler.SetMaxRequestTimeout(g.maxTimeout)
 	g.mux.HandleFunc("/v1/images/generations", imagesHandler.ServeHTTP)
 
+	// Moderations
+	moderationsHandler := handler.NewModerationsHandler(g.modelRegistry, g.hooks)
+	moderationsHandler.SetRateLimiter(g.rateLimiter)
+	moderationsHandler.SetMetricsRecorder(g.metricsRecorder())
+	moderationsHandler.SetMaxRequestTimeout(g.maxTimeout)
+	g.mux.HandleFunc("/v1/moderations", moderationsHandler.ServeHTTP)
+
+	// Audio transcriptions
+	audioHandler := handler.NewAudioTranscriptionsHandler(g.modelRegistry, g.hooks)
+	audioHandler.SetRateLimiter(g.rateLimiter)
+	audioHandler.SetMetricsRecorder(g.metricsRecorder())
+	audioHandler.SetMaxRequestTimeout(g.maxTimeout)
+	g.mux.HandleFunc("/v1/audio/transcriptions", audioHandler.ServeHTTP)
+
+	// Audio speech
+	speechHandler := handler.NewAudioSpeechHandler(g.modelRegistry, g.hooks)
+	speechHandler.SetRateLimiter(g.rateLimiter)
+	speechHandler.SetMetricsRecorder(g.metricsRecorder())
+	speechHandler.SetMaxRequestTimeout(g.maxTimeout)
+	g.mux.HandleFunc("/v1/audio/speech", speechHandler.ServeHTTP)
+
 	// Models
 	modelsHandler := handler.NewModelsHandler(g.modelRegistry)
 	g.mux.HandleFunc("/v1/models", modelsHandler.ServeHTTP)
@@ -69,9 +214,22 @@ func (g *Gateway) setupRoutes() {
 	// Health check
 	g.mux.HandleFunc("/health", g.handleHealth)
 
+	// Gateway statistics
+	g.mux.HandleFunc("/admin/stats", g.requireAdmin(g.handleStats))
+
+	// Cache prefill (if caching enabled and admin auth is configured)
+	if g.cache != nil && g.adminToken != "" {
+		g.mux.HandleFunc("/admin/cache/prefill", g.requireAdmin(g.handleCachePrefill))
+	}
+
+	// Models reload (if the registry can reload and admin auth is configured)
+	if _, ok := g.modelRegistry.(model.Reloadable); ok && g.adminToken != "" {
+		g.mux.HandleFunc("/admin/models/reload", g.requireAdmin(g.handleModelsReload))
+	}
+
 	// Metrics endpoint (if metrics enabled)
 	if g.metrics != nil {
-		g.mux.Handle("/metrics", promhttp.Handler())
+		g.mux.Handle("/metrics", g.metricsHandler())
 	}
 
 	// 404 for unmatched routes
@@ -80,6 +238,13 @@ func (g *Gateway) setupRoutes() {
 
 // ServeHTTP implements http.Handler
 func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Refuse new requests once shutdown has begun and track the rest
+	if !g.beginRequest() {
+		writeShuttingDown(w)
+		return
+	}
+	defer g.inFlight.Done()
+
 	if g.cors != nil {
 		origin := r.Header.Get("Origin")
 
@@ -110,7 +275,13 @@ func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	g.mux.ServeHTTP(w, r)
+	// Providers forward allowlisted client headers upstream
+	ctx := provider.WithClientHeaders(r.Context(), r.Header)
+	r = r.WithContext(ai_gateway.WithClientIP(ctx, g.clientIP(r)))
+	if g.attemptLog != nil {
+		r = r.WithContext(provider.WithAttemptLogging(r.Context(), g.attemptLog))
+	}
+	g.root.ServeHTTP(w, r)
 }
 
 // isOriginAllowed checks if the origin is allowed
@@ -177,8 +348,119 @@ func (g *Gateway) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"ok"}`))
 }
 
+// cacheRecorder returns the recorder for cache outcomes: the stats counters,
+// plus Prometheus when metrics are enabled
+func (g *Gateway) cacheRecorder() cache.Recorder {
+	if g.metrics != nil {
+		return cache.MultiRecorder(g.cacheCounters, g.metrics)
+	}
+	return g.cacheCounters
+}
+
+// statsResponse is the body of the admin stats endpoint
+type statsResponse struct {
+	// Cache is only present when response caching is enabled
+	Cache *cacheStatsResponse `json:"cache,omitempty"`
+	// LoadBalancers holds each load balancer's provider statistics by balancer name
+	LoadBalancers map[string][]loadbalancer.ProviderStats `json:"load_balancers,omitempty"`
+}
+
+// cacheStatsResponse combines hit/miss reporting with the cache's own size statistics
+type cacheStatsResponse struct {
+	cache.RecorderStats
+	Items     uint64 `json:"items"`
+	SizeBytes uint64 `json:"size_bytes"`
+}
+
+func (g *Gateway) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error":{"message":"only GET method is allowed","type":"invalid_request_error"}}`))
+		return
+	}
+
+	resp := statsResponse{LoadBalancers: g.loadBalancerStats()}
+	if g.cache != nil {
+		cacheStats := g.cache.Stats()
+		resp.Cache = &cacheStatsResponse{
+			RecorderStats: g.cacheCounters.Stats(),
+			Items:         cacheStats.Items,
+			SizeBytes:     cacheStats.Size,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PrefillCache stores known chat completions in the response cache for ttl
+// (0 uses the cache TTL), so the first matching request is served from it
+func (g *Gateway) PrefillCache(ctx context.Context, entries []handler.CacheEntry, ttl time.Duration) error {
+	return g.chatHandler.Prefill(ctx, entries, ttl)
+}
+
+// cachePrefillRequest is the body of the admin cache prefill endpoint
+type cachePrefillRequest struct {
+	Entries []handler.CacheEntry `json:"entries"`
+
+	// TTLSeconds overrides the cache TTL for these entries
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+func (g *Gateway) handleCachePrefill(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if g.adminToken == "" {
+		writeAdminError(w, &handler.GatewayError{Code: http.StatusUnauthorized, Message: "cache prefill requires an admin token", Type: "authentication_error"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error":{"message":"only POST method is allowed","type":"invalid_request_error"}}`))
+		return
+	}
+
+	var req cachePrefillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, handler.NewValidationError("invalid request body: "+err.Error()))
+		return
+	}
+	if len(req.Entries) == 0 {
+		writeAdminError(w, handler.NewValidationError("entries is required"))
+		return
+	}
+	if req.TTLSeconds < 0 {
+		writeAdminError(w, handler.NewValidationError("ttl_seconds must not be negative"))
+		return
+	}
+
+	if err := g.PrefillCache(r.Context(), req.Entries, time.Duration(req.TTLSeconds)*time.Second); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]int{"prefilled": len(req.Entries)})
+}
+
+// writeAdminError writes err as an OpenAI-style error response
+func writeAdminError(w http.ResponseWriter, err error) {
+	gwErr, ok := err.(*handler.GatewayError)
+	if !ok {
+		gwErr = &handler.GatewayError{Code: http.StatusInternalServerError, Message: err.Error(), Type: "api_error"}
+	}
+	w.WriteHeader(gwErr.Code)
+	json.NewEncoder(w).Encode(gwErr.ToOpenAIResponse())
+}
+
 func (g *Gateway) handleNotFound(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
 	w.Write([]byte(`{"error":{"message":"Not found","type":"invalid_request_error"}}`))
 }
+
+// metricsRecorder returns the handlers' metrics recorder, or nil when metrics are disabled
+func (g *Gateway) metricsRecorder() handler.MetricsRecorder {
+	if g.metrics == nil {
+		return nil
+	}
+	return g.metrics
+}
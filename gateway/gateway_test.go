@@ -7,11 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/deeplooplabs/ai-gateway/cache"
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/model"
 	"github.com/deeplooplabs/ai-gateway/provider"
 	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
 )
 
 func TestGateway_New(t *testing.T) {
@@ -63,6 +66,34 @@ func TestGateway_ServeHTTP_InvalidPath(t *testing.T) {
 	}
 }
 
+func TestGateway_DefaultModel(t *testing.T) {
+	post := func(gw *Gateway) *httptest.ResponseRecorder {
+		bodyBytes, _ := json.Marshal(map[string]any{
+			"model":    "my-custom-name",
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+		return w
+	}
+
+	w := post(New(WithModelRegistry(setupTestRegistry()), WithDefaultModel(&mockProvider{}, "gpt-4o-mini")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the default model to serve the request, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp openai2.ChatCompletionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Model != "gpt-4o-mini" {
+		t.Errorf("expected the model to be rewritten to gpt-4o-mini, got %q", resp.Model)
+	}
+
+	if w := post(New(WithModelRegistry(setupTestRegistry()))); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a default model, got %d", w.Code)
+	}
+}
+
 func setupTestRegistry() model.ModelRegistry {
 	registry := model.NewMapModelRegistry()
 	mockProv := &mockProvider{}
@@ -141,3 +172,271 @@ func TestGateway_ImagesEndpoint(t *testing.T) {
 		t.Errorf("unexpected status: %d", w.Code)
 	}
 }
+
+func TestGateway_AdminStats(t *testing.T) {
+	lru := cache.NewLRUCache(cache.DefaultConfig())
+	lru.Set(context.Background(), "key", []byte("value"), time.Minute)
+	gw := New(WithCache(lru, time.Minute))
+
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var stats struct {
+		Cache *struct {
+			Hits   uint64 `json:"hits"`
+			Misses uint64 `json:"misses"`
+			Items  uint64 `json:"items"`
+		} `json:"cache"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	if stats.Cache == nil || stats.Cache.Items != 1 || stats.Cache.Hits != 0 || stats.Cache.Misses != 0 {
+		t.Errorf("unexpected cache stats: %s", w.Body.String())
+	}
+}
+
+func TestGateway_AdminStatsWithoutCache(t *testing.T) {
+	gw := New()
+
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "{}\n" {
+		t.Errorf("expected empty stats, got %d: %q", w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_CacheStatsAfterRepeatedRequest(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithCache(cache.NewLRUCache(cache.DefaultConfig()), time.Minute),
+	)
+
+	body, _ := json.Marshal(map[string]any{
+		"model":       "gpt-4",
+		"temperature": 0,
+		"messages":    []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	for _, want := range []string{"MISS", "HIT"} {
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+		if got := w.Header().Get("X-Cache"); got != want {
+			t.Errorf("expected X-Cache %s, got %q (%d: %s)", want, got, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+	var stats struct {
+		Cache struct {
+			Hits   uint64 `json:"hits"`
+			Misses uint64 `json:"misses"`
+		} `json:"cache"`
+	}
+	json.NewDecoder(w.Body).Decode(&stats)
+	if stats.Cache.Hits != 1 || stats.Cache.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %s", w.Body.String())
+	}
+}
+
+func TestGateway_AdminCachePrefill(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithCache(cache.NewLRUCache(cache.DefaultConfig()), time.Minute),
+		WithAdminToken("admin-secret"),
+	)
+	prefillRequest := func(body []byte) *http.Request {
+		req := httptest.NewRequest("POST", "/admin/cache/prefill", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		return req
+	}
+
+	prefill, _ := json.Marshal(map[string]any{
+		"ttl_seconds": 3600,
+		"entries": []map[string]any{{
+			"request": map[string]any{
+				"model":       "gpt-4",
+				"temperature": 0,
+				"messages":    []map[string]string{{"role": "user", "content": "What are your opening hours?"}},
+			},
+			"response": map[string]any{
+				"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": "9am to 5pm"}}},
+			},
+		}},
+	})
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("POST", "/admin/cache/prefill", bytes.NewReader(prefill)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without the admin token, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	gw.ServeHTTP(w, prefillRequest(prefill))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"model":       "gpt-4",
+		"temperature": 0,
+		"messages":    []map[string]string{{"role": "user", "content": "What are your opening hours?"}},
+	})
+	w = httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+	if got := w.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("expected prefilled request to hit, got X-Cache %q (%d: %s)", got, w.Code, w.Body.String())
+	}
+
+	// Invalid entries are rejected
+	w = httptest.NewRecorder()
+	gw.ServeHTTP(w, prefillRequest([]byte(`{"entries":[{"request":{"model":"gpt-4"}}]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid entry, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_AdminCachePrefillTenant(t *testing.T) {
+	hooks := hook.NewRegistry()
+	hooks.Register(tenantHook{tenant: "acme"})
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithHooks(hooks),
+		WithCache(cache.NewLRUCache(cache.DefaultConfig()), time.Minute),
+		WithAdminToken("admin-secret"),
+	)
+
+	chat := map[string]any{
+		"model":       "gpt-4",
+		"temperature": 0,
+		"messages":    []map[string]string{{"role": "user", "content": "What are your opening hours?"}},
+	}
+	prefill, _ := json.Marshal(map[string]any{
+		"entries": []map[string]any{{
+			"tenant_id": "acme",
+			"request":   chat,
+			"response": map[string]any{
+				"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": "9am to 5pm"}}},
+			},
+		}},
+	})
+	req := httptest.NewRequest("POST", "/admin/cache/prefill", bytes.NewReader(prefill))
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The auth hook scopes the request to acme, the entry's tenant
+	body, _ := json.Marshal(chat)
+	req = httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-acme")
+	w = httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+	if got := w.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("expected the tenant's request to hit, got X-Cache %q (%d: %s)", got, w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_AdminCachePrefillRequiresToken(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithCache(cache.NewLRUCache(cache.DefaultConfig()), time.Minute),
+	)
+
+	body := []byte(`{"entries":[{"request":{"model":"gpt-4","temperature":0,"messages":[{"role":"user","content":"Hi"}]},"response":{"choices":[{"message":{"role":"assistant","content":"Hello"}}]}}]}`)
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("POST", "/admin/cache/prefill", bytes.NewReader(body)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without an admin token configured, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	gw.handleCachePrefill(w, httptest.NewRequest("POST", "/admin/cache/prefill", bytes.NewReader(body)))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 from the handler without an admin token, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGateway_RateLimiter(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithRateLimiter(ratelimit.NewTokenBucket(ratelimit.PerMinute(1))),
+	)
+
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	var codes []int
+	for range 2 {
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+		codes = append(codes, w.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("expected 200 then 429, got %v", codes)
+	}
+}
+
+func TestGateway_Moderations(t *testing.T) {
+	var path string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,"categories":{"violence":true},"category_scores":{"violence":0.97}}]}`))
+	}))
+	defer upstream.Close()
+
+	registry := model.NewMapModelRegistry()
+	registry.Register("omni-moderation-latest", provider.NewHTTPProvider(
+		provider.NewProviderConfig("openai").WithBaseURL(upstream.URL).WithAPIType(provider.APITypeModerations)))
+	gw := New(WithModelRegistry(registry))
+
+	body := []byte(`{"model":"omni-moderation-latest","input":"I will hurt you"}`)
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/moderations", bytes.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if path != "/v1/moderations" {
+		t.Errorf("expected the request on /v1/moderations, got %q", path)
+	}
+	var resp openai2.ModerationResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Results) != 1 || !resp.Results[0].Flagged || resp.Results[0].CategoryScores["violence"] != 0.97 {
+		t.Errorf("unexpected moderation result: %+v", resp)
+	}
+}
+
+func TestGateway_AdminStatsLoadBalancers(t *testing.T) {
+	gw := New(WithModelRegistry(setupLoadBalancedRegistry(t)))
+
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var stats struct {
+		LoadBalancers map[string][]struct {
+			Name    string `json:"name"`
+			Healthy bool   `json:"healthy"`
+		} `json:"load_balancers"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	members := stats.LoadBalancers["gpt-4"]
+	if len(members) != 1 || members[0].Name != "mock" || !members[0].Healthy {
+		t.Errorf("unexpected load balancer stats: %s", w.Body.String())
+	}
+}
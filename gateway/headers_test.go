@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// newHeaderUpstream returns a gateway in front of an upstream answering with
+// status and rate-limit headers
+func newHeaderUpstream(t *testing.T, status int) *Gateway {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_upstream")
+		w.Header().Set("x-ratelimit-remaining-requests", "42")
+		w.Header().Set("x-internal-debug", "secret")
+		w.Header().Set("Content-Type", "application/json")
+		if status != http.StatusOK {
+			w.Header().Set("retry-after", "7")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"message":"slow down"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", provider.NewHTTPProvider(provider.NewProviderConfig("openai").WithBaseURL(upstream.URL)))
+	return New(WithModelRegistry(registry))
+}
+
+func sendHeaderRequest(gw *Gateway) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+	return w
+}
+
+func TestGateway_PropagatesUpstreamHeaders(t *testing.T) {
+	w := sendHeaderRequest(newHeaderUpstream(t, http.StatusOK))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Upstream-Request-Id"); got != "req_upstream" {
+		t.Errorf("expected upstream request ID, got %q", got)
+	}
+	if got := w.Header().Get("X-Request-Id"); got == "" || got == "req_upstream" {
+		t.Errorf("expected the gateway's own request ID, got %q", got)
+	}
+	if got := w.Header().Get("X-Ratelimit-Remaining-Requests"); got != "42" {
+		t.Errorf("expected upstream rate limit, got %q", got)
+	}
+	if got := w.Header().Get("X-Internal-Debug"); got != "" {
+		t.Errorf("expected headers outside the allowlist to be dropped, got %q", got)
+	}
+}
+
+func TestGateway_KeepsClientRequestID(t *testing.T) {
+	gw := newHeaderUpstream(t, http.StatusOK)
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("X-Request-ID", "req_client")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if got := w.Header().Values("X-Request-Id"); len(got) != 1 || got[0] != "req_client" {
+		t.Errorf("expected the client's request ID echoed, got %v", got)
+	}
+	if got := w.Header().Get("X-Upstream-Request-Id"); got != "req_upstream" {
+		t.Errorf("expected the upstream request ID apart, got %q", got)
+	}
+}
+
+func TestGateway_PropagatesUpstreamErrorHeaders(t *testing.T) {
+	w := sendHeaderRequest(newHeaderUpstream(t, http.StatusTooManyRequests))
+
+	if w.Code == http.StatusOK {
+		t.Fatalf("expected an error, got 200: %s", w.Body.String())
+	}
+	if got := w.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("expected Retry-After on the error response, got %q", got)
+	}
+	if got := w.Header().Get("X-Ratelimit-Remaining-Requests"); got != "42" {
+		t.Errorf("expected upstream rate limit on the error response, got %q", got)
+	}
+}
+
+func TestGateway_ForwardsAllowlistedClientHeaders(t *testing.T) {
+	var got http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer upstream.Close()
+
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", provider.NewHTTPProvider(provider.NewProviderConfig("openai").
+		WithBaseURL(upstream.URL).
+		WithAPIKey("provider-key").
+		WithForwardHeaders([]string{"OpenAI-Organization", "x-prompt-cache-key", "Authorization"})))
+	gw := New(WithModelRegistry(registry))
+
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer client-key")
+	req.Header.Set("OpenAI-Organization", "org-123")
+	req.Header.Set("X-Prompt-Cache-Key", "session-1")
+	req.Header.Set("X-Not-Allowed", "nope")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got.Get("OpenAI-Organization") != "org-123" || got.Get("X-Prompt-Cache-Key") != "session-1" {
+		t.Errorf("expected allowlisted headers upstream, got %v", got)
+	}
+	if got.Get("X-Not-Allowed") != "" {
+		t.Error("expected headers outside the allowlist not to be forwarded")
+	}
+	if auth := got.Get("Authorization"); auth != "Bearer provider-key" {
+		t.Errorf("expected the provider key upstream, got %q", auth)
+	}
+}
@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/deeplooplabs/ai-gateway/loadbalancer"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// loadBalancers returns the load-balanced providers registered with the
+// gateway, including ones wrapped by another provider, each once
+func (g *Gateway) loadBalancers() []*loadbalancer.LoadBalancedProvider {
+	seen := make(map[*loadbalancer.LoadBalancedProvider]bool)
+	var balancers []*loadbalancer.LoadBalancedProvider
+	for _, prov := range append(g.registeredProviders(), g.defaultProv) {
+		lb := asLoadBalancer(prov)
+		if lb == nil || seen[lb] {
+			continue
+		}
+		seen[lb] = true
+		balancers = append(balancers, lb)
+	}
+	return balancers
+}
+
+// asLoadBalancer returns prov, or the provider it wraps, as a load balancer, if it is one
+func asLoadBalancer(prov provider.Provider) *loadbalancer.LoadBalancedProvider {
+	for prov != nil {
+		if lb, ok := prov.(*loadbalancer.LoadBalancedProvider); ok {
+			return lb
+		}
+		unwrapper, ok := prov.(interface{ Unwrap() provider.Provider })
+		if !ok {
+			return nil
+		}
+		prov = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// loadBalancerStats returns the provider statistics of every load balancer by
+// balancer name, or nil if there are none
+func (g *Gateway) loadBalancerStats() map[string][]loadbalancer.ProviderStats {
+	balancers := g.loadBalancers()
+	if len(balancers) == 0 {
+		return nil
+	}
+	stats := make(map[string][]loadbalancer.ProviderStats, len(balancers))
+	for _, lb := range balancers {
+		stats[lb.Name()] = lb.GetStats()
+	}
+	return stats
+}
+
+// metricsHandler serves the metrics, first refreshing the load balancer
+// gauges so each scrape sees the providers' current state
+func (g *Gateway) metricsHandler() http.Handler {
+	next := g.metrics.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.metrics.ReportLoadBalancers(g.loadBalancers())
+		next.ServeHTTP(w, r)
+	})
+}
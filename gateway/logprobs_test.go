@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+const upstreamLogprobs = `{"content":[{"token":"hi","logprob":-0.1,"bytes":[104,105],"top_logprobs":[]}]}`
+
+// newLogprobsGateway returns a gateway in front of an upstream answering
+// with logprobs, service_tier and system_fingerprint
+func newLogprobsGateway(t *testing.T) *Gateway {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte(`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gpt-4","service_tier":"default","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"role":"assistant","content":"hi"},"logprobs":` + upstreamLogprobs + `}]}` + "\n\n"))
+			w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4","service_tier":"default","system_fingerprint":"fp_1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"logprobs":` + upstreamLogprobs + `,"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", provider.NewHTTPProvider(provider.NewProviderConfig("openai").WithBaseURL(upstream.URL)))
+	return New(WithModelRegistry(registry))
+}
+
+// checkLogprobsFields checks a response or chunk carries the upstream fields
+func checkLogprobsFields(t *testing.T, data []byte) {
+	t.Helper()
+	var resp struct {
+		ServiceTier       string `json:"service_tier"`
+		SystemFingerprint string `json:"system_fingerprint"`
+		Choices           []struct {
+			Logprobs json.RawMessage `json:"logprobs"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("failed to decode %s: %v", data, err)
+	}
+	if resp.ServiceTier != "default" || resp.SystemFingerprint != "fp_1" {
+		t.Errorf("expected service_tier and system_fingerprint, got %s", data)
+	}
+	if len(resp.Choices) != 1 || !strings.Contains(string(resp.Choices[0].Logprobs), `"logprob":-0.1`) {
+		t.Errorf("expected logprobs passed through, got %s", data)
+	}
+}
+
+func TestGateway_PassesLogprobsThrough(t *testing.T) {
+	gw := newLogprobsGateway(t)
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"logprobs": true,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	checkLogprobsFields(t, w.Body.Bytes())
+}
+
+func TestGateway_PassesStreamedLogprobsThrough(t *testing.T) {
+	gw := newLogprobsGateway(t)
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"logprobs": true,
+		"stream":   true,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		checkLogprobsFields(t, []byte(data))
+		return
+	}
+	t.Fatalf("expected a streamed chunk, got %s", w.Body.String())
+}
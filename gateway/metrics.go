@@ -1,21 +1,49 @@
 package gateway
 
 import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/cache"
+	"github.com/deeplooplabs/ai-gateway/handler"
+	"github.com/deeplooplabs/ai-gateway/loadbalancer"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Metrics holds all Prometheus metrics for the gateway
 type Metrics struct {
+	// Registry holds the gateway's collectors, so several gateways can run in one process
+	Registry *prometheus.Registry
+
+	// TenantLabel maps tenant IDs to the tenant label of token metrics. Tenant IDs
+	// can be unbounded, so by default (nil) tokens aren't labeled by tenant.
+	TenantLabel func(tenantID string) string
+
 	RequestsTotal        *prometheus.CounterVec
 	RequestDuration      *prometheus.HistogramVec
+	UpstreamDuration     *prometheus.HistogramVec
 	TokensUsed           *prometheus.CounterVec
 	ErrorsTotal          *prometheus.CounterVec
 	ActiveRequests       prometheus.Gauge
 	CacheHits            *prometheus.CounterVec
 	CacheMisses          *prometheus.CounterVec
+	CacheTokensSaved     *prometheus.CounterVec
+	CacheCostSaved       *prometheus.CounterVec
 	RateLimitExceeded    *prometheus.CounterVec
 	ProviderRequestTotal *prometheus.CounterVec
+
+	// Load balancer provider gauges, refreshed by ReportLoadBalancers
+	ProviderActiveRequests *prometheus.GaugeVec
+	ProviderErrors         *prometheus.GaugeVec
+	ProviderHealthy        *prometheus.GaugeVec
+
+	// providerStatsMu serializes refreshes of the load balancer gauges
+	providerStatsMu sync.Mutex
 }
 
 // NewMetrics creates a new Metrics instance with Prometheus collectors
@@ -24,8 +52,13 @@ func NewMetrics(namespace string) *Metrics {
 		namespace = "aigateway"
 	}
 
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	factory := promauto.With(registry)
+
 	return &Metrics{
-		RequestsTotal: promauto.NewCounterVec(
+		Registry: registry,
+		RequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "requests_total",
@@ -33,7 +66,7 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"method", "endpoint", "status", "model"},
 		),
-		RequestDuration: promauto.NewHistogramVec(
+		RequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Name:      "request_duration_seconds",
@@ -42,15 +75,24 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"method", "endpoint", "model"},
 		),
-		TokensUsed: promauto.NewCounterVec(
+		UpstreamDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "upstream_duration_seconds",
+				Help:      "Time for providers to respond, up to the response headers for streams",
+				Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"endpoint", "provider"},
+		),
+		TokensUsed: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "tokens_used_total",
 				Help:      "Total number of tokens used",
 			},
-			[]string{"model", "type"}, // type: input, output, total
+			[]string{"model", "tenant", "type"}, // type: input, output
 		),
-		ErrorsTotal: promauto.NewCounterVec(
+		ErrorsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "errors_total",
@@ -58,14 +100,14 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"method", "endpoint", "error_type", "model"},
 		),
-		ActiveRequests: promauto.NewGauge(
+		ActiveRequests: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Name:      "active_requests",
 				Help:      "Number of requests currently being processed",
 			},
 		),
-		CacheHits: promauto.NewCounterVec(
+		CacheHits: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "cache_hits_total",
@@ -73,7 +115,7 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"endpoint"},
 		),
-		CacheMisses: promauto.NewCounterVec(
+		CacheMisses: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "cache_misses_total",
@@ -81,7 +123,23 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"endpoint"},
 		),
-		RateLimitExceeded: promauto.NewCounterVec(
+		CacheTokensSaved: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "cache_tokens_saved_total",
+				Help:      "Estimated tokens not sent upstream thanks to cache hits",
+			},
+			[]string{"model", "type"}, // type: input, output
+		),
+		CacheCostSaved: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "cache_cost_saved_total",
+				Help:      "Estimated cost saved by cache hits, per configured pricing",
+			},
+			[]string{"model"},
+		),
+		RateLimitExceeded: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "rate_limit_exceeded_total",
@@ -89,7 +147,7 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"tenant_id", "endpoint"},
 		),
-		ProviderRequestTotal: promauto.NewCounterVec(
+		ProviderRequestTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "provider_requests_total",
@@ -97,5 +155,128 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"provider", "status"},
 		),
+		ProviderActiveRequests: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "provider_active_requests",
+				Help:      "Number of in-flight requests per load-balanced provider",
+			},
+			[]string{"balancer", "provider"},
+		),
+		ProviderErrors: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "provider_errors",
+				Help:      "Number of errors recorded per load-balanced provider",
+			},
+			[]string{"balancer", "provider"},
+		),
+		ProviderHealthy: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "provider_healthy",
+				Help:      "Health status per load-balanced provider (1 = healthy, 0 = unhealthy)",
+			},
+			[]string{"balancer", "provider"},
+		),
 	}
 }
+
+// Handler returns the HTTP handler exposing the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// RecordRequest implements handler.MetricsRecorder
+func (m *Metrics) RecordRequest(method, endpoint, model string, status int, duration time.Duration) {
+	m.RequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(status), model).Inc()
+	m.RequestDuration.WithLabelValues(method, endpoint, model).Observe(duration.Seconds())
+}
+
+// RecordUpstream implements handler.MetricsRecorder
+func (m *Metrics) RecordUpstream(endpoint, provider string, duration time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	m.ProviderRequestTotal.WithLabelValues(provider, status).Inc()
+	m.UpstreamDuration.WithLabelValues(endpoint, provider).Observe(duration.Seconds())
+}
+
+// RecordTokens implements handler.MetricsRecorder
+func (m *Metrics) RecordTokens(model, tenant string, promptTokens, completionTokens int) {
+	label := ""
+	if m.TenantLabel != nil && tenant != "" {
+		label = m.TenantLabel(tenant)
+	}
+	m.TokensUsed.WithLabelValues(model, label, "input").Add(float64(promptTokens))
+	m.TokensUsed.WithLabelValues(model, label, "output").Add(float64(completionTokens))
+}
+
+// Ensure Metrics implements handler.MetricsRecorder
+var _ handler.MetricsRecorder = (*Metrics)(nil)
+
+// TenantAllowlist returns a TenantLabel that labels the given tenants by ID and
+// groups all others as "other", bounding the label's cardinality
+func TenantAllowlist(tenants ...string) func(tenantID string) string {
+	allowed := make(map[string]bool, len(tenants))
+	for _, tenant := range tenants {
+		allowed[tenant] = true
+	}
+	return func(tenantID string) string {
+		if allowed[tenantID] {
+			return tenantID
+		}
+		return "other"
+	}
+}
+
+// RecordProviderStats implements loadbalancer.StatsRecorder
+func (m *Metrics) RecordProviderStats(balancer string, stats loadbalancer.ProviderStats) {
+	m.ProviderActiveRequests.WithLabelValues(balancer, stats.Name).Set(float64(stats.ActiveRequests))
+	m.ProviderErrors.WithLabelValues(balancer, stats.Name).Set(float64(stats.TotalErrors))
+
+	healthy := 0.0
+	if stats.Healthy {
+		healthy = 1
+	}
+	m.ProviderHealthy.WithLabelValues(balancer, stats.Name).Set(healthy)
+}
+
+// ReportLoadBalancers replaces the load balancer gauges with the current
+// statistics of balancers, dropping providers no longer reported
+func (m *Metrics) ReportLoadBalancers(balancers []*loadbalancer.LoadBalancedProvider) {
+	m.providerStatsMu.Lock()
+	defer m.providerStatsMu.Unlock()
+
+	m.ProviderActiveRequests.Reset()
+	m.ProviderErrors.Reset()
+	m.ProviderHealthy.Reset()
+	for _, lb := range balancers {
+		lb.ReportStats(m)
+	}
+}
+
+// Ensure Metrics implements loadbalancer.StatsRecorder
+var _ loadbalancer.StatsRecorder = (*Metrics)(nil)
+
+// RecordHit implements cache.Recorder
+func (m *Metrics) RecordHit(endpoint, model string, saved cache.Savings) {
+	m.CacheHits.WithLabelValues(endpoint).Inc()
+	m.recordSavings(model, saved)
+}
+
+// RecordMiss implements cache.Recorder
+func (m *Metrics) RecordMiss(endpoint, model string) {
+	m.CacheMisses.WithLabelValues(endpoint).Inc()
+}
+
+// recordSavings adds the tokens and cost saved by a request
+func (m *Metrics) recordSavings(model string, saved cache.Savings) {
+	m.CacheTokensSaved.WithLabelValues(model, "input").Add(float64(saved.PromptTokens))
+	m.CacheTokensSaved.WithLabelValues(model, "output").Add(float64(saved.CompletionTokens))
+	m.CacheCostSaved.WithLabelValues(model).Add(saved.Cost)
+}
+
+// Ensure Metrics implements cache.Recorder
+var _ cache.Recorder = (*Metrics)(nil)
@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// scrapeMetrics returns the text exposition served at /metrics
+func scrapeMetrics(t *testing.T, gw *Gateway) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from /metrics, got %d", w.Code)
+	}
+	return w.Body.String()
+}
+
+func TestGateway_Metrics(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupTestRegistry()),
+		WithMetrics("test"),
+	)
+
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	metrics := scrapeMetrics(t, gw)
+	for _, want := range []string{
+		`test_requests_total{endpoint="/v1/chat/completions",method="POST",model="gpt-4",status="200"} 1`,
+		`test_request_duration_seconds_count{endpoint="/v1/chat/completions",method="POST",model="gpt-4"} 1`,
+		`test_upstream_duration_seconds_count{endpoint="/v1/chat/completions",provider="mock"} 1`,
+		`test_provider_requests_total{provider="mock",status="success"} 1`,
+		`test_tokens_used_total{model="gpt-4",tenant="",type="input"} 0`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("expected metrics to contain %s", want)
+		}
+	}
+
+	// Requests failing before routing have no model
+	w = httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/embeddings", strings.NewReader("{")))
+	if want := `test_requests_total{endpoint="/v1/embeddings",method="POST",model="",status="400"} 1`; !strings.Contains(scrapeMetrics(t, gw), want) {
+		t.Errorf("expected metrics to contain %s", want)
+	}
+}
+
+func TestMetrics_TenantLabel(t *testing.T) {
+	gw := New(
+		WithMetrics("tenants"),
+		WithMetricsTenantLabel(TenantAllowlist("acme")),
+	)
+	gw.metrics.RecordTokens("gpt-4", "acme", 10, 5)
+	gw.metrics.RecordTokens("gpt-4", "tenant-1", 1, 1)
+	gw.metrics.RecordTokens("gpt-4", "tenant-2", 1, 1)
+
+	metrics := scrapeMetrics(t, gw)
+	for _, want := range []string{
+		`tenants_tokens_used_total{model="gpt-4",tenant="acme",type="input"} 10`,
+		`tenants_tokens_used_total{model="gpt-4",tenant="acme",type="output"} 5`,
+		`tenants_tokens_used_total{model="gpt-4",tenant="other",type="input"} 2`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("expected metrics to contain %s", want)
+		}
+	}
+	if strings.Contains(metrics, "tenant-1") {
+		t.Error("expected tenants outside the allowlist not to be labeled by ID")
+	}
+}
+
+// setupLoadBalancedRegistry registers gpt-4 as a load balancer over the mock provider
+func setupLoadBalancedRegistry(t *testing.T) model.ModelRegistry {
+	t.Helper()
+	registry := model.NewMapModelRegistry()
+	if err := registry.RegisterGroup("gpt-4", []provider.Provider{&mockProvider{}}, nil); err != nil {
+		t.Fatalf("RegisterGroup failed: %v", err)
+	}
+	return registry
+}
+
+func TestGateway_LoadBalancerMetrics(t *testing.T) {
+	gw := New(
+		WithModelRegistry(setupLoadBalancedRegistry(t)),
+		WithMetrics("lb"),
+	)
+
+	metrics := scrapeMetrics(t, gw)
+	for _, want := range []string{
+		`lb_provider_healthy{balancer="gpt-4",provider="mock"} 1`,
+		`lb_provider_active_requests{balancer="gpt-4",provider="mock"} 0`,
+		`lb_provider_errors{balancer="gpt-4",provider="mock"} 0`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("expected metrics to contain %s", want)
+		}
+	}
+
+	// Balancers no longer reported are dropped
+	gw.metrics.ReportLoadBalancers(nil)
+	w := httptest.NewRecorder()
+	gw.metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if strings.Contains(w.Body.String(), "lb_provider_healthy") {
+		t.Errorf("expected no load balancer gauges, got %s", w.Body.String())
+	}
+}
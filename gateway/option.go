@@ -1,11 +1,18 @@
 package gateway
 
 import (
+	"log/slog"
+	"net/netip"
 	"time"
 
+	"github.com/deeplooplabs/ai-gateway/audit"
 	"github.com/deeplooplabs/ai-gateway/cache"
+	"github.com/deeplooplabs/ai-gateway/handler"
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/openresponses"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/quota"
 	"github.com/deeplooplabs/ai-gateway/ratelimit"
 )
 
@@ -52,6 +59,17 @@ func WithModelRegistry(registry model.ModelRegistry) Option {
 	}
 }
 
+// WithDefaultModel routes requests for models the registry doesn't know to
+// prov instead of answering 404, rewriting the model to modelRewrite if set.
+// The registry must implement model.Defaulter, as MapModelRegistry and
+// ReloadableRegistry do.
+func WithDefaultModel(prov provider.Provider, modelRewrite string) Option {
+	return func(g *Gateway) {
+		g.defaultProv = prov
+		g.defaultModel = modelRewrite
+	}
+}
+
 // WithHooks sets the hook registry
 func WithHooks(hooks *hook.Registry) Option {
 	return func(g *Gateway) {
@@ -66,6 +84,14 @@ func WithHook(h hook.Hook) Option {
 	}
 }
 
+// WithOpenResponsesHook registers a hook from the openresponses package,
+// e.g. an openresponses.StreamingHook run on every /v1/responses stream event
+func WithOpenResponsesHook(h hook.Hook) Option {
+	return func(g *Gateway) {
+		g.responseHooks = append(g.responseHooks, h)
+	}
+}
+
 // WithCORS sets the CORS configuration.
 // Pass nil to disable CORS.
 func WithCORS(cors *CORSConfig) Option {
@@ -74,23 +100,215 @@ func WithCORS(cors *CORSConfig) Option {
 	}
 }
 
-// WithMetrics enables Prometheus metrics collection
+// WithMetrics enables Prometheus metrics collection, served at /metrics
 func WithMetrics(namespace string) Option {
 	return func(g *Gateway) {
 		g.metrics = NewMetrics(namespace)
 	}
 }
 
-// WithCache enables response caching
-func WithCache(cacheImpl cache.Cache) Option {
+// WithMetricsTenantLabel labels token metrics by tenant, mapped through label
+// (e.g. TenantAllowlist) to keep the label's cardinality bounded
+func WithMetricsTenantLabel(label func(tenantID string) string) Option {
+	return func(g *Gateway) {
+		g.tenantLabel = label
+	}
+}
+
+// WithCache enables exact-match caching of deterministic (temperature 0, no tools)
+// non-streaming chat completions for ttl. A zero ttl uses the cache's default.
+func WithCache(cacheImpl cache.Cache, ttl time.Duration) Option {
 	return func(g *Gateway) {
 		g.cache = cacheImpl
+		g.cacheTTL = ttl
 	}
 }
 
-// WithRateLimiter enables rate limiting
+// WithCachePricing sets per-model prices used to estimate the cost saved by cache hits
+func WithCachePricing(pricing cache.Pricing) Option {
+	return func(g *Gateway) {
+		g.cachePricing = pricing
+	}
+}
+
+// WithRateLimiter enables rate limiting per tenant (or client address when
+// unauthenticated), e.g. ratelimit.NewTokenBucket(ratelimit.PerMinute(60)).
+// Requests over the limit get a 429 with Retry-After and never reach a provider.
 func WithRateLimiter(limiter ratelimit.Limiter) Option {
 	return func(g *Gateway) {
 		g.rateLimiter = limiter
 	}
 }
+
+// WithTrustedProxies sets the addresses of reverse proxies in front of the
+// gateway, e.g. netip.MustParsePrefix("10.0.0.0/8"). For requests arriving
+// through them the client IP, which unauthenticated requests are rate limited
+// by, is taken from X-Forwarded-For: the last address that isn't a trusted
+// proxy. Without trusted proxies the header is ignored, as any client can set it.
+func WithTrustedProxies(prefixes []netip.Prefix) Option {
+	return func(g *Gateway) {
+		g.proxies = prefixes
+	}
+}
+
+// WithQuotaManager enables per-tenant token usage recording
+func WithQuotaManager(manager quota.Manager) Option {
+	return func(g *Gateway) {
+		g.quota = manager
+	}
+}
+
+// WithAuditSink enables full request/response audit logging to sink
+func WithAuditSink(sink audit.Sink) Option {
+	return func(g *Gateway) {
+		g.audit = sink
+	}
+}
+
+// WithResponseStore enables store:true and previous_response_id on /v1/responses
+func WithResponseStore(store openresponses.ResponseStore) Option {
+	return func(g *Gateway) {
+		g.responseStore = store
+	}
+}
+
+// WithStrictResponsesConversion rejects /v1/responses requests to Chat
+// Completions-only providers with a 400 listing the features the conversion
+// would drop (e.g. reasoning or image inputs), instead of dropping them
+func WithStrictResponsesConversion() Option {
+	return func(g *Gateway) {
+		g.strictConvert = true
+	}
+}
+
+// WithSchemaValidation checks non-streaming chat and responses answers to
+// requests with a strict json_schema format against the schema, returning a
+// 502 when the provider's output doesn't match
+func WithSchemaValidation() Option {
+	return func(g *Gateway) {
+		g.schemaCheck = true
+	}
+}
+
+// WithMessageIDFunc sets the function generating the IDs of /v1/responses
+// message output items (default openresponses.MessageID). Providers converting
+// responses themselves take it from ProviderConfig.WithMessageIDFunc.
+func WithMessageIDFunc(fn openresponses.MessageIDFunc) Option {
+	return func(g *Gateway) {
+		g.messageIDs = fn
+	}
+}
+
+// WithSSEConfig sets the framing of streamed chat and responses events,
+// e.g. "\r\n" line terminators or an initial ":ok" preamble for strict clients
+func WithSSEConfig(cfg handler.SSEConfig) Option {
+	return func(g *Gateway) {
+		g.sse = cfg
+	}
+}
+
+// WithOutputTokenDefaults sets the max_output_tokens applied to Responses API
+// requests that omit it, per model or as a gateway-wide default
+func WithOutputTokenDefaults(defaults handler.OutputTokenDefaults) Option {
+	return func(g *Gateway) {
+		g.maxTokens = defaults
+	}
+}
+
+// WithChoicesFallback sets how chat requests with n > 1 are served by providers
+// that return a single choice: fanned out into n requests (the default) or rejected
+func WithChoicesFallback(fallback handler.ChoicesFallback) Option {
+	return func(g *Gateway) {
+		g.choices = fallback
+	}
+}
+
+// WithToolsFallback sets how chat and responses requests with tools are served
+// by providers without function calling: sent without the tools (the default)
+// or rejected
+func WithToolsFallback(fallback handler.ToolsFallback) Option {
+	return func(g *Gateway) {
+		g.tools = fallback
+	}
+}
+
+// WithStreamFallback sets how stream:true responses requests are served by
+// providers answering without streaming: replayed as streaming events (the
+// default) or sent as a single response.completed event
+func WithStreamFallback(fallback handler.StreamFallback) Option {
+	return func(g *Gateway) {
+		g.streams = fallback
+	}
+}
+
+// WithCapabilityProbe probes every registered provider implementing
+// provider.CapabilityProber concurrently when the gateway is created, allowing timeout per
+// probe, and validates chat requests against the cached results. Providers
+// that can't be probed, or whose probe fails, are assumed to support everything.
+func WithCapabilityProbe(timeout time.Duration) Option {
+	return func(g *Gateway) {
+		g.probeTimeout = timeout
+	}
+}
+
+// WithMaxRequestTimeout lets clients bound each request with an
+// X-Request-Timeout header (in seconds), clamped to max. Requests that run
+// out of time get a 504, or an error event and [DONE] once streaming.
+func WithMaxRequestTimeout(max time.Duration) Option {
+	return func(g *Gateway) {
+		g.maxTimeout = max
+	}
+}
+
+// WithRequestIDHeader reads request IDs from header instead of X-Request-ID
+// and echoes them in it on chat and responses replies. Without that header,
+// the trace ID of a W3C traceparent is used. With "traceparent" itself, the
+// inbound traceparent is echoed unchanged.
+func WithRequestIDHeader(header string) Option {
+	return func(g *Gateway) {
+		g.requestIDHdr = header
+	}
+}
+
+// WithCompleteOnDisconnect keeps non-streaming provider calls running after
+// the client disconnects when their result would be kept: cacheable chat
+// completions are cached and stored responses are saved, so a retry doesn't
+// pay for the generation again
+func WithCompleteOnDisconnect() Option {
+	return func(g *Gateway) {
+		g.finishCalls = true
+	}
+}
+
+// WithAdminToken requires /admin endpoints to be called with token as a bearer
+// token. POST /admin/models/reload is only served with a token configured.
+func WithAdminToken(token string) Option {
+	return func(g *Gateway) {
+		g.adminToken = token
+	}
+}
+
+// WithAttemptLogging logs every upstream HTTP attempt to logger, with the
+// request ID, provider, attempt number, latency and outcome, so the attempts
+// behind a slow request can be reconstructed. Meant for debugging.
+func WithAttemptLogging(logger *slog.Logger) Option {
+	return func(g *Gateway) {
+		g.attemptLog = logger
+	}
+}
+
+// WithDegradedHeader reports in an X-Gateway-Degraded header why a chat
+// completion isn't a plain upstream answer: a fallback, a cache hit, an
+// intercepted response, estimated usage or truncation
+func WithDegradedHeader() Option {
+	return func(g *Gateway) {
+		g.degraded = true
+	}
+}
+
+// WithAccessLog logs every request to logger once it has been served
+func WithAccessLog(logger *slog.Logger) Option {
+	return func(g *Gateway) {
+		g.accessLog = logger
+	}
+}
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/handler"
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// Recover wraps next so a panicking request is answered with a 500 error
+// instead of crashing the server. The panic and its stack are logged and
+// passed to the error hooks. If a stream has already started, an error event
+// and [DONE] are written to it instead, framed as sse configures.
+func Recover(hooks *hook.Registry, sse handler.SSEConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoverWriter{ResponseWriter: w, sse: sse}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// ErrAbortHandler deliberately aborts the response; let net/http handle it
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			err := fmt.Errorf("panic: %v", rec)
+			slog.ErrorContext(r.Context(), "recovered from panic",
+				"error", err,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"stack", string(debug.Stack()),
+			)
+			if hooks != nil {
+				for _, h := range hooks.ErrorHooks() {
+					h.OnError(r.Context(), err)
+				}
+			}
+			rw.writePanic()
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// internalErrorBody is the OpenAI-style error returned for a recovered panic
+var internalErrorBody = func() []byte {
+	data, _ := json.Marshal(map[string]any{
+		"error": map[string]any{
+			"message": "Internal server error",
+			"type":    "server_error",
+		},
+	})
+	return data
+}()
+
+// recoverWriter tracks whether the response has started, so a recovered
+// panic knows whether it can still send an error status
+type recoverWriter struct {
+	http.ResponseWriter
+	sse         handler.SSEConfig
+	wroteHeader bool
+}
+
+func (w *recoverWriter) WriteHeader(statusCode int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recoverWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming handlers
+func (w *recoverWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the underlying writer does
+func (w *recoverWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *recoverWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writePanic answers a request whose handler panicked
+func (w *recoverWriter) writePanic() {
+	if !w.wroteHeader {
+		h := w.Header()
+		// Drop headers the handler set for a response that never happened
+		h.Del("Content-Length")
+		h.Del("Content-Encoding")
+		h.Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(internalErrorBody)
+		return
+	}
+
+	// The status is already sent; a stream can still be ended with an error event
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	w.sse.WriteEvent(w, "error", internalErrorBody)
+	w.sse.WriteEvent(w, "", []byte("[DONE]"))
+	w.Flush()
+}
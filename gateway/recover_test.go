@@ -0,0 +1,138 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/handler"
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// panicHook panics before every chat request
+type panicHook struct{}
+
+func (panicHook) Name() string { return "panic" }
+
+func (panicHook) BeforeRequest(ctx context.Context, req *openai.ChatCompletionRequest) error {
+	var m map[string]string
+	m["boom"] = "nil map"
+	return nil
+}
+
+func (panicHook) AfterRequest(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) error {
+	return nil
+}
+
+// errorRecorder records errors passed to error hooks
+type errorRecorder struct {
+	errs []error
+}
+
+func (e *errorRecorder) Name() string { return "error-recorder" }
+
+func (e *errorRecorder) OnError(ctx context.Context, err error) {
+	e.errs = append(e.errs, err)
+}
+
+func TestGateway_RecoversPanic(t *testing.T) {
+	hooks := hook.NewRegistry()
+	errs := &errorRecorder{}
+	hooks.Register(panicHook{}, errs)
+	gw := New(WithModelRegistry(setupTestRegistry()), WithHooks(hooks))
+
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON error, got content type %q", ct)
+	}
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode error: %v: %s", err, w.Body.String())
+	}
+	if resp.Error.Type != "server_error" || resp.Error.Message == "" {
+		t.Errorf("unexpected error body: %s", w.Body.String())
+	}
+	if len(errs.errs) != 1 || !strings.Contains(errs.errs[0].Error(), "nil map") {
+		t.Errorf("expected the panic to reach the error hook, got %v", errs.errs)
+	}
+}
+
+func TestGateway_RecoversPanicMidStream(t *testing.T) {
+	gw := New(WithModelRegistry(setupTestRegistry()))
+	gw.mux.HandleFunc("/panic-stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"1\"}\n\n"))
+		w.(http.Flusher).Flush()
+		panic("stream converter failed")
+	})
+
+	w := httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest("GET", "/panic-stream", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the already sent status to stand, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "data: {\"id\":\"1\"}\n\n") {
+		t.Errorf("expected earlier events to be kept, got %q", body)
+	}
+	if !strings.Contains(body, "event: error\ndata: {\"error\":") {
+		t.Errorf("expected an error event, got %q", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("expected the stream to end with [DONE], got %q", body)
+	}
+}
+
+func TestRecover_SSEFraming(t *testing.T) {
+	h := Recover(nil, handler.SSEConfig{LineTerminator: "\r\n"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"id\":\"1\"}\r\n\r\n"))
+		panic("stream converter failed")
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	body := w.Body.String()
+	if !strings.Contains(body, "event: error\r\ndata: {\"error\":") {
+		t.Errorf("expected a CRLF-framed error event, got %q", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\r\n\r\n") {
+		t.Errorf("expected the stream to end with a CRLF-framed [DONE], got %q", body)
+	}
+}
+
+func TestRecover_AbortHandler(t *testing.T) {
+	h := Recover(nil, handler.SSEConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("expected ErrAbortHandler to propagate, got %v", rec)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// Server serves a Gateway over HTTP. Its Shutdown drains the gateway as well
+// as the HTTP server.
+type Server struct {
+	*http.Server
+	gateway *Gateway
+}
+
+// NewServer creates a server listening on addr for gw
+func NewServer(addr string, gw *Gateway) *Server {
+	return &Server{
+		Server:  &http.Server{Addr: addr, Handler: gw},
+		gateway: gw,
+	}
+}
+
+// Shutdown stops accepting connections, waits for in-flight requests
+// (including streams) until ctx is done, and then shuts down the gateway
+func (s *Server) Shutdown(ctx context.Context) error {
+	return errors.Join(s.Server.Shutdown(ctx), s.gateway.Shutdown(ctx))
+}
+
+// beginRequest tracks a new request, or returns false once shutdown has begun
+func (g *Gateway) beginRequest() bool {
+	g.shutdownMu.Lock()
+	defer g.shutdownMu.Unlock()
+	if g.shuttingDown {
+		return false
+	}
+	g.inFlight.Add(1)
+	return true
+}
+
+// Shutdown refuses new requests with 503, waits for in-flight requests and
+// background responses to finish until ctx is done, and then closes the quota manager and any
+// registered providers with background goroutines, such as load balancers
+// running health checks. Resources are closed even if ctx expires first.
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	g.shutdownMu.Lock()
+	g.shuttingDown = true
+	g.shutdownMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		g.inFlight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	g.closeOnce.Do(func() {
+		g.closeErr = g.closeResources()
+	})
+	return errors.Join(err, g.closeErr)
+}
+
+// closeResources closes the quota manager and registered providers that
+// implement io.Closer, and waits for async hooks to finish
+func (g *Gateway) closeResources() error {
+	var errs []error
+	errs = append(errs, g.hooks.Close())
+	if closer, ok := g.quota.(io.Closer); ok {
+		errs = append(errs, closer.Close())
+	}
+
+	// Several models may share a provider, so close each once
+	closed := make(map[io.Closer]bool)
+	for _, prov := range g.registeredProviders() {
+		closer := providerCloser(prov)
+		if closer == nil || closed[closer] {
+			continue
+		}
+		closed[closer] = true
+		errs = append(errs, closer.Close())
+	}
+
+	// The default model's provider needn't be registered under a name
+	if closer := providerCloser(g.defaultProv); closer != nil && !closed[closer] {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// registeredProviders returns the providers of the model registry, including
+// pattern registrations when the registry can list them
+func (g *Gateway) registeredProviders() []provider.Provider {
+	if lister, ok := g.modelRegistry.(model.ProviderLister); ok {
+		return lister.Providers()
+	}
+	var providers []provider.Provider
+	for _, name := range g.modelRegistry.ListModels() {
+		prov, _ := g.modelRegistry.Resolve(name)
+		providers = append(providers, prov)
+	}
+	return providers
+}
+
+// providerCloser returns the closer of prov or of a provider it wraps, if any
+func providerCloser(prov provider.Provider) io.Closer {
+	for prov != nil {
+		if closer, ok := prov.(io.Closer); ok {
+			return closer
+		}
+		unwrapper, ok := prov.(interface{ Unwrap() provider.Provider })
+		if !ok {
+			return nil
+		}
+		prov = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// writeShuttingDown refuses a request received during shutdown
+func writeShuttingDown(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write([]byte(`{"error":{"message":"Server is shutting down","type":"server_error"}}`))
+}
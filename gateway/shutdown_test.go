@@ -0,0 +1,162 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/model"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/quota"
+)
+
+// blockingStreamProvider streams one chunk, then waits for release before finishing
+type blockingStreamProvider struct {
+	mockProvider
+	started chan struct{}
+	release chan struct{}
+	closed  atomic.Int32
+}
+
+func newBlockingStreamProvider() *blockingStreamProvider {
+	return &blockingStreamProvider{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (p *blockingStreamProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	if !req.Stream {
+		return p.mockProvider.SendRequest(ctx, req)
+	}
+	chunkChan := make(chan *provider.Chunk)
+	errChan := make(chan error)
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+		chunkChan <- provider.NewOpenAIChunk([]byte(`{"id":"test-id","object":"chat.completion.chunk","model":"` + req.Model + `","choices":[{"index":0,"delta":{"content":"Hello"}}]}`))
+		close(p.started)
+		<-p.release
+		chunkChan <- provider.NewOpenAIChunkDone()
+	}()
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, func() error { return nil }), nil
+}
+
+// Close records that the gateway closed the provider
+func (p *blockingStreamProvider) Close() error {
+	p.closed.Add(1)
+	return nil
+}
+
+// startStream serves a streaming chat request in the background, closing the returned channel when it's done
+func startStream(gw *Gateway, w *httptest.ResponseRecorder) chan struct{} {
+	body, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   true,
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gw.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+	}()
+	return done
+}
+
+func TestGateway_ShutdownDrainsStreams(t *testing.T) {
+	prov := newBlockingStreamProvider()
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", prov)
+	registry.Register("gpt-4o", prov)
+	gw := New(
+		WithModelRegistry(registry),
+		WithQuotaManager(quota.NewMemoryManager(&quota.Config{ResetPeriod: quota.Daily, Enabled: true})),
+	)
+
+	stream := httptest.NewRecorder()
+	streamDone := startStream(gw, stream)
+	<-prov.started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErr <- gw.Shutdown(ctx)
+	}()
+
+	// New requests are refused once shutdown begins
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		if w.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected requests to be refused during shutdown, got %d", w.Code)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("expected shutdown to wait for the stream, returned %v", err)
+	default:
+	}
+
+	close(prov.release)
+	<-streamDone
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	if body := stream.Body.String(); !strings.Contains(body, "Hello") || !strings.Contains(body, "data: [DONE]") {
+		t.Errorf("expected the stream to complete, got %q", body)
+	}
+	if closed := prov.closed.Load(); closed != 1 {
+		t.Errorf("expected the shared provider to be closed once, got %d", closed)
+	}
+}
+
+func TestGateway_ShutdownDeadline(t *testing.T) {
+	prov := newBlockingStreamProvider()
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", prov)
+	gw := New(WithModelRegistry(registry))
+
+	streamDone := startStream(gw, httptest.NewRecorder())
+	<-prov.started
+	defer func() {
+		close(prov.release)
+		<-streamDone
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := gw.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if prov.closed.Load() != 1 {
+		t.Error("expected providers to be closed after the deadline")
+	}
+}
+
+func TestGateway_ShutdownClosesPatternProviders(t *testing.T) {
+	named := newBlockingStreamProvider()
+	pattern := newBlockingStreamProvider()
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4", named)
+	registry.Register("ft:gpt-4o:*", pattern)
+	gw := New(WithModelRegistry(registry))
+
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if named.closed.Load() != 1 || pattern.closed.Load() != 1 {
+		t.Errorf("expected every provider closed once, got named=%d pattern=%d", named.closed.Load(), pattern.closed.Load())
+	}
+}
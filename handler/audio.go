@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
+)
+
+// MaxAudioFileSize is the largest audio upload accepted for transcription (25 MB)
+const MaxAudioFileSize = 25 << 20
+
+// AudioTranscriptionsHandler handles audio transcription requests
+type AudioTranscriptionsHandler struct {
+	// registry is typed as `any` to avoid circular dependencies.
+	// The handler only needs the Resolve(model string) (provider.Provider, string) method,
+	// which is checked via a local interface type assertion in ServeHTTP.
+	registry any
+	hooks    *hook.Registry
+	limiter  ratelimit.Limiter
+	metrics  MetricsRecorder
+	timeout  time.Duration
+}
+
+// NewAudioTranscriptionsHandler creates a new audio transcriptions handler
+func NewAudioTranscriptionsHandler(registry any, hooks *hook.Registry) *AudioTranscriptionsHandler {
+	return &AudioTranscriptionsHandler{
+		registry: registry,
+		hooks:    hooks,
+	}
+}
+
+// SetRateLimiter enables per-tenant rate limiting
+func (h *AudioTranscriptionsHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *AudioTranscriptionsHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
+// ServeHTTP implements http.Handler
+func (h *AudioTranscriptionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Ensure request body is closed
+	defer r.Body.Close()
+
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/audio/transcriptions", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, NewValidationError(timeoutErr.Error()))
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		h.writeError(w, r, NewMethodNotAllowedError("only POST method is allowed"))
+		return
+	}
+
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, NewRateLimitError("rate limit exceeded"))
+		return
+	}
+
+	// Parse multipart form; the extra megabyte leaves room for the other fields
+	r.Body = http.MaxBytesReader(w, r.Body, MaxAudioFileSize+1<<20)
+	if err := r.ParseMultipartForm(MaxAudioFileSize); err != nil {
+		h.writeError(w, r, NewValidationError("invalid multipart form: "+err.Error()))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.writeError(w, r, NewValidationError("file is required"))
+		return
+	}
+	defer file.Close()
+
+	audio, err := io.ReadAll(file)
+	if err != nil {
+		h.writeError(w, r, NewValidationError("failed to read file: "+err.Error()))
+		return
+	}
+
+	// Validate request
+	model := r.FormValue("model")
+	if model == "" {
+		h.writeError(w, r, NewValidationError("model is required"))
+		return
+	}
+
+	provReq := provider.NewAudioTranscriptionRequest(model, header.Filename, audio)
+	provReq.AudioLanguage = r.FormValue("language")
+	provReq.AudioPrompt = r.FormValue("prompt")
+	provReq.AudioResponseFormat = r.FormValue("response_format")
+	if v := r.FormValue("temperature"); v != "" {
+		temperature, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			h.writeError(w, r, NewValidationError("temperature must be a number"))
+			return
+		}
+		provReq.Temperature = &temperature
+	}
+
+	ctx := r.Context()
+
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(ctx, h.hooks, model)
+	if routeErr != nil {
+		h.writeError(w, r, routeErr)
+		return
+	}
+	model = routed
+
+	// Resolve provider
+	type resolver interface {
+		Resolve(model string) (provider.Provider, string)
+	}
+	var prov provider.Provider
+	var modelRewrite string
+
+	if reg, ok := h.registry.(resolver); ok {
+		prov, modelRewrite = reg.Resolve(model)
+		if prov == nil {
+			h.writeError(w, r, NewNotFoundError("model not found: "+model))
+			return
+		}
+	} else {
+		h.writeError(w, r, NewProviderError("registry not available", nil))
+		return
+	}
+
+	if !prov.SupportedAPIs().Supports(provider.APITypeAudioTranscription) {
+		h.writeError(w, r, NewValidationError(fmt.Sprintf("provider %s does not support audio transcription for model %s", prov.Name(), model)))
+		return
+	}
+
+	// Apply model rewrite if specified
+	if modelRewrite != "" {
+		provReq.Model = modelRewrite
+	}
+
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/audio/transcriptions", prov)
+
+	// Record routing info for hooks and logging
+	ctx = withRouteInfo(ctx, prov, model, provReq.Model)
+	r = r.WithContext(ctx)
+
+	// Send request to provider
+	provResp, err := prov.SendRequest(ctx, provReq)
+	if err != nil {
+		h.writeError(w, r, NewProviderError("provider request failed", err))
+		return
+	}
+	copyHeaders(w.Header(), provResp.Headers)
+
+	// Get transcription response
+	resp, err := provResp.GetTranscription()
+	if err != nil {
+		h.writeError(w, r, NewProviderError("invalid response", err))
+		return
+	}
+
+	// Plain text formats are returned without a JSON envelope
+	switch provReq.AudioResponseFormat {
+	case "text", "srt", "vtt":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, resp.Text)
+		return
+	}
+
+	// Write response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.writeError(w, r, NewProviderError("failed to encode response", err))
+		return
+	}
+}
+
+func (h *AudioTranscriptionsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	setUpstreamErrorHeaders(w, err)
+	err = timeoutError(r, err)
+
+	var gwErr *GatewayError
+	if !errors.As(err, &gwErr) {
+		gwErr = NewProviderError("internal error", err)
+	}
+
+	// Call ErrorHooks to notify of the error
+	ctx := r.Context()
+	if h.hooks != nil {
+		for _, hh := range h.hooks.ErrorHooks() {
+			hh.OnError(ctx, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(gwErr.Code)
+	if encodeErr := json.NewEncoder(w).Encode(gwErr.ToOpenAIResponse()); encodeErr != nil {
+		fmt.Printf("failed to encode error response: %v\n", encodeErr)
+	}
+}
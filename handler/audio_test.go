@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	prov "github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// mockAudioProvider transcribes every file as "hello world"
+type mockAudioProvider struct {
+	lastReq *prov.Request
+}
+
+func (m *mockAudioProvider) Name() string {
+	return "mock-audio"
+}
+
+func (m *mockAudioProvider) SupportedAPIs() prov.APIType {
+	return prov.APITypeAudioTranscription
+}
+
+func (m *mockAudioProvider) SendRequest(ctx context.Context, req *prov.Request) (*prov.Response, error) {
+	m.lastReq = req
+	return prov.NewTranscriptionResponse(&openai.TranscriptionResponse{
+		Text:     "hello world",
+		Segments: []openai.TranscriptionSegment{{ID: 0, Start: 0, End: 0.5, Text: "hello world"}},
+	}), nil
+}
+
+// silentWAV returns a minimal 16-bit mono PCM WAV file with n silent samples
+func silentWAV(n int) []byte {
+	var buf bytes.Buffer
+	dataSize := uint32(n * 2)
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))    // fmt chunk size
+	binary.Write(&buf, binary.LittleEndian, uint16(1))     // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))     // mono
+	binary.Write(&buf, binary.LittleEndian, uint32(16000)) // sample rate
+	binary.Write(&buf, binary.LittleEndian, uint32(32000)) // byte rate
+	binary.Write(&buf, binary.LittleEndian, uint16(2))     // block align
+	binary.Write(&buf, binary.LittleEndian, uint16(16))    // bits per sample
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	buf.Write(make([]byte, dataSize))
+	return buf.Bytes()
+}
+
+func newTranscriptionRequest(t *testing.T, fields map[string]string, audio []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if audio != nil {
+		part, err := writer.CreateFormFile("file", "speech.wav")
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write(audio)
+	}
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/v1/audio/transcriptions", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAudioTranscriptionsHandler_ServeHTTP(t *testing.T) {
+	provider := &mockAudioProvider{}
+	handler := NewAudioTranscriptionsHandler(&mapModelRegistry{provider: provider}, hook.NewRegistry())
+
+	audio := silentWAV(800)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTranscriptionRequest(t, map[string]string{
+		"model":       "whisper-1",
+		"language":    "en",
+		"temperature": "0.2",
+	}, audio))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp openai.TranscriptionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Text != "hello world" || len(resp.Segments) != 1 {
+		t.Errorf("unexpected transcription: %+v", resp)
+	}
+
+	got := provider.lastReq
+	if got.APIType != prov.APITypeAudioTranscription || got.Model != "whisper-1" {
+		t.Errorf("unexpected provider request: %v %s", got.APIType, got.Model)
+	}
+	if got.AudioFilename != "speech.wav" || !bytes.Equal(got.AudioFile, audio) {
+		t.Errorf("expected audio file to be forwarded, got %s (%d bytes)", got.AudioFilename, len(got.AudioFile))
+	}
+	if got.AudioLanguage != "en" || got.Temperature == nil || *got.Temperature != 0.2 {
+		t.Errorf("expected form fields to be forwarded, got language=%q temperature=%v", got.AudioLanguage, got.Temperature)
+	}
+}
+
+func TestAudioTranscriptionsHandler_TextFormat(t *testing.T) {
+	handler := NewAudioTranscriptionsHandler(&mapModelRegistry{provider: &mockAudioProvider{}}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTranscriptionRequest(t, map[string]string{
+		"model":           "whisper-1",
+		"response_format": "text",
+	}, silentWAV(10)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != "hello world" {
+		t.Errorf("expected plain text transcription, got %q", w.Body.String())
+	}
+}
+
+func TestAudioTranscriptionsHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		audio  []byte
+	}{
+		{"missing file", map[string]string{"model": "whisper-1"}, nil},
+		{"missing model", map[string]string{}, silentWAV(10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAudioTranscriptionsHandler(&mapModelRegistry{provider: &mockAudioProvider{}}, hook.NewRegistry())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newTranscriptionRequest(t, tt.fields, tt.audio))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
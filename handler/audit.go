@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/audit"
+	"github.com/deeplooplabs/ai-gateway/hook"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// writeAudit sends an audit record to sink. Sink failures are reported to error
+// hooks and never affect the response.
+func writeAudit(ctx context.Context, sink audit.Sink, hooks *hook.Registry, r *http.Request, stream bool, req *openai2.ChatCompletionRequest, resp *openai2.ChatCompletionResponse) {
+	if sink == nil {
+		return
+	}
+
+	info, _ := ai_gateway.RouteInfoFromContext(ctx)
+	record := &audit.Record{
+		Timestamp: time.Now().UTC(),
+		RequestID: ai_gateway.RequestIDFromContext(ctx),
+		TenantID:  ai_gateway.TenantIDFromContext(ctx),
+		Model:     info.OriginalModel,
+		Provider:  info.Provider,
+		Endpoint:  r.URL.Path,
+		Stream:    stream,
+		Request:   req,
+		Response:  resp,
+	}
+
+	if record.RequestID == "" {
+		record.RequestID, _ = requestID(r, "")
+	}
+
+	if err := sink.Write(ctx, record); err != nil {
+		for _, hh := range hooks.ErrorHooks() {
+			hh.OnError(ctx, fmt.Errorf("audit: %w", err))
+		}
+	}
+}
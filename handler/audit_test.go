@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/audit"
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// capturingSink records every audit record it receives
+type capturingSink struct {
+	records []*audit.Record
+	err     error
+}
+
+func (s *capturingSink) Write(ctx context.Context, record *audit.Record) error {
+	s.records = append(s.records, record)
+	return s.err
+}
+
+// errorRecordingHook records errors passed to OnError
+type errorRecordingHook struct {
+	errs []error
+}
+
+func (h *errorRecordingHook) Name() string {
+	return "error-recorder"
+}
+
+func (h *errorRecordingHook) OnError(ctx context.Context, err error) {
+	h.errs = append(h.errs, err)
+}
+
+func newAuditedChatRequest(stream bool) *http.Request {
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   stream,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	req.Header.Set("X-Request-ID", "req-1")
+	return req.WithContext(context.WithValue(req.Context(), "tenant_id", "tenant-1"))
+}
+
+func TestChatHandler_Audit(t *testing.T) {
+	sink := &capturingSink{}
+	handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+	handler.SetAuditSink(sink)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuditedChatRequest(false))
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.RequestID != "req-1" || record.TenantID != "tenant-1" || record.Model != "gpt-4" || record.Provider != "mock" {
+		t.Errorf("unexpected record metadata: %+v", record)
+	}
+	if record.Stream || record.Timestamp.IsZero() {
+		t.Errorf("unexpected stream flag or timestamp: %+v", record)
+	}
+	if record.Request == nil || record.Request.Messages[0].Content != "Hello" {
+		t.Errorf("expected audited request, got %+v", record.Request)
+	}
+	if record.Response == nil || record.Response.Choices[0].Message.Content != "Hello!" || record.Response.Usage.TotalTokens != 15 {
+		t.Errorf("expected audited response with usage, got %+v", record.Response)
+	}
+}
+
+func TestChatHandler_AuditStream(t *testing.T) {
+	sink := &capturingSink{}
+	handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+	handler.SetAuditSink(sink)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuditedChatRequest(true))
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if !record.Stream {
+		t.Error("expected stream record")
+	}
+	if record.Response == nil || len(record.Response.Choices) != 1 || record.Response.Choices[0].Message.Content != "Hello!" {
+		t.Fatalf("expected accumulated response, got %+v", record.Response)
+	}
+	if record.Response.Usage.TotalTokens == 0 {
+		t.Error("expected usage on accumulated response")
+	}
+}
+
+func TestChatHandler_AuditSinkFailure(t *testing.T) {
+	sink := &capturingSink{err: errors.New("disk full")}
+	errHook := &errorRecordingHook{}
+	hooks := hook.NewRegistry()
+	hooks.Register(errHook)
+
+	handler := NewChatHandler(newMockRegistry(), hooks)
+	handler.SetAuditSink(sink)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newAuditedChatRequest(false))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 despite sink failure, got %d", w.Code)
+	}
+	if len(errHook.errs) != 1 || !errors.Is(errHook.errs[0], sink.err) {
+		t.Errorf("expected sink error reported via OnError, got %v", errHook.errs)
+	}
+}
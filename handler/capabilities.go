@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// SetCapabilityCache sets the probed provider capabilities consulted before
+// sending requests. Without it every provider is assumed permissive.
+func (h *ChatHandler) SetCapabilityCache(capabilities *provider.CapabilityCache) {
+	h.capabilities = capabilities
+}
+
+// checkStreaming rejects streamed requests to providers probed without streaming support
+func (h *ChatHandler) checkStreaming(req *openai2.ChatCompletionRequest, prov provider.Provider) error {
+	if req.Stream && !h.capabilities.Lookup(prov).Streaming {
+		return NewValidationError(fmt.Sprintf("model %s does not support streaming", req.Model))
+	}
+	return nil
+}
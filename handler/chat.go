@@ -3,20 +3,49 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"time"
 
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/audit"
+	"github.com/deeplooplabs/ai-gateway/cache"
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/model"
 	"github.com/deeplooplabs/ai-gateway/provider"
 	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/quota"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
 )
 
 // ChatHandler handles chat completion requests
 type ChatHandler struct {
 	registry model.ModelRegistry
 	hooks    *hook.Registry
+	quota    quota.Manager
+	audit    audit.Sink
+	sse      SSEConfig
+	limiter  ratelimit.Limiter
+	metrics  MetricsRecorder
+	choices  ChoicesFallback
+	tools    ToolsFallback
+	timeout  time.Duration
+
+	requestIDHeader string
+
+	completeOnDisconnect bool
+	reportDegraded       bool
+	validateSchemas      bool
+
+	capabilities *provider.CapabilityCache
+
+	cache         cache.Cache
+	cacheTTL      time.Duration
+	cacheRecorder cache.Recorder
+	cachePricing  cache.Pricing
 }
 
 // NewChatHandler creates a new chat handler
@@ -27,11 +56,56 @@ func NewChatHandler(registry model.ModelRegistry, hooks *hook.Registry) *ChatHan
 	}
 }
 
+// SetQuotaManager sets the quota manager used to record token usage
+func (h *ChatHandler) SetQuotaManager(manager quota.Manager) {
+	h.quota = manager
+}
+
+// SetAuditSink sets the sink receiving full request/response audit records
+func (h *ChatHandler) SetAuditSink(sink audit.Sink) {
+	h.audit = sink
+}
+
+// SetRateLimiter enables per-tenant rate limiting
+func (h *ChatHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *ChatHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
+// SetSSEConfig sets the framing used for streaming responses
+func (h *ChatHandler) SetSSEConfig(cfg SSEConfig) {
+	h.sse = cfg
+}
+
 // ServeHTTP implements http.Handler
 func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Ensure request body is closed
 	defer r.Body.Close()
 
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/chat/completions", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, NewValidationError(timeoutErr.Error()))
+		return
+	}
+
+	// Assign a stable request ID for hooks, audit and sampling
+	r = assignRequestID(w, r, h.requestIDHeader)
+
+	// Track why the response may be degraded for the X-Gateway-Degraded header
+	if h.reportDegraded {
+		r = r.WithContext(withDegradation(r.Context()))
+	}
+
 	// Call AuthenticationHooks to validate Authorization header
 	for _, hh := range h.hooks.AuthenticationHooks() {
 		success, tenantID, err := hh.Authenticate(r.Context(), r.Header.Get("Authorization"))
@@ -48,6 +122,12 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r = r.WithContext(ctx)
 	}
 
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, NewRateLimitError("rate limit exceeded"))
+		return
+	}
+
 	// Parse request
 	var req openai2.ChatCompletionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -65,6 +145,14 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(r.Context(), h.hooks, req.Model)
+	if routeErr != nil {
+		h.writeError(w, r, routeErr)
+		return
+	}
+	req.Model = routed
+
 	// Resolve provider
 	prov, modelRewrite := h.registry.Resolve(req.Model)
 	if prov == nil {
@@ -73,10 +161,35 @@ func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply model rewrite if specified
+	originalModel := req.Model
 	if modelRewrite != "" {
 		req.Model = modelRewrite
 	}
 
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/chat/completions", prov)
+
+	// Record routing info for hooks and logging
+	r = r.WithContext(withRouteInfo(r.Context(), prov, originalModel, req.Model))
+
+	// Single-choice providers can't serve n > 1 directly
+	if err := h.checkChoices(&req, prov); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// Providers without function calling can't be sent tools
+	if err := h.checkTools(r.Context(), &req, prov); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	// Providers probed without streaming support can't serve streams
+	if err := h.checkStreaming(&req, prov); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
 	// Handle streaming vs non-streaming
 	if req.Stream {
 		h.handleStream(w, r, &req, prov)
@@ -94,11 +207,20 @@ func (h *ChatHandler) handleNonStream(w http.ResponseWriter, r *http.Request, re
 	unifiedReq.Temperature = req.Temperature
 	unifiedReq.TopP = req.TopP
 	unifiedReq.MaxTokens = req.MaxTokens
+	unifiedReq.MaxCompletionTokens = req.MaxCompletionTokens
 	unifiedReq.Stop = req.Stop
+	unifiedReq.N = req.N
 	unifiedReq.PresencePenalty = req.PresencePenalty
 	unifiedReq.FrequencyPenalty = req.FrequencyPenalty
 	unifiedReq.Tools = req.Tools
 	unifiedReq.ToolChoice = req.ToolChoice
+	unifiedReq.ResponseFormat = req.ResponseFormat
+	unifiedReq.User = req.User
+	unifiedReq.Seed = req.Seed
+	unifiedReq.LogProbs = req.LogProbs
+	unifiedReq.TopLogProbs = req.TopLogProbs
+	unifiedReq.ServiceTier = req.ServiceTier
+	unifiedReq.Metadata = req.Metadata
 	unifiedReq.Endpoint = "/v1/chat/completions"
 
 	// Call BeforeRequest hooks
@@ -109,23 +231,60 @@ func (h *ChatHandler) handleNonStream(w http.ResponseWriter, r *http.Request, re
 		}
 	}
 
-	// Send request to provider using unified interface
-	resp, err := prov.SendRequest(r.Context(), unifiedReq)
+	// Intercept hooks may answer without calling the provider
+	chatResp, err := h.intercept(r.Context(), req)
 	if err != nil {
-		h.writeError(w, r, NewProviderError("provider error", err))
+		h.writeError(w, r, err)
 		return
 	}
-	defer resp.Close()
+	intercepted := chatResp != nil
+	if intercepted {
+		markDegraded(r.Context(), DegradedIntercepted)
+	}
 
-	// Convert response to Chat Completions format if needed
-	chatResp, err := resp.GetChatCompletion()
-	if err != nil {
-		h.writeError(w, r, NewProviderError("failed to convert response", err))
-		return
+	// Serve deterministic requests from the cache when possible
+	var cacheKey string
+	var cachePrompt cache.Prompt
+	var cacheable, cached bool
+	if h.cache != nil && !intercepted {
+		cacheKey, cachePrompt, cacheable = chatCacheKey(r.Context(), prov, req)
 	}
-	if chatResp == nil {
-		h.writeError(w, r, NewProviderError("nil response", nil))
-		return
+	if cacheable {
+		chatResp, cached = h.cachedResponse(r.Context(), w, cacheKey, cachePrompt)
+	}
+	if cached {
+		markDegraded(r.Context(), DegradedCached)
+	}
+
+	// Finish calls whose result is cached even if the client goes away
+	clientCtx := r.Context()
+	if cacheable && !cached && h.completeOnDisconnect {
+		ctx, cancel := detachClient(clientCtx)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	if !cached && !intercepted {
+		var headers http.Header
+		var err error
+		chatResp, headers, err = sendChatRequest(r.Context(), prov, unifiedReq)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		copyHeaders(w.Header(), headers)
+		if checksResponseFormat(h.validateSchemas, h.capabilities, prov) {
+			if err := validateResponseFormat(req.ResponseFormat, chatResp.Choices); err != nil {
+				h.writeError(w, r, NewProviderError("response does not match the response_format schema: "+err.Error(), err))
+				return
+			}
+		}
+
+		// Cache the provider's response before hooks can modify it
+		if cacheable {
+			h.storeResponse(r.Context(), cacheKey, cachePrompt, chatResp, h.cacheTTL)
+			logDisconnected(r.Context(), clientCtx, "client disconnected, cached the completed response")
+		}
 	}
 
 	// Call AfterRequest hooks
@@ -136,9 +295,29 @@ func (h *ChatHandler) handleNonStream(w http.ResponseWriter, r *http.Request, re
 		}
 	}
 
+	// Cache hits and intercepted requests cost no upstream tokens
+	if !cached && !intercepted {
+		h.recordUsage(r.Context(), &chatResp.Usage)
+	}
+
+	// Let mutation hooks rewrite what the client receives
+	for _, hh := range h.hooks.ResponseMutationHooks() {
+		mutated, err := hh.MutateResponse(r.Context(), req, chatResp)
+		if err != nil {
+			h.writeError(w, r, fmt.Errorf("hook error: %w", err))
+			return
+		}
+		if mutated != nil {
+			chatResp = mutated
+		}
+	}
+	writeAudit(r.Context(), h.audit, h.hooks, r, false, req, chatResp)
+
 	// Write response
+	markTruncated(r.Context(), chatResp.Choices)
+	writeDegradedHeader(r.Context(), w)
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(chatResp); err != nil {
+	if err := json.NewEncoder(w).Encode(debugResponse(r, chatResp)); err != nil {
 		h.writeError(w, r, NewProviderError("failed to encode response", err))
 		return
 	}
@@ -161,39 +340,112 @@ func (h *ChatHandler) handleStream(w http.ResponseWriter, r *http.Request, req *
 	unifiedReq.Temperature = req.Temperature
 	unifiedReq.TopP = req.TopP
 	unifiedReq.MaxTokens = req.MaxTokens
+	unifiedReq.MaxCompletionTokens = req.MaxCompletionTokens
 	unifiedReq.Stop = req.Stop
+	unifiedReq.N = req.N
 	unifiedReq.PresencePenalty = req.PresencePenalty
 	unifiedReq.FrequencyPenalty = req.FrequencyPenalty
 	unifiedReq.Tools = req.Tools
 	unifiedReq.ToolChoice = req.ToolChoice
+	unifiedReq.ResponseFormat = req.ResponseFormat
+	unifiedReq.User = req.User
+	unifiedReq.Seed = req.Seed
+	unifiedReq.LogProbs = req.LogProbs
+	unifiedReq.TopLogProbs = req.TopLogProbs
+	unifiedReq.ServiceTier = req.ServiceTier
+	unifiedReq.Metadata = req.Metadata
+	unifiedReq.StreamOptions = req.StreamOptions
 	unifiedReq.Endpoint = "/v1/chat/completions"
 
-	// Send request to provider using unified interface
-	resp, err := prov.SendRequest(r.Context(), unifiedReq)
+	// Call BeforeRequest hooks
+	for _, hh := range h.hooks.RequestHooks() {
+		if err := hh.BeforeRequest(r.Context(), req); err != nil {
+			h.writeError(w, r, fmt.Errorf("hook error: %w", err))
+			return
+		}
+	}
+
+	// Intercept hooks may answer without calling the provider
+	intercepted, err := h.intercept(r.Context(), req)
 	if err != nil {
-		h.writeError(w, r, NewProviderError("provider error", err))
+		h.writeError(w, r, err)
 		return
 	}
+
+	// Send request to provider using unified interface
+	var resp *provider.Response
+	if intercepted != nil {
+		markDegraded(r.Context(), DegradedIntercepted)
+		resp = replayStream(intercepted)
+	} else {
+		resp, err = prov.SendRequest(r.Context(), unifiedReq)
+		if err != nil {
+			h.writeError(w, r, NewProviderError("provider error", err))
+			return
+		}
+	}
 	defer resp.Close()
 
 	if !resp.Stream {
 		h.writeError(w, r, NewValidationError("provider returned non-streaming response"))
 		return
 	}
+	copyHeaders(w.Header(), resp.Headers)
+
+	// Reasons found once the stream has started are sent as a trailer
+	writeDegradedHeader(r.Context(), w)
+	sentReasons := len(degradedReasons(r.Context()))
+
+	// Optional preamble so strict clients start rendering before the first chunk
+	if h.sse.Preamble {
+		h.sse.writePreamble(w)
+		flusher.Flush()
+	}
+
+	includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+	acc := openai2.NewStreamAccumulator()
+	start := time.Now()
+	chunkIndex := 0
 
 	// Process chunks
 	for {
 		select {
 		case <-r.Context().Done():
+			if timedOut(r.Context()) {
+				h.writeStreamTimeout(w, r, flusher)
+			}
 			return
 		case chunk, ok := <-resp.Chunks:
 			if !ok {
+				if timedOut(r.Context()) {
+					h.writeStreamTimeout(w, r, flusher)
+				}
 				return
 			}
 
 			if chunk.Done {
+				usage := acc.Usage()
+				if usage == nil {
+					usage = estimateUsage(req, acc)
+					markDegraded(r.Context(), DegradedEstimatedUsage)
+					if includeUsage {
+						h.writeUsageChunk(w, req, acc, usage)
+					}
+				}
+				markTruncated(r.Context(), acc.Response().Choices)
+				writeDegradedTrailer(r.Context(), w, sentReasons)
+				// Intercepted requests cost no upstream tokens
+				if intercepted == nil {
+					h.recordUsage(r.Context(), usage)
+				}
+				if h.audit != nil {
+					accumulated := acc.Response()
+					accumulated.Usage = *usage
+					writeAudit(r.Context(), h.audit, h.hooks, r, true, req, accumulated)
+				}
+
 				// Send [DONE] marker
-				io.WriteString(w, "data: [DONE]\n\n")
+				h.sse.writeData(w, []byte("[DONE]"))
 				flusher.Flush()
 				return
 			}
@@ -209,6 +461,11 @@ func (h *ChatHandler) handleStream(w http.ResponseWriter, r *http.Request, req *
 			}
 
 			if len(data) > 0 {
+				var parsed openai2.ChatCompletionStreamResponse
+				if err := json.Unmarshal(data, &parsed); err == nil {
+					acc.Add(&parsed)
+				}
+
 				// Call streaming hooks
 				modifiedData := data
 				for _, hh := range h.hooks.StreamingHooks() {
@@ -221,13 +478,17 @@ func (h *ChatHandler) handleStream(w http.ResponseWriter, r *http.Request, req *
 				}
 
 				// Write SSE formatted chunk
-				io.WriteString(w, "data: ")
-				io.WriteString(w, string(modifiedData))
-				io.WriteString(w, "\n\n")
+				h.sse.writeData(w, modifiedData)
 				flusher.Flush()
+
+				notifyStreamStats(r.Context(), h.hooks, &chunkIndex, len(modifiedData), start)
 			}
 
 		case err := <-resp.Errors:
+			if err != nil && timedOut(r.Context()) {
+				h.writeStreamTimeout(w, r, flusher)
+				return
+			}
 			if err != nil {
 				h.writeError(w, r, NewProviderError("stream error", err))
 				return
@@ -236,11 +497,79 @@ func (h *ChatHandler) handleStream(w http.ResponseWriter, r *http.Request, req *
 	}
 }
 
+// writeStreamTimeout ends a stream cut short by the request timeout with an
+// error event followed by [DONE]
+func (h *ChatHandler) writeStreamTimeout(w http.ResponseWriter, r *http.Request, flusher http.Flusher) {
+	gwErr := NewTimeoutError("request timed out", context.Cause(r.Context()))
+	for _, hh := range h.hooks.ErrorHooks() {
+		hh.OnError(r.Context(), gwErr)
+	}
+	if data, err := json.Marshal(gwErr.ToOpenAIResponse()); err == nil {
+		h.sse.writeData(w, data)
+	}
+	h.sse.writeData(w, []byte("[DONE]"))
+	flusher.Flush()
+}
+
+// writeUsageChunk writes a final chunk carrying usage and no choices
+func (h *ChatHandler) writeUsageChunk(w io.Writer, req *openai2.ChatCompletionRequest, acc *openai2.StreamAccumulator, usage *openai2.Usage) {
+	model := acc.Model()
+	if model == "" {
+		model = req.Model
+	}
+	data, err := json.Marshal(&openai2.ChatCompletionStreamResponse{
+		ID:      acc.ID(),
+		Object:  "chat.completion.chunk",
+		Created: acc.Created(),
+		Model:   model,
+		Choices: []openai2.Choice{},
+		Usage:   usage,
+	})
+	if err != nil {
+		return
+	}
+	h.sse.writeData(w, data)
+}
+
+// recordUsage records token usage and its cost against the request's tenant.
+// Cost is priced by the model the client requested.
+func (h *ChatHandler) recordUsage(ctx context.Context, usage *openai2.Usage) {
+	recordTokens(ctx, h.metrics, usage)
+	if h.quota == nil || usage == nil {
+		return
+	}
+	tenantID := ai_gateway.TenantIDFromContext(ctx)
+	if tenantID == "" {
+		return
+	}
+	route, _ := ai_gateway.RouteInfoFromContext(ctx)
+	tokens := quota.TokenUsage{
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+		TotalTokens:  usage.TotalTokens,
+	}
+	if err := h.quota.RecordCost(ctx, tenantID, route.OriginalModel, tokens); err != nil {
+		slog.Warn("failed to record usage", "tenant_id", tenantID, "error", err)
+	}
+}
+
+// estimateUsage estimates usage for streams where the provider didn't report it
+func estimateUsage(req *openai2.ChatCompletionRequest, acc *openai2.StreamAccumulator) *openai2.Usage {
+	prompt := openai2.EstimatePromptTokens(req.Messages)
+	completion := openai2.EstimateTokens(acc.Content())
+	return &openai2.Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
+
 func (h *ChatHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	setUpstreamErrorHeaders(w, err)
+	err = timeoutError(r, err)
+
 	var gwErr *GatewayError
-	if e, ok := err.(*GatewayError); ok {
-		gwErr = e
-	} else {
+	if !errors.As(err, &gwErr) {
 		gwErr = NewProviderError("internal error", err)
 	}
 
@@ -257,6 +586,27 @@ func (h *ChatHandler) writeError(w http.ResponseWriter, r *http.Request, err err
 	}
 }
 
+// notifyStreamStats reports a written chunk to stream stats hooks and advances the index
+func notifyStreamStats(ctx context.Context, hooks *hook.Registry, index *int, size int, start time.Time) {
+	statsHooks := hooks.StreamStatsHooks()
+	if len(statsHooks) > 0 {
+		stats := hook.StreamStats{Index: *index, Bytes: size, Elapsed: time.Since(start)}
+		for _, hh := range statsHooks {
+			hh.OnStreamStats(ctx, stats)
+		}
+	}
+	*index++
+}
+
+// withRouteInfo stores the resolved provider and model rewrite in the context
+func withRouteInfo(ctx context.Context, prov provider.Provider, originalModel, model string) context.Context {
+	return ai_gateway.WithRouteInfo(ctx, ai_gateway.RouteInfo{
+		Provider:      prov.Name(),
+		OriginalModel: originalModel,
+		Model:         model,
+	})
+}
+
 // GatewayError represents a gateway error (simplified for handler)
 type GatewayError struct {
 	Code    int
@@ -273,14 +623,34 @@ func NewNotFoundError(msg string) *GatewayError {
 	return &GatewayError{Code: 404, Message: msg, Type: "not_found_error"}
 }
 
+// NewProviderError creates an error for a failed provider call: 429 when the
+// upstream rate limited the request, 504 when it timed out, and 502 otherwise
 func NewProviderError(msg string, err error) *GatewayError {
-	return &GatewayError{Code: 502, Message: msg, Type: "api_error", Err: err}
+	code, errType := providerErrorStatus(err)
+	return &GatewayError{Code: code, Message: msg, Type: errType, Err: err}
+}
+
+// providerErrorStatus maps the kind of a provider error to a status code and error type
+func providerErrorStatus(err error) (int, string) {
+	if kind, ok := provider.ErrorKindOf(err); ok {
+		switch kind {
+		case provider.ErrorKindRateLimited:
+			return 429, "rate_limit_error"
+		case provider.ErrorKindTimeout:
+			return 504, "timeout_error"
+		}
+	}
+	return 502, "api_error"
 }
 
 func NewMethodNotAllowedError(msg string) *GatewayError {
 	return &GatewayError{Code: 405, Message: msg, Type: "invalid_request_error"}
 }
 
+func NewRateLimitError(msg string) *GatewayError {
+	return &GatewayError{Code: 429, Message: msg, Type: "rate_limit_error"}
+}
+
 func (e *GatewayError) Error() string {
 	if e.Err != nil {
 		return e.Message + ": " + e.Err.Error()
@@ -288,6 +658,11 @@ func (e *GatewayError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error
+func (e *GatewayError) Unwrap() error {
+	return e.Err
+}
+
 func (e *GatewayError) ToOpenAIResponse() map[string]any {
 	return map[string]any{
 		"error": map[string]any{
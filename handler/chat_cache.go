@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/cache"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// chatCacheEndpoint labels cache outcomes recorded by the chat handler
+const chatCacheEndpoint = "/v1/chat/completions"
+
+// SetCache enables exact-match caching of deterministic non-streaming
+// completions for ttl (0 uses the cache's default TTL)
+func (h *ChatHandler) SetCache(c cache.Cache, ttl time.Duration) {
+	h.cache = c
+	h.cacheTTL = ttl
+}
+
+// SetCacheRecorder sets the recorder receiving cache outcomes. Savings are
+// costed with pricing, which may be nil.
+func (h *ChatHandler) SetCacheRecorder(recorder cache.Recorder, pricing cache.Pricing) {
+	h.cacheRecorder = recorder
+	h.cachePricing = pricing
+}
+
+// chatCacheKey returns the cache key for req and the prompt behind it for
+// semantic caches, or false if req must not be cached. Only deterministic
+// requests are cached: temperature 0, no tools, and no store side effects.
+// Keys are scoped to the tenant and provider.
+func chatCacheKey(ctx context.Context, prov provider.Provider, req *openai2.ChatCompletionRequest) (string, cache.Prompt, bool) {
+	if req.Stream || req.Temperature == nil || *req.Temperature != 0 || len(req.Tools) > 0 {
+		return "", cache.Prompt{}, false
+	}
+	if req.Store != nil && *req.Store {
+		return "", cache.Prompt{}, false
+	}
+
+	tenant := ai_gateway.TenantIDFromContext(ctx)
+	key, err := hashCacheKey(tenant, prov.Name(), req)
+	if err != nil {
+		return "", cache.Prompt{}, false
+	}
+
+	// The prompt is the last user message; everything else but the model is its
+	// context, so the semantic cache's own scope decides whether models are shared
+	prompt := cache.Prompt{Model: req.Model, Tenant: tenant}
+	rest := *req
+	rest.Model = ""
+	rest.Messages = append([]openai2.Message(nil), req.Messages...)
+	for i := len(rest.Messages) - 1; i >= 0; i-- {
+		if rest.Messages[i].Role == "user" {
+			prompt.Text = rest.Messages[i].Content
+			rest.Messages[i].Content = ""
+			break
+		}
+	}
+	if prompt.Context, err = hashCacheKey("", "", &rest); err != nil {
+		return "", cache.Prompt{}, false
+	}
+
+	return "chat:" + key, prompt, true
+}
+
+// hashCacheKey returns a hex digest identifying req for tenant and provider
+func hashCacheKey(tenant, provider string, req *openai2.ChatCompletionRequest) (string, error) {
+	data, err := json.Marshal(struct {
+		Tenant   string                         `json:"tenant"`
+		Provider string                         `json:"provider"`
+		Request  *openai2.ChatCompletionRequest `json:"request"`
+	}{tenant, provider, req})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cachedResponse returns the cached completion for key, recording the hit or miss
+func (h *ChatHandler) cachedResponse(ctx context.Context, w http.ResponseWriter, key string, prompt cache.Prompt) (*openai2.ChatCompletionResponse, bool) {
+	model := prompt.Model
+	if data, ok := h.cache.Get(cache.WithPrompt(ctx, prompt), key); ok {
+		var resp openai2.ChatCompletionResponse
+		if err := json.Unmarshal(data, &resp); err == nil {
+			w.Header().Set("X-Cache", "HIT")
+			if h.cacheRecorder != nil {
+				h.cacheRecorder.RecordHit(chatCacheEndpoint, model, h.cachePricing.Estimate(model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens))
+			}
+			return &resp, true
+		}
+		slog.WarnContext(ctx, "discarding undecodable cache entry", "key", key)
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	if h.cacheRecorder != nil {
+		h.cacheRecorder.RecordMiss(chatCacheEndpoint, model)
+	}
+	return nil, false
+}
+
+// storeResponse caches resp under key for ttl
+func (h *ChatHandler) storeResponse(ctx context.Context, key string, prompt cache.Prompt, resp *openai2.ChatCompletionResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := h.cache.Set(cache.WithPrompt(ctx, prompt), key, data, ttl); err != nil {
+		slog.WarnContext(ctx, "failed to cache response", "error", err)
+		return err
+	}
+	return nil
+}
+
+// CacheEntry is a known request and the response to serve for it
+type CacheEntry struct {
+	Request  openai2.ChatCompletionRequest  `json:"request"`
+	Response openai2.ChatCompletionResponse `json:"response"`
+
+	// TenantID scopes the entry to a tenant, as authentication hooks would
+	// for its requests. Empty uses the tenant in the context passed to Prefill.
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// Prefill stores entries in the cache for ttl (0 uses the handler's TTL), so the
+// first matching request is a hit. Entries are keyed as ServeHTTP keys their
+// requests: scoped to their tenant, after routing, model rewrites and the
+// BeforeRequest hooks. Every entry is validated before any is stored.
+func (h *ChatHandler) Prefill(ctx context.Context, entries []CacheEntry, ttl time.Duration) error {
+	if h.cache == nil {
+		return NewValidationError("response caching is not enabled")
+	}
+	if ttl == 0 {
+		ttl = h.cacheTTL
+	}
+
+	type prefill struct {
+		ctx    context.Context
+		key    string
+		prompt cache.Prompt
+		resp   *openai2.ChatCompletionResponse
+	}
+	prefills := make([]prefill, 0, len(entries))
+	for i := range entries {
+		entry := &entries[i]
+		req := entry.Request
+		if req.Model == "" {
+			return NewValidationError(fmt.Sprintf("entry %d: model is required", i))
+		}
+		if len(req.Messages) == 0 {
+			return NewValidationError(fmt.Sprintf("entry %d: messages is required", i))
+		}
+		if len(entry.Response.Choices) == 0 {
+			return NewValidationError(fmt.Sprintf("entry %d: response must have at least one choice", i))
+		}
+
+		// Key the entry as ServeHTTP would after routing and the request hooks
+		entryCtx := ctx
+		if entry.TenantID != "" {
+			entryCtx = context.WithValue(ctx, "tenant_id", entry.TenantID)
+		}
+		routed, routeErr := routeModel(entryCtx, h.hooks, req.Model)
+		if routeErr != nil {
+			return routeErr
+		}
+		req.Model = routed
+		prov, modelRewrite := h.registry.Resolve(req.Model)
+		if prov == nil {
+			return NewNotFoundError(fmt.Sprintf("entry %d: model not found: %s", i, req.Model))
+		}
+		originalModel := req.Model
+		if modelRewrite != "" {
+			req.Model = modelRewrite
+		}
+		entryCtx = withRouteInfo(entryCtx, prov, originalModel, req.Model)
+		// Hooks may edit messages in place; leave the caller's entry intact
+		req.Messages = append([]openai2.Message(nil), req.Messages...)
+		for _, hh := range h.hooks.RequestHooks() {
+			if err := hh.BeforeRequest(entryCtx, &req); err != nil {
+				return fmt.Errorf("entry %d: hook error: %w", i, err)
+			}
+		}
+		key, prompt, ok := chatCacheKey(entryCtx, prov, &req)
+		if !ok {
+			return NewValidationError(fmt.Sprintf("entry %d: request is not cacheable (requires temperature 0, no tools, no stream and no store)", i))
+		}
+
+		resp := entry.Response
+		if resp.Object == "" {
+			resp.Object = "chat.completion"
+		}
+		if resp.Model == "" {
+			resp.Model = entry.Request.Model
+		}
+		prefills = append(prefills, prefill{ctx: entryCtx, key: key, prompt: prompt, resp: &resp})
+	}
+
+	for _, p := range prefills {
+		if err := h.storeResponse(p.ctx, p.key, p.prompt, p.resp, ttl); err != nil {
+			return fmt.Errorf("failed to prefill cache: %w", err)
+		}
+	}
+	return nil
+}
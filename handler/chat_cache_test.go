@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/cache"
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// countingChatProvider counts the requests that reach the provider
+type countingChatProvider struct {
+	mockChatProvider
+	calls int
+}
+
+func (m *countingChatProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.calls++
+	return m.mockChatProvider.SendRequest(ctx, req)
+}
+
+func newCachingChatHandler(prov provider.Provider) (*ChatHandler, *cache.Counters) {
+	counters := cache.NewCounters()
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetCache(cache.NewLRUCache(cache.DefaultConfig()), time.Minute)
+	handler.SetCacheRecorder(counters, cache.Pricing{"gpt-4": {Input: 30, Output: 60}})
+	return handler, counters
+}
+
+func postChatBody(handler *ChatHandler, body map[string]any) *httptest.ResponseRecorder {
+	bodyBytes, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+	return w
+}
+
+func deterministicChat(content string) map[string]any {
+	return map[string]any{
+		"model":       "gpt-4",
+		"temperature": 0,
+		"messages":    []map[string]string{{"role": "user", "content": content}},
+	}
+}
+
+func TestChatHandler_CacheHit(t *testing.T) {
+	prov := &countingChatProvider{}
+	handler, counters := newCachingChatHandler(prov)
+
+	first := postChatBody(handler, deterministicChat("Hello"))
+	second := postChatBody(handler, deterministicChat("Hello"))
+
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("expected 200s, got %d and %d", first.Code, second.Code)
+	}
+	if got := first.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("expected first request to miss, got X-Cache %q", got)
+	}
+	if got := second.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("expected repeated request to hit, got X-Cache %q", got)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("expected cached body\nfirst:  %s\nsecond: %s", first.Body.String(), second.Body.String())
+	}
+	if prov.calls != 1 {
+		t.Errorf("expected 1 provider call, got %d", prov.calls)
+	}
+
+	stats := counters.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %+v", stats)
+	}
+	if stats.TokensSaved == 0 || stats.EstimatedCostSaved == 0 {
+		t.Errorf("expected savings to be recorded, got %+v", stats)
+	}
+}
+
+func TestChatHandler_CacheMissOnDifferentRequest(t *testing.T) {
+	prov := &countingChatProvider{}
+	handler, counters := newCachingChatHandler(prov)
+
+	postChatBody(handler, deterministicChat("Hello"))
+	w := postChatBody(handler, deterministicChat("Goodbye"))
+
+	if got := w.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("expected differing request to miss, got X-Cache %q", got)
+	}
+	if prov.calls != 2 {
+		t.Errorf("expected 2 provider calls, got %d", prov.calls)
+	}
+	if stats := counters.Stats(); stats.Hits != 0 || stats.Misses != 2 {
+		t.Errorf("expected 2 misses, got %+v", stats)
+	}
+}
+
+func TestChatHandler_CacheSkipped(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(body map[string]any)
+	}{
+		{"no temperature", func(body map[string]any) { delete(body, "temperature") }},
+		{"nonzero temperature", func(body map[string]any) { body["temperature"] = 0.7 }},
+		{"store", func(body map[string]any) { body["store"] = true }},
+		{"tools", func(body map[string]any) {
+			body["tools"] = []map[string]any{{"type": "function", "function": map[string]any{"name": "get_weather"}}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &countingChatProvider{}
+			handler, counters := newCachingChatHandler(prov)
+
+			for range 2 {
+				body := deterministicChat("Hello")
+				tt.edit(body)
+				w := postChatBody(handler, body)
+				if got := w.Header().Get("X-Cache"); got != "" {
+					t.Errorf("expected no X-Cache header, got %q", got)
+				}
+			}
+			if prov.calls != 2 {
+				t.Errorf("expected every request to reach the provider, got %d calls", prov.calls)
+			}
+			if stats := counters.Stats(); stats.Hits != 0 || stats.Misses != 0 {
+				t.Errorf("expected no cache outcomes, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestChatHandler_SemanticCache(t *testing.T) {
+	// Prompts embed to the same vector when they match ignoring case and punctuation
+	embedder := cache.EmbedderFunc(func(ctx context.Context, text string) ([]float32, error) {
+		vector := make([]float32, 26)
+		for _, r := range strings.ToLower(text) {
+			if 'a' <= r && r <= 'z' {
+				vector[r-'a']++
+			}
+		}
+		return vector, nil
+	})
+	semantic := cache.NewSemanticCache(cache.NewLRUCache(cache.DefaultConfig()), cache.NewMemoryVectorStore(), embedder, cache.SemanticConfig{})
+
+	prov := &countingChatProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetCache(semantic, time.Minute)
+
+	postChatBody(handler, deterministicChat("What is the capital of France?"))
+	similar := postChatBody(handler, deterministicChat("what is the capital of france"))
+	distinct := postChatBody(handler, deterministicChat("Summarize this quarterly report"))
+
+	if got := similar.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("expected near-identical prompt to hit, got X-Cache %q", got)
+	}
+	if got := distinct.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("expected distinct prompt to miss, got X-Cache %q", got)
+	}
+	if prov.calls != 2 {
+		t.Errorf("expected 2 provider calls, got %d", prov.calls)
+	}
+}
+
+func prefillEntry(content, answer string) CacheEntry {
+	temperature := 0.0
+	return CacheEntry{
+		Request: openai.ChatCompletionRequest{
+			Model:       "gpt-4",
+			Temperature: &temperature,
+			Messages:    []openai.Message{{Role: "user", Content: content}},
+		},
+		Response: openai.ChatCompletionResponse{
+			ID:      "chatcmpl-faq",
+			Choices: []openai.Choice{{Message: openai.Message{Role: "assistant", Content: answer}, FinishReason: "stop"}},
+		},
+	}
+}
+
+func TestChatHandler_Prefill(t *testing.T) {
+	prov := &countingChatProvider{}
+	handler, counters := newCachingChatHandler(prov)
+
+	if err := handler.Prefill(context.Background(), []CacheEntry{prefillEntry("What are your opening hours?", "9am to 5pm")}, time.Minute); err != nil {
+		t.Fatalf("unexpected prefill error: %v", err)
+	}
+
+	w := postChatBody(handler, deterministicChat("What are your opening hours?"))
+	if got := w.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("expected prefilled request to hit, got X-Cache %q", got)
+	}
+	if prov.calls != 0 {
+		t.Errorf("expected no provider calls, got %d", prov.calls)
+	}
+
+	var resp openai.ChatCompletionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "9am to 5pm" || resp.Object != "chat.completion" || resp.Model != "gpt-4" {
+		t.Errorf("unexpected prefilled response: %+v", resp)
+	}
+	if stats := counters.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("expected 1 hit, got %+v", stats)
+	}
+}
+
+// uppercaseHook rewrites every message to upper case before the request is sent
+type uppercaseHook struct{}
+
+func (uppercaseHook) Name() string { return "uppercase" }
+
+func (uppercaseHook) BeforeRequest(ctx context.Context, req *openai.ChatCompletionRequest) error {
+	for i := range req.Messages {
+		req.Messages[i].Content = strings.ToUpper(req.Messages[i].Content)
+	}
+	return nil
+}
+
+func (uppercaseHook) AfterRequest(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) error {
+	return nil
+}
+
+func TestChatHandler_PrefillTenantAndHooks(t *testing.T) {
+	prov := &countingChatProvider{}
+	hooks := hook.NewRegistry()
+	hooks.Register(&tenantAuthHook{}, uppercaseHook{})
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hooks)
+	handler.SetCache(cache.NewLRUCache(cache.DefaultConfig()), time.Minute)
+
+	entry := prefillEntry("What are your opening hours?", "9am to 5pm")
+	entry.TenantID = "acme"
+	if err := handler.Prefill(context.Background(), []CacheEntry{entry}, time.Minute); err != nil {
+		t.Fatalf("unexpected prefill error: %v", err)
+	}
+	if entry.Request.Messages[0].Content != "What are your opening hours?" {
+		t.Errorf("expected the caller's entry unchanged, got %q", entry.Request.Messages[0].Content)
+	}
+
+	post := func(tenant string) string {
+		bodyBytes, _ := json.Marshal(deterministicChat("What are your opening hours?"))
+		req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+		req.Header.Set("Authorization", tenant)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Header().Get("X-Cache")
+	}
+	if got := post("acme"); got != "HIT" {
+		t.Errorf("expected the entry's tenant to hit, got X-Cache %q", got)
+	}
+	if got := post("globex"); got != "MISS" {
+		t.Errorf("expected other tenants to miss, got X-Cache %q", got)
+	}
+}
+
+func TestChatHandler_PrefillValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(entry *CacheEntry)
+	}{
+		{"missing model", func(e *CacheEntry) { e.Request.Model = "" }},
+		{"missing messages", func(e *CacheEntry) { e.Request.Messages = nil }},
+		{"no choices", func(e *CacheEntry) { e.Response.Choices = nil }},
+		{"not deterministic", func(e *CacheEntry) { e.Request.Temperature = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+			lru := cache.NewLRUCache(cache.DefaultConfig())
+			handler.SetCache(lru, time.Minute)
+
+			// A valid entry is not stored when another entry is invalid
+			invalid := prefillEntry("Hello", "Hi")
+			tt.edit(&invalid)
+			err := handler.Prefill(context.Background(), []CacheEntry{prefillEntry("Hi", "Hello"), invalid}, 0)
+
+			var gwErr *GatewayError
+			if !errors.As(err, &gwErr) || gwErr.Code != http.StatusBadRequest {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if items := lru.Stats().Items; items != 0 {
+				t.Errorf("expected nothing to be cached, got %d items", items)
+			}
+		})
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/model"
 	"github.com/deeplooplabs/ai-gateway/provider"
 	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/quota"
 )
 
 func TestChatHandler_ServeHTTP(t *testing.T) {
@@ -85,6 +91,422 @@ func TestChatHandler_Stream(t *testing.T) {
 	}
 }
 
+func TestChatHandler_StreamIncludeUsage(t *testing.T) {
+	manager := quota.NewMemoryManager(&quota.Config{ResetPeriod: quota.Never, Enabled: true})
+
+	handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+	handler.SetQuotaManager(manager)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":          "gpt-4",
+		"messages":       []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": true},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "tenant-1"))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	if len(events) < 2 || events[len(events)-1] != "data: [DONE]" {
+		t.Fatalf("expected stream to end with [DONE], got %q", w.Body.String())
+	}
+
+	var chunk openai2.ChatCompletionStreamResponse
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(events[len(events)-2], "data: ")), &chunk); err != nil {
+		t.Fatalf("failed to decode usage chunk: %v", err)
+	}
+	if chunk.Usage == nil || chunk.Usage.TotalTokens == 0 {
+		t.Fatalf("expected usage on final chunk, got %+v", chunk.Usage)
+	}
+	if len(chunk.Choices) != 0 {
+		t.Errorf("expected no choices on usage chunk, got %d", len(chunk.Choices))
+	}
+
+	usage, err := manager.GetUsage(context.Background(), "tenant-1")
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+	if usage.TotalTokens != int64(chunk.Usage.TotalTokens) {
+		t.Errorf("expected recorded total %d, got %d", chunk.Usage.TotalTokens, usage.TotalTokens)
+	}
+}
+
+func TestChatHandler_RecordsCost(t *testing.T) {
+	manager := quota.NewMemoryManager(&quota.Config{
+		ResetPeriod: quota.Never,
+		Enabled:     true,
+		Prices:      quota.PriceTable{"gpt-4": {Input: 1, Output: 2}},
+	})
+
+	handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+	handler.SetQuotaManager(manager)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	req = req.WithContext(context.WithValue(req.Context(), "tenant_id", "tenant-1"))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	usage, err := manager.GetUsage(context.Background(), "tenant-1")
+	if err != nil {
+		t.Fatalf("failed to get usage: %v", err)
+	}
+	// 10 prompt tokens at $1/1K and 5 completion tokens at $2/1K
+	if usage.TotalTokens != 15 || usage.Cost < 0.0199 || usage.Cost > 0.0201 {
+		t.Errorf("expected 15 tokens costing $0.02, got %+v", usage)
+	}
+}
+
+// statsRecordingHook records the stream stats it receives
+type statsRecordingHook struct {
+	stats []hook.StreamStats
+}
+
+func (h *statsRecordingHook) Name() string {
+	return "stats-recorder"
+}
+
+func (h *statsRecordingHook) OnStreamStats(ctx context.Context, stats hook.StreamStats) {
+	h.stats = append(h.stats, stats)
+}
+
+// multiChunkProvider streams one content chunk per word
+type multiChunkProvider struct {
+	mockChatProvider
+	words []string
+}
+
+func (m *multiChunkProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	chunkChan := make(chan *provider.Chunk, len(m.words)+1)
+	errChan := make(chan error, 1)
+	for _, word := range m.words {
+		chunkData := `{"id":"test-id","object":"chat.completion.chunk","created":1234567890,"model":"` + req.Model + `","choices":[{"index":0,"delta":{"content":"` + word + `"}}]}`
+		chunkChan <- provider.NewOpenAIChunk([]byte(chunkData))
+	}
+	chunkChan <- provider.NewOpenAIChunkDone()
+	close(chunkChan)
+	close(errChan)
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, func() error { return nil }), nil
+}
+
+// assertStreamStats checks that stats were reported once per chunk in order
+func assertStreamStats(t *testing.T, stats []hook.StreamStats, want int) {
+	t.Helper()
+	if len(stats) != want {
+		t.Fatalf("expected %d stats callbacks, got %d", want, len(stats))
+	}
+	for i, s := range stats {
+		if s.Index != i {
+			t.Errorf("expected index %d, got %d", i, s.Index)
+		}
+		if s.Bytes <= 0 {
+			t.Errorf("expected positive byte size at index %d, got %d", i, s.Bytes)
+		}
+		if i > 0 && s.Elapsed < stats[i-1].Elapsed {
+			t.Errorf("elapsed decreased at index %d", i)
+		}
+	}
+}
+
+func TestChatHandler_StreamStats(t *testing.T) {
+	recorder := &statsRecordingHook{}
+	hooks := hook.NewRegistry()
+	hooks.Register(recorder)
+
+	prov := &multiChunkProvider{words: []string{"Hello", " there", "!"}}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hooks)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   true,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assertStreamStats(t, recorder.stats, 3)
+}
+
+// choicesProvider returns one choice per requested n
+type choicesProvider struct {
+	mockChatProvider
+}
+
+func (m *choicesProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	n := 1
+	if req.N != nil {
+		n = *req.N
+	}
+
+	if req.Stream {
+		chunkChan := make(chan *provider.Chunk, 2*n+1)
+		errChan := make(chan error, 1)
+		for i := 0; i < n; i++ {
+			chunkData := fmt.Sprintf(`{"id":"test-id","object":"chat.completion.chunk","created":1234567890,"model":"%s","choices":[{"index":%d,"delta":{"role":"assistant","content":"choice %d"}}]}`, req.Model, i, i)
+			chunkChan <- provider.NewOpenAIChunk([]byte(chunkData))
+		}
+		for i := 0; i < n; i++ {
+			chunkData := fmt.Sprintf(`{"id":"test-id","object":"chat.completion.chunk","created":1234567890,"model":"%s","choices":[{"index":%d,"delta":{},"finish_reason":"stop"}]}`, req.Model, i)
+			chunkChan <- provider.NewOpenAIChunk([]byte(chunkData))
+		}
+		chunkChan <- provider.NewOpenAIChunkDone()
+		close(chunkChan)
+		close(errChan)
+		return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, func() error { return nil }), nil
+	}
+
+	choices := make([]openai2.Choice, n)
+	for i := range choices {
+		choices[i] = openai2.Choice{
+			Index:        i,
+			Message:      openai2.Message{Role: "assistant", Content: fmt.Sprintf("choice %d", i)},
+			FinishReason: "stop",
+		}
+	}
+	return provider.NewChatCompletionResponse(&openai2.ChatCompletionResponse{
+		ID:      "test-id",
+		Object:  "chat.completion",
+		Model:   req.Model,
+		Choices: choices,
+	}), nil
+}
+
+func TestChatHandler_MultipleChoices(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &choicesProvider{}}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"n":        2,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	var resp openai2.ChatCompletionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(resp.Choices))
+	}
+	for i, choice := range resp.Choices {
+		if choice.Index != i || choice.Message.Content != fmt.Sprintf("choice %d", i) {
+			t.Errorf("unexpected choice %d: %+v", i, choice)
+		}
+	}
+}
+
+func TestChatHandler_StreamMultipleChoices(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &choicesProvider{}}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   true,
+		"n":        2,
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	acc := openai2.NewStreamAccumulator()
+	for _, event := range strings.Split(strings.TrimSpace(w.Body.String()), "\n\n") {
+		data := strings.TrimPrefix(event, "data: ")
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openai2.ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to decode chunk: %v", err)
+		}
+		acc.Add(&chunk)
+	}
+
+	resp := acc.Response()
+	if len(resp.Choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(resp.Choices))
+	}
+	for i, choice := range resp.Choices {
+		if choice.Index != i || choice.Message.Content != fmt.Sprintf("choice %d", i) || choice.FinishReason != "stop" {
+			t.Errorf("unexpected choice %d: %+v", i, choice)
+		}
+	}
+}
+
+// routeRecordingHook records the route info seen by AfterRequest
+type routeRecordingHook struct {
+	info ai_gateway.RouteInfo
+	ok   bool
+}
+
+func (h *routeRecordingHook) Name() string {
+	return "route-recorder"
+}
+
+func (h *routeRecordingHook) BeforeRequest(ctx context.Context, req *openai2.ChatCompletionRequest) error {
+	return nil
+}
+
+func (h *routeRecordingHook) AfterRequest(ctx context.Context, req *openai2.ChatCompletionRequest, resp *openai2.ChatCompletionResponse) error {
+	h.info, h.ok = ai_gateway.RouteInfoFromContext(ctx)
+	return nil
+}
+
+// rewritingRegistry resolves every model to its provider with a fixed rewrite
+type rewritingRegistry struct {
+	mapModelRegistry
+	rewrite string
+}
+
+func (m *rewritingRegistry) Resolve(model string) (provider.Provider, string) {
+	return m.provider, m.rewrite
+}
+
+func TestChatHandler_RouteInfo(t *testing.T) {
+	registry := &rewritingRegistry{mapModelRegistry: mapModelRegistry{provider: &mockChatProvider{}}, rewrite: "gpt-4o"}
+	recorder := &routeRecordingHook{}
+	hooks := hook.NewRegistry()
+	hooks.Register(recorder)
+
+	handler := NewChatHandler(registry, hooks)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !recorder.ok {
+		t.Fatal("expected route info in hook context")
+	}
+	if recorder.info.Provider != "mock" {
+		t.Errorf("expected provider 'mock', got '%s'", recorder.info.Provider)
+	}
+	if recorder.info.OriginalModel != "gpt-4" || recorder.info.Model != "gpt-4o" {
+		t.Errorf("expected rewrite gpt-4 -> gpt-4o, got %s -> %s", recorder.info.OriginalModel, recorder.info.Model)
+	}
+}
+
+// disclaimerHook appends a disclaimer to every choice, recording hook order
+type disclaimerHook struct {
+	calls []string
+}
+
+func (h *disclaimerHook) Name() string {
+	return "disclaimer"
+}
+
+func (h *disclaimerHook) BeforeRequest(ctx context.Context, req *openai2.ChatCompletionRequest) error {
+	return nil
+}
+
+func (h *disclaimerHook) AfterRequest(ctx context.Context, req *openai2.ChatCompletionRequest, resp *openai2.ChatCompletionResponse) error {
+	h.calls = append(h.calls, "after")
+	return nil
+}
+
+func (h *disclaimerHook) MutateResponse(ctx context.Context, req *openai2.ChatCompletionRequest, resp *openai2.ChatCompletionResponse) (*openai2.ChatCompletionResponse, error) {
+	h.calls = append(h.calls, "mutate")
+	mutated := *resp
+	mutated.Choices = append([]openai2.Choice(nil), resp.Choices...)
+	for i := range mutated.Choices {
+		mutated.Choices[i].Message.Content += "\n\nAnswers may be inaccurate."
+	}
+	return &mutated, nil
+}
+
+func TestChatHandler_ResponseMutationHook(t *testing.T) {
+	hooks := hook.NewRegistry()
+	disclaimer := &disclaimerHook{}
+	hooks.Register(disclaimer)
+	handler := NewChatHandler(newMockRegistry(), hooks)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp openai2.ChatCompletionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "Hello!\n\nAnswers may be inaccurate." {
+		t.Errorf("expected the mutated content, got %q", got)
+	}
+	if strings.Join(disclaimer.calls, ",") != "after,mutate" {
+		t.Errorf("expected AfterRequest before MutateResponse, got %v", disclaimer.calls)
+	}
+}
+
+// failingChatProvider fails every request with err
+type failingChatProvider struct {
+	mockChatProvider
+	err error
+}
+
+func (m *failingChatProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	return nil, m.err
+}
+
+func TestChatHandler_ProviderErrorKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantType string
+	}{
+		{name: "rate limited", err: &provider.Error{Kind: provider.ErrorKindRateLimited, StatusCode: 429}, wantCode: http.StatusTooManyRequests, wantType: "rate_limit_error"},
+		{name: "timeout", err: &provider.Error{Kind: provider.ErrorKindTimeout, Err: context.DeadlineExceeded}, wantCode: http.StatusGatewayTimeout, wantType: "timeout_error"},
+		{name: "upstream", err: &provider.Error{Kind: provider.ErrorKindUpstream, StatusCode: 500}, wantCode: http.StatusBadGateway, wantType: "api_error"},
+		{name: "unclassified", err: fmt.Errorf("boom"), wantCode: http.StatusBadGateway, wantType: "api_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChatHandler(&mapModelRegistry{provider: &failingChatProvider{err: tt.err}}, hook.NewRegistry())
+
+			bodyBytes, _ := json.Marshal(map[string]any{
+				"model":    "gpt-4",
+				"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+			})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"type":"`+tt.wantType+`"`) {
+				t.Errorf("expected type %s, got %s", tt.wantType, w.Body.String())
+			}
+		})
+	}
+}
+
 func newMockRegistry() model.ModelRegistry {
 	prov := &mockChatProvider{}
 	return &mapModelRegistry{provider: prov}
@@ -157,3 +579,108 @@ func (m *mockChatProvider) SendRequest(ctx context.Context, req *provider.Reques
 		},
 	}), nil
 }
+
+// slowAfterRequestHook stalls every AfterRequest call
+type slowAfterRequestHook struct {
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (h *slowAfterRequestHook) Name() string {
+	return "slow-after-request"
+}
+
+func (h *slowAfterRequestHook) BeforeRequest(ctx context.Context, req *openai2.ChatCompletionRequest) error {
+	return nil
+}
+
+func (h *slowAfterRequestHook) AfterRequest(ctx context.Context, req *openai2.ChatCompletionRequest, resp *openai2.ChatCompletionResponse) error {
+	time.Sleep(h.delay)
+	h.calls.Add(1)
+	return nil
+}
+
+func TestChatHandler_AsyncHookDoesNotDelayResponse(t *testing.T) {
+	hooks := hook.NewRegistry(hook.WithAsync(1))
+	slow := &slowAfterRequestHook{delay: 300 * time.Millisecond}
+	hooks.RegisterAsync(slow)
+	handler := NewChatHandler(newMockRegistry(), hooks)
+
+	start := time.Now()
+	w := postChatBody(handler, map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	elapsed := time.Since(start)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if elapsed >= slow.delay {
+		t.Errorf("expected the response before the async hook finished, took %v", elapsed)
+	}
+
+	hooks.Close()
+	if slow.calls.Load() != 1 {
+		t.Errorf("expected the async hook to run once, got %d", slow.calls.Load())
+	}
+}
+
+func TestChatHandler_ForwardsRequestFields(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			var upstream map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&upstream)
+				if stream {
+					w.Header().Set("Content-Type", "text/event-stream")
+					w.Write([]byte("data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n"))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+			}))
+			defer server.Close()
+
+			prov := provider.NewHTTPProviderWithBaseURL(server.URL, "test-key")
+			handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+			w := postChatBody(handler, map[string]any{
+				"model":                 "o3-mini",
+				"messages":              []map[string]string{{"role": "user", "content": "Hello"}},
+				"stream":                stream,
+				"seed":                  42,
+				"logprobs":              true,
+				"top_logprobs":          3,
+				"service_tier":          "flex",
+				"user":                  "user-1",
+				"metadata":              map[string]string{"run": "eval-7"},
+				"max_tokens":            100,
+				"max_completion_tokens": 2000,
+			})
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			want := map[string]any{
+				"seed":                  float64(42),
+				"logprobs":              true,
+				"top_logprobs":          float64(3),
+				"service_tier":          "flex",
+				"user":                  "user-1",
+				"max_completion_tokens": float64(2000),
+			}
+			for field, value := range want {
+				if upstream[field] != value {
+					t.Errorf("expected %s=%v upstream, got %v", field, value, upstream[field])
+				}
+			}
+			if metadata, _ := upstream["metadata"].(map[string]any); metadata["run"] != "eval-7" {
+				t.Errorf("expected the metadata upstream, got %v", upstream["metadata"])
+			}
+			if _, ok := upstream["max_tokens"]; ok {
+				t.Errorf("expected max_tokens to be replaced by max_completion_tokens, got %v", upstream["max_tokens"])
+			}
+		})
+	}
+}
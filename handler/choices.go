@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// ChoicesFallback controls how the chat handler serves n > 1 with providers
+// that always return a single choice (see provider.IsSingleChoice)
+type ChoicesFallback int
+
+const (
+	// ChoicesFanOut sends n concurrent single-choice requests and merges their
+	// choices (the default). Streaming requests are rejected.
+	ChoicesFanOut ChoicesFallback = iota
+	// ChoicesReject rejects the request with a validation error
+	ChoicesReject
+)
+
+// SetChoicesFallback sets how n > 1 is served by single-choice providers
+func (h *ChatHandler) SetChoicesFallback(fallback ChoicesFallback) {
+	h.choices = fallback
+}
+
+// needsFanOut reports whether req asks for more choices than prov returns per request
+func needsFanOut(n *int, prov provider.Provider) bool {
+	return n != nil && *n > 1 && provider.IsSingleChoice(prov)
+}
+
+// checkChoices returns an error if req asks for n > 1 choices that prov can't provide
+func (h *ChatHandler) checkChoices(req *openai2.ChatCompletionRequest, prov provider.Provider) error {
+	if !needsFanOut(req.N, prov) {
+		return nil
+	}
+	if h.choices == ChoicesReject {
+		return NewValidationError(fmt.Sprintf("n > 1 is not supported for model %s", req.Model))
+	}
+	if req.Stream {
+		return NewValidationError(fmt.Sprintf("n > 1 is not supported when streaming model %s", req.Model))
+	}
+	return nil
+}
+
+// sendChatRequest sends a non-streaming chat request, fanning n > 1 out over
+// single-choice providers. It returns the response with the upstream headers
+// to pass back to the client.
+func sendChatRequest(ctx context.Context, prov provider.Provider, req *provider.Request) (*openai2.ChatCompletionResponse, http.Header, error) {
+	if needsFanOut(req.N, prov) {
+		markDegraded(ctx, DegradedFallback)
+		chatResp, headers, err := sendFanOut(ctx, prov, req, *req.N)
+		if err != nil {
+			return nil, nil, NewProviderError("provider error", err)
+		}
+		return chatResp, headers, nil
+	}
+
+	// Send request to provider using unified interface
+	resp, err := prov.SendRequest(ctx, req)
+	if err != nil {
+		return nil, nil, NewProviderError("provider error", err)
+	}
+	defer resp.Close()
+
+	// Convert response to Chat Completions format if needed
+	chatResp, err := resp.GetChatCompletion()
+	if err != nil {
+		return nil, nil, NewProviderError("failed to convert response", err)
+	}
+	if chatResp == nil {
+		return nil, nil, NewProviderError("nil response", nil)
+	}
+	return chatResp, resp.Headers, nil
+}
+
+// sendFanOut sends n single-choice copies of req concurrently and merges their
+// choices in request order, summing usage. The first failure cancels the rest.
+// The upstream headers are those of the first request.
+func sendFanOut(ctx context.Context, prov provider.Provider, req *provider.Request, n int) (*openai2.ChatCompletionResponse, http.Header, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*openai2.ChatCompletionResponse, n)
+	headers := make([]http.Header, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if rec := recover(); rec != nil {
+					errs[i] = panicError(ctx, nil, rec)
+					cancel()
+				}
+			}()
+			single := *req
+			single.N = nil
+			// Offset the seed so the copies differ but stay reproducible
+			if req.Seed != nil {
+				seed := *req.Seed + i
+				single.Seed = &seed
+			}
+			resp, err := prov.SendRequest(ctx, &single)
+			if err == nil {
+				defer resp.Close()
+				headers[i] = resp.Headers
+				responses[i], err = resp.GetChatCompletion()
+			}
+			if err == nil && responses[i] == nil {
+				err = errors.New("nil response")
+			}
+			if err != nil {
+				errs[i] = err
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	merged := *responses[0]
+	merged.Choices = make([]openai2.Choice, 0, n)
+	merged.Usage = openai2.Usage{}
+	for _, resp := range responses {
+		for _, choice := range resp.Choices {
+			choice.Index = len(merged.Choices)
+			merged.Choices = append(merged.Choices, choice)
+		}
+		merged.Usage.PromptTokens += resp.Usage.PromptTokens
+		merged.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		merged.Usage.TotalTokens += resp.Usage.TotalTokens
+	}
+	return &merged, headers[0], nil
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// singleChoiceProvider ignores n and counts its calls
+type singleChoiceProvider struct {
+	mockChatProvider
+	calls atomic.Int32
+}
+
+func (m *singleChoiceProvider) SingleChoice() bool {
+	return true
+}
+
+func (m *singleChoiceProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.calls.Add(1)
+	if req.N != nil {
+		return nil, context.Canceled
+	}
+	return m.mockChatProvider.SendRequest(ctx, req)
+}
+
+func newChoicesRequest(t *testing.T, body map[string]any) *http.Request {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestChatHandler_ChoicesFanOut(t *testing.T) {
+	prov := &singleChoiceProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, map[string]any{
+		"model":    "claude-3",
+		"messages": []map[string]string{{"role": "user", "content": "Hi"}},
+		"n":        3,
+	}))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if calls := prov.calls.Load(); calls != 3 {
+		t.Errorf("expected 3 provider calls, got %d", calls)
+	}
+
+	var resp openai2.ChatCompletionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Choices) != 3 {
+		t.Fatalf("expected 3 choices, got %d", len(resp.Choices))
+	}
+	for i, choice := range resp.Choices {
+		if choice.Index != i {
+			t.Errorf("expected choice %d to have index %d, got %d", i, i, choice.Index)
+		}
+		if choice.Message.Content != "Hello!" {
+			t.Errorf("unexpected content for choice %d: %q", i, choice.Message.Content)
+		}
+	}
+	if resp.Usage.PromptTokens != 30 || resp.Usage.CompletionTokens != 15 || resp.Usage.TotalTokens != 45 {
+		t.Errorf("expected summed usage 30/15/45, got %+v", resp.Usage)
+	}
+}
+
+func TestChatHandler_ChoicesReject(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback ChoicesFallback
+		stream   bool
+	}{
+		{"reject", ChoicesReject, false},
+		{"fan out stream", ChoicesFanOut, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &singleChoiceProvider{}
+			handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+			handler.SetChoicesFallback(tt.fallback)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newChoicesRequest(t, map[string]any{
+				"model":    "claude-3",
+				"messages": []map[string]string{{"role": "user", "content": "Hi"}},
+				"n":        2,
+				"stream":   tt.stream,
+			}))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if calls := prov.calls.Load(); calls != 0 {
+				t.Errorf("expected no provider calls, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestChatHandler_ChoicesSingle(t *testing.T) {
+	// n = 1 is sent as is
+	prov := &singleChoiceProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetChoicesFallback(ChoicesReject)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, map[string]any{
+		"model":    "claude-3",
+		"messages": []map[string]string{{"role": "user", "content": "Hi"}},
+	}))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if calls := prov.calls.Load(); calls != 1 {
+		t.Errorf("expected 1 provider call, got %d", calls)
+	}
+}
+
+// seedRecordingProvider is a single-choice provider recording the seed of each call
+type seedRecordingProvider struct {
+	singleChoiceProvider
+	mu    sync.Mutex
+	seeds []int
+}
+
+func (m *seedRecordingProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.mu.Lock()
+	m.seeds = append(m.seeds, *req.Seed)
+	m.mu.Unlock()
+	return m.singleChoiceProvider.SendRequest(ctx, req)
+}
+
+func TestChatHandler_ChoicesFanOutSeeds(t *testing.T) {
+	prov := &seedRecordingProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, map[string]any{
+		"model":    "claude-3",
+		"messages": []map[string]string{{"role": "user", "content": "Hi"}},
+		"n":        3,
+		"seed":     7,
+	}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	slices.Sort(prov.seeds)
+	if !slices.Equal(prov.seeds, []int{7, 8, 9}) {
+		t.Errorf("expected each copy to get its own seed, got %v", prov.seeds)
+	}
+}
+
+// panickingSingleChoiceProvider panics on every fanned-out request
+type panickingSingleChoiceProvider struct {
+	singleChoiceProvider
+}
+
+func (m *panickingSingleChoiceProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	panic("provider bug")
+}
+
+func TestChatHandler_ChoicesFanOutPanic(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &panickingSingleChoiceProvider{}}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, map[string]any{
+		"model":    "claude-3",
+		"messages": []map[string]string{{"role": "user", "content": "Hi"}},
+		"n":        2,
+	}))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("expected a panicking copy to fail the request with 502, got %d: %s", w.Code, w.Body.String())
+	}
+}
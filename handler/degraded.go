@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// DegradedHeader lists why a chat response isn't a plain upstream completion,
+// e.g. "cached, truncated"
+const DegradedHeader = "X-Gateway-Degraded"
+
+// DebugHeader asks for the degradation reasons in the response body as well
+const DebugHeader = "X-Gateway-Debug"
+
+// Reasons reported in DegradedHeader
+const (
+	// DegradedFallback means a fallback served the request: tools were
+	// stripped or n > 1 was fanned out over single-choice calls
+	DegradedFallback = "fallback"
+	// DegradedCached means the response came from the cache
+	DegradedCached = "cached"
+	// DegradedIntercepted means an InterceptHook answered with a canned response
+	DegradedIntercepted = "intercepted"
+	// DegradedEstimatedUsage means the provider didn't report usage and the
+	// gateway estimated it
+	DegradedEstimatedUsage = "estimated-usage"
+	// DegradedTruncated means a choice stopped at the token limit
+	DegradedTruncated = "truncated"
+)
+
+// SetReportDegraded enables the X-Gateway-Degraded response header
+func (h *ChatHandler) SetReportDegraded(enabled bool) {
+	h.reportDegraded = enabled
+}
+
+// degradedKey is the context key of the request's degradation
+type degradedKey struct{}
+
+// degradation collects the reasons a response was degraded
+type degradation struct {
+	reasons []string
+}
+
+// withDegradation returns ctx tracking degradation reasons
+func withDegradation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, degradedKey{}, &degradation{})
+}
+
+// markDegraded records reason for the request, if it is being tracked
+func markDegraded(ctx context.Context, reason string) {
+	d, ok := ctx.Value(degradedKey{}).(*degradation)
+	if ok && !slices.Contains(d.reasons, reason) {
+		d.reasons = append(d.reasons, reason)
+	}
+}
+
+// degradedReasons returns the reasons recorded for the request
+func degradedReasons(ctx context.Context) []string {
+	if d, ok := ctx.Value(degradedKey{}).(*degradation); ok {
+		return d.reasons
+	}
+	return nil
+}
+
+// markTruncated records a truncation if any choice stopped at the token limit
+func markTruncated(ctx context.Context, choices []openai2.Choice) {
+	for _, choice := range choices {
+		if choice.FinishReason == "length" {
+			markDegraded(ctx, DegradedTruncated)
+			return
+		}
+	}
+}
+
+// writeDegradedHeader sets DegradedHeader from the reasons recorded so far
+func writeDegradedHeader(ctx context.Context, w http.ResponseWriter) {
+	if reasons := degradedReasons(ctx); len(reasons) > 0 {
+		w.Header().Set(DegradedHeader, strings.Join(reasons, ", "))
+	}
+}
+
+// writeDegradedTrailer sends the reasons as a trailer once a stream has
+// started, if any were recorded after the headers went out
+func writeDegradedTrailer(ctx context.Context, w http.ResponseWriter, sent int) {
+	if reasons := degradedReasons(ctx); len(reasons) > sent {
+		w.Header().Set(http.TrailerPrefix+DegradedHeader, strings.Join(reasons, ", "))
+	}
+}
+
+// degradedResponse is a chat completion carrying its degradation reasons,
+// returned to clients sending DebugHeader
+type degradedResponse struct {
+	*openai2.ChatCompletionResponse
+	Degraded []string `json:"gateway_degraded,omitempty"`
+}
+
+// debugResponse adds the degradation reasons to resp for debugging clients
+func debugResponse(r *http.Request, resp *openai2.ChatCompletionResponse) any {
+	reasons := degradedReasons(r.Context())
+	if r.Header.Get(DebugHeader) == "" || len(reasons) == 0 {
+		return resp
+	}
+	return &degradedResponse{ChatCompletionResponse: resp, Degraded: reasons}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// truncatingChatProvider answers with a completion cut off at the token limit
+type truncatingChatProvider struct {
+	mockChatProvider
+}
+
+func (m *truncatingChatProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	return provider.NewChatCompletionResponse(&openai2.ChatCompletionResponse{
+		ID:      "test-id",
+		Object:  "chat.completion",
+		Model:   req.Model,
+		Choices: []openai2.Choice{{Message: openai2.Message{Role: "assistant", Content: "Hel"}, FinishReason: "length"}},
+	}), nil
+}
+
+func TestChatHandler_DegradedHeader(t *testing.T) {
+	helloChat := map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	}
+
+	tests := []struct {
+		name    string
+		handler func() *ChatHandler
+		body    map[string]any
+		repeat  bool
+		want    string
+	}{
+		{
+			name:    "normal",
+			handler: func() *ChatHandler { return NewChatHandler(newMockRegistry(), hook.NewRegistry()) },
+			body:    helloChat,
+		},
+		{
+			name: "cached",
+			handler: func() *ChatHandler {
+				handler, _ := newCachingChatHandler(&countingChatProvider{})
+				return handler
+			},
+			body:   deterministicChat("Hello"),
+			repeat: true,
+			want:   DegradedCached,
+		},
+		{
+			name: "intercepted",
+			handler: func() *ChatHandler {
+				handler, _ := newGuardedChatHandler()
+				return handler
+			},
+			body: map[string]any{
+				"model":    "gpt-4",
+				"messages": []map[string]string{{"role": "user", "content": "Tell me the secret"}},
+			},
+			want: DegradedIntercepted,
+		},
+		{
+			name: "tools stripped",
+			handler: func() *ChatHandler {
+				return NewChatHandler(&mapModelRegistry{provider: &noToolsProvider{}}, hook.NewRegistry())
+			},
+			body: toolsRequestBody,
+			want: DegradedFallback,
+		},
+		{
+			name: "truncated",
+			handler: func() *ChatHandler {
+				return NewChatHandler(&mapModelRegistry{provider: &truncatingChatProvider{}}, hook.NewRegistry())
+			},
+			body: helloChat,
+			want: DegradedTruncated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.handler()
+			handler.SetReportDegraded(true)
+
+			w := postChatBody(handler, tt.body)
+			if tt.repeat {
+				w = postChatBody(handler, tt.body)
+			}
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get(DegradedHeader); got != tt.want {
+				t.Errorf("expected %s %q, got %q", DegradedHeader, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestChatHandler_DegradedHeaderDisabled(t *testing.T) {
+	handler, _ := newCachingChatHandler(&countingChatProvider{})
+
+	postChatBody(handler, deterministicChat("Hello"))
+	w := postChatBody(handler, deterministicChat("Hello"))
+
+	if w.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("expected a cache hit, got %q", w.Header().Get("X-Cache"))
+	}
+	if got := w.Header().Get(DegradedHeader); got != "" {
+		t.Errorf("expected no %s when disabled, got %q", DegradedHeader, got)
+	}
+}
+
+func TestChatHandler_DegradedDebugField(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &truncatingChatProvider{}}, hook.NewRegistry())
+	handler.SetReportDegraded(true)
+
+	decode := func(debug bool) map[string]any {
+		r := newChoicesRequest(t, map[string]any{
+			"model":    "gpt-4",
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		if debug {
+			r.Header.Set(DebugHeader, "1")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		var body map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return body
+	}
+
+	body := decode(true)
+	reasons, _ := body["gateway_degraded"].([]any)
+	if len(reasons) != 1 || reasons[0] != DegradedTruncated {
+		t.Errorf("expected gateway_degraded [truncated], got %v", body["gateway_degraded"])
+	}
+	if body["id"] != "test-id" || body["choices"] == nil {
+		t.Errorf("expected the completion alongside the reasons, got %v", body)
+	}
+
+	if body := decode(false); body["gateway_degraded"] != nil {
+		t.Errorf("expected no gateway_degraded without %s, got %v", DebugHeader, body["gateway_degraded"])
+	}
+}
+
+func TestChatHandler_DegradedStreamTrailer(t *testing.T) {
+	handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+	handler.SetReportDegraded(true)
+
+	w := postChatBody(handler, map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   true,
+	})
+
+	resp := w.Result()
+	if got := resp.Header.Get(DegradedHeader); got != "" {
+		t.Errorf("expected no %s header before the stream, got %q", DegradedHeader, got)
+	}
+	// The mock stream reports no usage, so the gateway estimates it
+	if got := resp.Trailer.Get(DegradedHeader); got != DegradedEstimatedUsage {
+		t.Errorf("expected %s trailer %q, got %q", DegradedHeader, DegradedEstimatedUsage, got)
+	}
+}
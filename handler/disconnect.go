@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetCompleteOnDisconnect lets non-streaming provider calls whose result is
+// cached run to completion when the client disconnects, so a retry is a hit
+func (h *ChatHandler) SetCompleteOnDisconnect(enabled bool) {
+	h.completeOnDisconnect = enabled
+}
+
+// SetCompleteOnDisconnect lets non-streaming provider calls whose result is
+// stored run to completion when the client disconnects
+func (h *ResponsesHandler) SetCompleteOnDisconnect(enabled bool) {
+	h.completeOnDisconnect = enabled
+}
+
+// detachClient returns a context that keeps ctx's values and request timeout
+// but isn't cancelled when the client disconnects
+func detachClient(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadlineCause(detached, deadline, errRequestTimeout)
+	}
+	return detached, func() {}
+}
+
+// logDisconnected notes a provider call completed for a client that has gone
+func logDisconnected(ctx, clientCtx context.Context, msg string) {
+	if clientCtx.Err() != nil {
+		slog.InfoContext(ctx, msg)
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// slowChatProvider blocks each request until released or cancelled
+type slowChatProvider struct {
+	countingChatProvider
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newSlowChatProvider() *slowChatProvider {
+	return &slowChatProvider{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (m *slowChatProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.once.Do(func() { close(m.started) })
+	select {
+	case <-m.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.countingChatProvider.SendRequest(ctx, req)
+}
+
+// serveDisconnecting serves a deterministic chat request whose client
+// disconnects once the provider call has started
+func serveDisconnecting(t *testing.T, handler *ChatHandler, prov *slowChatProvider) {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(deterministicChat("Hello"))
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)).WithContext(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	<-prov.started
+	cancel()
+	// Give a call tied to the client time to see the cancellation
+	time.Sleep(20 * time.Millisecond)
+	close(prov.release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("request did not finish")
+	}
+}
+
+func TestChatHandler_CompleteOnDisconnect(t *testing.T) {
+	prov := newSlowChatProvider()
+	handler, _ := newCachingChatHandler(prov)
+	handler.SetCompleteOnDisconnect(true)
+
+	serveDisconnecting(t, handler, prov)
+	if prov.calls != 1 {
+		t.Fatalf("expected the provider call to complete, got %d calls", prov.calls)
+	}
+
+	// The retry is served from the cache
+	w := postChatBody(handler, deterministicChat("Hello"))
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected a cache hit, got %d with X-Cache %q", w.Code, w.Header().Get("X-Cache"))
+	}
+	if prov.calls != 1 {
+		t.Errorf("expected no further provider calls, got %d", prov.calls)
+	}
+}
+
+func TestChatHandler_DisconnectCancelsByDefault(t *testing.T) {
+	prov := newSlowChatProvider()
+	handler, _ := newCachingChatHandler(prov)
+
+	serveDisconnecting(t, handler, prov)
+	if prov.calls != 0 {
+		t.Fatalf("expected the provider call to be cancelled, got %d calls", prov.calls)
+	}
+
+	w := postChatBody(handler, deterministicChat("Hello"))
+	if w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("expected a cache miss, got X-Cache %q", w.Header().Get("X-Cache"))
+	}
+}
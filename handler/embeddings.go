@@ -2,13 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/provider"
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
 )
 
 // EmbeddingsHandler handles embedding requests
@@ -18,6 +21,9 @@ type EmbeddingsHandler struct {
 	// which is checked via a local interface type assertion in ServeHTTP.
 	registry any
 	hooks    *hook.Registry
+	limiter  ratelimit.Limiter
+	metrics  MetricsRecorder
+	timeout  time.Duration
 }
 
 // NewEmbeddingsHandler creates a new embeddings handler
@@ -28,13 +34,44 @@ func NewEmbeddingsHandler(registry any, hooks *hook.Registry) *EmbeddingsHandler
 	}
 }
 
+// SetRateLimiter enables per-tenant rate limiting
+func (h *EmbeddingsHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *EmbeddingsHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
 // ServeHTTP implements http.Handler
 func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Ensure request body is closed
 	defer r.Body.Close()
 
-	// Parse request
-	var req openai.EmbeddingRequest
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/embeddings", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, NewValidationError(timeoutErr.Error()))
+		return
+	}
+
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, NewRateLimitError("rate limit exceeded"))
+		return
+	}
+
+	// Parse request, with the stream flag some SDKs send
+	var req struct {
+		openai.EmbeddingRequest
+		Stream bool `json:"stream"`
+	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.writeError(w, r, NewValidationError("invalid request body: "+err.Error()))
 		return
@@ -49,6 +86,10 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, r, NewValidationError("input is required"))
 		return
 	}
+	if req.Stream {
+		h.writeError(w, r, NewValidationError("streaming is not supported for embeddings"))
+		return
+	}
 
 	ctx := r.Context()
 
@@ -59,6 +100,14 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"dimensions", req.Dimensions,
 	)
 
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(ctx, h.hooks, req.Model)
+	if routeErr != nil {
+		h.writeError(w, r, routeErr)
+		return
+	}
+	req.Model = routed
+
 	// Resolve provider
 	type resolver interface {
 		Resolve(model string) (provider.Provider, string)
@@ -78,6 +127,7 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply model rewrite if specified
+	originalModel := req.Model
 	if modelRewrite != "" {
 		slog.InfoContext(ctx, "Model rewrite applied",
 			"original", req.Model,
@@ -86,6 +136,13 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		req.Model = modelRewrite
 	}
 
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/embeddings", prov)
+
+	// Record routing info for hooks and logging
+	ctx = withRouteInfo(ctx, prov, originalModel, req.Model)
+	r = r.WithContext(ctx)
+
 	slog.InfoContext(ctx, "Provider resolved",
 		"provider", prov.Name(),
 		"supported_apis", prov.SupportedAPIs().String(),
@@ -107,6 +164,7 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, r, NewProviderError("provider request failed", err))
 		return
 	}
+	copyHeaders(w.Header(), provResp.Headers)
 
 	// Get embedding response
 	resp, err := provResp.GetEmbedding()
@@ -115,6 +173,8 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordTokens(ctx, h.metrics, &openai.Usage{PromptTokens: resp.Usage.PromptTokens, TotalTokens: resp.Usage.TotalTokens})
+
 	slog.InfoContext(ctx, "Embeddings response successful",
 		"embedding_count", len(resp.Data),
 		"model", resp.Model,
@@ -130,10 +190,11 @@ func (h *EmbeddingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *EmbeddingsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	setUpstreamErrorHeaders(w, err)
+	err = timeoutError(r, err)
+
 	var gwErr *GatewayError
-	if e, ok := err.(*GatewayError); ok {
-		gwErr = e
-	} else {
+	if !errors.As(err, &gwErr) {
 		gwErr = NewProviderError("internal error", err)
 	}
 
@@ -135,6 +135,35 @@ func TestEmbeddingsHandler_ServeHTTP_EmptyInput(t *testing.T) {
 	}
 }
 
+func TestEmbeddingsHandler_ServeHTTP_Stream(t *testing.T) {
+	prov := &mockEmbeddingsProvider{}
+	handler := NewEmbeddingsHandler(&mockModelRegistry{provider: prov}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"input":  "hello world",
+		"model":  "text-embedding-3-small",
+		"stream": true,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/embeddings", bytes.NewReader(bodyBytes)))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if resp.Error.Type != "invalid_request_error" || resp.Error.Message != "streaming is not supported for embeddings" {
+		t.Errorf("unexpected error: %+v", resp.Error)
+	}
+}
+
 func TestEmbeddingsHandler_ServeHTTP_ModelNotFound(t *testing.T) {
 	// Registry with no provider
 	registry := &mockModelRegistry{provider: nil}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// UpstreamRequestIDHeader carries the upstream's X-Request-Id, so it doesn't
+// replace the gateway's own request ID
+const UpstreamRequestIDHeader = "X-Upstream-Request-Id"
+
+// copyHeaders copies the upstream response headers surfaced by the provider,
+// such as rate limits and request IDs, to dst. The upstream request ID is
+// renamed to UpstreamRequestIDHeader, and headers the gateway already set,
+// such as its own request ID, are never overwritten.
+func copyHeaders(dst, src http.Header) {
+	for name, values := range src {
+		if http.CanonicalHeaderKey(name) == "X-Request-Id" {
+			name = UpstreamRequestIDHeader
+		}
+		if _, ok := dst[http.CanonicalHeaderKey(name)]; ok {
+			continue
+		}
+		dst[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+	}
+}
+
+// setUpstreamErrorHeaders copies the headers of an upstream error in err's
+// chain onto w, so clients see Retry-After and rate limits on failures too
+func setUpstreamErrorHeaders(w http.ResponseWriter, err error) {
+	var upstreamErr *provider.Error
+	if errors.As(err, &upstreamErr) {
+		copyHeaders(w.Header(), upstreamErr.Headers)
+	}
+}
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/provider"
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
 )
 
 // ImagesHandler handles image generation requests
@@ -17,6 +20,9 @@ type ImagesHandler struct {
 	// which is checked via a local interface type assertion in ServeHTTP.
 	registry any
 	hooks    *hook.Registry
+	limiter  ratelimit.Limiter
+	metrics  MetricsRecorder
+	timeout  time.Duration
 }
 
 // NewImagesHandler creates a new images handler
@@ -27,11 +33,39 @@ func NewImagesHandler(registry any, hooks *hook.Registry) *ImagesHandler {
 	}
 }
 
+// SetRateLimiter enables per-tenant rate limiting
+func (h *ImagesHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *ImagesHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
 // ServeHTTP implements http.Handler
 func (h *ImagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Ensure request body is closed
 	defer r.Body.Close()
 
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/images/generations", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, NewValidationError(timeoutErr.Error()))
+		return
+	}
+
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, NewRateLimitError("rate limit exceeded"))
+		return
+	}
+
 	// Parse request
 	var req openai.ImageRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -52,6 +86,14 @@ func (h *ImagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(ctx, h.hooks, req.Model)
+	if routeErr != nil {
+		h.writeError(w, r, routeErr)
+		return
+	}
+	req.Model = routed
+
 	// Resolve provider
 	type resolver interface {
 		Resolve(model string) (provider.Provider, string)
@@ -71,10 +113,18 @@ func (h *ImagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply model rewrite if specified
+	originalModel := req.Model
 	if modelRewrite != "" {
 		req.Model = modelRewrite
 	}
 
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/images/generations", prov)
+
+	// Record routing info for hooks and logging
+	ctx = withRouteInfo(ctx, prov, originalModel, req.Model)
+	r = r.WithContext(ctx)
+
 	// Create provider request
 	provReq := provider.NewImagesRequest(req.Model, req.Prompt)
 	provReq.ImageN = req.N
@@ -88,6 +138,7 @@ func (h *ImagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, r, NewProviderError("provider request failed", err))
 		return
 	}
+	copyHeaders(w.Header(), provResp.Headers)
 
 	// Get image response
 	resp, err := provResp.GetImage()
@@ -105,10 +156,11 @@ func (h *ImagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ImagesHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	setUpstreamErrorHeaders(w, err)
+	err = timeoutError(r, err)
+
 	var gwErr *GatewayError
-	if e, ok := err.(*GatewayError); ok {
-		gwErr = e
-	} else {
+	if !errors.As(err, &gwErr) {
 		gwErr = NewProviderError("internal error", err)
 	}
 
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// intercept runs the InterceptHooks and returns the first synthetic response,
+// or nil if the request should be sent to the provider
+func (h *ChatHandler) intercept(ctx context.Context, req *openai2.ChatCompletionRequest) (*openai2.ChatCompletionResponse, error) {
+	for _, hh := range h.hooks.InterceptHooks() {
+		resp, err := hh.Intercept(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("hook error: %w", err)
+		}
+		if resp == nil {
+			continue
+		}
+
+		// Fill in what a canned response is likely to leave out
+		if resp.Object == "" {
+			resp.Object = "chat.completion"
+		}
+		if resp.Model == "" {
+			resp.Model = req.Model
+		}
+		if resp.Created == 0 {
+			resp.Created = time.Now().Unix()
+		}
+		return resp, nil
+	}
+	return nil, nil
+}
+
+// replayStream replays a complete response as a stream: a delta with each
+// choice's message, a chunk with its finish_reason and a done marker
+func replayStream(resp *openai2.ChatCompletionResponse) *provider.Response {
+	chunkChan := make(chan *provider.Chunk, 2*len(resp.Choices)+1)
+	errChan := make(chan error)
+
+	send := func(choice openai2.Choice) {
+		data, err := json.Marshal(&openai2.ChatCompletionStreamResponse{
+			ID:      resp.ID,
+			Object:  "chat.completion.chunk",
+			Created: resp.Created,
+			Model:   resp.Model,
+			Choices: []openai2.Choice{choice},
+		})
+		if err == nil {
+			chunkChan <- provider.NewOpenAIChunk(data)
+		}
+	}
+	for _, choice := range resp.Choices {
+		toolCalls := make([]openai2.ToolCall, len(choice.Message.ToolCalls))
+		for i, call := range choice.Message.ToolCalls {
+			call.Index = &i
+			toolCalls[i] = call
+		}
+		send(openai2.Choice{
+			Index: choice.Index,
+			Delta: &openai2.Delta{
+				Role:      choice.Message.Role,
+				Content:   choice.Message.Content,
+				Refusal:   choice.Message.Refusal,
+				ToolCalls: toolCalls,
+			},
+		})
+		send(openai2.Choice{Index: choice.Index, Delta: &openai2.Delta{}, FinishReason: choice.FinishReason})
+	}
+	chunkChan <- provider.NewOpenAIChunkDone()
+	close(chunkChan)
+	close(errChan)
+
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, func() error { return nil })
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// guardrailHook refuses every request mentioning "secret"
+type guardrailHook struct{}
+
+func (h *guardrailHook) Name() string {
+	return "guardrail"
+}
+
+func (h *guardrailHook) Intercept(ctx context.Context, req *openai2.ChatCompletionRequest) (*openai2.ChatCompletionResponse, error) {
+	if !strings.Contains(req.Messages[len(req.Messages)-1].Content, "secret") {
+		return nil, nil
+	}
+	return &openai2.ChatCompletionResponse{
+		ID: "guardrail-1",
+		Choices: []openai2.Choice{{
+			Message:      openai2.Message{Role: "assistant", Content: "I can't help with that."},
+			FinishReason: "stop",
+		}},
+	}, nil
+}
+
+func newGuardedChatHandler() (*ChatHandler, *countingChatProvider) {
+	prov := &countingChatProvider{}
+	hooks := hook.NewRegistry()
+	hooks.Register(&guardrailHook{})
+	return NewChatHandler(&mapModelRegistry{provider: prov}, hooks), prov
+}
+
+func TestChatHandler_Intercept(t *testing.T) {
+	handler, prov := newGuardedChatHandler()
+
+	w := postChatBody(handler, map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Tell me the secret"}},
+	})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp openai2.ChatCompletionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ID != "guardrail-1" || resp.Choices[0].Message.Content != "I can't help with that." {
+		t.Errorf("expected the intercepted response, got %+v", resp)
+	}
+	if resp.Object != "chat.completion" || resp.Model != "gpt-4" {
+		t.Errorf("expected object and model to be filled in, got %q and %q", resp.Object, resp.Model)
+	}
+	if prov.calls != 0 {
+		t.Errorf("expected the provider not to be called, got %d calls", prov.calls)
+	}
+
+	// Requests the hook lets through reach the provider
+	postChatBody(handler, map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+	})
+	if prov.calls != 1 {
+		t.Errorf("expected 1 provider call, got %d", prov.calls)
+	}
+}
+
+func TestChatHandler_InterceptStream(t *testing.T) {
+	handler, prov := newGuardedChatHandler()
+
+	w := postChatBody(handler, map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Tell me the secret"}},
+		"stream":   true,
+	})
+
+	acc := openai2.NewStreamAccumulator()
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk openai2.ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("invalid chunk %q: %v", data, err)
+		}
+		acc.Add(&chunk)
+	}
+
+	resp := acc.Response()
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "I can't help with that." || resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected the intercepted response as chunks, got %+v", resp.Choices)
+	}
+	if !strings.HasSuffix(w.Body.String(), "data: [DONE]\n\n") {
+		t.Errorf("expected the stream to end with [DONE], got %s", w.Body.String())
+	}
+	if prov.calls != 0 {
+		t.Errorf("expected the provider not to be called, got %d calls", prov.calls)
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// MetricsRecorder receives request metrics from the handlers, e.g. to export them to Prometheus
+type MetricsRecorder interface {
+	// RecordRequest records a served request, labeled by the model the client requested
+	RecordRequest(method, endpoint, model string, status int, duration time.Duration)
+
+	// RecordUpstream records a provider call and how long the provider took to respond
+	RecordUpstream(endpoint, provider string, duration time.Duration, err error)
+
+	// RecordTokens records token usage of a request by model and tenant
+	RecordTokens(model, tenant string, promptTokens, completionTokens int)
+}
+
+// requestMetrics observes the status and duration of one request
+type requestMetrics struct {
+	http.ResponseWriter
+	recorder MetricsRecorder
+	endpoint string
+	status   int
+	start    time.Time
+}
+
+// observeRequest wraps w to observe the response status for recorder. Call
+// finish once the request is served. w is returned unchanged if recorder is nil.
+func observeRequest(recorder MetricsRecorder, endpoint string, w http.ResponseWriter) (http.ResponseWriter, *requestMetrics) {
+	if recorder == nil {
+		return w, nil
+	}
+	m := &requestMetrics{ResponseWriter: w, recorder: recorder, endpoint: endpoint, start: time.Now()}
+	return m, m
+}
+
+// WriteHeader implements http.ResponseWriter
+func (m *requestMetrics) WriteHeader(status int) {
+	if m.status == 0 {
+		m.status = status
+	}
+	m.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (m *requestMetrics) Write(b []byte) (int, error) {
+	if m.status == 0 {
+		m.status = http.StatusOK
+	}
+	return m.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so streaming still works through the wrapper
+func (m *requestMetrics) Flush() {
+	if flusher, ok := m.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (m *requestMetrics) Unwrap() http.ResponseWriter {
+	return m.ResponseWriter
+}
+
+// finish records the request and fills in its summary, if any. r must be the
+// final request, carrying the route info. m may be nil.
+func (m *requestMetrics) finish(r *http.Request) {
+	ctx := r.Context()
+	route, _ := ai_gateway.RouteInfoFromContext(ctx)
+	if summary := ai_gateway.RequestSummaryFromContext(ctx); summary != nil {
+		summary.RequestID = ai_gateway.RequestIDFromContext(ctx)
+		summary.TenantID = ai_gateway.TenantIDFromContext(ctx)
+		if route.OriginalModel != "" {
+			summary.Model = route.OriginalModel
+		}
+		summary.Provider = route.Provider
+	}
+
+	if m == nil {
+		return
+	}
+	status := m.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	m.recorder.RecordRequest(r.Method, m.endpoint, route.OriginalModel, status, time.Since(m.start))
+}
+
+// observedProvider times provider calls for a MetricsRecorder
+type observedProvider struct {
+	provider.Provider
+	recorder MetricsRecorder
+	endpoint string
+}
+
+// observeProvider wraps prov to record its latency, or returns it unchanged if recorder is nil
+func observeProvider(recorder MetricsRecorder, endpoint string, prov provider.Provider) provider.Provider {
+	if recorder == nil {
+		return prov
+	}
+	return &observedProvider{Provider: prov, recorder: recorder, endpoint: endpoint}
+}
+
+// SendRequest implements provider.Provider. For streams it times the response headers.
+func (p *observedProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	start := time.Now()
+	resp, err := p.Provider.SendRequest(ctx, req)
+	p.recorder.RecordUpstream(p.endpoint, p.Name(), time.Since(start), err)
+	return resp, err
+}
+
+// Unwrap returns the observed provider
+func (p *observedProvider) Unwrap() provider.Provider {
+	return p.Provider
+}
+
+// recordTokens records usage for the request's requested model and tenant
+func recordTokens(ctx context.Context, recorder MetricsRecorder, usage *openai.Usage) {
+	if recorder == nil || usage == nil {
+		return
+	}
+	route, _ := ai_gateway.RouteInfoFromContext(ctx)
+	recorder.RecordTokens(route.OriginalModel, ai_gateway.TenantIDFromContext(ctx), usage.PromptTokens, usage.CompletionTokens)
+}
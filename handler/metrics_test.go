@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// recordedRequest is a request seen by recordingMetrics
+type recordedRequest struct {
+	endpoint, model string
+	status          int
+}
+
+// recordingMetrics is a MetricsRecorder keeping what it was given
+type recordingMetrics struct {
+	mu        sync.Mutex
+	requests  []recordedRequest
+	upstreams []string
+	tokens    int
+}
+
+func (m *recordingMetrics) RecordRequest(method, endpoint, model string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, recordedRequest{endpoint, model, status})
+}
+
+func (m *recordingMetrics) RecordUpstream(endpoint, provider string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.upstreams = append(m.upstreams, provider)
+}
+
+func (m *recordingMetrics) RecordTokens(model, tenant string, promptTokens, completionTokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens += promptTokens + completionTokens
+}
+
+func TestChatHandler_Metrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   map[string]any
+		status int
+		calls  int
+		tokens int
+	}{
+		{"non-streaming", map[string]any{"model": "gpt-4", "messages": []map[string]string{{"role": "user", "content": "Hi"}}}, http.StatusOK, 1, 15},
+		{"streaming", map[string]any{"model": "gpt-4", "messages": []map[string]string{{"role": "user", "content": "Hi"}}, "stream": true}, http.StatusOK, 1, 0},
+		{"invalid", map[string]any{"model": "gpt-4"}, http.StatusBadRequest, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &recordingMetrics{}
+			handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+			handler.SetMetricsRecorder(metrics)
+
+			bodyBytes, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if len(metrics.requests) != 1 || metrics.requests[0].status != tt.status || metrics.requests[0].endpoint != "/v1/chat/completions" {
+				t.Errorf("unexpected recorded requests %+v", metrics.requests)
+			}
+			if len(metrics.upstreams) != tt.calls {
+				t.Errorf("expected %d upstream calls, got %v", tt.calls, metrics.upstreams)
+			}
+			if tt.tokens > 0 && metrics.tokens != tt.tokens {
+				t.Errorf("expected %d tokens, got %d", tt.tokens, metrics.tokens)
+			}
+		})
+	}
+}
+
+func TestChatHandler_MetricsKeepSingleChoice(t *testing.T) {
+	// Observing the provider must not hide that it is single-choice
+	prov := &singleChoiceProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetMetricsRecorder(&recordingMetrics{})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, map[string]any{
+		"model":    "claude-3",
+		"messages": []map[string]string{{"role": "user", "content": "Hi"}},
+		"n":        2,
+	}))
+
+	if w.Code != http.StatusOK || prov.calls.Load() != 2 {
+		t.Errorf("expected fan-out over 2 calls, got %d with %d calls: %s", w.Code, prov.calls.Load(), w.Body.String())
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"sort"
 	"time"
@@ -75,9 +76,7 @@ func (h *ModelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 func (h *ModelsHandler) writeError(w http.ResponseWriter, err error) {
 	var gwErr *GatewayError
-	if e, ok := err.(*GatewayError); ok {
-		gwErr = e
-	} else {
+	if !errors.As(err, &gwErr) {
 		gwErr = NewProviderError("internal error", err)
 	}
 
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
+)
+
+// DefaultModerationModel is used when a moderation request does not specify a model
+const DefaultModerationModel = "omni-moderation-latest"
+
+// ModerationsHandler handles content moderation requests
+type ModerationsHandler struct {
+	// registry is typed as `any` to avoid circular dependencies.
+	// The handler only needs the Resolve(model string) (provider.Provider, string) method,
+	// which is checked via a local interface type assertion in ServeHTTP.
+	registry any
+	hooks    *hook.Registry
+	limiter  ratelimit.Limiter
+	metrics  MetricsRecorder
+	timeout  time.Duration
+}
+
+// NewModerationsHandler creates a new moderations handler
+func NewModerationsHandler(registry any, hooks *hook.Registry) *ModerationsHandler {
+	return &ModerationsHandler{
+		registry: registry,
+		hooks:    hooks,
+	}
+}
+
+// SetRateLimiter enables per-tenant rate limiting
+func (h *ModerationsHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *ModerationsHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
+// ServeHTTP implements http.Handler
+func (h *ModerationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Ensure request body is closed
+	defer r.Body.Close()
+
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/moderations", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, NewValidationError(timeoutErr.Error()))
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		h.writeError(w, r, NewMethodNotAllowedError("only POST method is allowed"))
+		return
+	}
+
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, NewRateLimitError("rate limit exceeded"))
+		return
+	}
+
+	// Parse request
+	var req openai.ModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, NewValidationError("invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate request
+	if req.Input == nil {
+		h.writeError(w, r, NewValidationError("input is required"))
+		return
+	}
+
+	// Default model if not specified
+	if req.Model == "" {
+		req.Model = DefaultModerationModel
+	}
+
+	ctx := r.Context()
+
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(ctx, h.hooks, req.Model)
+	if routeErr != nil {
+		h.writeError(w, r, routeErr)
+		return
+	}
+	req.Model = routed
+
+	// Resolve provider
+	type resolver interface {
+		Resolve(model string) (provider.Provider, string)
+	}
+	var prov provider.Provider
+	var modelRewrite string
+
+	if reg, ok := h.registry.(resolver); ok {
+		prov, modelRewrite = reg.Resolve(req.Model)
+		if prov == nil {
+			h.writeError(w, r, NewNotFoundError("model not found: "+req.Model))
+			return
+		}
+	} else {
+		h.writeError(w, r, NewProviderError("registry not available", nil))
+		return
+	}
+
+	if !prov.SupportedAPIs().Supports(provider.APITypeModerations) {
+		h.writeError(w, r, NewValidationError(fmt.Sprintf("provider %s does not support moderations for model %s", prov.Name(), req.Model)))
+		return
+	}
+
+	// Apply model rewrite if specified
+	originalModel := req.Model
+	if modelRewrite != "" {
+		req.Model = modelRewrite
+	}
+
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/moderations", prov)
+
+	// Record routing info for hooks and logging
+	ctx = withRouteInfo(ctx, prov, originalModel, req.Model)
+	r = r.WithContext(ctx)
+
+	// Send request to provider
+	provResp, err := prov.SendRequest(ctx, provider.NewModerationsRequest(req.Model, req.Input))
+	if err != nil {
+		h.writeError(w, r, NewProviderError("provider request failed", err))
+		return
+	}
+	copyHeaders(w.Header(), provResp.Headers)
+
+	// Get moderation response
+	resp, err := provResp.GetModeration()
+	if err != nil {
+		h.writeError(w, r, NewProviderError("invalid response", err))
+		return
+	}
+
+	// Write response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.writeError(w, r, NewProviderError("failed to encode response", err))
+		return
+	}
+}
+
+func (h *ModerationsHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	setUpstreamErrorHeaders(w, err)
+	err = timeoutError(r, err)
+
+	var gwErr *GatewayError
+	if !errors.As(err, &gwErr) {
+		gwErr = NewProviderError("internal error", err)
+	}
+
+	// Call ErrorHooks to notify of the error
+	ctx := r.Context()
+	if h.hooks != nil {
+		for _, hh := range h.hooks.ErrorHooks() {
+			hh.OnError(ctx, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(gwErr.Code)
+	if encodeErr := json.NewEncoder(w).Encode(gwErr.ToOpenAIResponse()); encodeErr != nil {
+		fmt.Printf("failed to encode error response: %v\n", encodeErr)
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	prov "github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// mockModerationsProvider flags every input containing "kill"
+type mockModerationsProvider struct {
+	lastReq *prov.Request
+}
+
+func (m *mockModerationsProvider) Name() string {
+	return "mock-moderations"
+}
+
+func (m *mockModerationsProvider) SupportedAPIs() prov.APIType {
+	return prov.APITypeModerations
+}
+
+func (m *mockModerationsProvider) SendRequest(ctx context.Context, req *prov.Request) (*prov.Response, error) {
+	m.lastReq = req
+	return prov.NewModerationResponse(&openai.ModerationResponse{
+		ID:    "modr-123",
+		Model: req.Model,
+		Results: []openai.ModerationResult{{
+			Flagged:        true,
+			Categories:     map[string]bool{"violence": true, "hate": false},
+			CategoryScores: map[string]float64{"violence": 0.97, "hate": 0.01},
+		}},
+	}), nil
+}
+
+func newModerationRequest(body map[string]any) *http.Request {
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/v1/moderations", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestModerationsHandler_ServeHTTP(t *testing.T) {
+	provider := &mockModerationsProvider{}
+	handler := NewModerationsHandler(&mapModelRegistry{provider: provider}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newModerationRequest(map[string]any{
+		"input": []string{"I want to kill them."},
+	}))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp openai.ModerationResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Results) != 1 || !resp.Results[0].Flagged {
+		t.Fatalf("expected one flagged result, got %+v", resp.Results)
+	}
+	if !resp.Results[0].Categories["violence"] || resp.Results[0].CategoryScores["violence"] != 0.97 {
+		t.Errorf("unexpected categories: %+v", resp.Results[0])
+	}
+
+	if provider.lastReq.APIType != prov.APITypeModerations {
+		t.Errorf("expected moderations API type, got %v", provider.lastReq.APIType)
+	}
+	if provider.lastReq.Model != DefaultModerationModel {
+		t.Errorf("expected default model %s, got %s", DefaultModerationModel, provider.lastReq.Model)
+	}
+}
+
+func TestModerationsHandler_MissingInput(t *testing.T) {
+	handler := NewModerationsHandler(&mapModelRegistry{provider: &mockModerationsProvider{}}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newModerationRequest(map[string]any{"model": "omni-moderation-latest"}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestModerationsHandler_UnsupportedProvider(t *testing.T) {
+	handler := NewModerationsHandler(newMockRegistry(), hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newModerationRequest(map[string]any{"input": "hello"}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// panicError converts a panic recovered in a goroutine started by a handler,
+// which the gateway's Recover can't see, into an error. The panic and its
+// stack are logged and passed to the error hooks, if any.
+func panicError(ctx context.Context, hooks *hook.Registry, rec any) error {
+	err := fmt.Errorf("panic: %v", rec)
+	slog.ErrorContext(ctx, "recovered from panic", "error", err, "stack", string(debug.Stack()))
+	if hooks != nil {
+		for _, h := range hooks.ErrorHooks() {
+			h.OnError(ctx, err)
+		}
+	}
+	return err
+}
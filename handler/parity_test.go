@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/openresponses"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// parityFixtures are complete responses used to check that streaming and
+// non-streaming paths produce the same logical completion
+var parityFixtures = []struct {
+	name string
+	resp openai.ChatCompletionResponse
+}{
+	{
+		name: "content",
+		resp: parityResponse(openai.Choice{
+			Message:      openai.Message{Role: "assistant", Content: "The capital of France is Paris. It has been since the 10th century."},
+			FinishReason: "stop",
+		}),
+	},
+	{
+		name: "tool calls",
+		resp: parityResponse(openai.Choice{
+			Message: openai.Message{Role: "assistant", ToolCalls: []openai.ToolCall{
+				{ID: "call_1", Type: "function", Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris","unit":"celsius"}`}},
+				{ID: "call_2", Type: "function", Function: openai.FunctionCall{Name: "get_time", Arguments: `{"timezone":"Europe/Paris"}`}},
+			}},
+			FinishReason: "tool_calls",
+		}),
+	},
+	{
+		name: "content and tool calls",
+		resp: parityResponse(openai.Choice{
+			Message: openai.Message{Role: "assistant", Content: "Let me check that for you.", ToolCalls: []openai.ToolCall{
+				{ID: "call_1", Type: "function", Function: openai.FunctionCall{Name: "search", Arguments: `{"query":"weather in Paris"}`}},
+			}},
+			FinishReason: "tool_calls",
+		}),
+	},
+	{
+		name: "refusal",
+		resp: parityResponse(openai.Choice{
+			Message:      openai.Message{Role: "assistant", Refusal: "I'm sorry, I can't help with that request."},
+			FinishReason: "stop",
+		}),
+	},
+	{
+		name: "content filter",
+		resp: parityResponse(openai.Choice{
+			Message:      openai.Message{Role: "assistant", Content: "Here is how you"},
+			FinishReason: "content_filter",
+		}),
+	},
+	{
+		name: "multiple choices",
+		resp: parityResponse(
+			openai.Choice{Index: 0, Message: openai.Message{Role: "assistant", Content: "Héllo, wörld!"}, FinishReason: "stop"},
+			openai.Choice{Index: 1, Message: openai.Message{Role: "assistant", Content: "Hi there"}, FinishReason: "length"},
+		),
+	},
+}
+
+func parityResponse(choices ...openai.Choice) openai.ChatCompletionResponse {
+	return openai.ChatCompletionResponse{
+		ID:      "chatcmpl-parity",
+		Object:  "chat.completion",
+		Created: 1234567890,
+		Model:   "gpt-4",
+		Choices: choices,
+		Usage:   openai.Usage{PromptTokens: 12, CompletionTokens: 20, TotalTokens: 32},
+	}
+}
+
+// streamChunks splits a complete response into the chunks an upstream would
+// stream for it: a role delta, small content and refusal fragments, a header
+// delta per tool call followed by argument fragments, a finish reason, and a
+// final usage-only chunk
+func streamChunks(resp *openai.ChatCompletionResponse) []openai.ChatCompletionStreamResponse {
+	var chunks []openai.ChatCompletionStreamResponse
+	emit := func(index int, delta *openai.Delta, finishReason string) {
+		chunks = append(chunks, openai.ChatCompletionStreamResponse{
+			ID:      resp.ID,
+			Object:  "chat.completion.chunk",
+			Created: resp.Created,
+			Model:   resp.Model,
+			Choices: []openai.Choice{{Index: index, Delta: delta, FinishReason: finishReason}},
+		})
+	}
+
+	for _, choice := range resp.Choices {
+		msg := choice.Message
+		emit(choice.Index, &openai.Delta{Role: msg.Role}, "")
+		for _, piece := range splitText(msg.Content, 5) {
+			emit(choice.Index, &openai.Delta{Content: piece}, "")
+		}
+		for _, piece := range splitText(msg.Refusal, 5) {
+			emit(choice.Index, &openai.Delta{Refusal: piece}, "")
+		}
+		for i, tc := range msg.ToolCalls {
+			index := i
+			emit(choice.Index, &openai.Delta{ToolCalls: []openai.ToolCall{{
+				Index:    &index,
+				ID:       tc.ID,
+				Type:     tc.Type,
+				Function: openai.FunctionCall{Name: tc.Function.Name},
+			}}}, "")
+			for _, piece := range splitText(tc.Function.Arguments, 7) {
+				emit(choice.Index, &openai.Delta{ToolCalls: []openai.ToolCall{{
+					Index:    &index,
+					Function: openai.FunctionCall{Arguments: piece},
+				}}}, "")
+			}
+		}
+		emit(choice.Index, &openai.Delta{}, choice.FinishReason)
+	}
+
+	usage := resp.Usage
+	chunks = append(chunks, openai.ChatCompletionStreamResponse{
+		ID:      resp.ID,
+		Object:  "chat.completion.chunk",
+		Created: resp.Created,
+		Model:   resp.Model,
+		Choices: []openai.Choice{},
+		Usage:   &usage,
+	})
+	return chunks
+}
+
+// splitText splits text into pieces of at most n runes
+func splitText(text string, n int) []string {
+	var pieces []string
+	runes := []rune(text)
+	for len(runes) > 0 {
+		end := min(n, len(runes))
+		pieces = append(pieces, string(runes[:end]))
+		runes = runes[end:]
+	}
+	return pieces
+}
+
+// assertChatParity checks that two responses describe the same completion:
+// content, refusal, tool calls, finish reason and usage per choice
+func assertChatParity(t *testing.T, want, got *openai.ChatCompletionResponse) {
+	t.Helper()
+	if len(got.Choices) != len(want.Choices) {
+		t.Fatalf("expected %d choices, got %d", len(want.Choices), len(got.Choices))
+	}
+	for i, w := range want.Choices {
+		g := got.Choices[i]
+		if g.Index != w.Index {
+			t.Errorf("choice %d: expected index %d, got %d", i, w.Index, g.Index)
+		}
+		if g.Message.Role != w.Message.Role {
+			t.Errorf("choice %d: expected role %q, got %q", i, w.Message.Role, g.Message.Role)
+		}
+		if g.Message.Content != w.Message.Content {
+			t.Errorf("choice %d: expected content %q, got %q", i, w.Message.Content, g.Message.Content)
+		}
+		if g.Message.Refusal != w.Message.Refusal {
+			t.Errorf("choice %d: expected refusal %q, got %q", i, w.Message.Refusal, g.Message.Refusal)
+		}
+		if g.FinishReason != w.FinishReason {
+			t.Errorf("choice %d: expected finish reason %q, got %q", i, w.FinishReason, g.FinishReason)
+		}
+		if len(g.Message.ToolCalls) != len(w.Message.ToolCalls) {
+			t.Errorf("choice %d: expected %d tool calls, got %d", i, len(w.Message.ToolCalls), len(g.Message.ToolCalls))
+			continue
+		}
+		for j, wc := range w.Message.ToolCalls {
+			gc := g.Message.ToolCalls[j]
+			if gc.ID != wc.ID || gc.Type != wc.Type || gc.Function != wc.Function {
+				t.Errorf("choice %d: tool call %d: expected %+v, got %+v", i, j, wc, gc)
+			}
+		}
+	}
+	if got.Usage != want.Usage {
+		t.Errorf("expected usage %+v, got %+v", want.Usage, got.Usage)
+	}
+}
+
+// parityProvider serves a fixture either as a complete response or as a stream
+type parityProvider struct {
+	mockChatProvider
+	resp *openai.ChatCompletionResponse
+}
+
+func (p *parityProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	if !req.Stream {
+		resp := *p.resp
+		return provider.NewChatCompletionResponse(&resp), nil
+	}
+
+	chunks := streamChunks(p.resp)
+	chunkChan := make(chan *provider.Chunk, len(chunks)+1)
+	errChan := make(chan error, 1)
+	for _, chunk := range chunks {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return nil, err
+		}
+		chunkChan <- provider.NewOpenAIChunk(data)
+	}
+	chunkChan <- provider.NewOpenAIChunkDone()
+	close(chunkChan)
+	close(errChan)
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, func() error { return nil }), nil
+}
+
+func TestStreamParity_Accumulator(t *testing.T) {
+	for _, tt := range parityFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := openai.NewStreamAccumulator()
+			for _, chunk := range streamChunks(&tt.resp) {
+				// Round-trip through JSON as the handlers do
+				data, err := json.Marshal(chunk)
+				if err != nil {
+					t.Fatalf("failed to marshal chunk: %v", err)
+				}
+				var parsed openai.ChatCompletionStreamResponse
+				if err := json.Unmarshal(data, &parsed); err != nil {
+					t.Fatalf("failed to unmarshal chunk: %v", err)
+				}
+				acc.Add(&parsed)
+			}
+			assertChatParity(t, &tt.resp, acc.Response())
+		})
+	}
+}
+
+func TestStreamParity_ChatHandler(t *testing.T) {
+	for _, tt := range parityFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChatHandler(&mapModelRegistry{provider: &parityProvider{resp: &tt.resp}}, hook.NewRegistry())
+			nonStream := postParityChat(t, handler, false)
+			stream := postParityChat(t, handler, true)
+
+			assertChatParity(t, &tt.resp, nonStream)
+			assertChatParity(t, nonStream, stream)
+		})
+	}
+}
+
+// postParityChat sends a chat request and returns the completion, accumulating
+// the SSE stream when stream is set
+func postParityChat(t *testing.T, handler *ChatHandler, stream bool) *openai.ChatCompletionResponse {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   stream,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if !stream {
+		var resp openai.ChatCompletionResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return &resp
+	}
+
+	acc := openai.NewStreamAccumulator()
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk openai.ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to decode chunk %q: %v", data, err)
+		}
+		acc.Add(&chunk)
+	}
+	return acc.Response()
+}
+
+func TestStreamParity_ResponsesConverter(t *testing.T) {
+	converter := openresponses.NewConverter()
+	for _, tt := range parityFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			want := converter.ChatCompletionToResponse(&tt.resp, "resp_parity", nil).Output
+
+			seq := 0
+			items := openresponses.NewStreamItems("resp_parity")
+			for _, chunk := range streamChunks(&tt.resp) {
+				data, err := json.Marshal(chunk)
+				if err != nil {
+					t.Fatalf("failed to marshal chunk: %v", err)
+				}
+				converter.StreamingChunkToEvents(data, &seq, items)
+			}
+			got := items.Output()
+
+			wantJSON, _ := json.Marshal(want)
+			gotJSON, _ := json.Marshal(got)
+			if !bytes.Equal(wantJSON, gotJSON) {
+				t.Errorf("streamed output differs from non-streaming output\nwant: %s\ngot:  %s", wantJSON, gotJSON)
+			}
+		})
+	}
+}
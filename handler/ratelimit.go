@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
+)
+
+// checkRateLimit takes one request from limiter for the request's tenant, or its
+// client address when unauthenticated, and sets the X-RateLimit-* headers when
+// the limiter reports them. It sets Retry-After and returns false if the request
+// is over the limit and must not reach the provider.
+func checkRateLimit(w http.ResponseWriter, r *http.Request, limiter ratelimit.Limiter) bool {
+	if limiter == nil {
+		return true
+	}
+	key := rateLimitKey(r)
+
+	reporter, ok := limiter.(ratelimit.Reporter)
+	if !ok {
+		if limiter.Allow(r.Context(), key) {
+			return true
+		}
+		w.Header().Set("Retry-After", "1")
+		return false
+	}
+
+	allowed, status := reporter.Take(r.Context(), key)
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("X-RateLimit-Reset", ceilSeconds(status.Reset))
+	if !allowed {
+		w.Header().Set("Retry-After", ceilSeconds(max(status.RetryAfter, time.Second)))
+	}
+	return allowed
+}
+
+// rateLimitKey returns the key requests are limited by: the tenant, or else
+// the client IP the gateway resolved through trusted proxies, or else the
+// connection's address
+func rateLimitKey(r *http.Request) string {
+	if tenantID := ai_gateway.TenantIDFromContext(r.Context()); tenantID != "" {
+		return "tenant:" + tenantID
+	}
+	if ip := ai_gateway.ClientIPFromContext(r.Context()); ip != "" {
+		return "addr:" + ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// ceilSeconds formats d as a whole number of seconds, rounding up
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
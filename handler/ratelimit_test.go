@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
+)
+
+func TestChatHandler_RateLimit(t *testing.T) {
+	hooks := hook.NewRegistry()
+	hooks.Register(&tenantAuthHook{})
+	prov := &countingChatProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hooks)
+	handler.SetRateLimiter(ratelimit.NewTokenBucket(ratelimit.PerMinute(2)))
+
+	post := func(tenant string) *httptest.ResponseRecorder {
+		bodyBytes, _ := json.Marshal(map[string]any{
+			"model":    "gpt-4",
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+		req.Header.Set("Authorization", tenant)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := post("tenant-a")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d: %s", i+1, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != remaining {
+			t.Errorf("request %d: expected %s remaining, got %q", i+1, remaining, got)
+		}
+	}
+
+	w := post("tenant-a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %s", w.Code, w.Body.String())
+	}
+	for header, want := range map[string]string{
+		"Retry-After":           "30",
+		"X-RateLimit-Limit":     "2",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     "60",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("expected %s %s, got %q", header, want, got)
+		}
+	}
+	var errResp struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil || errResp.Error.Type != "rate_limit_error" {
+		t.Errorf("expected rate_limit_error, got %v %+v", err, errResp)
+	}
+	if prov.calls != 2 {
+		t.Errorf("expected rate limited request not to reach the provider, got %d calls", prov.calls)
+	}
+
+	// Tenants are limited independently
+	if w := post("tenant-b"); w.Code != http.StatusOK {
+		t.Errorf("expected another tenant to be allowed, got %d", w.Code)
+	}
+}
+
+func TestEmbeddingsHandler_RateLimitByClientAddress(t *testing.T) {
+	handler := NewEmbeddingsHandler(newMockRegistry(), hook.NewRegistry())
+	handler.SetRateLimiter(ratelimit.NewTokenBucket(ratelimit.PerMinute(1)))
+
+	post := func(remoteAddr string) int {
+		req := httptest.NewRequest("POST", "/v1/embeddings", bytes.NewReader([]byte(`{"model":"text-embedding-3-small","input":"Hello"}`)))
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	post("10.0.0.1:1234")
+	if code := post("10.0.0.1:5678"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for the same client, got %d", code)
+	}
+	if code := post("10.0.0.2:1234"); code == http.StatusTooManyRequests {
+		t.Error("expected another client not to be rate limited")
+	}
+}
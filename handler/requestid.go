@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+)
+
+// DefaultRequestIDHeader is the header carrying request IDs unless configured otherwise
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// traceparentHeader is the W3C Trace Context header
+const traceparentHeader = "traceparent"
+
+// SetRequestIDHeader sets the header request IDs are read from and echoed in,
+// e.g. "X-Correlation-ID" or "traceparent" (defaults to X-Request-ID)
+func (h *ChatHandler) SetRequestIDHeader(header string) {
+	h.requestIDHeader = header
+}
+
+// SetRequestIDHeader sets the header request IDs are read from and echoed in,
+// e.g. "X-Correlation-ID" or "traceparent" (defaults to X-Request-ID)
+func (h *ResponsesHandler) SetRequestIDHeader(header string) {
+	h.requestIDHeader = header
+}
+
+// assignRequestID stores the request's ID in its context for hooks, audit and
+// sampling, and echoes it to the client in header
+func assignRequestID(w http.ResponseWriter, r *http.Request, header string) *http.Request {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	id, echo := requestID(r, header)
+	w.Header().Set(header, echo)
+	return r.WithContext(ai_gateway.WithRequestID(r.Context(), id))
+}
+
+// requestID returns the ID of r and the value echoing it in header. The ID is
+// taken from header, then from the trace ID of a W3C traceparent, and is
+// random otherwise.
+func requestID(r *http.Request, header string) (id, echo string) {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	tracing := strings.EqualFold(header, traceparentHeader)
+
+	if !tracing {
+		if id := r.Header.Get(header); id != "" {
+			return id, id
+		}
+	}
+	if traceparent := r.Header.Get(traceparentHeader); traceparent != "" {
+		if traceID, ok := parseTraceparent(traceparent); ok {
+			if tracing {
+				return traceID, traceparent
+			}
+			return traceID, traceID
+		}
+	}
+
+	if !tracing {
+		id := uuid.New().String()
+		return id, id
+	}
+	// A traceparent echo needs a trace ID and parent ID in hex
+	traceID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	parentID := make([]byte, 8)
+	rand.Read(parentID)
+	return traceID, "00-" + traceID + "-" + hex.EncodeToString(parentID) + "-01"
+}
+
+// parseTraceparent returns the trace ID of a W3C traceparent value such as
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func parseTraceparent(value string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", false
+	}
+	traceID, parentID := parts[1], parts[2]
+	if len(traceID) != 32 || len(parentID) != 16 || !isLowerHex(traceID) || !isLowerHex(parentID) {
+		return "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return "", false
+	}
+	return traceID, true
+}
+
+// isLowerHex reports whether s is made of lowercase hex digits
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestChatHandler_RequestIDHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		inbound  map[string]string
+		wantID   string
+		wantEcho string
+	}{
+		{
+			name:     "default header",
+			inbound:  map[string]string{"X-Request-ID": "req-1"},
+			wantID:   "req-1",
+			wantEcho: "req-1",
+		},
+		{
+			name:     "custom header",
+			header:   "X-Correlation-ID",
+			inbound:  map[string]string{"X-Correlation-ID": "corr-1", "X-Request-ID": "req-1"},
+			wantID:   "corr-1",
+			wantEcho: "corr-1",
+		},
+		{
+			name:     "traceparent fallback",
+			header:   "X-Correlation-ID",
+			inbound:  map[string]string{"traceparent": testTraceparent},
+			wantID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantEcho: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:     "traceparent header",
+			header:   "traceparent",
+			inbound:  map[string]string{"traceparent": testTraceparent},
+			wantID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantEcho: testTraceparent,
+		},
+		{
+			name:    "invalid traceparent",
+			header:  "X-Correlation-ID",
+			inbound: map[string]string{"traceparent": "00-not-a-trace-01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &capturingSink{}
+			handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+			handler.SetAuditSink(sink)
+			handler.SetRequestIDHeader(tt.header)
+
+			bodyBytes, _ := json.Marshal(map[string]any{
+				"model":    "gpt-4",
+				"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+			})
+			req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes))
+			for k, v := range tt.inbound {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if len(sink.records) != 1 {
+				t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+			}
+			id := sink.records[0].RequestID
+			header := tt.header
+			if header == "" {
+				header = DefaultRequestIDHeader
+			}
+			echo := w.Header().Get(header)
+
+			if tt.wantID == "" {
+				// A fresh ID is generated and echoed
+				if id == "" || echo != id {
+					t.Errorf("expected a generated ID echoed in %s, got ID %q and echo %q", header, id, echo)
+				}
+				return
+			}
+			if id != tt.wantID {
+				t.Errorf("expected request ID %q, got %q", tt.wantID, id)
+			}
+			if echo != tt.wantEcho {
+				t.Errorf("expected %s %q, got %q", header, tt.wantEcho, echo)
+			}
+		})
+	}
+}
+
+func TestRequestID_GeneratedTraceparent(t *testing.T) {
+	req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	id, echo := requestID(req, "traceparent")
+
+	traceID, ok := parseTraceparent(echo)
+	if !ok {
+		t.Fatalf("expected a valid traceparent echo, got %q", echo)
+	}
+	if traceID != id || !strings.HasPrefix(echo, "00-") {
+		t.Errorf("expected the echo to carry trace ID %q, got %q", id, echo)
+	}
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// SetSchemaValidation checks the answers to requests with a strict
+// json_schema response_format against the schema, failing with a 502 when the
+// provider's output doesn't match. Streamed answers aren't checked. Answers
+// from providers probed without JSON mode are always checked.
+func (h *ChatHandler) SetSchemaValidation(enabled bool) {
+	h.validateSchemas = enabled
+}
+
+// SetSchemaValidation checks the answers to requests with a strict
+// json_schema text format against the schema, failing with a 502 when the
+// provider's output doesn't match. Streamed answers aren't checked. Answers
+// from providers probed without JSON mode are always checked.
+func (h *ResponsesHandler) SetSchemaValidation(enabled bool) {
+	h.validateSchemas = enabled
+}
+
+// checksResponseFormat reports whether answers from prov are validated
+// against the response format: when enabled, and for providers probed without
+// JSON mode, which don't enforce it themselves
+func checksResponseFormat(enabled bool, capabilities *provider.CapabilityCache, prov provider.Provider) bool {
+	return enabled || !capabilities.Lookup(prov).JSONMode
+}
+
+// validateResponseFormat checks the content of every choice against format's
+// schema if it is a strict json_schema. Choices with tool calls or a refusal
+// carry no structured output and are skipped.
+func validateResponseFormat(format *openai2.ResponseFormat, choices []openai2.Choice) error {
+	if format == nil || format.Type != "json_schema" || format.JSONSchema == nil {
+		return nil
+	}
+	if strict := format.JSONSchema.Strict; strict == nil || !*strict {
+		return nil
+	}
+
+	for _, choice := range choices {
+		if len(choice.Message.ToolCalls) > 0 || choice.Message.Refusal != "" {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(choice.Message.Content), &value); err != nil {
+			return fmt.Errorf("choice %d: content is not valid JSON", choice.Index)
+		}
+		v := schemaValidator{root: format.JSONSchema.Schema}
+		if err := v.validate(format.JSONSchema.Schema, value, "$"); err != nil {
+			return fmt.Errorf("choice %d: %w", choice.Index, err)
+		}
+	}
+	return nil
+}
+
+// schemaValidator checks values against the subset of JSON Schema used for
+// structured outputs: type, enum, const, properties, required,
+// additionalProperties, items, anyOf and local $refs
+type schemaValidator struct {
+	root map[string]any
+}
+
+// validate checks value, found at path, against schema
+func (v schemaValidator) validate(schema map[string]any, value any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := v.resolve(ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return v.validate(resolved, value, path)
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return hasType(value, t) }) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), typeName(value))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(e, value) }) {
+		return fmt.Errorf("%s: value is not one of the allowed values", path)
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		return fmt.Errorf("%s: value does not match the constant", path)
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, option := range anyOf {
+			if s, ok := option.(map[string]any); ok && v.validate(s, value, path) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: value matches none of anyOf", path)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		return v.validateObject(schema, value, path)
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				if err := v.validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateObject checks the properties of object against schema
+func (v schemaValidator) validateObject(schema map[string]any, object map[string]any, path string) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := object[name]; !ok {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := path + "." + name
+		if property, ok := properties[name].(map[string]any); ok {
+			if err := v.validate(property, object[name], propertyPath); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property", propertyPath)
+			}
+		case map[string]any:
+			if err := v.validate(additional, object[name], propertyPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema a local $ref such as "#/$defs/step" points to
+func (v schemaValidator) resolve(ref string) (map[string]any, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	var node any = v.root
+	for _, part := range strings.Split(ref[len("#/"):], "/") {
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		node = object[part]
+	}
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return schema, nil
+}
+
+// schemaTypes returns the type names of a schema's "type" keyword
+func schemaTypes(t any) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value is of the JSON Schema type t
+func hasType(value any, t string) bool {
+	switch t {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number", "string", "boolean", "object", "array", "null":
+		return typeName(value) == t
+	}
+	return true
+}
+
+// typeName returns the JSON Schema type name of a decoded JSON value
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+// jsonEqual reports whether two decoded JSON values are equal
+func jsonEqual(a, b any) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(x) == string(y)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// structuredProvider answers every request with content and records the request
+type structuredProvider struct {
+	mockChatProvider
+	content string
+	lastReq *provider.Request
+}
+
+func (m *structuredProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.lastReq = req
+	return provider.NewChatCompletionResponse(&openai2.ChatCompletionResponse{
+		ID:      "test-id",
+		Object:  "chat.completion",
+		Model:   req.Model,
+		Choices: []openai2.Choice{{Message: openai2.Message{Role: "assistant", Content: m.content}, FinishReason: "stop"}},
+	}), nil
+}
+
+// answerSchema requires an object with a numeric answer and nothing else
+var answerSchema = map[string]any{
+	"type":                 "object",
+	"properties":           map[string]any{"answer": map[string]any{"type": "number"}},
+	"required":             []any{"answer"},
+	"additionalProperties": false,
+}
+
+func structuredChat(strict bool) map[string]any {
+	return map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "What is 6 times 7?"}},
+		"response_format": map[string]any{
+			"type":        "json_schema",
+			"json_schema": map[string]any{"name": "answer", "schema": answerSchema, "strict": strict},
+		},
+	}
+}
+
+func TestChatHandler_ResponseFormat(t *testing.T) {
+	prov := &structuredProvider{content: `{"answer":42}`}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+	w := postChatBody(handler, structuredChat(true))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	chatReq, err := prov.lastReq.ToChatCompletionRequest()
+	if err != nil {
+		t.Fatalf("ToChatCompletionRequest failed: %v", err)
+	}
+	upstream, _ := json.Marshal(chatReq)
+	var body struct {
+		ResponseFormat struct {
+			Type       string `json:"type"`
+			JSONSchema struct {
+				Name   string         `json:"name"`
+				Schema map[string]any `json:"schema"`
+				Strict bool           `json:"strict"`
+			} `json:"json_schema"`
+		} `json:"response_format"`
+	}
+	if err := json.Unmarshal(upstream, &body); err != nil {
+		t.Fatalf("failed to decode upstream body: %v", err)
+	}
+	format := body.ResponseFormat
+	if format.Type != "json_schema" || format.JSONSchema.Name != "answer" || !format.JSONSchema.Strict {
+		t.Errorf("expected the response format upstream, got %s", upstream)
+	}
+	if !jsonEqual(format.JSONSchema.Schema, answerSchema) {
+		t.Errorf("expected the schema unchanged, got %v", format.JSONSchema.Schema)
+	}
+}
+
+// jsonlessProvider is probed without JSON mode support
+type jsonlessProvider struct {
+	*structuredProvider
+}
+
+func (m *jsonlessProvider) ProbeCapabilities(ctx context.Context) (provider.Capabilities, error) {
+	return provider.Capabilities{Tools: true, Streaming: true}, nil
+}
+
+func TestChatHandler_SchemaValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		strict   bool
+		validate bool
+		// noJSONMode probes the provider without JSON mode support
+		noJSONMode bool
+		wantCode   int
+		wantErr    string
+	}{
+		{name: "matching", content: `{"answer":42}`, strict: true, validate: true, wantCode: 200},
+		{name: "missing property", content: `{"result":42}`, strict: true, validate: true, wantCode: 502, wantErr: "$: missing required property"},
+		{name: "wrong type", content: `{"answer":"42"}`, strict: true, validate: true, wantCode: 502, wantErr: "$.answer: expected number, got string"},
+		{name: "not JSON", content: "The answer is 42", strict: true, validate: true, wantCode: 502, wantErr: "content is not valid JSON"},
+		{name: "not strict", content: `{"result":42}`, validate: true, wantCode: 200},
+		{name: "validation disabled", content: `{"result":42}`, strict: true, wantCode: 200},
+		{name: "provider without JSON mode", content: `{"result":42}`, strict: true, noJSONMode: true, wantCode: 502, wantErr: "$: missing required property"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prov provider.Provider = &structuredProvider{content: tt.content}
+			capabilities := provider.NewCapabilityCache()
+			if tt.noJSONMode {
+				prov = &jsonlessProvider{prov.(*structuredProvider)}
+			}
+			capabilities.Probe(context.Background(), prov)
+			handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+			handler.SetSchemaValidation(tt.validate)
+			handler.SetCapabilityCache(capabilities)
+
+			w := postChatBody(handler, structuredChat(tt.strict))
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantErr != "" && !strings.Contains(w.Body.String(), tt.wantErr) {
+				t.Errorf("expected an error mentioning %q, got %s", tt.wantErr, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResponsesHandler_TextFormat(t *testing.T) {
+	prov := &structuredProvider{content: `{"answer":"42"}`}
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model": "gpt-4",
+		"input": "What is 6 times 7?",
+		"text": map[string]any{
+			"format": map[string]any{"type": "json_schema", "name": "answer", "schema": answerSchema, "strict": true},
+		},
+	})
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes)))
+		return w
+	}
+
+	if w := send(); w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	format := prov.lastReq.ResponseFormat
+	if format == nil || format.Type != "json_schema" || format.JSONSchema == nil || format.JSONSchema.Name != "answer" {
+		t.Fatalf("expected the text format as a response format, got %+v", format)
+	}
+	if !jsonEqual(format.JSONSchema.Schema, answerSchema) {
+		t.Errorf("expected the schema unchanged, got %v", format.JSONSchema.Schema)
+	}
+
+	handler.SetSchemaValidation(true)
+	w := send()
+	if w.Code != 502 || !strings.Contains(w.Body.String(), "$.answer: expected number, got string") {
+		t.Errorf("expected a 502 schema mismatch, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestValidateResponseFormat(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"steps":  map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/step"}},
+			"status": map[string]any{"enum": []any{"done", "failed"}},
+			"note":   map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "null"}}},
+		},
+		"$defs": map[string]any{
+			"step": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"n": map[string]any{"type": "integer"}},
+				"required":   []any{"n"},
+			},
+		},
+	}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: `{"steps":[{"n":1},{"n":2}],"status":"done","note":null}`},
+		{content: `{"steps":[{"n":1},{"n":2.5}]}`, want: "$.steps[1].n: expected integer, got number"},
+		{content: `{"steps":[{}]}`, want: `$.steps[0]: missing required property "n"`},
+		{content: `{"status":"pending"}`, want: "$.status: value is not one of the allowed values"},
+		{content: `{"note":3}`, want: "$.note: value matches none of anyOf"},
+		{content: `[]`, want: "$: expected object, got array"},
+	}
+	strict := true
+	format := &openai2.ResponseFormat{Type: "json_schema", JSONSchema: &openai2.JSONSchema{Name: "plan", Schema: schema, Strict: &strict}}
+	for _, tt := range tests {
+		err := validateResponseFormat(format, []openai2.Choice{{Message: openai2.Message{Content: tt.content}}})
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.content, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.content, tt.want, err)
+		}
+	}
+}
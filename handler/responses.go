@@ -3,16 +3,22 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/audit"
 	openai2 "github.com/deeplooplabs/ai-gateway/openresponses"
 	"github.com/deeplooplabs/ai-gateway/hook"
 	"github.com/deeplooplabs/ai-gateway/model"
 	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
 )
 
 // ResponsesHandler handles OpenResponses API requests
@@ -21,6 +27,24 @@ type ResponsesHandler struct {
 	hooks     *hook.Registry
 	orHooks   *openai2.Registry
 	converter *openai2.Converter
+	audit     audit.Sink
+	store     openai2.ResponseStore
+	sse       SSEConfig
+	limiter   ratelimit.Limiter
+	metrics   MetricsRecorder
+	maxTokens OutputTokenDefaults
+	timeout   time.Duration
+	strict    bool
+
+	requestIDHeader string
+
+	completeOnDisconnect bool
+	validateSchemas      bool
+
+	streamFallback StreamFallback
+	capabilities   *provider.CapabilityCache
+	tools          ToolsFallback
+	background     *sync.WaitGroup
 }
 
 // NewResponsesHandler creates a new responses handler
@@ -38,33 +62,110 @@ func (h *ResponsesHandler) SetOpenResponsesHooks(orHooks *openai2.Registry) {
 	h.orHooks = orHooks
 }
 
+// SetAuditSink sets the sink receiving full request/response audit records
+func (h *ResponsesHandler) SetAuditSink(sink audit.Sink) {
+	h.audit = sink
+}
+
+// SetResponseStore sets the store used for store:true and previous_response_id
+func (h *ResponsesHandler) SetResponseStore(store openai2.ResponseStore) {
+	h.store = store
+}
+
+// SetSSEConfig sets the framing used for streaming responses
+func (h *ResponsesHandler) SetSSEConfig(cfg SSEConfig) {
+	h.sse = cfg
+}
+
+// SetRateLimiter enables per-tenant rate limiting
+func (h *ResponsesHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *ResponsesHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
+// SetBackgroundWaitGroup tracks the goroutines completing background
+// responses in wg, so a server can wait for them when shutting down
+func (h *ResponsesHandler) SetBackgroundWaitGroup(wg *sync.WaitGroup) {
+	h.background = wg
+}
+
+// SetStrictConversion rejects requests to Chat Completions-only providers that
+// use features the conversion would drop, instead of silently dropping them
+func (h *ResponsesHandler) SetStrictConversion(strict bool) {
+	h.strict = strict
+}
+
+// SetMessageIDFunc sets the function generating the IDs of message output
+// items, for streamed and non-streamed responses alike
+func (h *ResponsesHandler) SetMessageIDFunc(fn openai2.MessageIDFunc) {
+	h.converter.SetMessageIDFunc(fn)
+}
+
+// OutputTokenDefaults sets max_output_tokens for requests that omit it, since
+// some providers otherwise default to very short completions
+type OutputTokenDefaults struct {
+	// Default applies to models without an entry in Models; 0 leaves the provider's default
+	Default int
+
+	// Models maps requested model names to their default
+	Models map[string]int
+}
+
+// forModel returns the default for model, or 0 if none applies
+func (d OutputTokenDefaults) forModel(model string) int {
+	if n, ok := d.Models[model]; ok {
+		return n
+	}
+	return d.Default
+}
+
+// SetOutputTokenDefaults sets the max_output_tokens applied when a request omits it.
+// Client-provided values always take precedence.
+func (h *ResponsesHandler) SetOutputTokenDefaults(defaults OutputTokenDefaults) {
+	h.maxTokens = defaults
+}
+
 // ServeHTTP implements http.Handler for /v1/responses
 func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/responses", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, ai_gateway.NewValidationError(timeoutErr.Error()))
+		return
+	}
+
 	// Only POST is supported
 	if r.Method != http.MethodPost {
 		h.writeError(w, r, ai_gateway.NewValidationError("Only POST method is allowed"))
 		return
 	}
 
-	// Call AuthenticationHooks
-	ctx := r.Context()
-	for _, hh := range h.hooks.AuthenticationHooks() {
-		success, tenantID, err := hh.Authenticate(ctx, r.Header.Get("Authorization"))
-		if err != nil {
-			h.writeError(w, r, ai_gateway.NewServerError("Authentication failed: "+err.Error(), err))
-			return
-		}
-		if !success {
-			h.writeError(w, r, ai_gateway.NewAuthenticationError("Invalid API key"))
-			return
-		}
-		// Store tenantID in context
-		ctx = context.WithValue(ctx, "tenant_id", tenantID)
-		r = r.WithContext(ctx)
+	// Assign a stable request ID for hooks, audit and sampling
+	r = assignRequestID(w, r, h.requestIDHeader)
+
+	r, ok := h.authenticate(w, r)
+	if !ok {
+		return
 	}
 
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, ai_gateway.NewRateLimitError("Rate limit exceeded"))
+		return
+	}
+	ctx := r.Context()
+
 	// Parse request
 	var req openai2.CreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -82,12 +183,31 @@ func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, r, ai_gateway.NewValidationError("input is required"))
 		return
 	}
+	if gwErr := checkInput(req.Input); gwErr != nil {
+		h.writeError(w, r, gwErr)
+		return
+	}
 
 	// Set default truncation
 	if req.Truncation == "" {
 		req.Truncation = openai2.TruncationAuto
 	}
 
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(ctx, h.hooks, req.Model)
+	if routeErr != nil {
+		h.writeError(w, r, &ai_gateway.GatewayError{Code: routeErr.Code, Message: routeErr.Message, Type: routeErr.Type, InnerError: routeErr.Err})
+		return
+	}
+	req.Model = routed
+
+	// Apply the configured output token default, keyed by the requested model
+	if req.MaxOutputTokens == nil {
+		if n := h.maxTokens.forModel(req.Model); n > 0 {
+			req.MaxOutputTokens = &n
+		}
+	}
+
 	// Resolve provider
 	prov, modelRewrite := h.registry.Resolve(req.Model)
 	if prov == nil {
@@ -96,10 +216,35 @@ func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply model rewrite if specified
+	originalModel := req.Model
 	if modelRewrite != "" {
 		req.Model = modelRewrite
 	}
 
+	// In strict mode, refuse to drop what a Chat Completions provider can't represent
+	if h.strict && !prov.SupportedAPIs().Supports(provider.APITypeResponses) {
+		if dropped := h.converter.DroppedFields(&req); len(dropped) > 0 {
+			gwErr := ai_gateway.NewValidationError(fmt.Sprintf(
+				"Model %s is served through Chat Completions, which can't represent: %s",
+				originalModel, strings.Join(dropped, ", ")))
+			gwErr.Param = dropped[0]
+			h.writeError(w, r, gwErr)
+			return
+		}
+	}
+
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/responses", prov)
+
+	// Record routing info for hooks and logging
+	ctx = withRouteInfo(ctx, prov, originalModel, req.Model)
+	r = r.WithContext(ctx)
+
+	if req.Background != nil && *req.Background {
+		h.handleBackground(ctx, w, r, &req, prov)
+		return
+	}
+
 	// Handle streaming vs non-streaming
 	stream := req.Stream != nil && *req.Stream
 	if stream {
@@ -110,17 +255,234 @@ func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.handleNonStream(ctx, w, r, &req, prov)
 }
 
+// checkInput rejects an empty input string or array, which the conversion
+// would otherwise fail on with a less helpful error
+func checkInput(input openai2.InputParam) *ai_gateway.GatewayError {
+	var msg string
+	switch in := input.(type) {
+	case string:
+		if in == "" {
+			msg = "input must not be an empty string"
+		}
+	case []any:
+		if len(in) == 0 {
+			msg = "input must contain at least one item"
+		}
+	}
+	if msg == "" {
+		return nil
+	}
+	gwErr := ai_gateway.NewValidationError(msg)
+	gwErr.Param = "input"
+	return gwErr
+}
+
 func (h *ResponsesHandler) handleNonStream(ctx context.Context, w http.ResponseWriter, r *http.Request, req *openai2.CreateRequest, prov provider.Provider) {
 	// Generate response ID
 	responseID := "resp_" + uuid.New().String()
 
+	chatReq, gwErr := h.prepareRequest(ctx, req, prov)
+	if gwErr != nil {
+		h.writeError(w, r, gwErr)
+		return
+	}
+
+	// Finish calls whose result is stored even if the client goes away
+	clientCtx := ctx
+	if h.completeOnDisconnect && h.store != nil && req.Store != nil && *req.Store {
+		var cancel context.CancelFunc
+		ctx, cancel = detachClient(ctx)
+		defer cancel()
+	}
+
+	orResp, gwErr := h.createResponse(ctx, r, req, chatReq, prov, responseID, w.Header())
+	if gwErr != nil {
+		h.writeError(w, r, gwErr)
+		return
+	}
+	h.saveResponse(ctx, req, chatReq, orResp)
+	if orResp.Store {
+		logDisconnected(ctx, clientCtx, "client disconnected, stored the completed response")
+	}
+
+	// Write response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(orResp); err != nil {
+		h.writeError(w, r, ai_gateway.NewServerError("Failed to encode response: "+err.Error(), err))
+		return
+	}
+}
+
+// handleBackground stores a queued response, returns it immediately and
+// completes the request in a goroutine. Clients poll GET /v1/responses/{id}.
+func (h *ResponsesHandler) handleBackground(ctx context.Context, w http.ResponseWriter, r *http.Request, req *openai2.CreateRequest, prov provider.Provider) {
+	if h.store == nil {
+		h.writeError(w, r, ai_gateway.NewValidationError("background is not supported: no response store configured"))
+		return
+	}
+	if req.Stream != nil && *req.Stream {
+		h.writeError(w, r, ai_gateway.NewValidationError("background cannot be combined with stream"))
+		return
+	}
+	if req.Store != nil && !*req.Store {
+		h.writeError(w, r, ai_gateway.NewValidationError("background requires store to be true"))
+		return
+	}
+	store := true
+	req.Store = &store
+
+	responseID := "resp_" + uuid.New().String()
+
+	chatReq, gwErr := h.prepareRequest(ctx, req, prov)
+	if gwErr != nil {
+		h.writeError(w, r, gwErr)
+		return
+	}
+
+	queued := openai2.NewResponse(responseID, req.Model)
+	queued.Status = openai2.ResponseStatusQueued
+	queued.Background = true
+	queued.Tools = h.converter.FunctionTools(req.Tools)
+	if req.PreviousResponseID != "" {
+		queued.PreviousResponseID = &req.PreviousResponseID
+	}
+
+	tenantID := ai_gateway.TenantIDFromContext(ctx)
+	if err := h.store.Save(ctx, &openai2.StoredResponse{Response: queued, TenantID: tenantID, Messages: chatReq.Messages}); err != nil {
+		h.writeError(w, r, ai_gateway.NewServerError("Failed to store response: "+err.Error(), err))
+		return
+	}
+
+	// The provider call must outlive the client request
+	bgCtx, cancel := h.backgroundContext(ctx)
+	if h.background != nil {
+		h.background.Add(1)
+	}
+	go func() {
+		defer cancel()
+		if h.background != nil {
+			defer h.background.Done()
+		}
+
+		// Each transition saves a new Response so readers never see a partial update
+		inProgress := *queued
+		inProgress.Status = openai2.ResponseStatusInProgress
+		fail := func(gwErr *ai_gateway.GatewayError) {
+			failed := inProgress
+			failed.Status = openai2.ResponseStatusFailed
+			failed.Error = &openai2.Error{Type: gwErr.Type, Message: gwErr.Message}
+			h.saveBackground(bgCtx, &openai2.StoredResponse{Response: &failed, TenantID: tenantID, Messages: chatReq.Messages})
+		}
+		defer func() {
+			if rec := recover(); rec != nil {
+				err := panicError(bgCtx, h.hooks, rec)
+				fail(ai_gateway.NewServerError("Internal server error", err))
+			}
+		}()
+
+		if !h.saveBackground(bgCtx, &openai2.StoredResponse{Response: &inProgress, TenantID: tenantID, Messages: chatReq.Messages}) {
+			return
+		}
+
+		orResp, gwErr := h.createResponse(bgCtx, r, req, chatReq, prov, responseID, nil)
+		if gwErr != nil {
+			for _, hh := range h.hooks.ErrorHooks() {
+				hh.OnError(bgCtx, gwErr)
+			}
+			fail(gwErr)
+			return
+		}
+
+		orResp.Background = true
+		orResp.Store = true
+		if req.PreviousResponseID != "" {
+			orResp.PreviousResponseID = &req.PreviousResponseID
+		}
+		h.saveBackground(bgCtx, &openai2.StoredResponse{Response: orResp, TenantID: tenantID, Messages: chatReq.Messages})
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queued)
+}
+
+// DefaultBackgroundTimeout bounds background responses whose request set no
+// X-Request-Timeout, so a hung upstream can't keep one running forever
+const DefaultBackgroundTimeout = 10 * time.Minute
+
+// backgroundContext detaches ctx from the client for a background response,
+// keeping its request timeout or else applying DefaultBackgroundTimeout
+func (h *ResponsesHandler) backgroundContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return detachClient(ctx)
+	}
+	return context.WithTimeoutCause(context.WithoutCancel(ctx), DefaultBackgroundTimeout, errRequestTimeout)
+}
+
+// saveBackground replaces a stored background response, reporting failures to
+// ErrorHooks. It returns false without saving if the response was deleted
+// since it was queued. Stores implementing ResponseUpdater check and save
+// atomically; others are checked first, so a concurrent delete may be undone.
+func (h *ResponsesHandler) saveBackground(ctx context.Context, stored *openai2.StoredResponse) bool {
+	var err error
+	if updater, ok := h.store.(openai2.ResponseUpdater); ok {
+		err = updater.Update(ctx, stored)
+		if errors.Is(err, openai2.ErrResponseNotFound) {
+			return false
+		}
+	} else {
+		if !h.backgroundStored(ctx, stored.Response.ID) {
+			return false
+		}
+		err = h.store.Save(ctx, stored)
+	}
+	if err != nil {
+		for _, hh := range h.hooks.ErrorHooks() {
+			hh.OnError(ctx, fmt.Errorf("store response: %w", err))
+		}
+	}
+	return true
+}
+
+// backgroundStored reports whether a background response is still stored, i.e.
+// wasn't deleted while it ran. Other store failures are reported to ErrorHooks
+// and don't stop the response.
+func (h *ResponsesHandler) backgroundStored(ctx context.Context, responseID string) bool {
+	_, err := h.store.Get(ctx, responseID)
+	if errors.Is(err, openai2.ErrResponseNotFound) {
+		return false
+	}
+	if err != nil {
+		for _, hh := range h.hooks.ErrorHooks() {
+			hh.OnError(ctx, fmt.Errorf("load response: %w", err))
+		}
+	}
+	return true
+}
+
+// prepareRequest converts req to Chat Completions format, including any
+// previous conversation, and drops tools prov can't call
+func (h *ResponsesHandler) prepareRequest(ctx context.Context, req *openai2.CreateRequest, prov provider.Provider) (*openai.ChatCompletionRequest, *ai_gateway.GatewayError) {
 	// Convert to OpenAI format
 	chatReq, err := h.converter.RequestToChatCompletion(req)
 	if err != nil {
-		h.writeError(w, r, ai_gateway.NewValidationError("Failed to convert request: "+err.Error()))
-		return
+		return nil, ai_gateway.NewValidationError("Failed to convert request: " + err.Error())
 	}
 
+	// Prepend the stored conversation when continuing a previous response
+	if gwErr := h.continueConversation(ctx, req, chatReq); gwErr != nil {
+		return nil, gwErr
+	}
+
+	// Providers without function calling can't be sent tools
+	if err := applyToolsFallback(ctx, h.hooks, h.capabilities, h.tools, chatReq, prov); err != nil {
+		return nil, ai_gateway.NewValidationError(err.Error())
+	}
+	return chatReq, nil
+}
+
+// createResponse sends chatReq to the provider and converts the result to an
+// OpenResponses response. The upstream headers are copied to header, if not nil.
+func (h *ResponsesHandler) createResponse(ctx context.Context, r *http.Request, req *openai2.CreateRequest, chatReq *openai.ChatCompletionRequest, prov provider.Provider, responseID string, header http.Header) (*openai2.Response, *ai_gateway.GatewayError) {
 	// Build unified request
 	unifiedReq := provider.NewChatCompletionsRequest(chatReq.Model, chatReq.Messages)
 	unifiedReq.Stream = false
@@ -128,10 +490,12 @@ func (h *ResponsesHandler) handleNonStream(ctx context.Context, w http.ResponseW
 	unifiedReq.TopP = chatReq.TopP
 	unifiedReq.MaxTokens = chatReq.MaxTokens
 	unifiedReq.Stop = chatReq.Stop
+	unifiedReq.N = chatReq.N
 	unifiedReq.PresencePenalty = chatReq.PresencePenalty
 	unifiedReq.FrequencyPenalty = chatReq.FrequencyPenalty
 	unifiedReq.Tools = chatReq.Tools
 	unifiedReq.ToolChoice = chatReq.ToolChoice
+	unifiedReq.ResponseFormat = chatReq.ResponseFormat
 	unifiedReq.Endpoint = "/v1/chat/completions"
 
 	// Call BeforeRequest hooks
@@ -139,49 +503,41 @@ func (h *ResponsesHandler) handleNonStream(ctx context.Context, w http.ResponseW
 		// Note: We're using OpenAI types for existing hooks
 		// In the future, we'll have OpenResponses-specific hooks
 		if err := hh.BeforeRequest(ctx, chatReq); err != nil {
-			h.writeError(w, r, ai_gateway.NewServerError("BeforeRequest hook failed: "+err.Error(), err))
-			return
+			return nil, ai_gateway.NewServerError("BeforeRequest hook failed: "+err.Error(), err)
 		}
 	}
 
 	// Send request to provider using unified interface
 	resp, err := prov.SendRequest(ctx, unifiedReq)
 	if err != nil {
-		h.writeError(w, r, ai_gateway.NewServerError("Provider error: "+err.Error(), err))
-		return
+		return nil, responsesProviderError(err)
 	}
 	defer resp.Close()
+	if header != nil {
+		copyHeaders(header, resp.Headers)
+	}
 
 	// Convert response to OpenResponses format
 	chatResp, err := resp.GetChatCompletion()
 	if err != nil {
-		h.writeError(w, r, ai_gateway.NewServerError("Failed to convert response: "+err.Error(), err))
-		return
+		return nil, ai_gateway.NewServerError("Failed to convert response: "+err.Error(), err)
 	}
 	if chatResp == nil {
-		h.writeError(w, r, ai_gateway.NewServerError("Empty response from provider", nil))
-		return
+		return nil, ai_gateway.NewServerError("Empty response from provider", nil)
 	}
-
-	// Convert tools from request to OpenResponses format for the response
-	var tools []openai2.Tool
-	if len(req.Tools) > 0 {
-		tools = make([]openai2.Tool, 0, len(req.Tools))
-		for _, tool := range req.Tools {
-			if fn, ok := tool.(*openai2.FunctionTool); ok {
-				tools = append(tools, fn)
-			}
+	if checksResponseFormat(h.validateSchemas, h.capabilities, prov) {
+		if err := validateResponseFormat(chatReq.ResponseFormat, chatResp.Choices); err != nil {
+			return nil, ai_gateway.NewProviderError("Response does not match the text.format schema: "+err.Error(), err)
 		}
 	}
 
-	orResp := h.converter.ChatCompletionToResponse(chatResp, responseID, tools)
+	recordTokens(ctx, h.metrics, &chatResp.Usage)
+	writeAudit(ctx, h.audit, h.hooks, r, false, chatReq, chatResp)
 
-	// Write response
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(orResp); err != nil {
-		h.writeError(w, r, ai_gateway.NewServerError("Failed to encode response: "+err.Error(), err))
-		return
-	}
+	// Echo the request's function tools in the response
+	tools := h.converter.FunctionTools(req.Tools)
+
+	return h.converter.ChatCompletionToResponse(chatResp, responseID, tools), nil
 }
 
 func (h *ResponsesHandler) handleStream(ctx context.Context, w http.ResponseWriter, r *http.Request, req *openai2.CreateRequest, prov provider.Provider) {
@@ -198,6 +554,10 @@ func (h *ResponsesHandler) handleStream(ctx context.Context, w http.ResponseWrit
 
 	// Create stream writer
 	writer := openai2.NewStreamWriter(w, flusher)
+	writer.SetLineTerminator(h.sse.lineTerminator())
+	if h.sse.Preamble {
+		writer.WriteComment("ok")
+	}
 
 	// Generate response ID
 	responseID := "resp_" + uuid.New().String()
@@ -206,10 +566,14 @@ func (h *ResponsesHandler) handleStream(ctx context.Context, w http.ResponseWrit
 	initResp := openai2.NewResponse(responseID, req.Model)
 
 	// Send response.created event with full response object
-	writer.WriteEvent(openai2.NewResponseCreatedEvent(writer.NextSequence(), initResp))
+	if !h.writeEvent(ctx, writer, openai2.NewResponseCreatedEvent(writer.NextSequence(), initResp)) {
+		return
+	}
 
 	// Send response.in_progress event with full response object
-	writer.WriteEvent(openai2.NewResponseInProgressEvent(writer.NextSequence(), initResp))
+	if !h.writeEvent(ctx, writer, openai2.NewResponseInProgressEvent(writer.NextSequence(), initResp)) {
+		return
+	}
 
 	// Convert to OpenAI format
 	chatReq, err := h.converter.RequestToChatCompletion(req)
@@ -223,21 +587,62 @@ func (h *ResponsesHandler) handleStream(ctx context.Context, w http.ResponseWrit
 		return
 	}
 
+	// Prepend the stored conversation when continuing a previous response
+	if gwErr := h.continueConversation(ctx, req, chatReq); gwErr != nil {
+		writer.WriteError(openai2.NewError(
+			"invalid_request_error",
+			"previous_response_not_found",
+			gwErr.Message,
+			"previous_response_id",
+		))
+		return
+	}
+
+	// Providers without function calling can't be sent tools
+	if err := applyToolsFallback(ctx, h.hooks, h.capabilities, h.tools, chatReq, prov); err != nil {
+		writer.WriteError(openai2.NewError(
+			"invalid_request_error",
+			"tools_not_supported",
+			err.Error(),
+			"tools",
+		))
+		return
+	}
+
 	// Build unified request
 	unifiedReq := provider.NewChatCompletionsRequest(chatReq.Model, chatReq.Messages)
-	unifiedReq.Stream = true
+	unifiedReq.Stream = h.capabilities.Lookup(prov).Streaming
 	unifiedReq.Temperature = chatReq.Temperature
 	unifiedReq.TopP = chatReq.TopP
 	unifiedReq.MaxTokens = chatReq.MaxTokens
 	unifiedReq.Stop = chatReq.Stop
+	unifiedReq.N = chatReq.N
 	unifiedReq.PresencePenalty = chatReq.PresencePenalty
 	unifiedReq.FrequencyPenalty = chatReq.FrequencyPenalty
 	unifiedReq.Tools = chatReq.Tools
 	unifiedReq.ToolChoice = chatReq.ToolChoice
+	unifiedReq.ResponseFormat = chatReq.ResponseFormat
 	unifiedReq.Endpoint = "/v1/chat/completions"
 
+	// Call BeforeRequest hooks
+	for _, hh := range h.hooks.RequestHooks() {
+		if err := hh.BeforeRequest(ctx, chatReq); err != nil {
+			writer.WriteError(openai2.NewError(
+				"server_error",
+				"hook_error",
+				"BeforeRequest hook failed: "+err.Error(),
+				"",
+			))
+			return
+		}
+	}
+
 	// Send request to provider using unified interface
 	resp, err := prov.SendRequest(ctx, unifiedReq)
+	if err != nil && timedOut(ctx) {
+		writer.WriteError(errStreamTimeout)
+		return
+	}
 	if err != nil {
 		writer.WriteError(openai2.NewError(
 			"server_error",
@@ -249,105 +654,121 @@ func (h *ResponsesHandler) handleStream(ctx context.Context, w http.ResponseWrit
 	}
 	defer resp.Close()
 
+	// Serve a non-streaming answer as configured by the stream fallback
+	var replayedUsage *openai.Usage
 	if !resp.Stream {
-		writer.WriteError(openai2.NewError(
-			"server_error",
-			"not_streaming",
-			"Provider returned non-streaming response",
-			"",
-		))
-		return
+		chatResp, err := resp.GetChatCompletion()
+		if err != nil || chatResp == nil {
+			writer.WriteError(openai2.NewError(
+				"server_error",
+				"not_streaming",
+				"Provider returned an unusable non-streaming response",
+				"",
+			))
+			return
+		}
+		recordTokens(ctx, h.metrics, &chatResp.Usage)
+		if h.streamFallback == StreamSingleEvent {
+			h.writeSingleEvent(ctx, writer, r, req, chatReq, chatResp, responseID)
+			return
+		}
+		replayedUsage = &chatResp.Usage
+		resp = replayStream(chatResp)
 	}
 
-	// Track state for item management
+	// Track output items, one per choice index
 	seq := 0
-	itemID := "msg_" + uuid.New().String()
-	outputIndex := 0
-	var itemAdded bool
+	items := openai2.NewStreamItems(responseID)
+	acc := openai.NewStreamAccumulator()
+	start := time.Now()
+	chunkIndex := 0
 
 	// Process chunks
 	for {
 		select {
 		case <-ctx.Done():
+			if timedOut(ctx) {
+				writer.WriteError(errStreamTimeout)
+			}
 			return
 		case chunk, ok := <-resp.Chunks:
-			if !ok {
-				// Channel closed, send completion
-				orResp := openai2.NewResponse(responseID, req.Model)
-				orResp.Status = openai2.ResponseStatusCompleted
-				now := time.Now().Unix()
-				orResp.CompletedAt = &now
-
-				writer.WriteEvent(openai2.NewResponseCompletedEvent(writer.NextSequence(), orResp))
-				writer.WriteDone()
+			// A stream closed by the request timeout didn't complete
+			if !ok && timedOut(ctx) {
+				writer.WriteError(errStreamTimeout)
 				return
 			}
 
-			if chunk.Done {
+			// A closed channel ends the stream like a done chunk
+			if !ok || chunk.Done {
+				// Close the items of choices that ended without a finish_reason
+				for _, event := range h.converter.StreamDoneEvents(&seq, items) {
+					if !h.writeEvent(ctx, writer, event) {
+						return
+					}
+				}
+
+				recordTokens(ctx, h.metrics, acc.Usage())
+				if h.audit != nil {
+					writeAudit(ctx, h.audit, h.hooks, r, true, chatReq, acc.Response())
+				}
+
 				// Send completion
 				orResp := openai2.NewResponse(responseID, req.Model)
 				orResp.Status = openai2.ResponseStatusCompleted
 				now := time.Now().Unix()
 				orResp.CompletedAt = &now
 
-				// Add completed message item if we haven't already
-				if !itemAdded {
-					messageItem := &openai2.MessageItem{
-						ID:     itemID,
-						Type:   "message",
-						Status: openai2.MessageStatusCompleted,
-						Role:   openai2.MessageRoleAssistant,
-						Content: []openai2.OutputTextContent{
-							{Type: "output_text", Text: "", Annotations: []openai2.Annotation{}, Logprobs: []openai2.LogProb{}},
-						},
-					}
-					orResp.Output = []openai2.ItemField{messageItem}
+				orResp.Output = items.Output()
+				// A replayed response reports the usage of the answer it replays
+				if replayedUsage != nil {
+					orResp.Usage = openai2.UsageFromChat(*replayedUsage)
 				}
+				// A choice cut short by max_output_tokens or a content filter ends the response incomplete
+				details := items.IncompleteDetails()
+				if details != nil {
+					orResp.Status = openai2.ResponseStatusIncomplete
+					orResp.CompletedAt = nil
+					orResp.IncompleteDetails = details
+				}
+				h.saveResponse(ctx, req, chatReq, orResp)
 
-				writer.WriteEvent(openai2.NewResponseCompletedEvent(writer.NextSequence(), orResp))
-				writer.WriteDone()
+				var final openai2.StreamingEvent = openai2.NewResponseCompletedEvent(writer.NextSequence(), orResp)
+				if details != nil {
+					final = openai2.NewResponseIncompleteEvent(writer.NextSequence(), orResp)
+				}
+				if h.writeEvent(ctx, writer, final) {
+					writer.WriteDone()
+				}
 				return
 			}
 
 			// Process chunk based on type
 			if chunk.Type == provider.ChunkTypeOpenAI && chunk.OpenAI != nil && len(chunk.OpenAI.Data) > 0 {
-				// Send item added event if not sent yet
-				if !itemAdded {
-					messageItem := &openai2.MessageItem{
-						ID:     itemID,
-						Type:   "message",
-						Status: openai2.MessageStatusInProgress,
-						Role:   openai2.MessageRoleAssistant,
-						Content: []openai2.OutputTextContent{
-							{Type: "output_text", Text: "", Annotations: []openai2.Annotation{}, Logprobs: []openai2.LogProb{}},
-						},
+				if h.audit != nil {
+					var parsed openai.ChatCompletionStreamResponse
+					if err := json.Unmarshal(chunk.OpenAI.Data, &parsed); err == nil {
+						acc.Add(&parsed)
 					}
-					writer.WriteEvent(openai2.NewResponseOutputItemAddedEvent(writer.NextSequence(), outputIndex, messageItem))
-					itemAdded = true
-
-					// Send content part added event
-					contentPart := openai2.OutputTextContent{Type: "output_text", Text: "", Annotations: []openai2.Annotation{}, Logprobs: []openai2.LogProb{}}
-					writer.WriteEvent(openai2.NewResponseContentPartAddedEvent(writer.NextSequence(), itemID, outputIndex, 0, contentPart))
 				}
 
-				// Convert chunk to events
-				events := h.converter.StreamingChunkToEvents(chunk.OpenAI.Data, &seq, itemID, outputIndex)
+				// Convert chunk to events, adding an output item per new choice index
+				events := h.converter.StreamingChunkToEvents(chunk.OpenAI.Data, &seq, items)
 
 				// Apply streaming hooks and write events
 				for _, event := range events {
-					if err := writer.WriteEvent(event); err != nil {
-						writer.WriteError(openai2.NewError(
-							"server_error",
-							"write_error",
-							"Failed to write event: "+err.Error(),
-							"",
-						))
+					if !h.writeEvent(ctx, writer, event) {
 						return
 					}
 				}
+
+				notifyStreamStats(ctx, h.hooks, &chunkIndex, len(chunk.OpenAI.Data), start)
 			}
 
 		case err := <-resp.Errors:
+			if err != nil && timedOut(ctx) {
+				writer.WriteError(errStreamTimeout)
+				return
+			}
 			if err != nil {
 				writer.WriteError(openai2.NewError(
 					"server_error",
@@ -361,7 +782,187 @@ func (h *ResponsesHandler) handleStream(ctx context.Context, w http.ResponseWrit
 	}
 }
 
+// responsesProviderError reports a failed provider call, as a rate limit or
+// timeout error when the provider classified it as one
+func responsesProviderError(err error) *ai_gateway.GatewayError {
+	message := "Provider error: " + err.Error()
+	if kind, ok := provider.ErrorKindOf(err); ok {
+		switch kind {
+		case provider.ErrorKindRateLimited:
+			gwErr := ai_gateway.NewRateLimitError(message)
+			gwErr.InnerError = err
+			return gwErr
+		case provider.ErrorKindTimeout:
+			return ai_gateway.NewTimeoutError(message, err)
+		}
+	}
+	return ai_gateway.NewServerError(message, err)
+}
+
+// writeEvent runs the OpenResponses streaming hooks on event and writes it.
+// Hooks may drop an event by returning nil. A hook or write error ends the
+// stream with an error event, and false is returned.
+func (h *ResponsesHandler) writeEvent(ctx context.Context, writer *openai2.StreamWriter, event openai2.StreamingEvent) bool {
+	for _, hh := range h.orHooks.StreamingHooks() {
+		modified, err := hh.OnEvent(ctx, event)
+		if err != nil {
+			writer.WriteError(openai2.NewError(
+				"server_error",
+				"hook_error",
+				"Streaming hook failed: "+err.Error(),
+				"",
+			))
+			return false
+		}
+		if modified == nil {
+			return true
+		}
+		event = modified
+	}
+
+	if err := writer.WriteEvent(event); err != nil {
+		writer.WriteError(openai2.NewError(
+			"server_error",
+			"write_error",
+			"Failed to write event: "+err.Error(),
+			"",
+		))
+		return false
+	}
+	return true
+}
+
+// ServeResponseByID implements GET and DELETE for /v1/responses/{id}
+func (h *ResponsesHandler) ServeResponseByID(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		h.writeError(w, r, &ai_gateway.GatewayError{
+			Code:    http.StatusMethodNotAllowed,
+			Message: "Only GET and DELETE methods are allowed",
+			Type:    "invalid_request_error",
+		})
+		return
+	}
+
+	r, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+
+	responseID := strings.TrimPrefix(r.URL.Path, "/v1/responses/")
+	stored, gwErr := h.loadResponse(ctx, responseID)
+	if gwErr != nil {
+		h.writeError(w, r, gwErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(stored.Response)
+		return
+	}
+
+	if err := h.store.Delete(ctx, responseID); err != nil && !errors.Is(err, openai2.ErrResponseNotFound) {
+		h.writeError(w, r, ai_gateway.NewServerError("Failed to delete response: "+err.Error(), err))
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"id":      responseID,
+		"object":  "response",
+		"deleted": true,
+	})
+}
+
+// authenticate runs the authentication hooks and stores the tenant ID in the request context.
+// It writes an error and returns false if authentication fails.
+func (h *ResponsesHandler) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	for _, hh := range h.hooks.AuthenticationHooks() {
+		success, tenantID, err := hh.Authenticate(r.Context(), r.Header.Get("Authorization"))
+		if err != nil {
+			h.writeError(w, r, ai_gateway.NewServerError("Authentication failed: "+err.Error(), err))
+			return r, false
+		}
+		if !success {
+			h.writeError(w, r, ai_gateway.NewAuthenticationError("Invalid API key"))
+			return r, false
+		}
+		// Store tenantID in context
+		r = r.WithContext(context.WithValue(r.Context(), "tenant_id", tenantID))
+	}
+	return r, true
+}
+
+// loadResponse returns a stored response visible to the request's tenant
+func (h *ResponsesHandler) loadResponse(ctx context.Context, responseID string) (*openai2.StoredResponse, *ai_gateway.GatewayError) {
+	if h.store == nil || responseID == "" || strings.Contains(responseID, "/") {
+		return nil, ai_gateway.NewNotFoundError("Response not found: " + responseID)
+	}
+
+	stored, err := h.store.Get(ctx, responseID)
+	if errors.Is(err, openai2.ErrResponseNotFound) {
+		return nil, ai_gateway.NewNotFoundError("Response not found: " + responseID)
+	}
+	if err != nil {
+		return nil, ai_gateway.NewServerError("Failed to load response: "+err.Error(), err)
+	}
+
+	// Responses are only visible to the tenant that created them
+	if stored.TenantID != ai_gateway.TenantIDFromContext(ctx) {
+		return nil, ai_gateway.NewNotFoundError("Response not found: " + responseID)
+	}
+	return stored, nil
+}
+
+// continueConversation prepends the history of req.PreviousResponseID to chatReq
+func (h *ResponsesHandler) continueConversation(ctx context.Context, req *openai2.CreateRequest, chatReq *openai.ChatCompletionRequest) *ai_gateway.GatewayError {
+	if req.PreviousResponseID == "" {
+		return nil
+	}
+	if h.store == nil {
+		return ai_gateway.NewValidationError("previous_response_id is not supported: no response store configured")
+	}
+
+	stored, gwErr := h.loadResponse(ctx, req.PreviousResponseID)
+	if gwErr != nil {
+		return gwErr
+	}
+
+	chatReq.Messages = append(h.converter.History(stored), chatReq.Messages...)
+	return nil
+}
+
+// saveResponse stores orResp for later continuation when the request asked for it
+func (h *ResponsesHandler) saveResponse(ctx context.Context, req *openai2.CreateRequest, chatReq *openai.ChatCompletionRequest, orResp *openai2.Response) {
+	if req.PreviousResponseID != "" {
+		orResp.PreviousResponseID = &req.PreviousResponseID
+	}
+
+	store := req.Store != nil && *req.Store && h.store != nil
+	orResp.Store = store
+	if !store {
+		return
+	}
+
+	stored := &openai2.StoredResponse{
+		Response: orResp,
+		TenantID: ai_gateway.TenantIDFromContext(ctx),
+		Messages: chatReq.Messages,
+	}
+	if err := h.store.Save(ctx, stored); err != nil {
+		for _, hh := range h.hooks.ErrorHooks() {
+			hh.OnError(ctx, fmt.Errorf("store response: %w", err))
+		}
+	}
+}
+
 func (h *ResponsesHandler) writeError(w http.ResponseWriter, r *http.Request, err *ai_gateway.GatewayError) {
+	setUpstreamErrorHeaders(w, err)
+	if timedOut(r.Context()) {
+		err = ai_gateway.NewTimeoutError("Request timed out", err)
+	}
+
 	// Call ErrorHooks
 	ctx := r.Context()
 	for _, hh := range h.hooks.ErrorHooks() {
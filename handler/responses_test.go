@@ -0,0 +1,757 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/openresponses"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+func TestResponsesHandler_StreamStats(t *testing.T) {
+	recorder := &statsRecordingHook{}
+	hooks := hook.NewRegistry()
+	hooks.Register(recorder)
+
+	prov := &multiChunkProvider{words: []string{"Hello", " there", "!"}}
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hooks)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "Hello",
+		"stream": true,
+	})
+	req := httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assertStreamStats(t, recorder.stats, 3)
+}
+
+// outputTexts returns the text of each message item in the output
+func outputTexts(t *testing.T, output []json.RawMessage) []string {
+	t.Helper()
+	texts := make([]string, 0, len(output))
+	for _, raw := range output {
+		var item openresponses.MessageItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			t.Fatalf("failed to decode output item: %v", err)
+		}
+		if len(item.Content) != 1 {
+			t.Fatalf("expected 1 content part, got %d", len(item.Content))
+		}
+		texts = append(texts, item.Content[0].Text)
+	}
+	return texts
+}
+
+func TestResponsesHandler_MultipleChoices(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &choicesProvider{}}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model": "gpt-4",
+		"input": "Hello",
+		"n":     2,
+	})
+	req := httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	var resp struct {
+		Output []json.RawMessage `json:"output"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	texts := outputTexts(t, resp.Output)
+	if len(texts) != 2 || texts[0] != "choice 0" || texts[1] != "choice 1" {
+		t.Errorf("expected one output item per choice, got %v", texts)
+	}
+}
+
+func TestResponsesHandler_StreamMultipleChoices(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &choicesProvider{}}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "Hello",
+		"stream": true,
+		"n":      2,
+	})
+	req := httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	var added []int
+	var completed []json.RawMessage
+	for _, event := range strings.Split(w.Body.String(), "\n\n") {
+		idx := strings.Index(event, "data: ")
+		if idx < 0 {
+			continue
+		}
+		data := event[idx+len("data: "):]
+		var e struct {
+			Type        string `json:"type"`
+			OutputIndex int    `json:"output_index"`
+			Response    struct {
+				Output []json.RawMessage `json:"output"`
+			} `json:"response"`
+		}
+		if json.Unmarshal([]byte(data), &e) != nil {
+			continue
+		}
+		switch e.Type {
+		case "response.output_item.added":
+			added = append(added, e.OutputIndex)
+		case "response.completed":
+			completed = e.Response.Output
+		}
+	}
+
+	if len(added) != 2 || added[0] != 0 || added[1] != 1 {
+		t.Errorf("expected output items added at indexes [0 1], got %v", added)
+	}
+	texts := outputTexts(t, completed)
+	if len(texts) != 2 || texts[0] != "choice 0" || texts[1] != "choice 1" {
+		t.Errorf("expected completed response with one item per choice, got %v", texts)
+	}
+}
+
+func TestResponsesHandler_StreamEventOrder(t *testing.T) {
+	// The provider ends the stream without a finish_reason
+	prov := &multiChunkProvider{words: []string{"Hello", " there"}}
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "Hello",
+		"stream": true,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes)))
+
+	var types []string
+	var doneText, partText string
+	for _, event := range strings.Split(w.Body.String(), "\n\n") {
+		idx := strings.Index(event, "data: ")
+		if idx < 0 {
+			continue
+		}
+		var e struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+			Part struct {
+				Text string `json:"text"`
+			} `json:"part"`
+		}
+		if json.Unmarshal([]byte(event[idx+len("data: "):]), &e) != nil {
+			continue
+		}
+		types = append(types, e.Type)
+		switch e.Type {
+		case "response.output_text.done":
+			doneText = e.Text
+		case "response.content_part.done":
+			partText = e.Part.Text
+		}
+	}
+
+	want := []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.content_part.added",
+		"response.output_text.delta",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.content_part.done",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected event order:\n got %v\nwant %v", types, want)
+	}
+	if doneText != "Hello there" || partText != "Hello there" {
+		t.Errorf("expected the full text on the done events, got %q and %q", doneText, partText)
+	}
+}
+
+// redactingEventHook masks text deltas and can fail on a given event type
+type redactingEventHook struct {
+	failOn string
+}
+
+func (h *redactingEventHook) Name() string { return "redact-events" }
+
+func (h *redactingEventHook) OnEvent(ctx context.Context, event openresponses.StreamingEvent) (openresponses.StreamingEvent, error) {
+	if event.GetType() == h.failOn {
+		return nil, errors.New("blocked")
+	}
+	if delta, ok := event.(*openresponses.ResponseOutputTextDeltaEvent); ok {
+		delta.Delta = strings.Repeat("*", len(delta.Delta))
+	}
+	return event, nil
+}
+
+func streamWithEventHook(eventHook *redactingEventHook) string {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &multiChunkProvider{words: []string{"Hello", " there"}}}, hook.NewRegistry())
+	orHooks := openresponses.NewRegistry(nil)
+	orHooks.Register(eventHook)
+	handler.SetOpenResponsesHooks(orHooks)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "Hello",
+		"stream": true,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes)))
+	return w.Body.String()
+}
+
+func TestResponsesHandler_StreamEventHook(t *testing.T) {
+	body := streamWithEventHook(&redactingEventHook{})
+
+	if !strings.Contains(body, `"delta":"*****"`) || !strings.Contains(body, `"delta":"******"`) {
+		t.Errorf("expected redacted deltas, got %s", body)
+	}
+	if strings.Contains(body, `"delta":"Hello"`) {
+		t.Errorf("expected no unredacted delta, got %s", body)
+	}
+	if !strings.Contains(body, "response.completed") {
+		t.Errorf("expected the stream to complete, got %s", body)
+	}
+}
+
+func TestResponsesHandler_StreamEventHookError(t *testing.T) {
+	body := streamWithEventHook(&redactingEventHook{failOn: "response.output_text.delta"})
+
+	if !strings.Contains(body, `"type":"error"`) || !strings.Contains(body, "blocked") {
+		t.Errorf("expected an error event, got %s", body)
+	}
+	if strings.Contains(body, "response.output_text.delta") || strings.Contains(body, "response.completed") {
+		t.Errorf("expected the stream to end at the failing event, got %s", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("expected the stream to end with [DONE], got %s", body)
+	}
+}
+
+func TestResponsesHandler_StrictConversion(t *testing.T) {
+	body, _ := json.Marshal(map[string]any{
+		"model":     "gpt-4",
+		"input":     "Hello",
+		"reasoning": map[string]any{"effort": "high"},
+	})
+	tests := []struct {
+		name     string
+		strict   bool
+		wantCode int
+	}{
+		{name: "lenient", wantCode: http.StatusOK},
+		{name: "strict", strict: true, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewResponsesHandler(newMockRegistry(), hook.NewRegistry())
+			handler.SetStrictConversion(tt.strict)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(body)))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.strict && !strings.Contains(w.Body.String(), "reasoning") {
+				t.Errorf("expected the error to list reasoning, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResponsesHandler_EmptyInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   any
+		message string
+	}{
+		{name: "empty array", input: []any{}, message: "input must contain at least one item"},
+		{name: "empty string", input: "", message: "input must not be an empty string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &recordingChatProvider{}
+			handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+
+			body, _ := json.Marshal(map[string]any{"model": "gpt-4", "input": tt.input})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(body)))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp struct {
+				Error struct {
+					Type    string `json:"type"`
+					Message string `json:"message"`
+					Param   string `json:"param"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode error: %v", err)
+			}
+			if resp.Error.Type != "invalid_request_error" || resp.Error.Param != "input" || resp.Error.Message != tt.message {
+				t.Errorf("expected an invalid_request_error on input saying %q, got %+v", tt.message, resp.Error)
+			}
+			if prov.lastReq != nil {
+				t.Error("expected the provider not to be called")
+			}
+		})
+	}
+}
+
+func TestResponsesHandler_EchoesTools(t *testing.T) {
+	handler := NewResponsesHandler(newMockRegistry(), hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model": "gpt-4",
+		"input": "What's the weather in Paris?",
+		"tools": []map[string]any{{
+			"type":       "function",
+			"name":       "get_weather",
+			"parameters": map[string]any{"type": "object"},
+		}},
+	})
+	req := httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	var resp struct {
+		Tools []openresponses.FunctionTool `json:"tools"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Tools) != 1 {
+		t.Fatalf("expected 1 tool, got %d", len(resp.Tools))
+	}
+	if resp.Tools[0].Type != "function" || resp.Tools[0].Name != "get_weather" {
+		t.Errorf("unexpected tool: %+v", resp.Tools[0])
+	}
+}
+
+// tenantAuthHook authenticates every request as the tenant named in the Authorization header
+type tenantAuthHook struct{}
+
+func (h *tenantAuthHook) Name() string { return "tenant-auth" }
+
+func (h *tenantAuthHook) Authenticate(ctx context.Context, apiKey string) (bool, string, error) {
+	if apiKey == "" {
+		return false, "", nil
+	}
+	return true, apiKey, nil
+}
+
+func newStoredResponsesHandler(t *testing.T, hooks *hook.Registry) (*ResponsesHandler, *openresponses.MemoryResponseStore) {
+	t.Helper()
+	store := openresponses.NewMemoryResponseStore()
+	err := store.Save(context.Background(), &openresponses.StoredResponse{
+		Response: &openresponses.Response{ID: "resp_1", Object: "response", Status: "completed"},
+		TenantID: "tenant-a",
+	})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	handler := NewResponsesHandler(&mapModelRegistry{}, hooks)
+	handler.SetResponseStore(store)
+	return handler, store
+}
+
+func TestResponsesHandler_GetResponse(t *testing.T) {
+	hooks := hook.NewRegistry()
+	hooks.Register(&tenantAuthHook{})
+	handler, _ := newStoredResponsesHandler(t, hooks)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/responses/resp_1", nil)
+	req.Header.Set("Authorization", "tenant-a")
+	w := httptest.NewRecorder()
+	handler.ServeResponseByID(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp openresponses.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.ID != "resp_1" {
+		t.Errorf("Expected id resp_1, got %s", resp.ID)
+	}
+}
+
+func TestResponsesHandler_GetResponseNotFound(t *testing.T) {
+	hooks := hook.NewRegistry()
+	hooks.Register(&tenantAuthHook{})
+	handler, _ := newStoredResponsesHandler(t, hooks)
+
+	tests := []struct {
+		name   string
+		path   string
+		tenant string
+	}{
+		{"missing", "/v1/responses/resp_missing", "tenant-a"},
+		{"other tenant", "/v1/responses/resp_1", "tenant-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", tt.tenant)
+			w := httptest.NewRecorder()
+			handler.ServeResponseByID(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestResponsesHandler_GetResponseUnauthenticated(t *testing.T) {
+	hooks := hook.NewRegistry()
+	hooks.Register(&tenantAuthHook{})
+	handler, _ := newStoredResponsesHandler(t, hooks)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/responses/resp_1", nil)
+	w := httptest.NewRecorder()
+	handler.ServeResponseByID(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestResponsesHandler_DeleteResponse(t *testing.T) {
+	hooks := hook.NewRegistry()
+	hooks.Register(&tenantAuthHook{})
+	handler, store := newStoredResponsesHandler(t, hooks)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/responses/resp_1", nil)
+	req.Header.Set("Authorization", "tenant-a")
+	w := httptest.NewRecorder()
+	handler.ServeResponseByID(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		ID      string `json:"id"`
+		Deleted bool   `json:"deleted"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.ID != "resp_1" || !body.Deleted {
+		t.Errorf("Unexpected delete body: %s", w.Body.String())
+	}
+
+	if _, err := store.Get(context.Background(), "resp_1"); err == nil {
+		t.Error("Expected response to be removed from the store")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/responses/resp_1", nil)
+	req.Header.Set("Authorization", "tenant-a")
+	w = httptest.NewRecorder()
+	handler.ServeResponseByID(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after delete, got %d", w.Code)
+	}
+}
+
+// gatedProvider blocks non-streaming requests until release is closed
+type gatedProvider struct {
+	mockChatProvider
+	release chan struct{}
+	err     error
+}
+
+func (m *gatedProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	<-m.release
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.mockChatProvider.SendRequest(ctx, req)
+}
+
+// postBackground submits a background request and returns the queued response
+func postBackground(t *testing.T, handler *ResponsesHandler) *openresponses.Response {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":      "gpt-4",
+		"input":      "Hello",
+		"background": true,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp openresponses.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return &resp
+}
+
+// pollResponse polls GET /v1/responses/{id} until the response reaches status
+func pollResponse(t *testing.T, handler *ResponsesHandler, id string, status openresponses.ResponseStatusEnum) *openresponses.Response {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		req := httptest.NewRequest(http.MethodGet, "/v1/responses/"+id, nil)
+		w := httptest.NewRecorder()
+		handler.ServeResponseByID(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp openresponses.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Status == status {
+			return &resp
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for status %s, last status %s", status, resp.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestResponsesHandler_Background(t *testing.T) {
+	prov := &gatedProvider{release: make(chan struct{})}
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetResponseStore(openresponses.NewMemoryResponseStore())
+
+	queued := postBackground(t, handler)
+	if queued.Status != openresponses.ResponseStatusQueued {
+		t.Errorf("Expected status queued, got %s", queued.Status)
+	}
+	if !queued.Background || !queued.Store {
+		t.Errorf("Expected background and store to be true, got %v and %v", queued.Background, queued.Store)
+	}
+
+	pollResponse(t, handler, queued.ID, openresponses.ResponseStatusInProgress)
+	close(prov.release)
+	completed := pollResponse(t, handler, queued.ID, openresponses.ResponseStatusCompleted)
+
+	if completed.ID != queued.ID || !completed.Background {
+		t.Errorf("Unexpected completed response: id=%s background=%v", completed.ID, completed.Background)
+	}
+	raw, _ := json.Marshal(completed.Output)
+	var output []json.RawMessage
+	json.Unmarshal(raw, &output)
+	if texts := outputTexts(t, output); len(texts) != 1 || texts[0] != "Hello!" {
+		t.Errorf("Expected output [Hello!], got %v", texts)
+	}
+}
+
+func TestResponsesHandler_BackgroundFailure(t *testing.T) {
+	prov := &gatedProvider{release: make(chan struct{}), err: errors.New("upstream unavailable")}
+	close(prov.release)
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetResponseStore(openresponses.NewMemoryResponseStore())
+
+	queued := postBackground(t, handler)
+	failed := pollResponse(t, handler, queued.ID, openresponses.ResponseStatusFailed)
+
+	if failed.Error == nil || !strings.Contains(failed.Error.Message, "upstream unavailable") {
+		t.Errorf("Expected provider error in failed response, got %+v", failed.Error)
+	}
+}
+
+func TestResponsesHandler_BackgroundDeleted(t *testing.T) {
+	prov := &gatedProvider{release: make(chan struct{})}
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	store := openresponses.NewMemoryResponseStore()
+	handler.SetResponseStore(store)
+	var wg sync.WaitGroup
+	handler.SetBackgroundWaitGroup(&wg)
+
+	queued := postBackground(t, handler)
+	pollResponse(t, handler, queued.ID, openresponses.ResponseStatusInProgress)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/responses/"+queued.ID, nil)
+	w := httptest.NewRecorder()
+	handler.ServeResponseByID(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	close(prov.release)
+	wg.Wait()
+	if _, err := store.Get(context.Background(), queued.ID); !errors.Is(err, openresponses.ErrResponseNotFound) {
+		t.Errorf("Expected the deleted response to stay deleted, got %v", err)
+	}
+}
+
+func TestResponsesHandler_BackgroundTimeout(t *testing.T) {
+	prov := &slowProvider{}
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetResponseStore(openresponses.NewMemoryResponseStore())
+	handler.SetMaxRequestTimeout(time.Minute)
+	var wg sync.WaitGroup
+	handler.SetBackgroundWaitGroup(&wg)
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":      "gpt-4",
+		"input":      "Hello",
+		"background": true,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader(bodyBytes))
+	req.Header.Set(RequestTimeoutHeader, "0.05")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var queued openresponses.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &queued); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	wg.Wait()
+	if prov.deadline.IsZero() {
+		t.Error("Expected the background provider call to have a deadline")
+	}
+	failed := pollResponse(t, handler, queued.ID, openresponses.ResponseStatusFailed)
+	if failed.Error == nil {
+		t.Error("Expected an error in the timed out response")
+	}
+}
+
+func TestResponsesHandler_BackgroundDefaultTimeout(t *testing.T) {
+	handler := NewResponsesHandler(newMockRegistry(), hook.NewRegistry())
+
+	ctx, cancel := handler.backgroundContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > DefaultBackgroundTimeout {
+		t.Errorf("Expected a deadline within %s, got %v", DefaultBackgroundTimeout, deadline)
+	}
+}
+
+// panickingProvider panics on every request
+type panickingProvider struct {
+	mockChatProvider
+}
+
+func (m *panickingProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	panic("provider bug")
+}
+
+func TestResponsesHandler_BackgroundPanic(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &panickingProvider{}}, hook.NewRegistry())
+	handler.SetResponseStore(openresponses.NewMemoryResponseStore())
+	var wg sync.WaitGroup
+	handler.SetBackgroundWaitGroup(&wg)
+
+	queued := postBackground(t, handler)
+	wg.Wait()
+	failed := pollResponse(t, handler, queued.ID, openresponses.ResponseStatusFailed)
+	if failed.Error == nil || failed.Error.Type != "server_error" {
+		t.Errorf("Expected a server error in the failed response, got %+v", failed.Error)
+	}
+}
+
+func TestResponsesHandler_BackgroundWithoutStore(t *testing.T) {
+	handler := NewResponsesHandler(newMockRegistry(), hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":      "gpt-4",
+		"input":      "Hello",
+		"background": true,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses", bytes.NewReader(bodyBytes))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+// recordingChatProvider records the last request sent to it
+type recordingChatProvider struct {
+	mockChatProvider
+	lastReq *provider.Request
+}
+
+func (m *recordingChatProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.lastReq = req
+	return m.mockChatProvider.SendRequest(ctx, req)
+}
+
+func TestResponsesHandler_OutputTokenDefaults(t *testing.T) {
+	defaults := OutputTokenDefaults{Default: 1024, Models: map[string]int{"gpt-4": 2048}}
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name     string
+		defaults OutputTokenDefaults
+		model    string
+		max      *int
+		want     *int
+	}{
+		{"per-model default", defaults, "gpt-4", nil, intPtr(2048)},
+		{"gateway default", defaults, "gpt-3.5-turbo", nil, intPtr(1024)},
+		{"client value kept", defaults, "gpt-4", intPtr(50), intPtr(50)},
+		{"no defaults", OutputTokenDefaults{}, "gpt-4", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &recordingChatProvider{}
+			handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+			handler.SetOutputTokenDefaults(tt.defaults)
+
+			body := map[string]any{"model": tt.model, "input": "Hello"}
+			if tt.max != nil {
+				body["max_output_tokens"] = *tt.max
+			}
+			bodyBytes, _ := json.Marshal(body)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes)))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			got := prov.lastReq.MaxTokens
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("expected max_tokens %v, got %v", deref(tt.want), deref(got))
+			}
+		})
+	}
+}
+
+func deref(n *int) any {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// routeModel runs the RoutingHooks in order, each seeing the previous hook's
+// choice, and returns the model name to resolve
+func routeModel(ctx context.Context, hooks *hook.Registry, model string) (string, *GatewayError) {
+	if hooks == nil {
+		return model, nil
+	}
+	for _, hh := range hooks.RoutingHooks() {
+		routed, err := hh.Route(ctx, model)
+		if err != nil {
+			return "", routingError(err)
+		}
+		if routed != "" {
+			model = routed
+		}
+	}
+	return model, nil
+}
+
+// routingError keeps the status of a GatewayError returned by a routing hook,
+// from this package or the root one, and reports any other hook error as a
+// server error, so every endpoint answers a failing hook the same way
+func routingError(err error) *GatewayError {
+	var gwErr *GatewayError
+	if errors.As(err, &gwErr) {
+		return gwErr
+	}
+	var rootErr *ai_gateway.GatewayError
+	if errors.As(err, &rootErr) {
+		return &GatewayError{Code: rootErr.Code, Message: rootErr.Message, Type: rootErr.Type, Err: rootErr.InnerError}
+	}
+	return &GatewayError{Code: http.StatusInternalServerError, Message: "Routing hook failed: " + err.Error(), Type: "server_error", Err: err}
+}
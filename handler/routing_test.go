@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/model"
+)
+
+// tierRoutingHook uses the API key as the tenant and routes "auto" by the
+// tenant's tier
+type tierRoutingHook struct {
+	premium map[string]bool
+}
+
+func (h *tierRoutingHook) Name() string {
+	return "tier-routing"
+}
+
+func (h *tierRoutingHook) Authenticate(ctx context.Context, apiKey string) (bool, string, error) {
+	return true, strings.TrimPrefix(apiKey, "Bearer "), nil
+}
+
+func (h *tierRoutingHook) Route(ctx context.Context, name string) (string, error) {
+	if name != "auto" {
+		return "", nil
+	}
+	tenant, _ := ctx.Value("tenant_id").(string)
+	if tenant == "" {
+		return "", errors.New("no tenant")
+	}
+	if h.premium[tenant] {
+		return "gpt-4o", nil
+	}
+	return "gpt-4o-mini", nil
+}
+
+func TestChatHandler_RoutingHook(t *testing.T) {
+	premium, mini := &countingChatProvider{}, &countingChatProvider{}
+	registry := model.NewMapModelRegistry()
+	registry.Register("gpt-4o", premium)
+	registry.Register("gpt-4o-mini", mini)
+
+	hooks := hook.NewRegistry()
+	hooks.Register(&tierRoutingHook{premium: map[string]bool{"acme": true}})
+	handler := NewChatHandler(registry, hooks)
+
+	tests := []struct {
+		tenant      string
+		model       string
+		wantStatus  int
+		wantPremium int
+		wantMini    int
+	}{
+		{tenant: "acme", model: "auto", wantStatus: http.StatusOK, wantPremium: 1},
+		{tenant: "globex", model: "auto", wantStatus: http.StatusOK, wantMini: 1},
+		{tenant: "globex", model: "gpt-4o", wantStatus: http.StatusOK, wantPremium: 1},
+		{model: "auto", wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		premium.calls, mini.calls = 0, 0
+		body, _ := json.Marshal(map[string]any{
+			"model":    tt.model,
+			"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		})
+		req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tt.tenant)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%q/%s: expected %d, got %d: %s", tt.tenant, tt.model, tt.wantStatus, w.Code, w.Body.String())
+		}
+		if premium.calls != tt.wantPremium || mini.calls != tt.wantMini {
+			t.Errorf("%q/%s: expected %d premium and %d mini calls, got %d and %d",
+				tt.tenant, tt.model, tt.wantPremium, tt.wantMini, premium.calls, mini.calls)
+		}
+	}
+}
+
+// failingRoutingHook fails every request with err
+type failingRoutingHook struct {
+	err error
+}
+
+func (h *failingRoutingHook) Name() string {
+	return "failing-routing"
+}
+
+func (h *failingRoutingHook) Route(ctx context.Context, name string) (string, error) {
+	return "", h.err
+}
+
+func TestRoutingHookErrorStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "plain", err: errors.New("routing table unavailable"), wantStatus: http.StatusInternalServerError},
+		{name: "handler", err: NewRateLimitError("tenant over budget"), wantStatus: http.StatusTooManyRequests},
+		{name: "root", err: ai_gateway.NewAuthenticationError("unknown tenant"), wantStatus: http.StatusUnauthorized},
+		{name: "wrapped", err: fmt.Errorf("lookup: %w", ai_gateway.NewValidationError("no such tier")), wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		hooks := hook.NewRegistry()
+		hooks.Register(&failingRoutingHook{err: tt.err})
+		endpoints := map[string]http.Handler{
+			"/v1/chat/completions": NewChatHandler(newMockRegistry(), hooks),
+			"/v1/responses":        NewResponsesHandler(newMockRegistry(), hooks),
+		}
+		for path, handler := range endpoints {
+			body, _ := json.Marshal(map[string]any{
+				"model":    "gpt-4",
+				"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+				"input":    "Hello",
+			})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewReader(body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("%s %s: expected %d, got %d: %s", tt.name, path, tt.wantStatus, w.Code, w.Body.String())
+			}
+		}
+	}
+}
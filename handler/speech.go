@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+	"github.com/deeplooplabs/ai-gateway/ratelimit"
+)
+
+// speechContentTypes maps speech response formats to their content types
+var speechContentTypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"opus": "audio/opus",
+	"aac":  "audio/aac",
+	"flac": "audio/flac",
+	"wav":  "audio/wav",
+	"pcm":  "audio/pcm",
+}
+
+// AudioSpeechHandler handles text-to-speech requests
+type AudioSpeechHandler struct {
+	// registry is typed as `any` to avoid circular dependencies.
+	// The handler only needs the Resolve(model string) (provider.Provider, string) method,
+	// which is checked via a local interface type assertion in ServeHTTP.
+	registry any
+	hooks    *hook.Registry
+	limiter  ratelimit.Limiter
+	metrics  MetricsRecorder
+	timeout  time.Duration
+}
+
+// NewAudioSpeechHandler creates a new audio speech handler
+func NewAudioSpeechHandler(registry any, hooks *hook.Registry) *AudioSpeechHandler {
+	return &AudioSpeechHandler{
+		registry: registry,
+		hooks:    hooks,
+	}
+}
+
+// SetRateLimiter enables per-tenant rate limiting
+func (h *AudioSpeechHandler) SetRateLimiter(limiter ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetricsRecorder sets the recorder receiving request, latency and token metrics
+func (h *AudioSpeechHandler) SetMetricsRecorder(recorder MetricsRecorder) {
+	h.metrics = recorder
+}
+
+// ServeHTTP implements http.Handler
+func (h *AudioSpeechHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Ensure request body is closed
+	defer r.Body.Close()
+
+	// Observe the request for metrics, labeled by its final route
+	w, observed := observeRequest(h.metrics, "/v1/audio/speech", w)
+	defer func() { observed.finish(r) }()
+
+	// Bound the provider call by the client's requested timeout
+	r, cancel, timeoutErr := withRequestTimeout(r, h.timeout)
+	defer cancel()
+	if timeoutErr != nil {
+		h.writeError(w, r, NewValidationError(timeoutErr.Error()))
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		h.writeError(w, r, NewMethodNotAllowedError("only POST method is allowed"))
+		return
+	}
+
+	// Reject requests over the rate limit before any work is done
+	if !checkRateLimit(w, r, h.limiter) {
+		h.writeError(w, r, NewRateLimitError("rate limit exceeded"))
+		return
+	}
+
+	// Parse request
+	var req openai.SpeechRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, r, NewValidationError("invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate request
+	switch {
+	case req.Model == "":
+		h.writeError(w, r, NewValidationError("model is required"))
+		return
+	case req.Input == "":
+		h.writeError(w, r, NewValidationError("input is required"))
+		return
+	case req.Voice == "":
+		h.writeError(w, r, NewValidationError("voice is required"))
+		return
+	}
+
+	// An explicit response_format wins; otherwise negotiate from Accept
+	if req.ResponseFormat == "" {
+		req.ResponseFormat = speechFormatFromAccept(r.Header.Get("Accept"))
+	}
+
+	ctx := r.Context()
+
+	// Let routing hooks pick the model
+	routed, routeErr := routeModel(ctx, h.hooks, req.Model)
+	if routeErr != nil {
+		h.writeError(w, r, routeErr)
+		return
+	}
+	req.Model = routed
+
+	// Resolve provider
+	type resolver interface {
+		Resolve(model string) (provider.Provider, string)
+	}
+	var prov provider.Provider
+	var modelRewrite string
+
+	if reg, ok := h.registry.(resolver); ok {
+		prov, modelRewrite = reg.Resolve(req.Model)
+		if prov == nil {
+			h.writeError(w, r, NewNotFoundError("model not found: "+req.Model))
+			return
+		}
+	} else {
+		h.writeError(w, r, NewProviderError("registry not available", nil))
+		return
+	}
+
+	if !prov.SupportedAPIs().Supports(provider.APITypeAudioSpeech) {
+		h.writeError(w, r, NewValidationError(fmt.Sprintf("provider %s does not support audio speech for model %s", prov.Name(), req.Model)))
+		return
+	}
+
+	provReq := provider.NewAudioSpeechRequest(req.Model, req.Input, req.Voice)
+	provReq.SpeechResponseFormat = req.ResponseFormat
+	provReq.SpeechSpeed = req.Speed
+	if contentType, ok := speechContentTypes[req.ResponseFormat]; ok {
+		provReq.Headers = map[string]string{"Accept": contentType}
+	}
+
+	// Apply model rewrite if specified
+	if modelRewrite != "" {
+		provReq.Model = modelRewrite
+	}
+
+	// Time provider calls for metrics
+	prov = observeProvider(h.metrics, "/v1/audio/speech", prov)
+
+	// Record routing info for hooks and logging
+	ctx = withRouteInfo(ctx, prov, req.Model, provReq.Model)
+	r = r.WithContext(ctx)
+
+	// Send request to provider
+	provResp, err := prov.SendRequest(ctx, provReq)
+	if err != nil {
+		h.writeError(w, r, NewProviderError("provider request failed", err))
+		return
+	}
+	defer provResp.Close()
+	copyHeaders(w.Header(), provResp.Headers)
+
+	audio, err := provResp.GetAudio()
+	if err != nil {
+		h.writeError(w, r, NewProviderError("invalid response", err))
+		return
+	}
+
+	// Prefer the upstream content type, falling back to the requested format
+	contentType := audio.ContentType
+	if contentType == "" {
+		format := req.ResponseFormat
+		if format == "" {
+			format = "mp3"
+		}
+		contentType = speechContentTypes[format]
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Stream the audio back unchanged
+	w.Header().Set("Content-Type", contentType)
+	if audio.ContentLength >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(audio.ContentLength, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, audio.Body); err != nil {
+		slog.WarnContext(r.Context(), "failed to write audio response", "error", err)
+	}
+}
+
+// speechFormatFromAccept returns the first speech format listed in an Accept
+// header, or "" if none is supported
+func speechFormatFromAccept(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		for format, contentType := range speechContentTypes {
+			if mediaType == contentType {
+				return format
+			}
+		}
+	}
+	return ""
+}
+
+func (h *AudioSpeechHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	setUpstreamErrorHeaders(w, err)
+	err = timeoutError(r, err)
+
+	var gwErr *GatewayError
+	if !errors.As(err, &gwErr) {
+		gwErr = NewProviderError("internal error", err)
+	}
+
+	// Call ErrorHooks to notify of the error
+	ctx := r.Context()
+	if h.hooks != nil {
+		for _, hh := range h.hooks.ErrorHooks() {
+			hh.OnError(ctx, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(gwErr.Code)
+	if encodeErr := json.NewEncoder(w).Encode(gwErr.ToOpenAIResponse()); encodeErr != nil {
+		fmt.Printf("failed to encode error response: %v\n", encodeErr)
+	}
+}
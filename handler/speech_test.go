@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	prov "github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// speechAudio is a few bytes of an MP3 frame, including bytes that aren't valid UTF-8
+var speechAudio = []byte{0xff, 0xfb, 0x90, 0x64, 0x00, 0x0f, 0xf0, 0x00, 0x00}
+
+// mockSpeechProvider returns speechAudio with contentType
+type mockSpeechProvider struct {
+	contentType string
+	lastReq     *prov.Request
+}
+
+func (m *mockSpeechProvider) Name() string {
+	return "mock-speech"
+}
+
+func (m *mockSpeechProvider) SupportedAPIs() prov.APIType {
+	return prov.APITypeAudioSpeech
+}
+
+func (m *mockSpeechProvider) SendRequest(ctx context.Context, req *prov.Request) (*prov.Response, error) {
+	m.lastReq = req
+	return prov.NewAudioSpeechResponse(&prov.BinaryBody{
+		Body:          io.NopCloser(bytes.NewReader(speechAudio)),
+		ContentType:   m.contentType,
+		ContentLength: int64(len(speechAudio)),
+	}), nil
+}
+
+func newSpeechRequest(body map[string]any) *http.Request {
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/v1/audio/speech", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestAudioSpeechHandler_ServeHTTP(t *testing.T) {
+	provider := &mockSpeechProvider{contentType: "audio/mpeg"}
+	handler := NewAudioSpeechHandler(&mapModelRegistry{provider: provider}, hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSpeechRequest(map[string]any{
+		"model":           "tts-1",
+		"input":           "Hello there",
+		"voice":           "alloy",
+		"response_format": "mp3",
+	}))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "audio/mpeg" {
+		t.Errorf("expected content type audio/mpeg, got %s", ct)
+	}
+	if cl := w.Header().Get("Content-Length"); cl != "9" {
+		t.Errorf("expected content length 9, got %q", cl)
+	}
+	if !bytes.Equal(w.Body.Bytes(), speechAudio) {
+		t.Errorf("expected audio bytes to be preserved, got %x", w.Body.Bytes())
+	}
+
+	got := provider.lastReq
+	if got.APIType != prov.APITypeAudioSpeech || got.SpeechInput != "Hello there" || got.SpeechVoice != "alloy" || got.SpeechResponseFormat != "mp3" {
+		t.Errorf("unexpected provider request: %+v", got)
+	}
+}
+
+func TestAudioSpeechHandler_AcceptNegotiation(t *testing.T) {
+	// Without an upstream content type the negotiated format decides it
+	provider := &mockSpeechProvider{}
+	handler := NewAudioSpeechHandler(&mapModelRegistry{provider: provider}, hook.NewRegistry())
+
+	req := newSpeechRequest(map[string]any{"model": "tts-1", "input": "Hello", "voice": "alloy"})
+	req.Header.Set("Accept", "application/json;q=0.5, audio/flac, audio/wav")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if provider.lastReq.SpeechResponseFormat != "flac" {
+		t.Errorf("expected format negotiated from Accept, got %q", provider.lastReq.SpeechResponseFormat)
+	}
+	if provider.lastReq.Headers["Accept"] != "audio/flac" {
+		t.Errorf("expected Accept to be forwarded upstream, got %q", provider.lastReq.Headers["Accept"])
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "audio/flac" {
+		t.Errorf("expected content type audio/flac, got %s", ct)
+	}
+}
+
+func TestAudioSpeechHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"missing model", map[string]any{"input": "Hello", "voice": "alloy"}},
+		{"missing input", map[string]any{"model": "tts-1", "voice": "alloy"}},
+		{"missing voice", map[string]any{"model": "tts-1", "input": "Hello"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAudioSpeechHandler(&mapModelRegistry{provider: &mockSpeechProvider{}}, hook.NewRegistry())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newSpeechRequest(tt.body))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAudioSpeechHandler_UnsupportedProvider(t *testing.T) {
+	handler := NewAudioSpeechHandler(newMockRegistry(), hook.NewRegistry())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSpeechRequest(map[string]any{"model": "gpt-4", "input": "Hello", "voice": "alloy"}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"io"
+)
+
+// SSEConfig controls how the streaming handlers frame server-sent events
+type SSEConfig struct {
+	// LineTerminator ends every line, and an empty line ends each event.
+	// One of "\n" (the default, giving "\n\n" between events), "\r\n" or "\r".
+	LineTerminator string
+
+	// Preamble writes an initial ":ok" comment before the first event.
+	// Some clients only start rendering once they have received data.
+	Preamble bool
+}
+
+// lineTerminator returns the configured line terminator, defaulting to "\n"
+func (c SSEConfig) lineTerminator() string {
+	switch c.LineTerminator {
+	case "\r\n", "\r":
+		return c.LineTerminator
+	}
+	return "\n"
+}
+
+// writeData writes data as a single SSE data event
+func (c SSEConfig) writeData(w io.Writer, data []byte) {
+	nl := c.lineTerminator()
+	io.WriteString(w, "data: ")
+	w.Write(data)
+	io.WriteString(w, nl+nl)
+}
+
+// WriteEvent writes data as an SSE event of the given type, or as a plain
+// data event if event is empty
+func (c SSEConfig) WriteEvent(w io.Writer, event string, data []byte) {
+	if event != "" {
+		io.WriteString(w, "event: "+event+c.lineTerminator())
+	}
+	c.writeData(w, data)
+}
+
+// writePreamble writes the ":ok" preamble comment
+func (c SSEConfig) writePreamble(w io.Writer) {
+	nl := c.lineTerminator()
+	io.WriteString(w, ":ok"+nl+nl)
+}
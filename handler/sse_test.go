@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// assertCRLFFraming checks that body starts with the preamble, uses only
+// "\r\n" line terminators and ends with the [DONE] marker
+func assertCRLFFraming(t *testing.T, body string) {
+	t.Helper()
+	if !strings.HasPrefix(body, ":ok\r\n\r\n") {
+		t.Errorf("expected :ok preamble, got %q", body[:min(len(body), 20)])
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\r\n\r\n") {
+		t.Errorf("expected CRLF [DONE] marker at end, got %q", body[max(0, len(body)-20):])
+	}
+	if bare := strings.Count(strings.ReplaceAll(body, "\r\n", ""), "\n"); bare != 0 {
+		t.Errorf("expected only CRLF line terminators, found %d bare newlines", bare)
+	}
+}
+
+func TestChatHandler_SSEConfig(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &multiChunkProvider{words: []string{"Hello", " there"}}}, hook.NewRegistry())
+	handler.SetSSEConfig(SSEConfig{LineTerminator: "\r\n", Preamble: true})
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":          "gpt-4",
+		"messages":       []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": true},
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+
+	body := w.Body.String()
+	assertCRLFFraming(t, body)
+	// Two content chunks, the estimated usage chunk and [DONE]
+	if n := strings.Count(body, "data: "); n != 4 {
+		t.Errorf("expected 4 data events, got %d: %q", n, body)
+	}
+}
+
+func TestChatHandler_SSEDefaultFraming(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &multiChunkProvider{words: []string{"Hello"}}}, hook.NewRegistry())
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"stream":   true,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(bodyBytes)))
+
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "data: {") || !strings.HasSuffix(body, "\n\ndata: [DONE]\n\n") {
+		t.Errorf("expected default \\n\\n framing without preamble, got %q", body)
+	}
+	if strings.Contains(body, "\r") {
+		t.Errorf("expected no carriage returns, got %q", body)
+	}
+}
+
+func TestResponsesHandler_SSEConfig(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &multiChunkProvider{words: []string{"Hello", " there"}}}, hook.NewRegistry())
+	handler.SetSSEConfig(SSEConfig{LineTerminator: "\r\n", Preamble: true})
+
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "Hello",
+		"stream": true,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes)))
+
+	body := w.Body.String()
+	assertCRLFFraming(t, body)
+	if !strings.Contains(body, "event: response.created\r\ndata: {") {
+		t.Errorf("expected CRLF between event and data lines, got %q", body)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/openresponses"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// StreamFallback controls how the responses handler serves stream:true
+// requests when the provider answers without streaming, or was probed without
+// streaming support and is sent the request non-streaming
+type StreamFallback int
+
+const (
+	// StreamSynthesize replays the complete response as the usual sequence
+	// of streaming events (the default)
+	StreamSynthesize StreamFallback = iota
+	// StreamSingleEvent sends the complete response in a single
+	// response.completed (or response.incomplete) event
+	StreamSingleEvent
+)
+
+// SetStreamFallback sets how streamed requests are served by non-streaming providers
+func (h *ResponsesHandler) SetStreamFallback(fallback StreamFallback) {
+	h.streamFallback = fallback
+}
+
+// SetCapabilityCache sets the probed provider capabilities. Streamed requests
+// to providers probed without streaming support are sent non-streaming.
+func (h *ResponsesHandler) SetCapabilityCache(capabilities *provider.CapabilityCache) {
+	h.capabilities = capabilities
+}
+
+// writeSingleEvent ends a stream with chatResp, a provider's complete answer,
+// as one final event
+func (h *ResponsesHandler) writeSingleEvent(ctx context.Context, writer *openai2.StreamWriter, r *http.Request, req *openai2.CreateRequest, chatReq *openai.ChatCompletionRequest, chatResp *openai.ChatCompletionResponse, responseID string) {
+	writeAudit(ctx, h.audit, h.hooks, r, true, chatReq, chatResp)
+
+	orResp := h.converter.ChatCompletionToResponse(chatResp, responseID, h.converter.FunctionTools(req.Tools))
+	h.saveResponse(ctx, req, chatReq, orResp)
+
+	var final openai2.StreamingEvent = openai2.NewResponseCompletedEvent(writer.NextSequence(), orResp)
+	if orResp.Status == openai2.ResponseStatusIncomplete {
+		final = openai2.NewResponseIncompleteEvent(writer.NextSequence(), orResp)
+	}
+	if h.writeEvent(ctx, writer, final) {
+		writer.WriteDone()
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// nonStreamingProvider answers every request in one piece and reports, when
+// probed, that it can't stream
+type nonStreamingProvider struct {
+	mockChatProvider
+	streamed bool
+}
+
+func (m *nonStreamingProvider) ProbeCapabilities(ctx context.Context) (provider.Capabilities, error) {
+	return provider.Capabilities{Tools: true, JSONMode: true}, nil
+}
+
+func (m *nonStreamingProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.streamed = req.Stream
+	unstreamed := *req
+	unstreamed.Stream = false
+	return m.mockChatProvider.SendRequest(ctx, &unstreamed)
+}
+
+// streamEvents streams a responses request through handler and returns the
+// event types, the text of the final response's first output item and the
+// final response's total tokens
+func streamEvents(t *testing.T, handler *ResponsesHandler) ([]string, string, int) {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "Hello",
+		"stream": true,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(bodyBytes)))
+
+	var types []string
+	var text string
+	var totalTokens int
+	for _, event := range strings.Split(w.Body.String(), "\n\n") {
+		idx := strings.Index(event, "data: ")
+		if idx < 0 {
+			continue
+		}
+		var e struct {
+			Type     string `json:"type"`
+			Response struct {
+				Output []struct {
+					Content []struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"output"`
+				Usage *struct {
+					TotalTokens int `json:"total_tokens"`
+				} `json:"usage"`
+			} `json:"response"`
+		}
+		if json.Unmarshal([]byte(event[idx+len("data: "):]), &e) != nil {
+			continue
+		}
+		types = append(types, e.Type)
+		if e.Type == "response.completed" && len(e.Response.Output) > 0 && len(e.Response.Output[0].Content) > 0 {
+			text = e.Response.Output[0].Content[0].Text
+		}
+		if e.Type == "response.completed" && e.Response.Usage != nil {
+			totalTokens = e.Response.Usage.TotalTokens
+		}
+	}
+	return types, text, totalTokens
+}
+
+func TestResponsesHandler_StreamSynthesize(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &nonStreamingProvider{}}, hook.NewRegistry())
+
+	types, text, totalTokens := streamEvents(t, handler)
+
+	want := []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.content_part.added",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.content_part.done",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected event order:\n got %v\nwant %v", types, want)
+	}
+	if text != "Hello!" {
+		t.Errorf("expected the provider's text in the completed response, got %q", text)
+	}
+	if totalTokens != 15 {
+		t.Errorf("expected the provider's usage in the completed response, got %d total tokens", totalTokens)
+	}
+}
+
+func TestResponsesHandler_StreamSingleEvent(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &nonStreamingProvider{}}, hook.NewRegistry())
+	handler.SetStreamFallback(StreamSingleEvent)
+
+	types, text, totalTokens := streamEvents(t, handler)
+
+	want := []string{"response.created", "response.in_progress", "response.completed"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected event order:\n got %v\nwant %v", types, want)
+	}
+	if text != "Hello!" {
+		t.Errorf("expected the provider's text in the completed response, got %q", text)
+	}
+	if totalTokens != 15 {
+		t.Errorf("expected the provider's usage in the completed response, got %d total tokens", totalTokens)
+	}
+}
+
+func TestResponsesHandler_StreamToProbedProvider(t *testing.T) {
+	prov := &nonStreamingProvider{}
+	capabilities := provider.NewCapabilityCache()
+	capabilities.Probe(context.Background(), prov)
+
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetCapabilityCache(capabilities)
+
+	types, _, _ := streamEvents(t, handler)
+
+	if prov.streamed {
+		t.Error("expected the request to be sent non-streaming")
+	}
+	if len(types) == 0 || types[len(types)-1] != "response.completed" {
+		t.Errorf("expected the stream to complete, got %v", types)
+	}
+}
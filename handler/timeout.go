@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/openresponses"
+)
+
+// RequestTimeoutHeader lets clients bound a request, in seconds
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// errRequestTimeout is the cause of contexts cancelled by a client timeout
+var errRequestTimeout = errors.New("request timeout exceeded")
+
+// errStreamTimeout ends Responses streams cut short by the request timeout
+var errStreamTimeout = openai2.NewError("server_error", "timeout", "Request timed out", "")
+
+// withRequestTimeout bounds r's context by the client's X-Request-Timeout,
+// clamped to max. The header is ignored when max is zero.
+func withRequestTimeout(r *http.Request, max time.Duration) (*http.Request, context.CancelFunc, error) {
+	value := r.Header.Get(RequestTimeoutHeader)
+	if max <= 0 || value == "" {
+		return r, func() {}, nil
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return r, func() {}, fmt.Errorf("invalid %s header: %q", RequestTimeoutHeader, value)
+	}
+
+	// Clamp before converting so huge values can't overflow the duration
+	timeout := max
+	if seconds < max.Seconds() {
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	ctx, cancel := context.WithTimeoutCause(r.Context(), timeout, errRequestTimeout)
+	return r.WithContext(ctx), cancel, nil
+}
+
+// timedOut reports whether ctx was cancelled by the client's request timeout
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errRequestTimeout)
+}
+
+// NewTimeoutError creates a gateway timeout error (504)
+func NewTimeoutError(msg string, err error) *GatewayError {
+	return &GatewayError{Code: 504, Message: msg, Type: "timeout_error", Err: err}
+}
+
+// timeoutError replaces err with a timeout error when the request timeout was hit
+func timeoutError(r *http.Request, err error) error {
+	if timedOut(r.Context()) {
+		return NewTimeoutError("request timed out", err)
+	}
+	return err
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *ChatHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *ResponsesHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *EmbeddingsHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *ImagesHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *ModerationsHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *AudioTranscriptionsHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
+
+// SetMaxRequestTimeout enables the X-Request-Timeout header, bounded by max
+func (h *AudioSpeechHandler) SetMaxRequestTimeout(max time.Duration) {
+	h.timeout = max
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// slowProvider answers streams with one chunk, then stalls until the request is cancelled
+type slowProvider struct {
+	mockChatProvider
+	deadline time.Time
+}
+
+func (m *slowProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.deadline, _ = ctx.Deadline()
+	if !req.Stream {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	chunkChan := make(chan *provider.Chunk, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+		chunkChan <- provider.NewOpenAIChunk([]byte(`{"id":"test-id","object":"chat.completion.chunk","model":"` + req.Model + `","choices":[{"index":0,"delta":{"content":"Hel"}}]}`))
+		<-ctx.Done()
+		errChan <- ctx.Err()
+	}()
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, func() error { return nil }), nil
+}
+
+func newTimeoutRequest(path, timeout string, stream bool) *http.Request {
+	body := map[string]any{
+		"model":    "gpt-4",
+		"messages": []map[string]string{{"role": "user", "content": "Hello"}},
+		"input":    "Hello",
+		"stream":   stream,
+	}
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	if timeout != "" {
+		req.Header.Set(RequestTimeoutHeader, timeout)
+	}
+	return req
+}
+
+func TestChatHandler_RequestTimeout(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &slowProvider{}}, hook.NewRegistry())
+	handler.SetMaxRequestTimeout(time.Minute)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTimeoutRequest("/v1/chat/completions", "0.05", false))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "timeout_error") {
+		t.Errorf("expected a timeout error, got %s", w.Body.String())
+	}
+}
+
+func TestChatHandler_RequestTimeoutStream(t *testing.T) {
+	handler := NewChatHandler(&mapModelRegistry{provider: &slowProvider{}}, hook.NewRegistry())
+	handler.SetMaxRequestTimeout(time.Minute)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTimeoutRequest("/v1/chat/completions", "0.05", true))
+
+	body := w.Body.String()
+	if !strings.Contains(body, `"content":"Hel"`) {
+		t.Errorf("expected the chunk sent before the timeout, got %s", body)
+	}
+	if !strings.Contains(body, "timeout_error") {
+		t.Errorf("expected a timeout error event, got %s", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("expected the stream to end with [DONE], got %s", body)
+	}
+}
+
+func TestResponsesHandler_RequestTimeoutStream(t *testing.T) {
+	handler := NewResponsesHandler(&mapModelRegistry{provider: &slowProvider{}}, hook.NewRegistry())
+	handler.SetMaxRequestTimeout(time.Minute)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTimeoutRequest("/v1/responses", "0.05", true))
+
+	body := w.Body.String()
+	if !strings.Contains(body, "Request timed out") {
+		t.Errorf("expected a timeout error event, got %s", body)
+	}
+	if strings.Contains(body, "response.completed") {
+		t.Errorf("expected a timed out stream not to complete, got %s", body)
+	}
+	if !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("expected the stream to end with [DONE], got %s", body)
+	}
+}
+
+func TestChatHandler_RequestTimeoutClamped(t *testing.T) {
+	prov := &slowProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetMaxRequestTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newTimeoutRequest("/v1/chat/completions", "3600", false))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", w.Code, w.Body.String())
+	}
+	if prov.deadline.IsZero() || prov.deadline.Sub(start) > time.Second {
+		t.Errorf("expected the deadline clamped to the 50ms max, got %v", prov.deadline.Sub(start))
+	}
+}
+
+func TestChatHandler_RequestTimeoutHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      time.Duration
+		timeout  string
+		wantCode int
+	}{
+		{name: "invalid", max: time.Minute, timeout: "soon", wantCode: http.StatusBadRequest},
+		{name: "negative", max: time.Minute, timeout: "-1", wantCode: http.StatusBadRequest},
+		{name: "ignored without max", timeout: "soon", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewChatHandler(newMockRegistry(), hook.NewRegistry())
+			handler.SetMaxRequestTimeout(tt.max)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newTimeoutRequest("/v1/chat/completions", tt.timeout, false))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
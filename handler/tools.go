@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+	"github.com/deeplooplabs/ai-gateway/provider"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// ToolsFallback controls how the chat handler serves requests with tools when
+// the provider doesn't support function calling (see provider.SupportsTools
+// and the probed provider.Capabilities)
+type ToolsFallback int
+
+const (
+	// ToolsStrip drops the tools and sends the request without them (the
+	// default). The error hooks are told about every stripped request.
+	ToolsStrip ToolsFallback = iota
+	// ToolsReject rejects the request with a validation error
+	ToolsReject
+)
+
+// SetToolsFallback sets how tools are handled for providers without function calling
+func (h *ChatHandler) SetToolsFallback(fallback ToolsFallback) {
+	h.tools = fallback
+}
+
+// SetToolsFallback sets how tools are handled for providers without function calling
+func (h *ResponsesHandler) SetToolsFallback(fallback ToolsFallback) {
+	h.tools = fallback
+}
+
+// checkTools strips tools from req if prov can't call them, or returns an
+// error if the handler rejects such requests
+func (h *ChatHandler) checkTools(ctx context.Context, req *openai2.ChatCompletionRequest, prov provider.Provider) error {
+	if err := applyToolsFallback(ctx, h.hooks, h.capabilities, h.tools, req, prov); err != nil {
+		return NewValidationError(err.Error())
+	}
+	return nil
+}
+
+// applyToolsFallback strips tools from req if prov can't call them, or
+// returns an error if fallback rejects such requests
+func applyToolsFallback(ctx context.Context, hooks *hook.Registry, capabilities *provider.CapabilityCache, fallback ToolsFallback, req *openai2.ChatCompletionRequest, prov provider.Provider) error {
+	if len(req.Tools) == 0 || (provider.SupportsTools(prov) && capabilities.Lookup(prov).Tools) {
+		return nil
+	}
+	if fallback == ToolsReject {
+		return fmt.Errorf("model %s does not support tools", req.Model)
+	}
+
+	warnToolsStripped(ctx, hooks, req.Model, len(req.Tools))
+	markDegraded(ctx, DegradedFallback)
+	req.Tools = nil
+	req.ToolChoice = nil
+	return nil
+}
+
+// warnToolsStripped logs and reports to the error hooks that tools were dropped
+func warnToolsStripped(ctx context.Context, hooks *hook.Registry, model string, count int) {
+	err := fmt.Errorf("model %s does not support tools; %d tool(s) stripped from the request", model, count)
+	slog.WarnContext(ctx, "stripped tools from request", "model", model, "tools", count)
+	for _, hh := range hooks.ErrorHooks() {
+		hh.OnError(ctx, err)
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/hook"
+)
+
+// noToolsProvider doesn't support function calling and records its last request
+type noToolsProvider struct {
+	recordingChatProvider
+}
+
+func (m *noToolsProvider) SupportsTools() bool {
+	return false
+}
+
+// toolsRequestBody is a chat request offering one tool
+var toolsRequestBody = map[string]any{
+	"model":    "gpt-4",
+	"messages": []map[string]string{{"role": "user", "content": "What's the weather?"}},
+	"tools": []map[string]any{{
+		"type":     "function",
+		"function": map[string]any{"name": "get_weather"},
+	}},
+	"tool_choice": "auto",
+}
+
+func TestChatHandler_ToolsStrip(t *testing.T) {
+	prov := &noToolsProvider{}
+	hooks := hook.NewRegistry()
+	errs := &errorRecordingHook{}
+	hooks.Register(errs)
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hooks)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, toolsRequestBody))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if prov.lastReq == nil || len(prov.lastReq.Tools) != 0 || prov.lastReq.ToolChoice != nil {
+		t.Errorf("expected tools to be stripped, got %+v", prov.lastReq)
+	}
+	if len(errs.errs) != 1 || !strings.Contains(errs.errs[0].Error(), "does not support tools") {
+		t.Errorf("expected a warning through the error hooks, got %v", errs.errs)
+	}
+}
+
+func TestChatHandler_ToolsReject(t *testing.T) {
+	prov := &noToolsProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetToolsFallback(ToolsReject)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, toolsRequestBody))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "does not support tools") {
+		t.Errorf("expected a clear error, got %s", w.Body.String())
+	}
+	if prov.lastReq != nil {
+		t.Error("expected the provider not to be called")
+	}
+}
+
+func TestChatHandler_ToolsSupported(t *testing.T) {
+	prov := &recordingChatProvider{}
+	handler := NewChatHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+	handler.SetToolsFallback(ToolsReject)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newChoicesRequest(t, toolsRequestBody))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(prov.lastReq.Tools) != 1 {
+		t.Errorf("expected tools to be forwarded, got %+v", prov.lastReq.Tools)
+	}
+}
+
+// responsesToolsRequest returns a responses request offering one tool
+func responsesToolsRequest(t *testing.T, stream bool) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{
+		"model":  "gpt-4",
+		"input":  "What's the weather?",
+		"stream": stream,
+		"tools":  []map[string]any{{"type": "function", "name": "get_weather"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	return httptest.NewRequest("POST", "/v1/responses", bytes.NewReader(body))
+}
+
+func TestResponsesHandler_ToolsStrip(t *testing.T) {
+	prov := &noToolsProvider{}
+	hooks := hook.NewRegistry()
+	errs := &errorRecordingHook{}
+	hooks.Register(errs)
+	handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hooks)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, responsesToolsRequest(t, false))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if prov.lastReq == nil || len(prov.lastReq.Tools) != 0 || prov.lastReq.ToolChoice != nil {
+		t.Errorf("expected tools to be stripped, got %+v", prov.lastReq)
+	}
+	if len(errs.errs) != 1 {
+		t.Errorf("expected a warning through the error hooks, got %v", errs.errs)
+	}
+}
+
+func TestResponsesHandler_ToolsReject(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		prov := &noToolsProvider{}
+		handler := NewResponsesHandler(&mapModelRegistry{provider: prov}, hook.NewRegistry())
+		handler.SetToolsFallback(ToolsReject)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, responsesToolsRequest(t, stream))
+
+		if !stream && w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "does not support tools") {
+			t.Errorf("stream=%v: expected a clear error, got %s", stream, w.Body.String())
+		}
+		if prov.lastReq != nil {
+			t.Errorf("stream=%v: expected the provider not to be called", stream)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package hook
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// DefaultAsyncPoolSize is the number of workers running async hooks when
+// WithAsync isn't given
+const DefaultAsyncPoolSize = 4
+
+// asyncQueuePerWorker bounds the calls waiting for each worker. Calls beyond
+// it are dropped rather than stalling requests.
+const asyncQueuePerWorker = 64
+
+// RegistryOption configures a Registry
+type RegistryOption func(*Registry)
+
+// WithAsync runs hooks registered with RegisterAsync on poolSize workers
+func WithAsync(poolSize int) RegistryOption {
+	return func(r *Registry) {
+		if poolSize <= 0 {
+			poolSize = DefaultAsyncPoolSize
+		}
+		r.async = newAsyncPool(poolSize)
+	}
+}
+
+// RegisterAsync registers hooks whose request and error callbacks run on the
+// registry's worker pool instead of in the request path. They see copies of
+// the request and response, so they can't modify them, and their errors are
+// logged instead of failing the request. Other callbacks, e.g. Authenticate,
+// still run synchronously.
+func (r *Registry) RegisterAsync(hooks ...Hook) {
+	if r.async == nil {
+		r.async = newAsyncPool(DefaultAsyncPoolSize)
+	}
+	for _, hook := range hooks {
+		r.register(hook, true)
+	}
+}
+
+// Close waits for queued async hook calls to finish. Later async calls are dropped.
+func (r *Registry) Close() error {
+	if r != nil && r.async != nil {
+		r.async.close()
+	}
+	return nil
+}
+
+// asyncPool runs hook calls on a fixed number of workers
+type asyncPool struct {
+	tasks  chan func()
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAsyncPool(size int) *asyncPool {
+	p := &asyncPool{tasks: make(chan func(), size*asyncQueuePerWorker)}
+	p.wg.Add(size)
+	for range size {
+		go p.work()
+	}
+	return p
+}
+
+func (p *asyncPool) work() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		task()
+	}
+}
+
+// submit queues call for a worker, dropping it if the queue is full
+func (p *asyncPool) submit(ctx context.Context, name string, call func(ctx context.Context) error) {
+	// Keep the request's values without being cancelled when it ends
+	ctx = context.WithoutCancel(ctx)
+	task := func() {
+		defer func() {
+			if v := recover(); v != nil {
+				slog.ErrorContext(ctx, "async hook panicked", "hook", name, "panic", v)
+			}
+		}()
+		if err := call(ctx); err != nil {
+			slog.WarnContext(ctx, "async hook failed", "hook", name, "error", err)
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		slog.WarnContext(ctx, "hook registry closed, dropping async hook call", "hook", name)
+		return
+	}
+	select {
+	case p.tasks <- task:
+	default:
+		slog.WarnContext(ctx, "async hook queue full, dropping call", "hook", name)
+	}
+}
+
+func (p *asyncPool) close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// asyncRequestHook runs a RequestHook on the pool
+type asyncRequestHook struct {
+	RequestHook
+	pool *asyncPool
+}
+
+func (h *asyncRequestHook) BeforeRequest(ctx context.Context, req *openai.ChatCompletionRequest) error {
+	reqCopy := copyRequest(req)
+	h.pool.submit(ctx, h.Name(), func(ctx context.Context) error {
+		return h.RequestHook.BeforeRequest(ctx, reqCopy)
+	})
+	return nil
+}
+
+func (h *asyncRequestHook) AfterRequest(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) error {
+	reqCopy, respCopy := copyRequest(req), copyResponse(resp)
+	h.pool.submit(ctx, h.Name(), func(ctx context.Context) error {
+		return h.RequestHook.AfterRequest(ctx, reqCopy, respCopy)
+	})
+	return nil
+}
+
+// asyncErrorHook runs an ErrorHook on the pool
+type asyncErrorHook struct {
+	ErrorHook
+	pool *asyncPool
+}
+
+func (h *asyncErrorHook) OnError(ctx context.Context, err error) {
+	h.pool.submit(ctx, h.Name(), func(ctx context.Context) error {
+		h.ErrorHook.OnError(ctx, err)
+		return nil
+	})
+}
+
+// copyRequest copies req deeply enough that later hooks editing its messages
+// don't race with an async hook reading them
+func copyRequest(req *openai.ChatCompletionRequest) *openai.ChatCompletionRequest {
+	if req == nil {
+		return nil
+	}
+	c := *req
+	c.Messages = slices.Clone(req.Messages)
+	return &c
+}
+
+// copyResponse copies resp deeply enough that later hooks editing its choices
+// don't race with an async hook reading them
+func copyResponse(resp *openai.ChatCompletionResponse) *openai.ChatCompletionResponse {
+	if resp == nil {
+		return nil
+	}
+	c := *resp
+	c.Choices = slices.Clone(resp.Choices)
+	return &c
+}
@@ -0,0 +1,101 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+type tenantKey struct{}
+
+// slowLoggingHook takes a while to log each response and records the tenant
+type slowLoggingHook struct {
+	delay   time.Duration
+	mu      sync.Mutex
+	tenants []string
+	errs    []error
+}
+
+func (h *slowLoggingHook) Name() string {
+	return "slow-logging"
+}
+
+func (h *slowLoggingHook) BeforeRequest(ctx context.Context, req *openai.ChatCompletionRequest) error {
+	req.Model = "rewritten"
+	return errors.New("logger unavailable")
+}
+
+func (h *slowLoggingHook) AfterRequest(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) error {
+	time.Sleep(h.delay)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	h.tenants = append(h.tenants, tenant)
+	return nil
+}
+
+func (h *slowLoggingHook) OnError(ctx context.Context, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.errs = append(h.errs, err)
+}
+
+func TestRegistry_RegisterAsync(t *testing.T) {
+	registry := NewRegistry(WithAsync(2))
+	slow := &slowLoggingHook{delay: 200 * time.Millisecond}
+	registry.RegisterAsync(slow)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "tenant-1"))
+	req := &openai.ChatCompletionRequest{Model: "gpt-4"}
+	resp := &openai.ChatCompletionResponse{ID: "resp-1"}
+
+	start := time.Now()
+	for _, hh := range registry.RequestHooks() {
+		if err := hh.BeforeRequest(ctx, req); err != nil {
+			t.Errorf("expected async hook errors not to fail the request, got %v", err)
+		}
+		if err := hh.AfterRequest(ctx, req, resp); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}
+	for _, hh := range registry.ErrorHooks() {
+		hh.OnError(ctx, errors.New("boom"))
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected async hooks not to delay the caller, took %v", elapsed)
+	}
+	// The request ends before the hooks run
+	cancel()
+
+	if req.Model != "gpt-4" {
+		t.Errorf("expected async hooks to see a copy of the request, got model %q", req.Model)
+	}
+
+	registry.Close()
+	if len(slow.tenants) != 1 || slow.tenants[0] != "tenant-1" {
+		t.Errorf("expected the hook to see the request's context values, got %v", slow.tenants)
+	}
+	if len(slow.errs) != 1 {
+		t.Errorf("expected 1 async error call, got %d", len(slow.errs))
+	}
+}
+
+func TestRegistry_RegisterAsyncKeepsOtherHooksSync(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterAsync(&mockAuthHook{mockHook: mockHook{name: "auth"}})
+	registry.Register(&slowLoggingHook{})
+
+	if len(registry.AuthenticationHooks()) != 1 {
+		t.Fatalf("expected the auth hook to be registered, got %d", len(registry.AuthenticationHooks()))
+	}
+	// Synchronously registered request hooks still fail requests
+	req := &openai.ChatCompletionRequest{Model: "gpt-4"}
+	if err := registry.RequestHooks()[0].BeforeRequest(context.Background(), req); err == nil {
+		t.Error("expected a synchronous hook error")
+	}
+	registry.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
 )
@@ -30,6 +31,32 @@ type RequestHook interface {
 	AfterRequest(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) error
 }
 
+// RoutingHook picks the model a request is sent to, e.g. by tenant
+type RoutingHook interface {
+	Hook
+	// Route is called before the model is resolved and returns the model name
+	// to resolve instead, or "" to keep model
+	Route(ctx context.Context, model string) (string, error)
+}
+
+// InterceptHook can answer a chat request itself without calling a provider,
+// e.g. a guardrail returning a canned refusal
+type InterceptHook interface {
+	Hook
+	// Intercept is called after the BeforeRequest hooks. A non-nil response is
+	// sent to the client instead of dispatching the request; nil continues.
+	Intercept(ctx context.Context, req *openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error)
+}
+
+// ResponseMutationHook rewrites non-streaming chat responses before they're
+// written, e.g. to strip internal reasoning or inject a disclaimer
+type ResponseMutationHook interface {
+	Hook
+	// MutateResponse is called after the AfterRequest hooks and returns the
+	// response to write instead, or nil to keep resp
+	MutateResponse(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) (*openai.ChatCompletionResponse, error)
+}
+
 // StreamingHook is called for each streaming chunk
 type StreamingHook interface {
 	Hook
@@ -38,6 +65,20 @@ type StreamingHook interface {
 	OnChunk(ctx context.Context, chunk []byte) ([]byte, error)
 }
 
+// StreamStats describes a single streamed chunk
+type StreamStats struct {
+	Index   int           // Zero-based index of the chunk within the stream
+	Bytes   int           // Size of the chunk payload in bytes
+	Elapsed time.Duration // Time since the stream started
+}
+
+// StreamStatsHook is called with stats for each streaming chunk
+type StreamStatsHook interface {
+	Hook
+	// OnStreamStats is called after each chunk is written to the client
+	OnStreamStats(ctx context.Context, stats StreamStats)
+}
+
 // ErrorHook is called when an error occurs
 type ErrorHook interface {
 	Hook
@@ -50,40 +91,89 @@ type Registry struct {
 	hooks               []Hook
 	authenticationHooks []AuthenticationHook
 	requestHooks        []RequestHook
+	routingHooks        []RoutingHook
+	interceptHooks      []InterceptHook
+	mutationHooks       []ResponseMutationHook
 	streamingHooks      []StreamingHook
+	streamStatsHooks    []StreamStatsHook
 	errorHooks          []ErrorHook
+	async               *asyncPool
 }
 
 // NewRegistry creates a new hook registry
-func NewRegistry() *Registry {
-	return &Registry{
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
 		hooks:               make([]Hook, 0),
 		authenticationHooks: make([]AuthenticationHook, 0),
 		requestHooks:        make([]RequestHook, 0),
+		routingHooks:        make([]RoutingHook, 0),
+		interceptHooks:      make([]InterceptHook, 0),
+		mutationHooks:       make([]ResponseMutationHook, 0),
 		streamingHooks:      make([]StreamingHook, 0),
+		streamStatsHooks:    make([]StreamStatsHook, 0),
 		errorHooks:          make([]ErrorHook, 0),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Register registers a hook based on its concrete type
 func (r *Registry) Register(hooks ...Hook) {
 	for _, hook := range hooks {
-		// Always add to general hooks list
-		r.hooks = append(r.hooks, hook)
-
-		// Also add to specific type lists if applicable
-		switch h := hook.(type) {
-		case AuthenticationHook:
-			r.authenticationHooks = append(r.authenticationHooks, h)
-		case RequestHook:
-			r.requestHooks = append(r.requestHooks, h)
-		case StreamingHook:
-			r.streamingHooks = append(r.streamingHooks, h)
-		case ErrorHook:
-			r.errorHooks = append(r.errorHooks, h)
-		default:
-			slog.Warn(fmt.Sprintf("unknown hook type: %T", h))
+		r.register(hook, false)
+	}
+}
+
+// register adds hook to every list it belongs to. The request and error
+// callbacks of async hooks are dispatched to the worker pool.
+func (r *Registry) register(hook Hook, async bool) {
+	// Always add to general hooks list
+	r.hooks = append(r.hooks, hook)
+
+	// Also add to every specific type list it implements
+	known := false
+	if h, ok := hook.(AuthenticationHook); ok {
+		r.authenticationHooks = append(r.authenticationHooks, h)
+		known = true
+	}
+	if h, ok := hook.(RequestHook); ok {
+		if async {
+			h = &asyncRequestHook{RequestHook: h, pool: r.async}
 		}
+		r.requestHooks = append(r.requestHooks, h)
+		known = true
+	}
+	if h, ok := hook.(RoutingHook); ok {
+		r.routingHooks = append(r.routingHooks, h)
+		known = true
+	}
+	if h, ok := hook.(InterceptHook); ok {
+		r.interceptHooks = append(r.interceptHooks, h)
+		known = true
+	}
+	if h, ok := hook.(ResponseMutationHook); ok {
+		r.mutationHooks = append(r.mutationHooks, h)
+		known = true
+	}
+	if h, ok := hook.(StreamingHook); ok {
+		r.streamingHooks = append(r.streamingHooks, h)
+		known = true
+	}
+	if h, ok := hook.(StreamStatsHook); ok {
+		r.streamStatsHooks = append(r.streamStatsHooks, h)
+		known = true
+	}
+	if h, ok := hook.(ErrorHook); ok {
+		if async {
+			h = &asyncErrorHook{ErrorHook: h, pool: r.async}
+		}
+		r.errorHooks = append(r.errorHooks, h)
+		known = true
+	}
+	if !known {
+		slog.Warn(fmt.Sprintf("unknown hook type: %T", hook))
 	}
 }
 
@@ -97,11 +187,31 @@ func (r *Registry) RequestHooks() []RequestHook {
 	return r.requestHooks
 }
 
+// RoutingHooks returns all routing hooks
+func (r *Registry) RoutingHooks() []RoutingHook {
+	return r.routingHooks
+}
+
+// InterceptHooks returns all intercept hooks
+func (r *Registry) InterceptHooks() []InterceptHook {
+	return r.interceptHooks
+}
+
+// ResponseMutationHooks returns all response mutation hooks
+func (r *Registry) ResponseMutationHooks() []ResponseMutationHook {
+	return r.mutationHooks
+}
+
 // StreamingHooks returns all streaming hooks
 func (r *Registry) StreamingHooks() []StreamingHook {
 	return r.streamingHooks
 }
 
+// StreamStatsHooks returns all stream stats hooks
+func (r *Registry) StreamStatsHooks() []StreamStatsHook {
+	return r.streamStatsHooks
+}
+
 // ErrorHooks returns all error hooks
 func (r *Registry) ErrorHooks() []ErrorHook {
 	return r.errorHooks
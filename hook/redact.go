@@ -0,0 +1,147 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// RedactionDirection selects which traffic a RedactionHook masks
+type RedactionDirection int
+
+const (
+	// RedactInbound masks prompts before they are sent upstream
+	RedactInbound RedactionDirection = 1 << iota
+	// RedactOutbound masks completions before they are returned to the client
+	RedactOutbound
+	// RedactBoth masks prompts and completions
+	RedactBoth = RedactInbound | RedactOutbound
+)
+
+// DefaultRedactionMask replaces matched PII
+const DefaultRedactionMask = "[REDACTED]"
+
+// DefaultRedactionPatterns returns the default PII patterns (emails and phone numbers)
+func DefaultRedactionPatterns() []*regexp.Regexp {
+	return []*regexp.Regexp{
+		regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)|\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`),
+	}
+}
+
+// RedactionHook masks PII in message content and streamed deltas
+type RedactionHook struct {
+	patterns  []*regexp.Regexp
+	mask      string
+	direction RedactionDirection
+}
+
+// RedactionOption configures a RedactionHook
+type RedactionOption func(*RedactionHook)
+
+// WithRedactionPatterns replaces the patterns to mask.
+// Use append(DefaultRedactionPatterns(), ...) to extend the defaults.
+func WithRedactionPatterns(patterns ...*regexp.Regexp) RedactionOption {
+	return func(h *RedactionHook) {
+		h.patterns = patterns
+	}
+}
+
+// WithRedactionMask sets the replacement for matched text
+func WithRedactionMask(mask string) RedactionOption {
+	return func(h *RedactionHook) {
+		h.mask = mask
+	}
+}
+
+// WithRedactionDirection sets which traffic is masked (default: RedactBoth)
+func WithRedactionDirection(direction RedactionDirection) RedactionOption {
+	return func(h *RedactionHook) {
+		h.direction = direction
+	}
+}
+
+// NewRedactionHook creates a redaction hook using the default patterns unless overridden
+func NewRedactionHook(opts ...RedactionOption) *RedactionHook {
+	h := &RedactionHook{
+		patterns:  DefaultRedactionPatterns(),
+		mask:      DefaultRedactionMask,
+		direction: RedactBoth,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Name returns the hook name
+func (h *RedactionHook) Name() string {
+	return "redaction"
+}
+
+// Redact masks every pattern match in text
+func (h *RedactionHook) Redact(text string) string {
+	for _, p := range h.patterns {
+		text = p.ReplaceAllString(text, h.mask)
+	}
+	return text
+}
+
+// BeforeRequest masks prompt messages when inbound redaction is enabled
+func (h *RedactionHook) BeforeRequest(ctx context.Context, req *openai.ChatCompletionRequest) error {
+	if h.direction&RedactInbound == 0 {
+		return nil
+	}
+	for i := range req.Messages {
+		req.Messages[i].Content = h.Redact(req.Messages[i].Content)
+	}
+	return nil
+}
+
+// AfterRequest masks completion messages when outbound redaction is enabled
+func (h *RedactionHook) AfterRequest(ctx context.Context, req *openai.ChatCompletionRequest, resp *openai.ChatCompletionResponse) error {
+	if h.direction&RedactOutbound == 0 {
+		return nil
+	}
+	for i := range resp.Choices {
+		resp.Choices[i].Message.Content = h.Redact(resp.Choices[i].Message.Content)
+	}
+	return nil
+}
+
+// OnChunk masks streamed deltas when outbound redaction is enabled.
+// Matches are found per chunk, so PII split across chunks is not masked.
+func (h *RedactionHook) OnChunk(ctx context.Context, chunk []byte) ([]byte, error) {
+	if h.direction&RedactOutbound == 0 {
+		return chunk, nil
+	}
+
+	var resp openai.ChatCompletionStreamResponse
+	if err := json.Unmarshal(chunk, &resp); err != nil {
+		// Not a chat completion chunk, pass through
+		return chunk, nil
+	}
+
+	changed := false
+	for i := range resp.Choices {
+		delta := resp.Choices[i].Delta
+		if delta == nil || delta.Content == "" {
+			continue
+		}
+		if redacted := h.Redact(delta.Content); redacted != delta.Content {
+			delta.Content = redacted
+			changed = true
+		}
+	}
+	if !changed {
+		return chunk, nil
+	}
+	return json.Marshal(&resp)
+}
+
+var (
+	_ RequestHook   = (*RedactionHook)(nil)
+	_ StreamingHook = (*RedactionHook)(nil)
+)
@@ -0,0 +1,134 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestRedactionHook_Inbound(t *testing.T) {
+	h := NewRedactionHook(WithRedactionDirection(RedactInbound))
+
+	req := &openai.ChatCompletionRequest{
+		Messages: []openai.Message{
+			{Role: "user", Content: "Mail jane.doe@example.com or call (555) 123-4567"},
+		},
+	}
+	if err := h.BeforeRequest(context.Background(), req); err != nil {
+		t.Fatalf("BeforeRequest failed: %v", err)
+	}
+
+	want := "Mail [REDACTED] or call [REDACTED]"
+	if req.Messages[0].Content != want {
+		t.Errorf("expected %q, got %q", want, req.Messages[0].Content)
+	}
+
+	// Outbound is disabled, so completions pass through
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.Choice{{Message: openai.Message{Content: "jane.doe@example.com"}}},
+	}
+	h.AfterRequest(context.Background(), req, resp)
+	if resp.Choices[0].Message.Content != "jane.doe@example.com" {
+		t.Errorf("expected outbound content unchanged, got %q", resp.Choices[0].Message.Content)
+	}
+}
+
+func TestRedactionHook_Outbound(t *testing.T) {
+	h := NewRedactionHook(WithRedactionDirection(RedactOutbound), WithRedactionMask("***"))
+
+	req := &openai.ChatCompletionRequest{
+		Messages: []openai.Message{{Role: "user", Content: "my email is a@b.io"}},
+	}
+	h.BeforeRequest(context.Background(), req)
+	if req.Messages[0].Content != "my email is a@b.io" {
+		t.Errorf("expected inbound content unchanged, got %q", req.Messages[0].Content)
+	}
+
+	resp := &openai.ChatCompletionResponse{
+		Choices: []openai.Choice{{Message: openai.Message{Role: "assistant", Content: "Reach me at +1 555-123-4567"}}},
+	}
+	if err := h.AfterRequest(context.Background(), req, resp); err != nil {
+		t.Fatalf("AfterRequest failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Reach me at ***" {
+		t.Errorf("unexpected completion: %q", resp.Choices[0].Message.Content)
+	}
+}
+
+func TestRedactionHook_OnChunk(t *testing.T) {
+	h := NewRedactionHook()
+
+	chunk := []byte(`{"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"write to bob@example.org"}}]}`)
+	out, err := h.OnChunk(context.Background(), chunk)
+	if err != nil {
+		t.Fatalf("OnChunk failed: %v", err)
+	}
+
+	var resp openai.ChatCompletionStreamResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	if got := resp.Choices[0].Delta.Content; got != "write to [REDACTED]" {
+		t.Errorf("unexpected delta: %q", got)
+	}
+
+	// Chunks without matches are returned untouched
+	clean := []byte(`{"choices":[{"index":0,"delta":{"content":"hello"}}]}`)
+	if out, _ := h.OnChunk(context.Background(), clean); string(out) != string(clean) {
+		t.Errorf("expected clean chunk unchanged, got %s", out)
+	}
+}
+
+func TestRedactionHook_ImageParts(t *testing.T) {
+	h := NewRedactionHook()
+
+	msg := openai.Message{Role: "assistant", Content: "Badge for jane.doe@example.com"}
+	msg.AppendImage("data:image/png;base64,iVBORw0KGgo=")
+	resp := &openai.ChatCompletionResponse{Choices: []openai.Choice{{Message: msg}}}
+	if err := h.AfterRequest(context.Background(), &openai.ChatCompletionRequest{}, resp); err != nil {
+		t.Fatalf("AfterRequest failed: %v", err)
+	}
+	data, _ := json.Marshal(resp.Choices[0].Message)
+	want := `{"role":"assistant","content":[{"type":"text","text":"Badge for [REDACTED]"},{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}]}`
+	if string(data) != want {
+		t.Errorf("expected the redacted text with the image:\n got %s\nwant %s", data, want)
+	}
+
+	chunk := []byte(`{"choices":[{"index":0,"delta":{"content":[{"type":"text","text":"mail bob@example.org"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]}}]}`)
+	out, err := h.OnChunk(context.Background(), chunk)
+	if err != nil {
+		t.Fatalf("OnChunk failed: %v", err)
+	}
+	var stream openai.ChatCompletionStreamResponse
+	if err := json.Unmarshal(out, &stream); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	delta := stream.Choices[0].Delta
+	if delta.Content != "mail [REDACTED]" || len(delta.Parts) != 2 || delta.Parts[1].Type != openai.ContentPartImageURL {
+		t.Errorf("expected the redacted text with the image, got %q and %+v", delta.Content, delta.Parts)
+	}
+}
+
+func TestRedactionHook_CustomPatterns(t *testing.T) {
+	ssn := regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	h := NewRedactionHook(WithRedactionPatterns(append(DefaultRedactionPatterns(), ssn)...))
+
+	if got := h.Redact("SSN 123-45-6789, mail x@y.com"); got != "SSN [REDACTED], mail [REDACTED]" {
+		t.Errorf("unexpected redaction: %q", got)
+	}
+}
+
+func TestHookRegistry_RegisterMultipleTypes(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(NewRedactionHook())
+
+	if len(registry.RequestHooks()) != 1 {
+		t.Errorf("expected 1 request hook, got %d", len(registry.RequestHooks()))
+	}
+	if len(registry.StreamingHooks()) != 1 {
+		t.Errorf("expected 1 streaming hook, got %d", len(registry.StreamingHooks()))
+	}
+}
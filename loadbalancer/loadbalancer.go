@@ -2,11 +2,17 @@ package loadbalancer
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
 	"github.com/deeplooplabs/ai-gateway/provider"
 )
 
@@ -22,6 +28,42 @@ const (
 	WeightedRandom
 	// LeastConnections selects the provider with fewest active connections
 	LeastConnections
+	// LeastLatency samples two random providers and selects the one with the
+	// lower latency EWMA (power of two choices)
+	LeastLatency
+	// Sticky hashes a key, the tenant by default, to select the same provider
+	// for every request with that key, moving on to the next healthy one if
+	// it is out of rotation. Requests without a key are sent round robin.
+	Sticky
+)
+
+// StickyKeyFunc returns the key a Sticky load balancer routes a request by
+type StickyKeyFunc func(ctx context.Context, req *provider.Request) string
+
+// TenantKey is the default StickyKeyFunc, keying requests by tenant
+func TenantKey(ctx context.Context, req *provider.Request) string {
+	return ai_gateway.TenantIDFromContext(ctx)
+}
+
+// latencyEWMAWeight is the weight of each new sample in the latency EWMA
+const latencyEWMAWeight = 0.2
+
+// ErrorCounting selects which errors count towards ErrorThreshold
+type ErrorCounting int
+
+const (
+	// CumulativeErrors counts every error since the provider last returned
+	// to rotation, however many requests succeeded in between
+	CumulativeErrors ErrorCounting = iota
+	// ConsecutiveErrors counts errors since the provider's last successful request
+	ConsecutiveErrors
+)
+
+// Defaults for the health heuristic
+const (
+	DefaultErrorThreshold     = 11  // Errors marking a provider unhealthy
+	DefaultErrorRateThreshold = 0.5 // Error rate at which health checks keep a provider out of rotation
+	DefaultCooldown           = 30 * time.Second // Time out of rotation before a provider without a probe URL is retried
 )
 
 // ProviderWithWeight wraps a provider with weight and health information
@@ -33,8 +75,23 @@ type ProviderWithWeight struct {
 	TotalErrors      uint64        // Total errors encountered
 	LastHealthCheck  time.Time     // Last health check time
 	Healthy          bool          // Health status
+	Draining         bool          // Excluded from selection while in-flight requests finish
 	HealthCheckURL   string        // Optional health check endpoint
 	HealthCheckInterval time.Duration // Health check interval (default: 30s)
+	LatencyEWMA      int64         // EWMA of successful response times in nanoseconds, 0 until measured
+
+	// Error window used by the health heuristic, reset when the provider
+	// returns to rotation
+	windowRequests uint64
+	windowErrors   uint64
+
+	// Errors since the last successful request
+	consecutiveErrors uint64
+
+	// When the provider left rotation, and whether a trial request is
+	// testing it after the cooldown
+	unhealthySince time.Time
+	trial          atomic.Bool
 }
 
 // LoadBalancedProvider wraps multiple providers with load balancing
@@ -43,12 +100,26 @@ type LoadBalancedProvider struct {
 	providers []*ProviderWithWeight
 	strategy  Strategy
 	counter   uint64 // For round-robin
+	stickyKey StickyKeyFunc
 	mu        sync.RWMutex
 	
 	// Health check configuration
 	healthCheckEnabled  bool
 	healthCheckInterval time.Duration
+	healthCheckClient   *http.Client
+	healthCheckTimeout  time.Duration
 	stopHealthCheck     chan struct{}
+	healthCheckDone     chan struct{}
+
+	// Health heuristic
+	errorThreshold     uint64
+	errorRateThreshold float64
+	errorCounting      ErrorCounting
+	cooldown           time.Duration
+
+	// healthCtx is cancelled on Close to abort in-flight probes
+	healthCtx    context.Context
+	cancelHealth context.CancelFunc
 }
 
 // Config holds load balancer configuration
@@ -59,6 +130,25 @@ type Config struct {
 	Weights             []int  // Optional weights for WeightedRandom
 	HealthCheckEnabled  bool
 	HealthCheckInterval time.Duration
+	// HealthCheckURLs are optional per-provider endpoints probed with a GET
+	// during health checks; a 2xx response returns the provider to rotation
+	HealthCheckURLs    []string
+	HealthCheckTimeout time.Duration // Probe timeout (default: 5s, at most HealthCheckInterval)
+
+	// ErrorThreshold is the number of provider errors, counted as selected by
+	// ErrorCounting, that takes a provider out of rotation (default: 11)
+	ErrorThreshold int
+	// ErrorRateThreshold is the error rate (0-1) at or above which health
+	// checks without a probe URL keep a provider out of rotation (default: 0.5)
+	ErrorRateThreshold float64
+	// ErrorCounting selects cumulative (default) or consecutive errors
+	ErrorCounting ErrorCounting
+	// StickyKeyFunc returns the key the Sticky strategy routes by (default: TenantKey)
+	StickyKeyFunc StickyKeyFunc
+	// Cooldown is how long a provider without a HealthCheckURL stays out of
+	// rotation before a single trial request is sent to it: success returns
+	// it to rotation, a failure starts another cooldown (default: 30s)
+	Cooldown time.Duration
 }
 
 // DefaultConfig returns a default load balancer configuration
@@ -68,6 +158,10 @@ func DefaultConfig(name string) *Config {
 		Strategy:            RoundRobin,
 		HealthCheckEnabled:  false,
 		HealthCheckInterval: 30 * time.Second,
+		HealthCheckTimeout:  5 * time.Second,
+		ErrorThreshold:      DefaultErrorThreshold,
+		ErrorRateThreshold:  DefaultErrorRateThreshold,
+		Cooldown:            DefaultCooldown,
 	}
 }
 
@@ -88,23 +182,67 @@ func New(config *Config) (*LoadBalancedProvider, error) {
 		if len(config.Weights) > i {
 			weight = config.Weights[i]
 		}
+		healthCheckURL := ""
+		if len(config.HealthCheckURLs) > i {
+			healthCheckURL = config.HealthCheckURLs[i]
+		}
 		
 		providerWrappers[i] = &ProviderWithWeight{
 			Provider:            p,
 			Weight:              weight,
 			Healthy:             true,
+			HealthCheckURL:      healthCheckURL,
 			HealthCheckInterval: config.HealthCheckInterval,
 			LastHealthCheck:     time.Now(),
 		}
 	}
 	
+	healthCheckTimeout := config.HealthCheckTimeout
+	if healthCheckTimeout <= 0 {
+		healthCheckTimeout = 5 * time.Second
+	}
+	// A probe never outlives the interval between checks
+	if config.HealthCheckInterval > 0 && config.HealthCheckInterval < healthCheckTimeout {
+		healthCheckTimeout = config.HealthCheckInterval
+	}
+	healthCtx, cancelHealth := context.WithCancel(context.Background())
+	
+	errorThreshold := config.ErrorThreshold
+	if errorThreshold <= 0 {
+		errorThreshold = DefaultErrorThreshold
+	}
+	errorRateThreshold := config.ErrorRateThreshold
+	if errorRateThreshold <= 0 || errorRateThreshold > 1 {
+		errorRateThreshold = DefaultErrorRateThreshold
+	}
+	
+	cooldown := config.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	
+	stickyKey := config.StickyKeyFunc
+	if stickyKey == nil {
+		stickyKey = TenantKey
+	}
+	
 	lb := &LoadBalancedProvider{
 		name:                config.Name,
 		providers:           providerWrappers,
 		strategy:            config.Strategy,
+		stickyKey:           stickyKey,
 		healthCheckEnabled:  config.HealthCheckEnabled,
 		healthCheckInterval: config.HealthCheckInterval,
+		healthCheckClient:   &http.Client{},
+		healthCheckTimeout:  healthCheckTimeout,
 		stopHealthCheck:     make(chan struct{}),
+		healthCheckDone:     make(chan struct{}),
+		healthCtx:           healthCtx,
+		cancelHealth:        cancelHealth,
+		errorThreshold:      uint64(errorThreshold),
+		errorRateThreshold:  errorRateThreshold,
+		errorCounting:       config.ErrorCounting,
+		cooldown:            cooldown,
 	}
 	
 	// Start health checks if enabled
@@ -125,52 +263,195 @@ func (lb *LoadBalancedProvider) SupportedAPIs() provider.APIType {
 	lb.mu.RLock()
 	defer lb.mu.RUnlock()
 	
-	if len(lb.providers) == 0 {
-		return 0
+	// Requests are only sent to providers supporting their API
+	var apis provider.APIType
+	for _, p := range lb.providers {
+		apis |= p.Provider.SupportedAPIs()
 	}
-	
-	// Return the API type of the first provider
-	// (assumes all providers support the same APIs)
-	return lb.providers[0].Provider.SupportedAPIs()
+	return apis
+}
+
+// SingleChoice reports whether any provider always returns a single choice.
+// A request may go to any of them, so n > 1 is only served natively when
+// every provider supports it.
+func (lb *LoadBalancedProvider) SingleChoice() bool {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	for _, p := range lb.providers {
+		if provider.IsSingleChoice(p.Provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsTools reports whether every provider accepts tools, since a
+// request may go to any of them
+func (lb *LoadBalancedProvider) SupportsTools() bool {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	for _, p := range lb.providers {
+		if !provider.SupportsTools(p.Provider) {
+			return false
+		}
+	}
+	return true
 }
 
 // SendRequest sends a request using the load balancing strategy
 func (lb *LoadBalancedProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
-	p, err := lb.selectProvider()
-	if err != nil {
-		return nil, err
+	// A provider out of rotation past its cooldown gets one trial request
+	p := lb.selectTrial(req.APIType)
+	trial := p != nil
+	if !trial {
+		var err error
+		if p, err = lb.selectProvider(ctx, req); err != nil {
+			return nil, err
+		}
 	}
 	
 	// Track active requests
 	atomic.AddInt32(&p.ActiveRequests, 1)
 	atomic.AddUint64(&p.TotalRequests, 1)
+	atomic.AddUint64(&p.windowRequests, 1)
 	defer atomic.AddInt32(&p.ActiveRequests, -1)
 	
 	// Send request
+	start := time.Now()
 	resp, err := p.Provider.SendRequest(ctx, req)
+	if trial {
+		lb.finishTrial(p, err)
+	}
 	if err != nil {
 		atomic.AddUint64(&p.TotalErrors, 1)
-		// Mark as unhealthy if too many errors
-		if atomic.LoadUint64(&p.TotalErrors) > 10 {
+		// Mark as unhealthy once provider faults reach the threshold
+		if countsAgainstHealth(err) && lb.recordError(p) >= lb.errorThreshold {
 			lb.mu.Lock()
-			p.Healthy = false
+			markUnhealthy(p)
 			lb.mu.Unlock()
 		}
 		return nil, err
 	}
+	atomic.StoreUint64(&p.consecutiveErrors, 0)
+	recordLatency(p, time.Since(start))
 	
 	return resp, nil
 }
 
+// selectTrial returns a provider without a probe URL that has been out of
+// rotation for the cooldown and supports apiType, claiming its trial request,
+// or nil if there is none
+func (lb *LoadBalancedProvider) selectTrial(apiType provider.APIType) *ProviderWithWeight {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	now := time.Now()
+	for _, p := range lb.providers {
+		if p.Healthy || p.Draining || p.HealthCheckURL != "" || p.unhealthySince.IsZero() || now.Sub(p.unhealthySince) < lb.cooldown {
+			continue
+		}
+		if apiType != 0 && !p.Provider.SupportedAPIs().Supports(apiType) {
+			continue
+		}
+		if p.trial.CompareAndSwap(false, true) {
+			return p
+		}
+	}
+	return nil
+}
+
+// finishTrial returns p to rotation if its trial request reached a working
+// upstream, or else starts another cooldown
+func (lb *LoadBalancedProvider) finishTrial(p *ProviderWithWeight, err error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	defer p.trial.Store(false)
+
+	if err != nil && countsAgainstHealth(err) {
+		p.unhealthySince = time.Now()
+		return
+	}
+	p.Healthy = true
+	resetErrorWindow(p)
+}
+
+// markUnhealthy takes p out of rotation, starting its cooldown if it was in
+// rotation. The caller holds the lock.
+func markUnhealthy(p *ProviderWithWeight) {
+	if p.Healthy {
+		p.unhealthySince = time.Now()
+	}
+	p.Healthy = false
+}
+
+// recordLatency folds a successful request's latency into the provider's EWMA.
+// Errors aren't recorded so a fast-failing provider doesn't attract traffic.
+func recordLatency(p *ProviderWithWeight, latency time.Duration) {
+	for {
+		old := atomic.LoadInt64(&p.LatencyEWMA)
+		next := int64(latency)
+		if old != 0 {
+			next = int64(latencyEWMAWeight*float64(latency) + (1-latencyEWMAWeight)*float64(old))
+		}
+		if atomic.CompareAndSwapInt64(&p.LatencyEWMA, old, next) {
+			return
+		}
+	}
+}
+
+// recordError counts a provider fault and returns the count compared
+// against the error threshold
+func (lb *LoadBalancedProvider) recordError(p *ProviderWithWeight) uint64 {
+	windowErrors := atomic.AddUint64(&p.windowErrors, 1)
+	consecutiveErrors := atomic.AddUint64(&p.consecutiveErrors, 1)
+	if lb.errorCounting == ConsecutiveErrors {
+		return consecutiveErrors
+	}
+	return windowErrors
+}
+
+// countsAgainstHealth reports whether err points at a failing provider.
+// Rate limiting, client errors and cancelled requests don't.
+func countsAgainstHealth(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var provErr *provider.Error
+	if !errors.As(err, &provErr) {
+		return true
+	}
+	switch provErr.Kind {
+	case provider.ErrorKindRateLimited:
+		return false
+	case provider.ErrorKindUpstream:
+		return provErr.StatusCode >= http.StatusInternalServerError
+	default:
+		return true
+	}
+}
+
 // selectProvider selects a provider based on the load balancing strategy
-func (lb *LoadBalancedProvider) selectProvider() (*ProviderWithWeight, error) {
+func (lb *LoadBalancedProvider) selectProvider(ctx context.Context, req *provider.Request) (*ProviderWithWeight, error) {
 	lb.mu.RLock()
 	defer lb.mu.RUnlock()
 	
-	// Filter healthy providers
+	// Sticky keys are hashed over every provider so the choice only moves
+	// while the chosen provider is out of rotation
+	if lb.strategy == Sticky {
+		if key := lb.stickyKey(ctx, req); key != "" {
+			if p := lb.selectSticky(key, req.APIType); p != nil {
+				return p, nil
+			}
+			return nil, errors.New("no healthy providers available")
+		}
+	}
+	
+	// Filter healthy providers supporting the request's API
 	healthyProviders := make([]*ProviderWithWeight, 0, len(lb.providers))
 	for _, p := range lb.providers {
-		if p.Healthy {
+		if p.available(req.APIType) {
 			healthyProviders = append(healthyProviders, p)
 		}
 	}
@@ -188,6 +469,8 @@ func (lb *LoadBalancedProvider) selectProvider() (*ProviderWithWeight, error) {
 		return lb.selectWeightedRandom(healthyProviders), nil
 	case LeastConnections:
 		return lb.selectLeastConnections(healthyProviders), nil
+	case LeastLatency:
+		return lb.selectLeastLatency(healthyProviders), nil
 	default:
 		return lb.selectRoundRobin(healthyProviders), nil
 	}
@@ -201,23 +484,33 @@ func (lb *LoadBalancedProvider) selectRoundRobin(providers []*ProviderWithWeight
 
 // selectRandom selects a random provider
 func (lb *LoadBalancedProvider) selectRandom(providers []*ProviderWithWeight) *ProviderWithWeight {
-	return providers[time.Now().UnixNano()%int64(len(providers))]
+	return providers[rand.IntN(len(providers))]
 }
 
 // selectWeightedRandom selects provider based on weights
 func (lb *LoadBalancedProvider) selectWeightedRandom(providers []*ProviderWithWeight) *ProviderWithWeight {
-	// Calculate total weight
+	// Calculate total weight, ignoring non-positive weights
 	totalWeight := 0
 	for _, p := range providers {
-		totalWeight += p.Weight
+		if p.Weight > 0 {
+			totalWeight += p.Weight
+		}
+	}
+	
+	// No usable weights: fall back to uniform selection
+	if totalWeight == 0 {
+		return lb.selectRandom(providers)
 	}
 	
 	// Select random value
-	random := int(time.Now().UnixNano() % int64(totalWeight))
+	random := rand.IntN(totalWeight)
 	
 	// Find provider based on weight
 	sum := 0
 	for _, p := range providers {
+		if p.Weight <= 0 {
+			continue
+		}
 		sum += p.Weight
 		if random < sum {
 			return p
@@ -243,8 +536,51 @@ func (lb *LoadBalancedProvider) selectLeastConnections(providers []*ProviderWith
 	return selected
 }
 
+// available reports whether p is in rotation and supports apiType, if set
+func (p *ProviderWithWeight) available(apiType provider.APIType) bool {
+	if !p.Healthy || p.Draining {
+		return false
+	}
+	return apiType == 0 || p.Provider.SupportedAPIs().Supports(apiType)
+}
+
+// selectSticky selects the provider key hashes to, or the next one available
+// after it, or nil if none is
+func (lb *LoadBalancedProvider) selectSticky(key string, apiType provider.APIType) *ProviderWithWeight {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	start := int(h.Sum64() % uint64(len(lb.providers)))
+	
+	for i := range lb.providers {
+		p := lb.providers[(start+i)%len(lb.providers)]
+		if p.available(apiType) {
+			return p
+		}
+	}
+	return nil
+}
+
+// selectLeastLatency selects the faster of two distinct random providers.
+// Providers without a measured latency are preferred so they get sampled.
+func (lb *LoadBalancedProvider) selectLeastLatency(providers []*ProviderWithWeight) *ProviderWithWeight {
+	if len(providers) == 1 {
+		return providers[0]
+	}
+	i := rand.IntN(len(providers))
+	j := rand.IntN(len(providers) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := providers[i], providers[j]
+	if atomic.LoadInt64(&b.LatencyEWMA) < atomic.LoadInt64(&a.LatencyEWMA) {
+		return b
+	}
+	return a
+}
+
 // runHealthChecks periodically checks provider health
 func (lb *LoadBalancedProvider) runHealthChecks() {
+	defer close(lb.healthCheckDone)
 	ticker := time.NewTicker(lb.healthCheckInterval)
 	defer ticker.Stop()
 	
@@ -260,27 +596,104 @@ func (lb *LoadBalancedProvider) runHealthChecks() {
 
 // checkHealth checks health of all providers
 func (lb *LoadBalancedProvider) checkHealth() {
-	lb.mu.Lock()
-	defer lb.mu.Unlock()
+	lb.mu.RLock()
+	providers := make([]*ProviderWithWeight, len(lb.providers))
+	copy(providers, lb.providers)
+	lb.mu.RUnlock()
 	
-	for _, p := range lb.providers {
-		// Simple health check: if error rate is low, mark as healthy
-		totalRequests := atomic.LoadUint64(&p.TotalRequests)
-		totalErrors := atomic.LoadUint64(&p.TotalErrors)
+	for _, p := range providers {
+		// Probe outside the lock so a slow endpoint doesn't block selection
+		var probed, probeOK bool
+		if p.HealthCheckURL != "" {
+			probed = true
+			probeOK = lb.probe(p.HealthCheckURL)
+		}
 		
-		if totalRequests > 0 {
-			errorRate := float64(totalErrors) / float64(totalRequests)
-			p.Healthy = errorRate < 0.5 // Mark healthy if error rate < 50%
+		// A probe aborted by Close says nothing about the provider
+		if lb.healthCtx.Err() != nil {
+			return
 		}
 		
+		lb.mu.Lock()
+		switch {
+		case probed && probeOK:
+			// Upstream is reachable again: return it to rotation and
+			// start a fresh error window
+			p.Healthy = true
+			resetErrorWindow(p)
+		case probed:
+			p.Healthy = false
+		default:
+			// Simple health check: if error rate is low, mark as healthy
+			windowRequests := atomic.LoadUint64(&p.windowRequests)
+			windowErrors := atomic.LoadUint64(&p.windowErrors)
+			if windowRequests > 0 {
+				errorRate := float64(windowErrors) / float64(windowRequests)
+				healthy := errorRate < lb.errorRateThreshold
+				if !healthy {
+					markUnhealthy(p)
+				} else if !p.Healthy {
+					resetErrorWindow(p)
+					p.Healthy = true
+				}
+			}
+		}
 		p.LastHealthCheck = time.Now()
+		lb.mu.Unlock()
 	}
 }
 
-// Close stops the health check goroutine
+// resetErrorWindow starts a fresh error window for a provider returning to rotation
+func resetErrorWindow(p *ProviderWithWeight) {
+	atomic.StoreUint64(&p.windowRequests, 0)
+	atomic.StoreUint64(&p.windowErrors, 0)
+	atomic.StoreUint64(&p.consecutiveErrors, 0)
+}
+
+// probe issues a GET against the health check URL and reports whether it
+// returned a 2xx status within the probe timeout
+func (lb *LoadBalancedProvider) probe(url string) bool {
+	ctx, cancel := context.WithTimeout(lb.healthCtx, lb.healthCheckTimeout)
+	defer cancel()
+	
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	
+	resp, err := lb.healthCheckClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// SetDraining marks a provider as draining (or not). A draining provider
+// receives no new requests but in-flight ones finish normally, and it is
+// not reported as unhealthy.
+func (lb *LoadBalancedProvider) SetDraining(name string, draining bool) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	
+	for _, p := range lb.providers {
+		if p.Provider.Name() == name {
+			p.Draining = draining
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("provider not found: %s", name)
+}
+
+// Close stops the health check goroutine, aborting any in-flight probe, and
+// waits for it to exit
 func (lb *LoadBalancedProvider) Close() error {
+	lb.cancelHealth()
 	if lb.healthCheckEnabled {
 		close(lb.stopHealthCheck)
+		<-lb.healthCheckDone
 	}
 	return nil
 }
@@ -296,6 +709,7 @@ func (lb *LoadBalancedProvider) GetStats() []ProviderStats {
 			Name:           p.Provider.Name(),
 			Weight:         p.Weight,
 			Healthy:        p.Healthy,
+			Draining:       p.Draining,
 			ActiveRequests: atomic.LoadInt32(&p.ActiveRequests),
 			TotalRequests:  atomic.LoadUint64(&p.TotalRequests),
 			TotalErrors:    atomic.LoadUint64(&p.TotalErrors),
@@ -308,11 +722,66 @@ func (lb *LoadBalancedProvider) GetStats() []ProviderStats {
 
 // ProviderStats represents statistics for a single provider
 type ProviderStats struct {
-	Name            string
-	Weight          int
-	Healthy         bool
-	ActiveRequests  int32
-	TotalRequests   uint64
-	TotalErrors     uint64
-	LastHealthCheck time.Time
+	Name            string    `json:"name"`
+	Weight          int       `json:"weight"`
+	Healthy         bool      `json:"healthy"`
+	Draining        bool      `json:"draining"`
+	ActiveRequests  int32     `json:"active_requests"`
+	TotalRequests   uint64    `json:"total_requests"`
+	TotalErrors     uint64    `json:"total_errors"`
+	LastHealthCheck time.Time `json:"last_health_check"`
+}
+
+// MarshalJSON implements json.Marshaler with stable field names.
+// The last health check is encoded as RFC 3339 and omitted when unset,
+// and the error rate is derived from the request and error counters.
+func (s ProviderStats) MarshalJSON() ([]byte, error) {
+	var lastHealthCheck string
+	if !s.LastHealthCheck.IsZero() {
+		lastHealthCheck = s.LastHealthCheck.UTC().Format(time.RFC3339)
+	}
+
+	var errorRate float64
+	if s.TotalRequests > 0 {
+		errorRate = float64(s.TotalErrors) / float64(s.TotalRequests)
+	}
+
+	return json.Marshal(struct {
+		Name            string  `json:"name"`
+		Weight          int     `json:"weight"`
+		Healthy         bool    `json:"healthy"`
+		Draining        bool    `json:"draining"`
+		ActiveRequests  int32   `json:"active_requests"`
+		TotalRequests   uint64  `json:"total_requests"`
+		TotalErrors     uint64  `json:"total_errors"`
+		ErrorRate       float64 `json:"error_rate"`
+		LastHealthCheck string  `json:"last_health_check,omitempty"`
+	}{
+		Name:            s.Name,
+		Weight:          s.Weight,
+		Healthy:         s.Healthy,
+		Draining:        s.Draining,
+		ActiveRequests:  s.ActiveRequests,
+		TotalRequests:   s.TotalRequests,
+		TotalErrors:     s.TotalErrors,
+		ErrorRate:       errorRate,
+		LastHealthCheck: lastHealthCheck,
+	})
+}
+
+// StatsRecorder receives per-provider statistics from a load balancer,
+// e.g. to export them as Prometheus gauges
+type StatsRecorder interface {
+	// RecordProviderStats records a snapshot of one provider's statistics
+	RecordProviderStats(balancer string, stats ProviderStats)
+}
+
+// ReportStats feeds the current statistics of every provider into the recorder
+func (lb *LoadBalancedProvider) ReportStats(recorder StatsRecorder) {
+	if recorder == nil {
+		return
+	}
+	for _, s := range lb.GetStats() {
+		recorder.RecordProviderStats(lb.name, s)
+	}
 }
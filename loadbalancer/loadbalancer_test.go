@@ -2,7 +2,14 @@ package loadbalancer
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,6 +39,25 @@ func (m *mockProvider) SendRequest(ctx context.Context, req *provider.Request) (
 	return &provider.Response{}, nil
 }
 
+// countingProvider is a goroutine-safe mock provider for concurrency tests
+type countingProvider struct {
+	name  string
+	calls atomic.Int64
+}
+
+func (c *countingProvider) Name() string {
+	return c.name
+}
+
+func (c *countingProvider) SupportedAPIs() provider.APIType {
+	return provider.APITypeChatCompletions
+}
+
+func (c *countingProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	c.calls.Add(1)
+	return &provider.Response{}, nil
+}
+
 func TestLoadBalancer_RoundRobin(t *testing.T) {
 	p1 := &mockProvider{name: "provider1"}
 	p2 := &mockProvider{name: "provider2"}
@@ -218,6 +244,60 @@ func TestLoadBalancer_NoHealthyProviders(t *testing.T) {
 	}
 }
 
+// erroringProvider fails every request with err
+type erroringProvider struct {
+	name string
+	err  error
+}
+
+func (e *erroringProvider) Name() string {
+	return e.name
+}
+
+func (e *erroringProvider) SupportedAPIs() provider.APIType {
+	return provider.APITypeChatCompletions
+}
+
+func (e *erroringProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	return nil, e.err
+}
+
+func TestLoadBalancer_HealthByErrorKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantHealthy bool
+	}{
+		{name: "rate limited", err: &provider.Error{Kind: provider.ErrorKindRateLimited, StatusCode: 429}, wantHealthy: true},
+		{name: "client error", err: &provider.Error{Kind: provider.ErrorKindUpstream, StatusCode: 400}, wantHealthy: true},
+		{name: "cancelled", err: context.Canceled, wantHealthy: true},
+		{name: "server error", err: &provider.Error{Kind: provider.ErrorKindUpstream, StatusCode: 500}, wantHealthy: false},
+		{name: "timeout", err: &provider.Error{Kind: provider.ErrorKindTimeout, Err: context.DeadlineExceeded}, wantHealthy: false},
+		{name: "network", err: &provider.Error{Kind: provider.ErrorKindNetwork, Err: errors.New("connection refused")}, wantHealthy: false},
+		{name: "unclassified", err: errors.New("mock error"), wantHealthy: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lb, err := New(&Config{
+				Name:      "test-lb",
+				Providers: []provider.Provider{&erroringProvider{name: "provider1", err: tt.err}},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create load balancer: %v", err)
+			}
+			defer lb.Close()
+
+			for i := 0; i < 15; i++ {
+				lb.SendRequest(context.Background(), &provider.Request{})
+			}
+
+			if healthy := lb.GetStats()[0].Healthy; healthy != tt.wantHealthy {
+				t.Errorf("expected healthy=%v, got %v", tt.wantHealthy, healthy)
+			}
+		})
+	}
+}
+
 func TestLoadBalancer_GetStats(t *testing.T) {
 	p1 := &mockProvider{name: "provider1"}
 	p2 := &mockProvider{name: "provider2"}
@@ -254,3 +334,597 @@ func TestLoadBalancer_GetStats(t *testing.T) {
 		t.Errorf("Expected 10 total requests, got %d", totalRequests)
 	}
 }
+
+func TestProviderStats_MarshalJSON(t *testing.T) {
+	stats := ProviderStats{
+		Name:            "provider1",
+		Weight:          2,
+		Healthy:         true,
+		ActiveRequests:  1,
+		TotalRequests:   4,
+		TotalErrors:     1,
+		LastHealthCheck: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("Failed to marshal stats: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal stats: %v", err)
+	}
+
+	expected := map[string]any{
+		"name":              "provider1",
+		"weight":            float64(2),
+		"healthy":           true,
+		"draining":          false,
+		"active_requests":   float64(1),
+		"total_requests":    float64(4),
+		"total_errors":      float64(1),
+		"error_rate":        0.25,
+		"last_health_check": "2026-01-02T03:04:05Z",
+	}
+	if len(decoded) != len(expected) {
+		t.Errorf("Expected %d fields, got %d: %s", len(expected), len(decoded), data)
+	}
+	for key, want := range expected {
+		if got := decoded[key]; got != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, got)
+		}
+	}
+
+	// Zero health check time is omitted
+	data, _ = json.Marshal(ProviderStats{Name: "provider2"})
+	if strings.Contains(string(data), "last_health_check") {
+		t.Errorf("Expected last_health_check to be omitted, got %s", data)
+	}
+}
+
+// recordingStatsRecorder captures recorded provider stats
+type recordingStatsRecorder struct {
+	balancer string
+	stats    []ProviderStats
+}
+
+func (r *recordingStatsRecorder) RecordProviderStats(balancer string, stats ProviderStats) {
+	r.balancer = balancer
+	r.stats = append(r.stats, stats)
+}
+
+func TestLoadBalancer_ReportStats(t *testing.T) {
+	p1 := &mockProvider{name: "provider1"}
+	p2 := &mockProvider{name: "provider2", shouldFail: true}
+
+	lb, err := New(&Config{
+		Name:      "test-lb",
+		Strategy:  RoundRobin,
+		Providers: []provider.Provider{p1, p2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		lb.SendRequest(ctx, &provider.Request{})
+	}
+
+	recorder := &recordingStatsRecorder{}
+	lb.ReportStats(recorder)
+
+	if recorder.balancer != "test-lb" {
+		t.Errorf("Expected balancer name 'test-lb', got %q", recorder.balancer)
+	}
+	if len(recorder.stats) != 2 {
+		t.Fatalf("Expected 2 recorded stats, got %d", len(recorder.stats))
+	}
+	if recorder.stats[1].Name != "provider2" || recorder.stats[1].TotalErrors != 2 {
+		t.Errorf("Expected provider2 with 2 errors, got %+v", recorder.stats[1])
+	}
+}
+
+func TestLoadBalancer_HealthProbeRecovery(t *testing.T) {
+	var upstreamHealthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !upstreamHealthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p1 := &mockProvider{name: "provider1", shouldFail: true}
+	p2 := &mockProvider{name: "provider2"}
+
+	lb, err := New(&Config{
+		Name:            "test-lb",
+		Strategy:        RoundRobin,
+		Providers:       []provider.Provider{p1, p2},
+		HealthCheckURLs: []string{server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	ctx := context.Background()
+	req := &provider.Request{}
+
+	// Generate errors on p1 to mark it unhealthy
+	for i := 0; i < 30; i++ {
+		lb.SendRequest(ctx, req)
+	}
+	if lb.GetStats()[0].Healthy {
+		t.Fatal("Expected provider1 to be unhealthy")
+	}
+
+	// Failing probe keeps it out of rotation
+	lb.checkHealth()
+	if lb.GetStats()[0].Healthy {
+		t.Fatal("Expected provider1 to stay unhealthy while the probe fails")
+	}
+
+	// Upstream recovers
+	p1.shouldFail = false
+	upstreamHealthy.Store(true)
+	lb.checkHealth()
+
+	stats := lb.GetStats()[0]
+	if !stats.Healthy {
+		t.Fatal("Expected provider1 to be healthy after a successful probe")
+	}
+	if stats.TotalErrors == 0 {
+		t.Error("Expected cumulative error count to be preserved")
+	}
+
+	p1InitialCount := p1.callCount
+	for i := 0; i < 4; i++ {
+		if _, err := lb.SendRequest(ctx, req); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+	if p1.callCount-p1InitialCount != 2 {
+		t.Errorf("Expected provider1 to receive 2 requests after recovery, got %d", p1.callCount-p1InitialCount)
+	}
+
+	// A single error after recovery doesn't remove it again
+	p1.shouldFail = true
+	lb.SendRequest(ctx, req)
+	lb.SendRequest(ctx, req)
+	if !lb.GetStats()[0].Healthy {
+		t.Error("Expected provider1 to remain healthy after a fresh error window")
+	}
+}
+
+func TestLoadBalancer_CloseDuringHealthProbe(t *testing.T) {
+	probing := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case probing <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	lb, err := New(&Config{
+		Name:                "test-lb",
+		Providers:           []provider.Provider{&mockProvider{name: "provider1"}},
+		HealthCheckEnabled:  true,
+		HealthCheckInterval: 200 * time.Millisecond,
+		HealthCheckTimeout:  time.Minute,
+		HealthCheckURLs:     []string{server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+
+	select {
+	case <-probing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a health probe")
+	}
+
+	// The probe would run until the 200ms interval without cancellation
+	start := time.Now()
+	lb.Close()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected Close to abort the in-flight probe, took %v", elapsed)
+	}
+	if !lb.GetStats()[0].Healthy {
+		t.Error("Expected an aborted probe not to mark the provider unhealthy")
+	}
+}
+
+func TestLoadBalancer_Draining(t *testing.T) {
+	p1 := &mockProvider{name: "provider1"}
+	p2 := &mockProvider{name: "provider2"}
+
+	lb, err := New(&Config{
+		Name:      "test-lb",
+		Strategy:  RoundRobin,
+		Providers: []provider.Provider{p1, p2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	if err := lb.SetDraining("provider1", true); err != nil {
+		t.Fatalf("Failed to drain provider: %v", err)
+	}
+	if err := lb.SetDraining("unknown", true); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+
+	ctx := context.Background()
+	req := &provider.Request{}
+	for i := 0; i < 5; i++ {
+		if _, err := lb.SendRequest(ctx, req); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+
+	if p1.callCount != 0 {
+		t.Errorf("Expected draining provider to receive no requests, got %d", p1.callCount)
+	}
+	if p2.callCount != 5 {
+		t.Errorf("Expected provider2 to receive 5 requests, got %d", p2.callCount)
+	}
+
+	stats := lb.GetStats()[0]
+	if !stats.Draining || !stats.Healthy {
+		t.Errorf("Expected provider1 to be draining but healthy, got %+v", stats)
+	}
+
+	// Undrain returns it to rotation
+	lb.SetDraining("provider1", false)
+	lb.SendRequest(ctx, req)
+	lb.SendRequest(ctx, req)
+	if p1.callCount == 0 {
+		t.Error("Expected provider1 to receive requests after undraining")
+	}
+}
+
+func TestLoadBalancer_WeightedRandomConcurrent(t *testing.T) {
+	p1 := &countingProvider{name: "provider1"}
+	p2 := &countingProvider{name: "provider2"}
+
+	lb, err := New(&Config{
+		Name:      "test-lb",
+		Strategy:  WeightedRandom,
+		Providers: []provider.Provider{p1, p2},
+		Weights:   []int{3, 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	const (
+		workers    = 50
+		perWorker  = 200
+		totalCalls = workers * perWorker
+	)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				lb.SendRequest(ctx, &provider.Request{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := p1.calls.Load() + p2.calls.Load()
+	if total != totalCalls {
+		t.Fatalf("Expected %d total requests, got %d", totalCalls, total)
+	}
+
+	// p1 should receive ~75% of requests, within a few percent
+	share := float64(p1.calls.Load()) / float64(total)
+	if share < 0.72 || share > 0.78 {
+		t.Errorf("Expected provider1 share ~0.75, got %.3f", share)
+	}
+}
+
+func TestLoadBalancer_WeightedRandomZeroWeights(t *testing.T) {
+	p1 := &mockProvider{name: "provider1"}
+	p2 := &mockProvider{name: "provider2"}
+
+	lb, err := New(&Config{
+		Name:      "test-lb",
+		Strategy:  WeightedRandom,
+		Providers: []provider.Provider{p1, p2},
+		Weights:   []int{0, 0},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		if _, err := lb.SendRequest(ctx, &provider.Request{}); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+	}
+
+	if p1.callCount+p2.callCount != 20 {
+		t.Errorf("Expected 20 total requests, got %d", p1.callCount+p2.callCount)
+	}
+}
+
+func TestLoadBalancer_ErrorThreshold(t *testing.T) {
+	p := &mockProvider{name: "provider1", shouldFail: true}
+	lb, err := New(&Config{
+		Name:           "test-lb",
+		Strategy:       RoundRobin,
+		Providers:      []provider.Provider{p},
+		ErrorThreshold: 3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := lb.SendRequest(ctx, &provider.Request{}); err == nil || err.Error() != "mock error" {
+			t.Fatalf("request %d: expected the provider's error, got %v", i, err)
+		}
+	}
+
+	// The third error took the provider out of rotation
+	if _, err := lb.SendRequest(ctx, &provider.Request{}); err == nil || err.Error() != "no healthy providers available" {
+		t.Errorf("expected no healthy providers after 3 errors, got %v", err)
+	}
+	if p.callCount != 3 {
+		t.Errorf("expected 3 provider calls, got %d", p.callCount)
+	}
+}
+
+func TestLoadBalancer_ErrorCounting(t *testing.T) {
+	tests := []struct {
+		name        string
+		counting    ErrorCounting
+		wantHealthy bool
+	}{
+		{name: "cumulative", counting: CumulativeErrors, wantHealthy: false},
+		{name: "consecutive", counting: ConsecutiveErrors, wantHealthy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &mockProvider{name: "provider1"}
+			lb, err := New(&Config{
+				Name:           "test-lb",
+				Strategy:       RoundRobin,
+				Providers:      []provider.Provider{p},
+				ErrorThreshold: 3,
+				ErrorCounting:  tt.counting,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create load balancer: %v", err)
+			}
+			defer lb.Close()
+
+			// Two errors, a success, then two more errors
+			ctx := context.Background()
+			for _, fail := range []bool{true, true, false, true, true} {
+				p.shouldFail = fail
+				lb.SendRequest(ctx, &provider.Request{})
+			}
+
+			if healthy := lb.GetStats()[0].Healthy; healthy != tt.wantHealthy {
+				t.Errorf("expected healthy=%v, got %v", tt.wantHealthy, healthy)
+			}
+		})
+	}
+}
+
+// delayedProvider answers every request after delay
+type delayedProvider struct {
+	countingProvider
+	delay time.Duration
+}
+
+func (d *delayedProvider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	time.Sleep(d.delay)
+	return d.countingProvider.SendRequest(ctx, req)
+}
+
+func TestLoadBalancer_LeastLatency(t *testing.T) {
+	slow := &delayedProvider{countingProvider: countingProvider{name: "slow"}, delay: 20 * time.Millisecond}
+	fast1 := &delayedProvider{countingProvider: countingProvider{name: "fast1"}}
+	fast2 := &delayedProvider{countingProvider: countingProvider{name: "fast2"}}
+
+	lb, err := New(&Config{
+		Name:      "test-lb",
+		Strategy:  LeastLatency,
+		Providers: []provider.Provider{slow, fast1, fast2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	const requests = 30
+	for i := 0; i < requests; i++ {
+		if _, err := lb.SendRequest(context.Background(), &provider.Request{}); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	// Round robin would send the slow provider a third of the requests
+	if got := slow.calls.Load(); got >= requests/3 {
+		t.Errorf("expected the slow provider to get a minority of requests, got %d of %d", got, requests)
+	}
+	if got := slow.calls.Load() + fast1.calls.Load() + fast2.calls.Load(); got != requests {
+		t.Errorf("expected %d requests in total, got %d", requests, got)
+	}
+	if lb.providers[0].LatencyEWMA < int64(slow.delay) {
+		t.Errorf("expected the slow provider's latency EWMA to reflect its delay, got %v", time.Duration(lb.providers[0].LatencyEWMA))
+	}
+}
+
+func TestLoadBalancer_Sticky(t *testing.T) {
+	providers := make([]*countingProvider, 4)
+	config := &Config{Name: "test-lb", Strategy: Sticky}
+	for i := range providers {
+		providers[i] = &countingProvider{name: fmt.Sprintf("provider%d", i)}
+		config.Providers = append(config.Providers, providers[i])
+	}
+	lb, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+
+	// selected sends a request for tenant and returns the provider that served it
+	selected := func(tenant string) int {
+		before := make([]int64, len(providers))
+		for i, p := range providers {
+			before[i] = p.calls.Load()
+		}
+		ctx := context.WithValue(context.Background(), "tenant_id", tenant)
+		if _, err := lb.SendRequest(ctx, &provider.Request{}); err != nil {
+			t.Fatalf("request for %s failed: %v", tenant, err)
+		}
+		for i, p := range providers {
+			if p.calls.Load() != before[i] {
+				return i
+			}
+		}
+		t.Fatalf("request for %s reached no provider", tenant)
+		return -1
+	}
+
+	// The same tenant always hits the same provider
+	first := selected("acme")
+	for i := 0; i < 10; i++ {
+		if got := selected("acme"); got != first {
+			t.Fatalf("request %d: expected provider%d, got provider%d", i, first, got)
+		}
+	}
+
+	// Different tenants spread out
+	used := make(map[int]bool)
+	for i := 0; i < 20; i++ {
+		used[selected(fmt.Sprintf("tenant-%d", i))] = true
+	}
+	if len(used) < 2 {
+		t.Errorf("expected tenants to spread over providers, all used %v", used)
+	}
+
+	// An unhealthy provider's tenants move to the next one and come back
+	lb.providers[first].Healthy = false
+	fallback := selected("acme")
+	if fallback != (first+1)%len(providers) {
+		t.Errorf("expected fallback to provider%d, got provider%d", (first+1)%len(providers), fallback)
+	}
+	if got := selected("acme"); got != fallback {
+		t.Errorf("expected the fallback to be stable, got provider%d", got)
+	}
+	lb.providers[first].Healthy = true
+	if got := selected("acme"); got != first {
+		t.Errorf("expected tenant to return to provider%d, got provider%d", first, got)
+	}
+}
+
+func TestLoadBalancer_SingleChoice(t *testing.T) {
+	multi := &mockProvider{name: "multi"}
+	single := provider.NewHTTPProvider(provider.NewProviderConfig("single").WithSingleChoice())
+
+	lb, err := New(&Config{Name: "mixed", Providers: []provider.Provider{multi, single}})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+	if !provider.IsSingleChoice(lb) {
+		t.Error("expected a group with a single-choice member to be single-choice")
+	}
+
+	lb, err = New(&Config{Name: "multi", Providers: []provider.Provider{multi}})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+	if provider.IsSingleChoice(lb) {
+		t.Error("expected a group of multi-choice members to serve n > 1")
+	}
+}
+
+func TestLoadBalancer_SupportsTools(t *testing.T) {
+	withTools := &mockProvider{name: "tools"}
+	withoutTools := provider.NewHTTPProvider(provider.NewProviderConfig("plain").WithoutTools())
+
+	lb, err := New(&Config{Name: "mixed", Providers: []provider.Provider{withTools, withoutTools}})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+	if provider.SupportsTools(lb) {
+		t.Error("expected a group with a member lacking tools not to support them")
+	}
+
+	lb, err = New(&Config{Name: "tools", Providers: []provider.Provider{withTools}})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+	if !provider.SupportsTools(lb) {
+		t.Error("expected a group whose members all support tools to support them")
+	}
+}
+
+func TestLoadBalancer_CooldownTrial(t *testing.T) {
+	p := &mockProvider{name: "provider1", shouldFail: true}
+	lb, err := New(&Config{
+		Name:           "test-lb",
+		Providers:      []provider.Provider{p},
+		ErrorThreshold: 1,
+		Cooldown:       20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create load balancer: %v", err)
+	}
+	defer lb.Close()
+	ctx := context.Background()
+
+	lb.SendRequest(ctx, &provider.Request{})
+	if _, err := lb.SendRequest(ctx, &provider.Request{}); err == nil || p.callCount != 1 {
+		t.Fatalf("expected the provider out of rotation, got err=%v after %d calls", err, p.callCount)
+	}
+
+	// A failed trial starts another cooldown
+	time.Sleep(30 * time.Millisecond)
+	lb.SendRequest(ctx, &provider.Request{})
+	if p.callCount != 2 {
+		t.Fatalf("expected a trial request after the cooldown, got %d calls", p.callCount)
+	}
+	if _, err := lb.SendRequest(ctx, &provider.Request{}); err == nil || p.callCount != 2 {
+		t.Fatalf("expected a failed trial to keep the provider out, got err=%v after %d calls", err, p.callCount)
+	}
+
+	// A successful trial returns it to rotation
+	time.Sleep(30 * time.Millisecond)
+	p.shouldFail = false
+	if _, err := lb.SendRequest(ctx, &provider.Request{}); err != nil {
+		t.Fatalf("expected the trial request to succeed, got %v", err)
+	}
+	if !lb.GetStats()[0].Healthy {
+		t.Error("expected the provider back in rotation after a successful trial")
+	}
+	if _, err := lb.SendRequest(ctx, &provider.Request{}); err != nil {
+		t.Errorf("expected requests to reach the recovered provider, got %v", err)
+	}
+}
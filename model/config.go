@@ -0,0 +1,149 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// RegistryConfig describes providers and the models they serve. It is read
+// from YAML or JSON by LoadRegistryFromFile.
+type RegistryConfig struct {
+	Providers []ProviderEntry `yaml:"providers"`
+	Models    []ModelEntry    `yaml:"models"`
+}
+
+// ProviderEntry configures an HTTP provider
+type ProviderEntry struct {
+	Name     string `yaml:"name"`
+	BaseURL  string `yaml:"base_url"`
+	BasePath string `yaml:"base_path"`
+	// APIKeyEnv names the environment variable holding the API key. It
+	// must be set if given; leave it out for upstreams without a key.
+	APIKeyEnv string `yaml:"api_key_env"`
+	// SupportedAPIs lists API type names such as "chat_completions" or "all"
+	// (default: all)
+	SupportedAPIs  []string      `yaml:"supported_apis"`
+	Timeout        time.Duration `yaml:"timeout"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	ReadTimeout    time.Duration `yaml:"read_timeout"`
+}
+
+// ModelEntry maps a model name, or a '*' pattern, to a provider
+type ModelEntry struct {
+	Name         string `yaml:"name"`
+	Provider     string `yaml:"provider"`
+	Rewrite      string `yaml:"rewrite"`
+	PreferredAPI string `yaml:"preferred_api"`
+}
+
+// LoadRegistryFromFile reads a RegistryConfig from the YAML or JSON file at
+// path and returns a registry of HTTP providers built from it
+func LoadRegistryFromFile(path string) (*MapModelRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read registry config: %w", err)
+	}
+	var config RegistryConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse registry config %s: %w", path, err)
+	}
+	registry, err := NewRegistryFromConfig(&config)
+	if err != nil {
+		return nil, fmt.Errorf("registry config %s: %w", path, err)
+	}
+	return registry, nil
+}
+
+// NewRegistryFromConfig builds a registry of HTTP providers from config. Unknown
+// provider references and duplicate names are errors.
+func NewRegistryFromConfig(config *RegistryConfig) (*MapModelRegistry, error) {
+	providers := make(map[string]provider.Provider, len(config.Providers))
+	for i, entry := range config.Providers {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("providers[%d]: name is required", i)
+		}
+		if _, ok := providers[entry.Name]; ok {
+			return nil, fmt.Errorf("duplicate provider %q", entry.Name)
+		}
+		prov, err := entry.newProvider()
+		if err != nil {
+			return nil, fmt.Errorf("provider %q: %w", entry.Name, err)
+		}
+		providers[entry.Name] = prov
+	}
+
+	registry := NewMapModelRegistry()
+	seen := make(map[string]bool, len(config.Models))
+	for i, entry := range config.Models {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("models[%d]: name is required", i)
+		}
+		if seen[entry.Name] {
+			return nil, fmt.Errorf("duplicate model %q", entry.Name)
+		}
+		seen[entry.Name] = true
+
+		prov, ok := providers[entry.Provider]
+		if !ok {
+			return nil, fmt.Errorf("model %q: unknown provider %q", entry.Name, entry.Provider)
+		}
+		opts := []RegisterOption{WithModelRewrite(entry.Rewrite)}
+		if entry.PreferredAPI != "" {
+			apiType, err := provider.ParseAPIType(entry.PreferredAPI)
+			if err != nil {
+				return nil, fmt.Errorf("model %q: preferred_api: %w", entry.Name, err)
+			}
+			opts = append(opts, WithPreferredAPI(apiType))
+		}
+		registry.RegisterWithOptions(entry.Name, prov, opts...)
+	}
+	return registry, nil
+}
+
+// newProvider builds the HTTP provider described by e
+func (e *ProviderEntry) newProvider() (provider.Provider, error) {
+	if e.BaseURL == "" {
+		return nil, errors.New("base_url is required")
+	}
+
+	apiKey := ""
+	if e.APIKeyEnv != "" {
+		apiKey = os.Getenv(e.APIKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", e.APIKeyEnv)
+		}
+	}
+
+	apis := provider.APITypeAll
+	if len(e.SupportedAPIs) > 0 {
+		apis = 0
+		for _, name := range e.SupportedAPIs {
+			apiType, err := provider.ParseAPIType(name)
+			if err != nil {
+				return nil, fmt.Errorf("supported_apis: %w", err)
+			}
+			apis |= apiType
+		}
+	}
+
+	config := provider.NewProviderConfig(e.Name).
+		WithBaseURL(e.BaseURL).
+		WithBasePath(e.BasePath).
+		WithAPIKey(apiKey).
+		WithAPIType(apis)
+	if e.Timeout > 0 {
+		config.Timeout = e.Timeout
+	}
+	if e.ConnectTimeout > 0 {
+		config.ConnectTimeout = e.ConnectTimeout
+	}
+	if e.ReadTimeout > 0 {
+		config.ReadTimeout = e.ReadTimeout
+	}
+	return provider.NewHTTPProvider(config), nil
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+const sampleRegistryConfig = `
+providers:
+  - name: openai
+    base_url: https://api.openai.com/v1
+    api_key_env: TEST_OPENAI_API_KEY
+    supported_apis: [chat_completions, embeddings]
+    timeout: 90s
+  - name: local
+    base_url: http://localhost:8000/v1
+    base_path: /v1
+
+models:
+  - name: gpt-4o
+    provider: openai
+  - name: text-embedding-3-small
+    provider: openai
+    preferred_api: embeddings
+  - name: llama
+    provider: local
+    rewrite: meta-llama/Llama-3.1-8B-Instruct
+  - name: "ft:llama:*"
+    provider: local
+`
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadRegistryFromFile(t *testing.T) {
+	t.Setenv("TEST_OPENAI_API_KEY", "sk-test")
+
+	registry, err := LoadRegistryFromFile(writeConfig(t, "models.yaml", sampleRegistryConfig))
+	if err != nil {
+		t.Fatalf("LoadRegistryFromFile failed: %v", err)
+	}
+
+	tests := []struct {
+		model    string
+		provider string
+		rewrite  string
+		apiType  provider.APIType
+	}{
+		{model: "gpt-4o", provider: "openai", apiType: provider.APITypeChatCompletions | provider.APITypeEmbeddings},
+		{model: "text-embedding-3-small", provider: "openai", apiType: provider.APITypeEmbeddings},
+		{model: "llama", provider: "local", rewrite: "meta-llama/Llama-3.1-8B-Instruct", apiType: provider.APITypeAll},
+		{model: "ft:llama:acme", provider: "local", apiType: provider.APITypeAll},
+	}
+	for _, tt := range tests {
+		p, rewrite, apiType := registry.ResolveWithAPI(tt.model)
+		if p == nil {
+			t.Errorf("%s: expected a provider", tt.model)
+			continue
+		}
+		if p.Name() != tt.provider || rewrite != tt.rewrite || apiType != tt.apiType {
+			t.Errorf("%s: got %s, %q, %v; want %s, %q, %v", tt.model, p.Name(), rewrite, apiType, tt.provider, tt.rewrite, tt.apiType)
+		}
+	}
+
+	if p, _ := registry.Resolve("unknown"); p != nil {
+		t.Errorf("expected no provider for an unconfigured model, got %s", p.Name())
+	}
+}
+
+func TestLoadRegistryFromFile_JSON(t *testing.T) {
+	path := writeConfig(t, "models.json", `{
+		"providers": [{"name": "local", "base_url": "http://localhost:8000/v1", "supported_apis": ["chat_completions"]}],
+		"models": [{"name": "llama", "provider": "local"}]
+	}`)
+
+	registry, err := LoadRegistryFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadRegistryFromFile failed: %v", err)
+	}
+	if p, _ := registry.Resolve("llama"); p == nil || p.Name() != "local" {
+		t.Errorf("expected llama to resolve to local, got %v", p)
+	}
+}
+
+func TestLoadRegistryFromFile_Errors(t *testing.T) {
+	const local = "providers:\n  - name: local\n    base_url: http://localhost:8000/v1\n"
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "unknown provider",
+			config: local + "models:\n  - name: gpt-4o\n    provider: openai\n",
+			want:   `model "gpt-4o": unknown provider "openai"`,
+		},
+		{
+			name:   "duplicate model",
+			config: local + "models:\n  - name: llama\n    provider: local\n  - name: llama\n    provider: local\n",
+			want:   `duplicate model "llama"`,
+		},
+		{
+			name:   "duplicate provider",
+			config: local + "  - name: local\n    base_url: http://localhost:9000/v1\n",
+			want:   `duplicate provider "local"`,
+		},
+		{
+			name:   "unset api key",
+			config: "providers:\n  - name: openai\n    base_url: https://api.openai.com/v1\n    api_key_env: TEST_UNSET_API_KEY\n",
+			want:   "environment variable TEST_UNSET_API_KEY is not set",
+		},
+		{
+			name:   "unknown API type",
+			config: local + "models:\n  - name: llama\n    provider: local\n    preferred_api: chat\n",
+			want:   `unknown API type "chat"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRegistryFromFile(writeConfig(t, "models.yaml", tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
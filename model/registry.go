@@ -3,8 +3,11 @@ package model
 import (
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 
+	"github.com/deeplooplabs/ai-gateway/loadbalancer"
 	"github.com/deeplooplabs/ai-gateway/provider"
 )
 
@@ -25,10 +28,36 @@ type ModelRegistry interface {
 	ListModels() []string
 }
 
-// MapModelRegistry is an in-memory model registry
+// MapModelRegistry is an in-memory model registry. Model names containing
+// '*' are registered as patterns, e.g. "ft:gpt-4o:*", where '*' matches any
+// run of characters. Exact names take precedence over patterns, and longer
+// patterns over shorter ones.
 type MapModelRegistry struct {
-	mu     sync.RWMutex
-	models map[string]ProviderRewrite
+	mu       sync.RWMutex
+	models   map[string]ProviderRewrite
+	patterns []modelPattern // Longest first
+	fallback *ProviderRewrite
+}
+
+// Defaulter is implemented by registries that can resolve unknown models to a
+// default provider
+type Defaulter interface {
+	// SetDefault sets the provider and optional model rewrite returned for
+	// models without a registration. A nil provider removes the default.
+	SetDefault(prov provider.Provider, modelRewrite string)
+}
+
+// ProviderLister is implemented by registries that can list every provider
+// they resolve to, including those registered under patterns or as the default
+type ProviderLister interface {
+	// Providers returns each distinct registered provider
+	Providers() []provider.Provider
+}
+
+// modelPattern is a registered model name pattern
+type modelPattern struct {
+	pattern string
+	ProviderRewrite
 }
 
 // NewMapModelRegistry creates a new map-based model registry
@@ -63,11 +92,11 @@ func (r *MapModelRegistry) Register(model string, prov provider.Provider) {
 	if prov != nil {
 		providerName = prov.Name()
 	}
-	r.models[model] = ProviderRewrite{
+	r.set(model, ProviderRewrite{
 		Provider:     prov,
 		ModelRewrite: "",
 		PreferredAPI: 0, // Auto-detect from provider
-	}
+	})
 	logModelRegistration(model, "", providerName, prov.SupportedAPIs())
 }
 
@@ -87,10 +116,121 @@ func (r *MapModelRegistry) RegisterWithOptions(model string, prov provider.Provi
 	for _, opt := range opts {
 		opt(&pr)
 	}
-	r.models[model] = pr
+	r.set(model, pr)
 	logModelRegistration(model, pr.ModelRewrite, providerName, pr.PreferredAPI)
 }
 
+// set stores pr as the registration of model, or of the pattern model is.
+// The caller holds the lock.
+func (r *MapModelRegistry) set(model string, pr ProviderRewrite) {
+	if !strings.Contains(model, "*") {
+		r.models[model] = pr
+		return
+	}
+
+	r.patterns = slices.DeleteFunc(r.patterns, func(p modelPattern) bool { return p.pattern == model })
+	r.patterns = append(r.patterns, modelPattern{pattern: model, ProviderRewrite: pr})
+	slices.SortStableFunc(r.patterns, func(a, b modelPattern) int { return len(b.pattern) - len(a.pattern) })
+}
+
+// SetDefault sets the provider and optional model rewrite that models matching
+// no name or pattern resolve to. Without a default they resolve to nil. A nil
+// provider removes the default.
+func (r *MapModelRegistry) SetDefault(prov provider.Provider, modelRewrite string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prov == nil {
+		r.fallback = nil
+		return
+	}
+	r.fallback = &ProviderRewrite{Provider: prov, ModelRewrite: modelRewrite}
+	logModelRegistration("(default)", modelRewrite, prov.Name(), prov.SupportedAPIs())
+}
+
+// lookup returns the registration of model: its exact match, or else the
+// longest matching pattern, or else the default
+func (r *MapModelRegistry) lookup(model string) (ProviderRewrite, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if pr, ok := r.models[model]; ok {
+		return pr, true
+	}
+	for _, p := range r.patterns {
+		if matchPattern(p.pattern, model) {
+			return p.ProviderRewrite, true
+		}
+	}
+	if r.fallback != nil {
+		return *r.fallback, true
+	}
+	return ProviderRewrite{}, false
+}
+
+// matchPattern reports whether name matches pattern, in which '*' matches
+// any run of characters
+func matchPattern(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, parts[last])
+}
+
+// RegisterGroup registers a model served by several providers behind a load
+// balancer built from config (loadbalancer.DefaultConfig if nil), which is
+// named after the model unless config names it. The model resolves to the
+// balancer, whose supported APIs are the union of its members'. Registering
+// a group again closes the balancer it replaces, unless another model still
+// uses it.
+func (r *MapModelRegistry) RegisterGroup(model string, providers []provider.Provider, config *loadbalancer.Config, opts ...RegisterOption) error {
+	if config == nil {
+		config = loadbalancer.DefaultConfig(model)
+	}
+	groupConfig := *config
+	groupConfig.Providers = providers
+	if groupConfig.Name == "" {
+		groupConfig.Name = model
+	}
+
+	lb, err := loadbalancer.New(&groupConfig)
+	if err != nil {
+		return fmt.Errorf("model %s: %w", model, err)
+	}
+	previous, _ := r.registration(model)
+	r.RegisterWithOptions(model, lb, opts...)
+
+	if old, ok := previous.(*loadbalancer.LoadBalancedProvider); ok && !slices.Contains(r.Providers(), provider.Provider(old)) {
+		old.Close()
+	}
+	return nil
+}
+
+// registration returns the provider registered under model, which may be a
+// pattern, ignoring other patterns and the default
+func (r *MapModelRegistry) registration(model string) (provider.Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !strings.Contains(model, "*") {
+		pr, ok := r.models[model]
+		return pr.Provider, ok
+	}
+	for _, p := range r.patterns {
+		if p.pattern == model {
+			return p.Provider, true
+		}
+	}
+	return nil, false
+}
+
 // logModelRegistration logs the model registration with type information
 func logModelRegistration(model, modelRewrite, providerName string, apiType provider.APIType) {
 	apiTypeStr := formatAPIType(apiType)
@@ -112,31 +252,15 @@ func logModelRegistration(model, modelRewrite, providerName string, apiType prov
 
 // formatAPIType formats the API type into a human-readable string
 func formatAPIType(apiType provider.APIType) string {
-	switch {
-	case apiType == 0:
+	if apiType == 0 {
 		return "auto"
-	case apiType.Supports(provider.APITypeChatCompletions) && apiType.Supports(provider.APITypeEmbeddings):
-		return "chat+embeddings"
-	case apiType.Supports(provider.APITypeChatCompletions) && apiType.Supports(provider.APITypeResponses):
-		return "chat+responses"
-	case apiType.Supports(provider.APITypeChatCompletions):
-		return "chat"
-	case apiType.Supports(provider.APITypeEmbeddings):
-		return "embedding"
-	case apiType.Supports(provider.APITypeImages):
-		return "image"
-	case apiType.Supports(provider.APITypeResponses):
-		return "response"
-	default:
-		return fmt.Sprintf("unknown(%d)", apiType)
 	}
+	return apiType.String()
 }
 
 // Resolve returns the provider and model rewrite for a given model name
 func (r *MapModelRegistry) Resolve(model string) (provider.Provider, string) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	if pr, ok := r.models[model]; ok {
+	if pr, ok := r.lookup(model); ok {
 		return pr.Provider, pr.ModelRewrite
 	}
 	return nil, ""
@@ -144,9 +268,7 @@ func (r *MapModelRegistry) Resolve(model string) (provider.Provider, string) {
 
 // ResolveWithAPI returns the provider, model rewrite, and preferred API type for a given model name
 func (r *MapModelRegistry) ResolveWithAPI(model string) (provider.Provider, string, provider.APIType) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	if pr, ok := r.models[model]; ok {
+	if pr, ok := r.lookup(model); ok {
 		apiType := pr.PreferredAPI
 		if apiType == 0 {
 			// Auto-detect from provider
@@ -157,7 +279,33 @@ func (r *MapModelRegistry) ResolveWithAPI(model string) (provider.Provider, stri
 	return nil, "", 0
 }
 
-// ListModels returns a list of all registered model names
+// Providers returns each distinct provider registered under a name or a
+// pattern, or as the default
+func (r *MapModelRegistry) Providers() []provider.Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[provider.Provider]bool)
+	var providers []provider.Provider
+	add := func(prov provider.Provider) {
+		if prov != nil && !seen[prov] {
+			seen[prov] = true
+			providers = append(providers, prov)
+		}
+	}
+	for _, pr := range r.models {
+		add(pr.Provider)
+	}
+	for _, p := range r.patterns {
+		add(p.Provider)
+	}
+	if r.fallback != nil {
+		add(r.fallback.Provider)
+	}
+	return providers
+}
+
+// ListModels returns a list of all registered model names. Patterns aren't listed.
 func (r *MapModelRegistry) ListModels() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -2,8 +2,13 @@ package model
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/deeplooplabs/ai-gateway/loadbalancer"
 	"github.com/deeplooplabs/ai-gateway/provider"
 )
 
@@ -102,3 +107,208 @@ func TestMapModelRegistry_ResolveWithAPI(t *testing.T) {
 		t.Errorf("expected APITypeResponses, got '%v'", apiType)
 	}
 }
+
+func TestMapModelRegistry_Patterns(t *testing.T) {
+	registry := NewMapModelRegistry()
+	registry.Register("ft:*", &mockProvider{name: "any-finetune"})
+	registry.Register("ft:gpt-4o:*", &mockProvider{name: "gpt-4o-finetune"})
+	registry.Register("ft:gpt-4o:acme:*", &mockProvider{name: "acme"})
+	registry.Register("ft:gpt-4o:acme:special", &mockProvider{name: "special"})
+	registry.RegisterWithOptions("*-preview", &mockProvider{name: "preview"}, WithModelRewrite("gpt-4o"))
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"ft:gpt-4o:acme:special", "special"}, // exact match beats every pattern
+		{"ft:gpt-4o:acme:abc123", "acme"},     // longest matching pattern wins
+		{"ft:gpt-4o:other:abc123", "gpt-4o-finetune"},
+		{"ft:gpt-3.5-turbo:acme:abc123", "any-finetune"},
+		{"o1-preview", "preview"},
+		{"gpt-4o", ""},
+	}
+	for _, tt := range tests {
+		p, _ := registry.Resolve(tt.model)
+		got := ""
+		if p != nil {
+			got = p.Name()
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+
+	if _, rewrite, _ := registry.ResolveWithAPI("o1-preview"); rewrite != "gpt-4o" {
+		t.Errorf("expected a pattern's rewrite to apply, got %q", rewrite)
+	}
+
+	// Registering a pattern again replaces it
+	registry.Register("ft:*", &mockProvider{name: "replaced"})
+	if p, _ := registry.Resolve("ft:babbage:x"); p == nil || p.Name() != "replaced" {
+		t.Errorf("expected the re-registered pattern, got %v", p)
+	}
+
+	if models := registry.ListModels(); len(models) != 1 || models[0] != "ft:gpt-4o:acme:special" {
+		t.Errorf("expected patterns not to be listed, got %v", models)
+	}
+}
+
+func TestMapModelRegistry_SetDefault(t *testing.T) {
+	registry := NewMapModelRegistry()
+	registry.Register("gpt-4", &mockProvider{name: "openai"})
+	registry.Register("claude-*", &mockProvider{name: "anthropic"})
+
+	if p, _ := registry.Resolve("unknown"); p != nil {
+		t.Fatalf("expected no provider without a default, got %s", p.Name())
+	}
+
+	registry.SetDefault(&mockProvider{name: "fallback"}, "gpt-4o-mini")
+	p, rewrite, apiType := registry.ResolveWithAPI("unknown")
+	if p == nil || p.Name() != "fallback" || rewrite != "gpt-4o-mini" {
+		t.Fatalf("expected the default with its rewrite, got %v and %q", p, rewrite)
+	}
+	if apiType != provider.APITypeChatCompletions {
+		t.Errorf("expected the default's supported APIs, got %v", apiType)
+	}
+
+	// Names and patterns take precedence over the default
+	if p, _ := registry.Resolve("gpt-4"); p.Name() != "openai" {
+		t.Errorf("expected the registered model, got %s", p.Name())
+	}
+	if p, _ := registry.Resolve("claude-3"); p.Name() != "anthropic" {
+		t.Errorf("expected the matching pattern, got %s", p.Name())
+	}
+
+	registry.SetDefault(nil, "")
+	if p, _ := registry.Resolve("unknown"); p != nil {
+		t.Errorf("expected the default to be removed, got %s", p.Name())
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"ft:*", "ft:", true},
+		{"ft:*", "ft:gpt-4o", true},
+		{"ft:*", "gpt-4o", false},
+		{"*-preview", "o1-preview", true},
+		{"gpt-*-mini*", "gpt-4o-mini-2024", true},
+		{"gpt-*-mini*", "gpt-4o", false},
+		{"a*b*b", "ab", false},
+		{"a*b*b", "abb", true},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+// groupMember counts the requests it receives
+type groupMember struct {
+	mockProvider
+	apis  provider.APIType
+	calls int
+}
+
+func (m *groupMember) SupportedAPIs() provider.APIType {
+	return m.apis
+}
+
+func (m *groupMember) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	m.calls++
+	return nil, nil
+}
+
+func TestMapModelRegistry_RegisterGroup(t *testing.T) {
+	registry := NewMapModelRegistry()
+	members := []*groupMember{
+		{mockProvider: mockProvider{name: "key1"}, apis: provider.APITypeChatCompletions},
+		{mockProvider: mockProvider{name: "key2"}, apis: provider.APITypeChatCompletions},
+		{mockProvider: mockProvider{name: "key3"}, apis: provider.APITypeChatCompletions | provider.APITypeEmbeddings},
+	}
+	if err := registry.RegisterGroup("gpt-4o", []provider.Provider{members[0], members[1], members[2]}, nil); err != nil {
+		t.Fatalf("RegisterGroup failed: %v", err)
+	}
+
+	p, _, apiType := registry.ResolveWithAPI("gpt-4o")
+	if p == nil || p.Name() != "gpt-4o" {
+		t.Fatalf("expected the group's balancer, got %v", p)
+	}
+	if apiType != provider.APITypeChatCompletions|provider.APITypeEmbeddings {
+		t.Errorf("expected the union of the members' APIs, got %v", apiType)
+	}
+
+	for range 6 {
+		p.SendRequest(context.Background(), provider.NewChatCompletionsRequest("gpt-4o", nil))
+	}
+	for _, m := range members {
+		if m.calls != 2 {
+			t.Errorf("expected %s to serve 2 requests, got %d", m.name, m.calls)
+		}
+	}
+
+	// Only members supporting a request's API are sent it
+	p.SendRequest(context.Background(), provider.NewEmbeddingsRequest("gpt-4o", "Hello"))
+	if members[2].calls != 3 {
+		t.Errorf("expected the embeddings request to reach key3, got %d calls", members[2].calls)
+	}
+
+	if err := registry.RegisterGroup("empty", nil, nil); err == nil {
+		t.Error("expected an error for a group without providers")
+	}
+}
+
+func TestMapModelRegistry_RegisterGroupClosesReplaced(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+	}))
+	defer server.Close()
+
+	registry := NewMapModelRegistry()
+	members := []provider.Provider{&mockProvider{name: "key1"}}
+	config := loadbalancer.DefaultConfig("gpt-4o")
+	config.HealthCheckEnabled = true
+	config.HealthCheckInterval = 5 * time.Millisecond
+	config.HealthCheckURLs = []string{server.URL}
+	if err := registry.RegisterGroup("gpt-4o", members, config); err != nil {
+		t.Fatalf("RegisterGroup failed: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for probes.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if probes.Load() == 0 {
+		t.Fatal("expected the balancer to probe its member")
+	}
+
+	// Replacing the group stops the old balancer's health checks
+	if err := registry.RegisterGroup("gpt-4o", members, nil); err != nil {
+		t.Fatalf("RegisterGroup failed: %v", err)
+	}
+	seen := probes.Load()
+	time.Sleep(30 * time.Millisecond)
+	if got := probes.Load(); got != seen {
+		t.Errorf("expected the replaced balancer to be closed, got %d more probes", got-seen)
+	}
+}
+
+func TestFormatAPIType(t *testing.T) {
+	tests := []struct {
+		apiType provider.APIType
+		want    string
+	}{
+		{0, "auto"},
+		{provider.APITypeChatCompletions, "chat_completions"},
+		{provider.APITypeChatCompletions | provider.APITypeEmbeddings, "chat_completions|embeddings"},
+		{provider.APITypeAll, "all"},
+	}
+	for _, tt := range tests {
+		if got := formatAPIType(tt.apiType); got != tt.want {
+			t.Errorf("formatAPIType(%d) = %q, want %q", int(tt.apiType), got, tt.want)
+		}
+	}
+}
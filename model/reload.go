@@ -0,0 +1,158 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// Reloadable is implemented by registries whose models come from an external
+// source, such as a watched file, and can be re-read on demand
+type Reloadable interface {
+	// Reload replaces the registered models with the source's current ones
+	Reload(ctx context.Context) error
+}
+
+// ReloadableRegistry serves models from a MapModelRegistry snapshot that can
+// be replaced at runtime. Lookups read the current snapshot through an atomic
+// pointer, so they always see one complete registry, never a mix of the old
+// and new models or an empty one. Providers a swap leaves unreferenced are
+// closed.
+type ReloadableRegistry struct {
+	current  atomic.Pointer[MapModelRegistry]
+	load     func(ctx context.Context) (*MapModelRegistry, error)
+	fallback atomic.Pointer[ProviderRewrite] // Kept across swaps
+}
+
+// NewReloadableRegistry creates a registry serving initial. Reload builds the
+// next snapshot with load, which may be nil if snapshots are only swapped in.
+func NewReloadableRegistry(initial *MapModelRegistry, load func(ctx context.Context) (*MapModelRegistry, error)) *ReloadableRegistry {
+	if initial == nil {
+		initial = NewMapModelRegistry()
+	}
+	r := &ReloadableRegistry{load: load}
+	r.current.Store(initial)
+	return r
+}
+
+// NewFileRegistry loads the registry config file at path, as
+// LoadRegistryFromFile does, and re-reads it on every Reload. A config that
+// fails to load leaves the current models in place.
+func NewFileRegistry(path string) (*ReloadableRegistry, error) {
+	initial, err := LoadRegistryFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReloadableRegistry(initial, func(ctx context.Context) (*MapModelRegistry, error) {
+		return LoadRegistryFromFile(path)
+	}), nil
+}
+
+// Current returns the snapshot serving lookups
+func (r *ReloadableRegistry) Current() *MapModelRegistry {
+	return r.current.Load()
+}
+
+// Swap atomically replaces the served models with registry. Lookups in
+// flight finish against the previous snapshot. Providers of the previous
+// snapshot that registry and the default don't use are then closed if they
+// implement io.Closer, which stops load balancer health checks and releases
+// idle connections without failing requests in flight.
+func (r *ReloadableRegistry) Swap(registry *MapModelRegistry) {
+	if registry == nil {
+		registry = NewMapModelRegistry()
+	}
+	previous := r.current.Swap(registry)
+	if previous != nil && previous != registry {
+		r.closeUnreferenced(previous.Providers(), registry)
+	}
+}
+
+// closeUnreferenced closes the providers that neither registry nor the
+// default use
+func (r *ReloadableRegistry) closeUnreferenced(providers []provider.Provider, registry *MapModelRegistry) {
+	kept := make(map[provider.Provider]bool)
+	for _, prov := range registry.Providers() {
+		kept[prov] = true
+	}
+	if fallback := r.fallback.Load(); fallback != nil {
+		kept[fallback.Provider] = true
+	}
+	for _, prov := range providers {
+		closer, ok := prov.(io.Closer)
+		if !ok || kept[prov] {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			slog.Warn("Failed to close replaced provider", "provider", prov.Name(), "error", err)
+		}
+	}
+}
+
+// Reload builds a new snapshot with the registry's loader and swaps it in
+func (r *ReloadableRegistry) Reload(ctx context.Context) error {
+	if r.load == nil {
+		return errors.New("registry has no loader")
+	}
+	registry, err := r.load(ctx)
+	if err != nil {
+		return err
+	}
+	r.Swap(registry)
+	return nil
+}
+
+// SetDefault sets the provider and optional model rewrite that models the
+// current snapshot doesn't resolve fall back to. Unlike a snapshot's own
+// default, it survives swaps. A nil provider removes the default.
+func (r *ReloadableRegistry) SetDefault(prov provider.Provider, modelRewrite string) {
+	if prov == nil {
+		r.fallback.Store(nil)
+		return
+	}
+	r.fallback.Store(&ProviderRewrite{Provider: prov, ModelRewrite: modelRewrite})
+	logModelRegistration("(default)", modelRewrite, prov.Name(), prov.SupportedAPIs())
+}
+
+// Resolve returns the provider and model rewrite from the current snapshot,
+// or the default
+func (r *ReloadableRegistry) Resolve(model string) (provider.Provider, string) {
+	if prov, rewrite := r.Current().Resolve(model); prov != nil {
+		return prov, rewrite
+	}
+	if fallback := r.fallback.Load(); fallback != nil {
+		return fallback.Provider, fallback.ModelRewrite
+	}
+	return nil, ""
+}
+
+// ResolveWithAPI returns the provider, model rewrite and API type from the
+// current snapshot, or the default
+func (r *ReloadableRegistry) ResolveWithAPI(model string) (provider.Provider, string, provider.APIType) {
+	if prov, rewrite, apiType := r.Current().ResolveWithAPI(model); prov != nil {
+		return prov, rewrite, apiType
+	}
+	if fallback := r.fallback.Load(); fallback != nil {
+		return fallback.Provider, fallback.ModelRewrite, fallback.Provider.SupportedAPIs()
+	}
+	return nil, "", 0
+}
+
+// Providers returns the providers of the current snapshot and the default
+func (r *ReloadableRegistry) Providers() []provider.Provider {
+	providers := r.Current().Providers()
+	if fallback := r.fallback.Load(); fallback != nil && !slices.Contains(providers, fallback.Provider) {
+		providers = append(providers, fallback.Provider)
+	}
+	return providers
+}
+
+// ListModels returns the models of the current snapshot
+func (r *ReloadableRegistry) ListModels() []string {
+	return r.Current().ListModels()
+}
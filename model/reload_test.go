@@ -0,0 +1,164 @@
+package model
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+var _ Reloadable = (*ReloadableRegistry)(nil)
+
+func snapshotOf(name string) *MapModelRegistry {
+	registry := NewMapModelRegistry()
+	prov := &mockProvider{name: name}
+	registry.Register("gpt-4o", prov)
+	registry.Register("claude-3", prov)
+	return registry
+}
+
+func TestReloadableRegistry_ConcurrentSwap(t *testing.T) {
+	registry := NewReloadableRegistry(snapshotOf("a"), nil)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if p, _ := registry.Resolve("gpt-4o"); p == nil {
+					t.Error("resolved no provider during a swap")
+					return
+				}
+
+				// Both models of one snapshot come from the same mapping
+				snapshot := registry.Current()
+				first, _ := snapshot.Resolve("gpt-4o")
+				second, _ := snapshot.Resolve("claude-3")
+				if first == nil || second == nil || first.Name() != second.Name() {
+					t.Errorf("observed a torn snapshot: %v and %v", first, second)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := range 2000 {
+		name := "a"
+		if i%2 == 0 {
+			name = "b"
+		}
+		registry.Swap(snapshotOf(name))
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestReloadableRegistry_Reload(t *testing.T) {
+	path := writeConfig(t, "models.yaml", "providers:\n  - name: old\n    base_url: http://localhost:8000/v1\nmodels:\n  - name: llama\n    provider: old\n")
+
+	registry, err := NewFileRegistry(path)
+	if err != nil {
+		t.Fatalf("NewFileRegistry failed: %v", err)
+	}
+	if p, _ := registry.Resolve("llama"); p == nil || p.Name() != "old" {
+		t.Fatalf("expected llama to resolve to old, got %v", p)
+	}
+
+	if err := os.WriteFile(path, []byte("providers:\n  - name: new\n    base_url: http://localhost:9000/v1\nmodels:\n  - name: llama\n    provider: new\n  - name: mistral\n    provider: new\n"), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+	if err := registry.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if p, _ := registry.Resolve("llama"); p == nil || p.Name() != "new" {
+		t.Errorf("expected llama to resolve to new after reload, got %v", p)
+	}
+	if len(registry.ListModels()) != 2 {
+		t.Errorf("expected 2 models after reload, got %v", registry.ListModels())
+	}
+
+	// A broken config keeps the current models
+	if err := os.WriteFile(path, []byte("models:\n  - name: llama\n    provider: missing\n"), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config: %v", err)
+	}
+	if err := registry.Reload(context.Background()); err == nil {
+		t.Error("expected an error reloading a broken config")
+	}
+	if p, _, apiType := registry.ResolveWithAPI("mistral"); p == nil || apiType != provider.APITypeAll {
+		t.Errorf("expected the previous models to remain, got %v and %v", p, apiType)
+	}
+
+	if err := NewReloadableRegistry(nil, nil).Reload(context.Background()); err == nil {
+		t.Error("expected an error reloading without a loader")
+	}
+}
+
+// closingProvider records that it was closed
+type closingProvider struct {
+	mockProvider
+	closed bool
+}
+
+func (p *closingProvider) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestReloadableRegistry_SwapClosesUnreferenced(t *testing.T) {
+	replaced := &closingProvider{mockProvider: mockProvider{name: "replaced"}}
+	shared := &closingProvider{mockProvider: mockProvider{name: "shared"}}
+	pattern := &closingProvider{mockProvider: mockProvider{name: "pattern"}}
+	fallback := &closingProvider{mockProvider: mockProvider{name: "fallback"}}
+
+	initial := NewMapModelRegistry()
+	initial.Register("gpt-4o", replaced)
+	initial.Register("claude-3", shared)
+	initial.Register("ft:*", pattern)
+	registry := NewReloadableRegistry(initial, nil)
+	registry.SetDefault(fallback, "")
+
+	next := NewMapModelRegistry()
+	next.Register("claude-3", shared)
+	next.Register("gpt-4o", fallback)
+	registry.Swap(next)
+
+	if !replaced.closed || !pattern.closed {
+		t.Errorf("expected unreferenced providers closed, got replaced=%v pattern=%v", replaced.closed, pattern.closed)
+	}
+	if shared.closed || fallback.closed {
+		t.Errorf("expected providers still in use left open, got shared=%v fallback=%v", shared.closed, fallback.closed)
+	}
+}
+
+func TestReloadableRegistry_DefaultSurvivesSwap(t *testing.T) {
+	var _ Defaulter = (*ReloadableRegistry)(nil)
+
+	registry := NewReloadableRegistry(snapshotOf("a"), nil)
+	registry.SetDefault(&mockProvider{name: "fallback"}, "gpt-4o-mini")
+	registry.Swap(snapshotOf("b"))
+
+	if p, rewrite := registry.Resolve("unknown"); p == nil || p.Name() != "fallback" || rewrite != "gpt-4o-mini" {
+		t.Errorf("expected the default after a swap, got %v and %q", p, rewrite)
+	}
+	if p, _, apiType := registry.ResolveWithAPI("unknown"); p == nil || apiType != provider.APITypeChatCompletions {
+		t.Errorf("expected the default's APIs, got %v and %v", p, apiType)
+	}
+	if p, _ := registry.Resolve("gpt-4o"); p == nil || p.Name() != "b" {
+		t.Errorf("expected registered models to resolve from the snapshot, got %v", p)
+	}
+
+	registry.SetDefault(nil, "")
+	if p, _ := registry.Resolve("unknown"); p != nil {
+		t.Errorf("expected no provider after removing the default, got %v", p)
+	}
+}
@@ -3,18 +3,46 @@ package openresponses
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	openai "github.com/deeplooplabs/ai-gateway/provider/openai"
 )
 
 // Converter handles conversion between OpenAI and OpenResponses formats
-type Converter struct{}
+type Converter struct {
+	messageID MessageIDFunc
+}
 
 // NewConverter creates a new Converter
 func NewConverter() *Converter {
 	return &Converter{}
 }
 
+// MessageIDFunc returns the ID of the message item for a choice of a response
+type MessageIDFunc func(responseID string, index int) string
+
+// MessageID is the default MessageIDFunc, returning "msg_<responseID>_<index>".
+// It depends only on its arguments, so a response converted whole or from a
+// stream gives its message items the same IDs.
+func MessageID(responseID string, index int) string {
+	return fmt.Sprintf("msg_%s_%d", responseID, index)
+}
+
+// SetMessageIDFunc sets the function generating message item IDs, used for
+// both streamed and non-streamed responses. nil restores MessageID.
+func (c *Converter) SetMessageIDFunc(fn MessageIDFunc) {
+	c.messageID = fn
+}
+
+// newMessageID returns the ID of the message item for choice index
+func (c *Converter) newMessageID(responseID string, index int) string {
+	if c.messageID == nil {
+		return MessageID(responseID, index)
+	}
+	return c.messageID(responseID, index)
+}
+
 // RequestToChatCompletion converts an OpenResponses CreateRequest to an OpenAI ChatCompletionRequest
 func (c *Converter) RequestToChatCompletion(req *CreateRequest) (*openai.ChatCompletionRequest, error) {
 	chatReq := &openai.ChatCompletionRequest{
@@ -24,6 +52,8 @@ func (c *Converter) RequestToChatCompletion(req *CreateRequest) (*openai.ChatCom
 		MaxTokens:        req.MaxOutputTokens,
 		PresencePenalty:  req.PresencePenalty,
 		FrequencyPenalty: req.FrequencyPenalty,
+		N:                req.N,
+		ResponseFormat:   ResponseFormatFromText(req.Text),
 	}
 
 	// Set stream flag
@@ -193,7 +223,7 @@ func (c *Converter) extractContentText(contentItems []json.RawMessage) string {
 func (c *Converter) toolsToOpenAI(tools []Tool) []openai.Tool {
 	var openAITools []openai.Tool
 	for _, tool := range tools {
-		if fn, ok := tool.(*FunctionTool); ok {
+		if fn, ok := asFunctionTool(tool); ok {
 			openAITools = append(openAITools, openai.Tool{
 				Type: "function",
 				Function: openai.FunctionDefinition{
@@ -207,6 +237,45 @@ func (c *Converter) toolsToOpenAI(tools []Tool) []openai.Tool {
 	return openAITools
 }
 
+// FunctionTools returns the function tools in tools as *FunctionTool values.
+// Tools decoded from JSON arrive as maps and are converted.
+func (c *Converter) FunctionTools(tools []Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+	result := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		if fn, ok := asFunctionTool(tool); ok {
+			result = append(result, fn)
+		}
+	}
+	return result
+}
+
+// asFunctionTool converts a tool to a *FunctionTool if it is a function tool
+func asFunctionTool(tool Tool) (*FunctionTool, bool) {
+	switch t := tool.(type) {
+	case *FunctionTool:
+		return t, true
+	case FunctionTool:
+		return &t, true
+	case map[string]any:
+		if t["type"] != "function" {
+			return nil, false
+		}
+		data, err := json.Marshal(t)
+		if err != nil {
+			return nil, false
+		}
+		var fn FunctionTool
+		if err := json.Unmarshal(data, &fn); err != nil {
+			return nil, false
+		}
+		return &fn, true
+	}
+	return nil, false
+}
+
 // ChatCompletionToResponse converts an OpenAI ChatCompletionResponse to an OpenResponses Response
 // tools parameter should be the tools from the original request (can be nil/empty)
 func (c *Converter) ChatCompletionToResponse(chatResp *openai.ChatCompletionResponse, responseID string, tools []Tool) *Response {
@@ -217,8 +286,15 @@ func (c *Converter) ChatCompletionToResponse(chatResp *openai.ChatCompletionResp
 			continue
 		}
 
+		// Tool calls become function_call items; skip the message if it has no text
+		toolCalls := choice.Message.ToolCalls
+		if len(toolCalls) > 0 && choice.Message.Content == "" {
+			output = append(output, toolCallsToItems(responseID, choice.Index, toolCalls)...)
+			continue
+		}
+
 		messageItem := &MessageItem{
-			ID:     generateMessageID(responseID, choice.Index),
+			ID:     c.newMessageID(responseID, choice.Index),
 			Type:   "message",
 			Status: MessageStatusCompleted,
 			Role:   MessageRoleEnum(choice.Message.Role),
@@ -232,14 +308,16 @@ func (c *Converter) ChatCompletionToResponse(chatResp *openai.ChatCompletionResp
 			},
 		}
 		output = append(output, messageItem)
+		output = append(output, ImageItems(responseID, choice.Index, &choice.Message)...)
+		output = append(output, toolCallsToItems(responseID, choice.Index, toolCalls)...)
 	}
 
 	// Create empty metadata object
 	emptyMetadata := make(MetadataParam)
-
-	// Create usage with details
-	inputTokensDetails := &InputTokensDetails{CachedTokens: 0}
-	outputTokensDetails := &OutputTokensDetails{ReasoningTokens: 0}
+	serviceTier := chatResp.ServiceTier
+	if serviceTier == "" {
+		serviceTier = string(ServiceTierAuto)
+	}
 
 	// Default text format - format is required
 	textFormat := &TextResponseFormat{Type: "text"}
@@ -272,88 +350,363 @@ func (c *Converter) ChatCompletionToResponse(chatResp *openai.ChatCompletionResp
 		Temperature:       1.0, // Required, default 1.0
 		Reasoning:         nil, // Required, can be null
 		User:              nil, // Required, can be null
-		Usage: &Usage{
-			InputTokens:        chatResp.Usage.PromptTokens,
-			OutputTokens:       chatResp.Usage.CompletionTokens,
-			TotalTokens:        chatResp.Usage.TotalTokens,
-			InputTokensDetails:  inputTokensDetails,
-			OutputTokensDetails: outputTokensDetails,
-		},
+		Usage:             UsageFromChat(chatResp.Usage),
 		MaxOutputTokens:   nil, // null when not set
 		MaxToolCalls:      nil, // null when not set
 		Store:             true, // Required, default true
 		Background:        false, // Required, default false
-		ServiceTier:       "auto", // Required, default "auto"
+		ServiceTier:       serviceTier, // Required, default "auto"
 		Metadata:          &emptyMetadata, // Required, empty object
 		IncompleteDetails: nil, // null when not incomplete
 		SafetyIdentifier:  nil, // null when not set
 		PromptCacheKey:    nil, // null when not set
 	}
 
+	// A choice cut short makes the whole response incomplete
+	for _, choice := range chatResp.Choices {
+		if reason := incompleteReason(choice.FinishReason); reason != "" {
+			resp.Status = ResponseStatusIncomplete
+			resp.CompletedAt = nil
+			resp.IncompleteDetails = &IncompleteDetails{Reason: reason}
+			break
+		}
+	}
+
 	return resp
 }
 
-// StreamingChunkToEvents converts an OpenAI streaming chunk to OpenResponses streaming events
-func (c *Converter) StreamingChunkToEvents(chunk []byte, seq *int, itemID string, outputIndex int) []StreamingEvent {
+// UsageFromChat converts Chat Completions token usage to a Response's usage
+func UsageFromChat(usage openai.Usage) *Usage {
+	return &Usage{
+		InputTokens:         usage.PromptTokens,
+		OutputTokens:        usage.CompletionTokens,
+		TotalTokens:         usage.TotalTokens,
+		InputTokensDetails:  &InputTokensDetails{CachedTokens: 0},
+		OutputTokensDetails: &OutputTokensDetails{ReasoningTokens: 0},
+	}
+}
+// incompleteReason maps a chat finish reason to the Responses incomplete reason,
+// or "" if the choice finished normally
+func incompleteReason(finishReason string) string {
+	switch finishReason {
+	case "length":
+		return "max_output_tokens"
+	case "content_filter":
+		return "content_filter"
+	}
+	return ""
+}
+
+// StreamItems tracks the output items of a streaming response. Each choice index
+// gets a message item for its text, an image_generation_call item per image
+// and a function_call item per tool call.
+type StreamItems struct {
+	responseID string
+	items      []*streamItem
+	choices    map[int]*streamChoice
+	incomplete string // incomplete reason of the first choice cut short
+}
+
+// streamChoice holds the output items belonging to a single choice
+type streamChoice struct {
+	message *streamItem
+	calls   map[int]*streamItem
+	images  int // number of image items added
+	done    bool
+}
+
+// streamItem holds the accumulated state of a single streamed output item
+type streamItem struct {
+	id          string
+	outputIndex int
+	function    bool   // function_call item rather than a message
+	callID      string // function_call only
+	name        string // function_call only
+	image       *ImageGenerationCallItem // set for image items, which are complete when added
+	text        strings.Builder
+	done        bool
+}
+
+// NewStreamItems creates a new tracker for the output items of a streaming response
+func NewStreamItems(responseID string) *StreamItems {
+	return &StreamItems{
+		responseID: responseID,
+		choices:    make(map[int]*streamChoice),
+	}
+}
+
+// Len returns the number of output items seen so far
+func (s *StreamItems) Len() int {
+	return len(s.items)
+}
+
+// Output returns the output items in output index order
+func (s *StreamItems) Output() []ItemField {
+	output := make([]ItemField, 0, len(s.items))
+	for _, item := range s.items {
+		output = append(output, item.toItem())
+	}
+	return output
+}
+
+// IncompleteDetails returns why the response was cut short, or nil if every
+// finished choice stopped normally
+func (s *StreamItems) IncompleteDetails() *IncompleteDetails {
+	if s.incomplete == "" {
+		return nil
+	}
+	return &IncompleteDetails{Reason: s.incomplete}
+}
+
+// choice returns the state for a choice index, creating it if needed
+func (s *StreamItems) choice(index int) *streamChoice {
+	choice, ok := s.choices[index]
+	if !ok {
+		choice = &streamChoice{calls: make(map[int]*streamItem)}
+		s.choices[index] = choice
+	}
+	return choice
+}
+
+// add appends a new output item
+func (s *StreamItems) add(item *streamItem) *streamItem {
+	item.outputIndex = len(s.items)
+	s.items = append(s.items, item)
+	return item
+}
+
+// toItem converts the accumulated state to an output item
+func (i *streamItem) toItem() ItemField {
+	if i.image != nil {
+		return i.image
+	}
+	if i.function {
+		status := FunctionCallStatusCompleted
+		if !i.done {
+			status = FunctionCallStatusIncomplete
+		}
+		return &FunctionCallItem{
+			ID:        i.id,
+			Type:      "function_call",
+			Status:    status,
+			CallID:    i.callID,
+			Name:      i.name,
+			Arguments: i.text.String(),
+		}
+	}
+
+	status := MessageStatusCompleted
+	if !i.done {
+		status = MessageStatusIncomplete
+	}
+	return newOutputMessage(i.id, status, i.text.String())
+}
+
+// StreamingChunkToEvents converts an OpenAI streaming chunk to OpenResponses streaming events.
+// Text deltas stream into a message item per choice; tool call deltas stream into a
+// function_call item per call. Items are added the first time they are seen. Image
+// parts arrive whole, so each becomes an image_generation_call item added and done at once.
+func (c *Converter) StreamingChunkToEvents(chunk []byte, seq *int, items *StreamItems) []StreamingEvent {
 	var chatResp openai.ChatCompletionStreamResponse
 	if err := json.Unmarshal(chunk, &chatResp); err != nil {
 		return nil
 	}
 
 	var events []StreamingEvent
+	next := func() int {
+		*seq++
+		return *seq
+	}
 
 	for _, choice := range chatResp.Choices {
+		state := items.choice(choice.Index)
+		if state.done {
+			continue
+		}
+
 		if choice.Delta != nil {
 			// Text delta
 			if choice.Delta.Content != "" {
-				*seq++
+				if state.message == nil {
+					state.message = items.add(&streamItem{id: c.newMessageID(items.responseID, choice.Index)})
+					events = append(events, c.messageAddedEvents(next, state.message)...)
+				}
+				msg := state.message
+				msg.text.WriteString(choice.Delta.Content)
 				events = append(events, NewResponseOutputTextDeltaEvent(
-					*seq, itemID, outputIndex, 0, choice.Delta.Content,
+					next(), msg.id, msg.outputIndex, 0, choice.Delta.Content,
 				))
 			}
+
+			// Image parts
+			for _, part := range choice.Delta.Parts {
+				if part.Type != openai.ContentPartImageURL || part.ImageURL == nil {
+					continue
+				}
+				image := items.add(&streamItem{
+					image: newImageItem(imageItemID(items.responseID, choice.Index, state.images), part.ImageURL.URL),
+					done:  true,
+				})
+				state.images++
+				events = append(events,
+					NewResponseOutputItemAddedEvent(next(), image.outputIndex, image.image),
+					NewResponseOutputItemDoneEvent(next(), image.outputIndex, image.image),
+				)
+			}
+
+			// Tool call deltas
+			for i, tc := range choice.Delta.ToolCalls {
+				toolIndex := i
+				if tc.Index != nil {
+					toolIndex = *tc.Index
+				}
+				call, ok := state.calls[toolIndex]
+				if !ok {
+					call = items.add(&streamItem{
+						id:       fmt.Sprintf("fc_%s_%d_%d", items.responseID, choice.Index, toolIndex),
+						function: true,
+					})
+					state.calls[toolIndex] = call
+				}
+				if tc.ID != "" {
+					call.callID = tc.ID
+				}
+				call.name += tc.Function.Name
+				if !ok {
+					events = append(events, NewResponseOutputItemAddedEvent(next(), call.outputIndex, &FunctionCallItem{
+						ID:     call.id,
+						Type:   "function_call",
+						Status: FunctionCallStatusInProgress,
+						CallID: call.callID,
+						Name:   call.name,
+					}))
+				}
+				if tc.Function.Arguments != "" {
+					call.text.WriteString(tc.Function.Arguments)
+					events = append(events, NewResponseFunctionCallArgumentsDeltaEvent(
+						next(), call.id, call.outputIndex, tc.Function.Arguments,
+					))
+				}
+			}
 		}
 
 		// Check if choice is complete
 		if choice.FinishReason != "" {
-			*seq++
-			// Send done event for the content
-			fullText := c.getAccumulatedText(choice)
-			events = append(events, NewResponseOutputTextDoneEvent(
-				*seq, itemID, outputIndex, 0, fullText,
-			))
-
-			*seq++
-			// Send item done event
-			messageItem := &MessageItem{
-				ID:     itemID,
-				Type:   "message",
-				Status: MessageStatusCompleted,
-				Role:   MessageRoleAssistant,
-				Content: []OutputTextContent{
-					{Type: "output_text", Text: fullText, Annotations: []Annotation{}, Logprobs: []LogProb{}},
-				},
+			if reason := incompleteReason(choice.FinishReason); reason != "" && items.incomplete == "" {
+				items.incomplete = reason
 			}
-			events = append(events, NewResponseOutputItemDoneEvent(*seq, outputIndex, messageItem))
+			events = append(events, c.finishChoiceEvents(next, items, choice.Index, state)...)
 		}
 	}
 
 	return events
 }
 
-// getAccumulatedText extracts the accumulated text from a choice
-func (c *Converter) getAccumulatedText(choice openai.Choice) string {
-	if choice.Message.Content != "" {
-		return choice.Message.Content
+// StreamDoneEvents finishes the choices still open when a stream ends without
+// a finish_reason, so every output item gets its done events before
+// response.completed. A stream without any choice gets an empty message item.
+func (c *Converter) StreamDoneEvents(seq *int, items *StreamItems) []StreamingEvent {
+	next := func() int {
+		*seq++
+		return *seq
 	}
-	if choice.Delta != nil && choice.Delta.Content != "" {
-		return choice.Delta.Content
+
+	if len(items.choices) == 0 {
+		items.choice(0)
 	}
-	return ""
+	indexes := make([]int, 0, len(items.choices))
+	for i, state := range items.choices {
+		if !state.done {
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+
+	var events []StreamingEvent
+	for _, i := range indexes {
+		events = append(events, c.finishChoiceEvents(next, items, i, items.choices[i])...)
+	}
+	return events
 }
 
-// generateMessageID generates a unique message ID
-func generateMessageID(responseID string, index int) string {
-	return fmt.Sprintf("msg_%s_%d", responseID, index)
+// finishChoiceEvents marks a choice done and returns the done events of its
+// items: output_text.done, content_part.done and output_item.done for the
+// message, then function_call_arguments.done and output_item.done per tool call
+func (c *Converter) finishChoiceEvents(next func() int, items *StreamItems, index int, state *streamChoice) []StreamingEvent {
+	state.done = true
+	var events []StreamingEvent
+
+	// Every choice produces at least a message item
+	if state.message == nil && len(state.calls) == 0 {
+		state.message = items.add(&streamItem{id: c.newMessageID(items.responseID, index)})
+		events = append(events, c.messageAddedEvents(next, state.message)...)
+	}
+
+	if msg := state.message; msg != nil {
+		msg.done = true
+		// Send done events for the text and its content part, then the item
+		text := msg.text.String()
+		events = append(events,
+			NewResponseOutputTextDoneEvent(next(), msg.id, msg.outputIndex, 0, text),
+			NewResponseContentPartDoneEvent(next(), msg.id, msg.outputIndex, 0, newOutputText(text)),
+			NewResponseOutputItemDoneEvent(next(), msg.outputIndex, msg.toItem()),
+		)
+	}
+
+	toolIndexes := make([]int, 0, len(state.calls))
+	for i := range state.calls {
+		toolIndexes = append(toolIndexes, i)
+	}
+	sort.Ints(toolIndexes)
+	for _, i := range toolIndexes {
+		call := state.calls[i]
+		call.done = true
+		events = append(events, NewResponseFunctionCallArgumentsDoneEvent(
+			next(), call.id, call.outputIndex, call.text.String(),
+		))
+		events = append(events, NewResponseOutputItemDoneEvent(next(), call.outputIndex, call.toItem()))
+	}
+	return events
+}
+
+// messageAddedEvents returns the output_item.added and content_part.added events for a message item
+func (c *Converter) messageAddedEvents(next func() int, msg *streamItem) []StreamingEvent {
+	return []StreamingEvent{
+		NewResponseOutputItemAddedEvent(next(), msg.outputIndex, newOutputMessage(msg.id, MessageStatusInProgress, "")),
+		NewResponseContentPartAddedEvent(next(), msg.id, msg.outputIndex, 0, newOutputText("")),
+	}
+}
+
+// newOutputMessage creates an assistant message item with a single text part
+func newOutputMessage(id string, status MessageStatusEnum, text string) *MessageItem {
+	return &MessageItem{
+		ID:      id,
+		Type:    "message",
+		Status:  status,
+		Role:    MessageRoleAssistant,
+		Content: []OutputTextContent{newOutputText(text)},
+	}
+}
+
+// newOutputText creates an output text part with the required empty arrays
+func newOutputText(text string) OutputTextContent {
+	return OutputTextContent{Type: "output_text", Text: text, Annotations: []Annotation{}, Logprobs: []LogProb{}}
+}
+
+// toolCallsToItems converts the tool calls of a choice to function_call output items
+func toolCallsToItems(responseID string, choiceIndex int, toolCalls []openai.ToolCall) []ItemField {
+	items := make([]ItemField, 0, len(toolCalls))
+	for i, tc := range toolCalls {
+		items = append(items, &FunctionCallItem{
+			ID:        fmt.Sprintf("fc_%s_%d_%d", responseID, choiceIndex, i),
+			Type:      "function_call",
+			Status:    FunctionCallStatusCompleted,
+			CallID:    tc.ID,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+	return items
 }
 
 // ResponseToChatCompletion converts an OpenResponses Response to an OpenAI ChatCompletionResponse
@@ -364,11 +717,16 @@ func (c *Converter) ResponseToChatCompletion(orResp *Response) *openai.ChatCompl
 
 	choices := make([]openai.Choice, 0, len(orResp.Output))
 
-	for i, item := range orResp.Output {
-		if msgItem, ok := item.(*MessageItem); ok && msgItem.Role != "" {
+	for _, item := range orResp.Output {
+		switch it := item.(type) {
+		case *MessageItem:
+			if it.Role == "" {
+				continue
+			}
+
 			// Extract content text from OutputTextContent
 			var content string
-			for _, c := range msgItem.Content {
+			for _, c := range it.Content {
 				if c.Type == "output_text" {
 					content += c.Text
 				}
@@ -376,18 +734,48 @@ func (c *Converter) ResponseToChatCompletion(orResp *Response) *openai.ChatCompl
 
 			// Map status to finish reason
 			finishReason := "stop"
-			if msgItem.Status == MessageStatusIncomplete {
+			if it.Status == MessageStatusIncomplete {
 				finishReason = "length"
 			}
 
 			choices = append(choices, openai.Choice{
-				Index: i,
+				Index: len(choices),
 				Message: openai.Message{
-					Role:    string(msgItem.Role),
+					Role:    string(it.Role),
 					Content: content,
 				},
 				FinishReason: finishReason,
 			})
+
+		case *FunctionCallItem:
+			// Function calls attach to the preceding message, or start a new assistant choice
+			if len(choices) == 0 {
+				choices = append(choices, openai.Choice{
+					Index:   0,
+					Message: openai.Message{Role: "assistant"},
+				})
+			}
+			last := &choices[len(choices)-1]
+			last.Message.ToolCalls = append(last.Message.ToolCalls, openai.ToolCall{
+				ID:   it.CallID,
+				Type: "function",
+				Function: openai.FunctionCall{
+					Name:      it.Name,
+					Arguments: it.Arguments,
+				},
+			})
+			last.FinishReason = "tool_calls"
+
+		case *ImageGenerationCallItem:
+			// Images attach to the preceding message as image parts
+			if len(choices) == 0 {
+				choices = append(choices, openai.Choice{
+					Index:        0,
+					Message:      openai.Message{Role: "assistant"},
+					FinishReason: "stop",
+				})
+			}
+			choices[len(choices)-1].Message.AppendImage(it.ImageURL())
 		}
 	}
 
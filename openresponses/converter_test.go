@@ -2,6 +2,7 @@ package openresponses
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	openai "github.com/deeplooplabs/ai-gateway/provider/openai"
@@ -142,3 +143,510 @@ func TestConverter_RequestToChatConversion(t *testing.T) {
 		t.Errorf("Expected content 'Say hello in exactly 3 words.', got '%s'", chatReq.Messages[0].Content)
 	}
 }
+
+func TestConverter_StreamingChunkToEvents_MultipleChoices(t *testing.T) {
+	c := NewConverter()
+	items := NewStreamItems("resp_1")
+	seq := 0
+
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"content":"Hi"}},{"index":1,"delta":{"content":"Hey"}}]}`,
+		`{"choices":[{"index":1,"delta":{"content":" there"},"finish_reason":"stop"}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+
+	doneText := make(map[int]string)
+	var added []int
+	for _, chunk := range chunks {
+		for _, event := range c.StreamingChunkToEvents([]byte(chunk), &seq, items) {
+			switch e := event.(type) {
+			case *ResponseOutputItemAddedEvent:
+				added = append(added, e.OutputIndex)
+			case *ResponseOutputTextDoneEvent:
+				doneText[e.OutputIndex] = e.Text
+			}
+		}
+	}
+
+	if len(added) != 2 || added[0] != 0 || added[1] != 1 {
+		t.Fatalf("expected items added for indexes [0 1], got %v", added)
+	}
+	if doneText[0] != "Hi" || doneText[1] != "Hey there" {
+		t.Errorf("unexpected done text: %v", doneText)
+	}
+
+	output := items.Output()
+	if len(output) != 2 {
+		t.Fatalf("expected 2 output items, got %d", len(output))
+	}
+	second, ok := output[1].(*MessageItem)
+	if !ok {
+		t.Fatalf("expected *MessageItem, got %T", output[1])
+	}
+	if second.ID != "msg_resp_1_1" || second.Content[0].Text != "Hey there" {
+		t.Errorf("unexpected second item: %s %q", second.ID, second.Content[0].Text)
+	}
+	if second.Status != MessageStatusCompleted {
+		t.Errorf("expected completed status, got %s", second.Status)
+	}
+}
+
+func TestConverter_StreamingChunkToEvents_Images(t *testing.T) {
+	c := NewConverter()
+	items := NewStreamItems("resp_1")
+	seq := 0
+
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"content":"Here is a cat"}}]}`,
+		`{"choices":[{"index":0,"delta":{"content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}]},"finish_reason":"stop"}]}`,
+	}
+
+	var done []ItemField
+	for _, chunk := range chunks {
+		for _, event := range c.StreamingChunkToEvents([]byte(chunk), &seq, items) {
+			if e, ok := event.(*ResponseOutputItemDoneEvent); ok {
+				done = append(done, e.Item)
+			}
+		}
+	}
+
+	output := items.Output()
+	if len(output) != 2 {
+		t.Fatalf("expected a message and an image item, got %d items", len(output))
+	}
+	image, ok := output[1].(*ImageGenerationCallItem)
+	if !ok {
+		t.Fatalf("expected *ImageGenerationCallItem, got %T", output[1])
+	}
+	if image.ID != "ig_resp_1_0_0" || image.Result != "iVBORw0KGgo=" || image.OutputFormat != "png" {
+		t.Errorf("unexpected image item: %+v", image)
+	}
+	if len(done) != 2 || done[0] != ItemField(image) {
+		t.Errorf("expected the image item done before the message, got %v", done)
+	}
+}
+
+func TestConverter_MessageIDsMatchStreaming(t *testing.T) {
+	chatResp := &openai.ChatCompletionResponse{
+		ID: "chatcmpl-1",
+		Choices: []openai.Choice{
+			{Index: 0, Message: openai.Message{Role: "assistant", Content: "Hi"}, FinishReason: "stop"},
+			{Index: 1, Message: openai.Message{Role: "assistant", Content: "Hey"}, FinishReason: "stop"},
+		},
+	}
+	chunk := `{"choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":"stop"},{"index":1,"delta":{"content":"Hey"},"finish_reason":"stop"}]}`
+
+	custom := func(responseID string, index int) string {
+		return fmt.Sprintf("item-%d-of-%s", index, responseID)
+	}
+	for _, fn := range []MessageIDFunc{nil, custom} {
+		c := NewConverter()
+		c.SetMessageIDFunc(fn)
+
+		unstreamed := c.ChatCompletionToResponse(chatResp, "resp_1", nil).Output
+		seq := 0
+		items := NewStreamItems("resp_1")
+		c.StreamingChunkToEvents([]byte(chunk), &seq, items)
+		streamed := items.Output()
+
+		if len(unstreamed) != 2 || len(streamed) != 2 {
+			t.Fatalf("expected 2 items each, got %d and %d", len(unstreamed), len(streamed))
+		}
+		for i := range unstreamed {
+			want, got := unstreamed[i].(*MessageItem).ID, streamed[i].(*MessageItem).ID
+			if got != want {
+				t.Errorf("item %d: streamed ID %q doesn't match %q", i, got, want)
+			}
+		}
+		if fn != nil && unstreamed[1].(*MessageItem).ID != "item-1-of-resp_1" {
+			t.Errorf("expected the custom ID, got %q", unstreamed[1].(*MessageItem).ID)
+		}
+	}
+}
+
+func TestConverter_ChatCompletionToResponse_ToolCalls(t *testing.T) {
+	c := NewConverter()
+
+	chatResp := &openai.ChatCompletionResponse{
+		ID:     "chatcmpl-1",
+		Object: "chat.completion",
+		Model:  "gpt-4o",
+		Choices: []openai.Choice{{
+			Index: 0,
+			Message: openai.Message{
+				Role: "assistant",
+				ToolCalls: []openai.ToolCall{{
+					ID:   "call_1",
+					Type: "function",
+					Function: openai.FunctionCall{
+						Name:      "get_weather",
+						Arguments: `{"city":"Paris"}`,
+					},
+				}},
+			},
+			FinishReason: "tool_calls",
+		}},
+	}
+
+	resp := c.ChatCompletionToResponse(chatResp, "resp_1", nil)
+
+	if len(resp.Output) != 1 {
+		t.Fatalf("Expected 1 output item, got %d", len(resp.Output))
+	}
+	fc, ok := resp.Output[0].(*FunctionCallItem)
+	if !ok {
+		t.Fatalf("Expected *FunctionCallItem, got %T", resp.Output[0])
+	}
+	if fc.Type != "function_call" || fc.CallID != "call_1" || fc.Name != "get_weather" || fc.Arguments != `{"city":"Paris"}` {
+		t.Errorf("Unexpected function call item: %+v", fc)
+	}
+
+	// Round trip back to Chat Completions
+	back := c.ResponseToChatCompletion(resp)
+	if back == nil || len(back.Choices) != 1 {
+		t.Fatalf("Expected 1 choice after round trip, got %+v", back)
+	}
+	choice := back.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("Expected finish reason 'tool_calls', got '%s'", choice.FinishReason)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].ID != "call_1" || choice.Message.ToolCalls[0].Function.Name != "get_weather" {
+		t.Errorf("Unexpected tool calls: %+v", choice.Message.ToolCalls)
+	}
+}
+
+func TestConverter_ChatCompletionToResponse_TextAndToolCalls(t *testing.T) {
+	c := NewConverter()
+
+	chatResp := &openai.ChatCompletionResponse{
+		Choices: []openai.Choice{{
+			Message: openai.Message{
+				Role:    "assistant",
+				Content: "Let me check.",
+				ToolCalls: []openai.ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: openai.FunctionCall{Name: "get_weather", Arguments: `{}`},
+				}},
+			},
+		}},
+	}
+
+	resp := c.ChatCompletionToResponse(chatResp, "resp_1", nil)
+
+	if len(resp.Output) != 2 {
+		t.Fatalf("Expected 2 output items, got %d", len(resp.Output))
+	}
+	if _, ok := resp.Output[0].(*MessageItem); !ok {
+		t.Errorf("Expected *MessageItem first, got %T", resp.Output[0])
+	}
+	if _, ok := resp.Output[1].(*FunctionCallItem); !ok {
+		t.Errorf("Expected *FunctionCallItem second, got %T", resp.Output[1])
+	}
+}
+
+func TestConverter_StreamingChunkToEvents_ToolCall(t *testing.T) {
+	c := NewConverter()
+	items := NewStreamItems("resp_1")
+	seq := 0
+
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"role":"assistant"}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+
+	var types []string
+	var deltas string
+	var doneArgs string
+	var doneItem *FunctionCallItem
+	for _, chunk := range chunks {
+		for _, event := range c.StreamingChunkToEvents([]byte(chunk), &seq, items) {
+			types = append(types, event.GetType())
+			switch e := event.(type) {
+			case *ResponseOutputItemAddedEvent:
+				fc, ok := e.Item.(*FunctionCallItem)
+				if !ok {
+					t.Fatalf("Expected *FunctionCallItem added, got %T", e.Item)
+				}
+				if fc.CallID != "call_1" || fc.Name != "get_weather" || fc.Status != FunctionCallStatusInProgress {
+					t.Errorf("Unexpected added item: %+v", fc)
+				}
+			case *ResponseFunctionCallArgumentsDeltaEvent:
+				deltas += e.Delta
+			case *ResponseFunctionCallArgumentsDoneEvent:
+				doneArgs = e.Arguments
+			case *ResponseOutputItemDoneEvent:
+				doneItem, _ = e.Item.(*FunctionCallItem)
+			}
+		}
+	}
+
+	want := []string{
+		"response.output_item.added",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.done",
+		"response.output_item.done",
+	}
+	if len(types) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], types[i])
+		}
+	}
+
+	if deltas != `{"city":"Paris"}` || doneArgs != deltas {
+		t.Errorf("Unexpected arguments: deltas %q, done %q", deltas, doneArgs)
+	}
+	if doneItem == nil || doneItem.Status != FunctionCallStatusCompleted || doneItem.Arguments != deltas {
+		t.Errorf("Unexpected done item: %+v", doneItem)
+	}
+
+	output := items.Output()
+	if len(output) != 1 {
+		t.Fatalf("Expected 1 output item, got %d", len(output))
+	}
+	if fc, ok := output[0].(*FunctionCallItem); !ok || fc.ID != "fc_resp_1_0_0" {
+		t.Errorf("Unexpected output item: %+v", output[0])
+	}
+}
+
+func TestConverter_StreamingChunkToEvents_ParallelToolCalls(t *testing.T) {
+	c := NewConverter()
+	items := NewStreamItems("resp_1")
+	seq := 0
+
+	// Argument fragments for the two calls interleave, including within one chunk
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{\"tz\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"CET\"}"}},{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+
+	added := make(map[string]int)
+	deltas := make(map[int]string)
+	done := make(map[string]string)
+	for _, chunk := range chunks {
+		for _, event := range c.StreamingChunkToEvents([]byte(chunk), &seq, items) {
+			switch e := event.(type) {
+			case *ResponseOutputItemAddedEvent:
+				fc := e.Item.(*FunctionCallItem)
+				added[fc.CallID] = e.OutputIndex
+			case *ResponseFunctionCallArgumentsDeltaEvent:
+				deltas[e.OutputIndex] += e.Delta
+			case *ResponseOutputItemDoneEvent:
+				fc := e.Item.(*FunctionCallItem)
+				done[fc.CallID] = fc.Arguments
+			}
+		}
+	}
+
+	if len(added) != 2 || added["call_a"] != 0 || added["call_b"] != 1 {
+		t.Fatalf("expected distinct items for each call, got %v", added)
+	}
+	if deltas[0] != `{"city":"Paris"}` || deltas[1] != `{"tz":"CET"}` {
+		t.Errorf("unexpected per-item argument deltas: %v", deltas)
+	}
+	if done["call_a"] != `{"city":"Paris"}` || done["call_b"] != `{"tz":"CET"}` {
+		t.Errorf("unexpected done arguments: %v", done)
+	}
+
+	output := items.Output()
+	if len(output) != 2 {
+		t.Fatalf("expected 2 output items, got %d", len(output))
+	}
+	for i, want := range []struct{ id, name, args string }{
+		{"fc_resp_1_0_0", "get_weather", `{"city":"Paris"}`},
+		{"fc_resp_1_0_1", "get_time", `{"tz":"CET"}`},
+	} {
+		fc, ok := output[i].(*FunctionCallItem)
+		if !ok || fc.ID != want.id || fc.Name != want.name || fc.Arguments != want.args || fc.Status != FunctionCallStatusCompleted {
+			t.Errorf("output %d: unexpected item %+v", i, output[i])
+		}
+	}
+}
+
+func TestConverter_ToolCallOnlyTurnRoundTrip(t *testing.T) {
+	c := NewConverter()
+
+	var chatResp openai.ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"object": "chat.completion",
+		"model": "gpt-4o",
+		"choices": [{
+			"index": 0,
+			"message": {
+				"role": "assistant",
+				"content": null,
+				"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]
+			},
+			"finish_reason": "tool_calls"
+		}]
+	}`), &chatResp)
+	if err != nil {
+		t.Fatalf("failed to decode chat response: %v", err)
+	}
+
+	resp := c.ChatCompletionToResponse(&chatResp, "resp_1", nil)
+	if len(resp.Output) != 1 {
+		t.Fatalf("expected only a function_call item, got %d items", len(resp.Output))
+	}
+	if _, ok := resp.Output[0].(*FunctionCallItem); !ok {
+		t.Fatalf("expected *FunctionCallItem, got %T", resp.Output[0])
+	}
+
+	back := c.ResponseToChatCompletion(resp)
+	data, err := json.Marshal(back.Choices[0].Message)
+	if err != nil {
+		t.Fatalf("failed to marshal message: %v", err)
+	}
+	var message map[string]any
+	json.Unmarshal(data, &message)
+	if content, exists := message["content"]; !exists || content != nil {
+		t.Errorf("expected null content, got %s", data)
+	}
+	if calls, _ := message["tool_calls"].([]any); len(calls) != 1 {
+		t.Errorf("expected 1 tool call, got %s", data)
+	}
+}
+
+func TestConverter_ImageOutputRoundTrip(t *testing.T) {
+	c := NewConverter()
+
+	var chatResp openai.ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"object": "chat.completion",
+		"model": "gpt-image",
+		"choices": [{
+			"index": 0,
+			"message": {
+				"role": "assistant",
+				"content": [
+					{"type": "text", "text": "Here is your cat."},
+					{"type": "image_url", "image_url": {"url": "data:image/jpeg;base64,/9j/4AAQ"}},
+					{"type": "image_url", "image_url": {"url": "https://example.com/cat.png"}}
+				]
+			},
+			"finish_reason": "stop"
+		}]
+	}`), &chatResp)
+	if err != nil {
+		t.Fatalf("failed to decode chat response: %v", err)
+	}
+
+	resp := c.ChatCompletionToResponse(&chatResp, "resp_1", nil)
+	if len(resp.Output) != 3 {
+		t.Fatalf("expected a message and 2 image items, got %d items", len(resp.Output))
+	}
+	inline, ok := resp.Output[1].(*ImageGenerationCallItem)
+	if !ok || inline.Result != "/9j/4AAQ" || inline.OutputFormat != "jpeg" {
+		t.Errorf("expected the inline image as base64, got %+v", resp.Output[1])
+	}
+	if linked, ok := resp.Output[2].(*ImageGenerationCallItem); !ok || linked.Result != "https://example.com/cat.png" {
+		t.Errorf("expected the linked image URL, got %+v", resp.Output[2])
+	}
+
+	back := c.ResponseToChatCompletion(resp)
+	if len(back.Choices) != 1 {
+		t.Fatalf("expected 1 choice, got %d", len(back.Choices))
+	}
+	message := back.Choices[0].Message
+	if message.Content != "Here is your cat." {
+		t.Errorf("expected the text, got %q", message.Content)
+	}
+	images := message.Images()
+	if len(images) != 2 || images[0] != "data:image/jpeg;base64,/9j/4AAQ" || images[1] != "https://example.com/cat.png" {
+		t.Errorf("expected both images to survive, got %v", images)
+	}
+}
+
+func TestConverter_RequestToChatCompletion_DecodedTools(t *testing.T) {
+	c := NewConverter()
+
+	var req CreateRequest
+	if err := json.Unmarshal([]byte(`{
+		"model": "gpt-4o",
+		"input": "hi",
+		"tools": [{"type": "function", "name": "get_weather", "description": "Get weather"}]
+	}`), &req); err != nil {
+		t.Fatalf("Failed to unmarshal request: %v", err)
+	}
+
+	chatReq, err := c.RequestToChatCompletion(&req)
+	if err != nil {
+		t.Fatalf("RequestToChatCompletion failed: %v", err)
+	}
+	if len(chatReq.Tools) != 1 || chatReq.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("Expected decoded function tool to be forwarded, got %+v", chatReq.Tools)
+	}
+}
+
+func TestConverter_ChatCompletionToResponse_FinishReasonStatus(t *testing.T) {
+	tests := []struct {
+		finishReason string
+		status       ResponseStatusEnum
+		reason       string
+	}{
+		{"stop", ResponseStatusCompleted, ""},
+		{"length", ResponseStatusIncomplete, "max_output_tokens"},
+		{"content_filter", ResponseStatusIncomplete, "content_filter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.finishReason, func(t *testing.T) {
+			c := NewConverter()
+			chatResp := &openai.ChatCompletionResponse{
+				ID:      "chatcmpl-1",
+				Created: 1234567890,
+				Model:   "gpt-4o",
+				Choices: []openai.Choice{{
+					Message:      openai.Message{Role: "assistant", Content: "Once upon a"},
+					FinishReason: tt.finishReason,
+				}},
+			}
+
+			resp := c.ChatCompletionToResponse(chatResp, "resp_1", nil)
+
+			if resp.Status != tt.status {
+				t.Errorf("expected status %s, got %s", tt.status, resp.Status)
+			}
+			if tt.reason == "" {
+				if resp.IncompleteDetails != nil || resp.CompletedAt == nil {
+					t.Errorf("expected completed response, got incomplete_details %+v, completed_at %v", resp.IncompleteDetails, resp.CompletedAt)
+				}
+				return
+			}
+			if resp.IncompleteDetails == nil || resp.IncompleteDetails.Reason != tt.reason {
+				t.Errorf("expected incomplete reason %q, got %+v", tt.reason, resp.IncompleteDetails)
+			}
+			if resp.CompletedAt != nil {
+				t.Errorf("expected no completed_at for an incomplete response, got %d", *resp.CompletedAt)
+			}
+		})
+	}
+}
+
+func TestConverter_StreamingChunkToEvents_IncompleteDetails(t *testing.T) {
+	c := NewConverter()
+	seq := 0
+
+	stopped := NewStreamItems("resp_1")
+	c.StreamingChunkToEvents([]byte(`{"choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":"stop"}]}`), &seq, stopped)
+	if details := stopped.IncompleteDetails(); details != nil {
+		t.Errorf("expected no incomplete details for stop, got %+v", details)
+	}
+
+	truncated := NewStreamItems("resp_2")
+	c.StreamingChunkToEvents([]byte(`{"choices":[{"index":0,"delta":{"content":"Once upon a"},"finish_reason":"length"}]}`), &seq, truncated)
+	if details := truncated.IncompleteDetails(); details == nil || details.Reason != "max_output_tokens" {
+		t.Errorf("expected max_output_tokens incomplete details, got %+v", details)
+	}
+}
@@ -31,6 +31,7 @@ const (
 type ResponseStatusEnum string
 
 const (
+	ResponseStatusQueued     ResponseStatusEnum = "queued"
 	ResponseStatusInProgress ResponseStatusEnum = "in_progress"
 	ResponseStatusCompleted ResponseStatusEnum = "completed"
 	ResponseStatusFailed    ResponseStatusEnum = "failed"
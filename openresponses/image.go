@@ -0,0 +1,77 @@
+package openresponses
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// ImageGenerationCallItem is an image in the output
+type ImageGenerationCallItem struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`   // "image_generation_call"
+	Status string `json:"status"` // "completed"
+	// Result is the base64 image data, or the image URL when the upstream
+	// returned a link rather than inline data
+	Result       string `json:"result"`
+	OutputFormat string `json:"output_format,omitempty"` // e.g. "png", for base64 results
+}
+
+// ImageItems returns the image parts of a chat message as image output items
+func ImageItems(responseID string, choiceIndex int, message *openai.Message) []ItemField {
+	urls := message.Images()
+	if len(urls) == 0 {
+		return nil
+	}
+	items := make([]ItemField, 0, len(urls))
+	for i, url := range urls {
+		items = append(items, newImageItem(imageItemID(responseID, choiceIndex, i), url))
+	}
+	return items
+}
+
+// imageItemID returns the ID of the i-th image item of a choice
+func imageItemID(responseID string, choiceIndex, i int) string {
+	return fmt.Sprintf("ig_%s_%d_%d", responseID, choiceIndex, i)
+}
+
+// newImageItem returns a completed image item for url, splitting data: URLs
+// into their base64 data and format
+func newImageItem(id, url string) *ImageGenerationCallItem {
+	item := &ImageGenerationCallItem{
+		ID:     id,
+		Type:   "image_generation_call",
+		Status: "completed",
+		Result: url,
+	}
+	if format, data, ok := parseDataURL(url); ok {
+		item.Result, item.OutputFormat = data, format
+	}
+	return item
+}
+
+// ImageURL returns the URL of the item's image, as a data: URL for base64 results
+func (i *ImageGenerationCallItem) ImageURL() string {
+	for _, scheme := range []string{"https://", "http://", "data:"} {
+		if strings.HasPrefix(i.Result, scheme) {
+			return i.Result
+		}
+	}
+	format := i.OutputFormat
+	if format == "" {
+		format = "png"
+	}
+	return "data:image/" + format + ";base64," + i.Result
+}
+
+// parseDataURL splits a base64 image data: URL into its format, e.g. "png",
+// and data
+func parseDataURL(url string) (string, string, bool) {
+	mediaType, data, ok := openai.ParseDataURL(url)
+	if !ok {
+		return "", "", false
+	}
+	format, ok := strings.CutPrefix(mediaType, "image/")
+	return format, data, ok
+}
@@ -0,0 +1,98 @@
+package openresponses
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DroppedFields lists the features of req that RequestToChatCompletion can't
+// represent and would silently drop, e.g. "reasoning" or
+// "input[0].content[1] (input_image)". It returns nil for lossless requests.
+func (c *Converter) DroppedFields(req *CreateRequest) []string {
+	var dropped []string
+	if req.Reasoning != nil {
+		dropped = append(dropped, "reasoning")
+	}
+	if req.Instructions != "" {
+		dropped = append(dropped, "instructions")
+	}
+	if req.Text != nil && !isTextFormat(req.Text.Format) && ResponseFormatFromText(req.Text) == nil {
+		dropped = append(dropped, "text.format")
+	}
+	if req.ToolChoice != nil {
+		dropped = append(dropped, "tool_choice")
+	}
+	if req.ParallelToolCalls != nil {
+		dropped = append(dropped, "parallel_tool_calls")
+	}
+	if req.MaxToolCalls != nil {
+		dropped = append(dropped, "max_tool_calls")
+	}
+	if req.TopLogprobs != nil {
+		dropped = append(dropped, "top_logprobs")
+	}
+
+	for i, tool := range req.Tools {
+		if _, ok := asFunctionTool(tool); !ok {
+			dropped = append(dropped, fmt.Sprintf("tools[%d] (%s)", i, typeOf(tool)))
+		}
+	}
+
+	items, _ := decodeItems(req.Input)
+	for i, item := range items {
+		if item["type"] != "message" || item["role"] == nil {
+			dropped = append(dropped, fmt.Sprintf("input[%d] (%s)", i, typeOf(item)))
+			continue
+		}
+		parts, _ := item["content"].([]any)
+		for j, part := range parts {
+			// Only input_text parts are converted
+			if p, ok := part.(map[string]any); !ok || p["type"] != "input_text" {
+				dropped = append(dropped, fmt.Sprintf("input[%d].content[%d] (%s)", i, j, typeOf(part)))
+			}
+		}
+	}
+	return dropped
+}
+
+// decodeItems returns the items of an input array as generic JSON objects
+func decodeItems(input InputParam) ([]map[string]any, error) {
+	if _, ok := input.(string); ok || input == nil {
+		return nil, nil
+	}
+	data, ok := input.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(input); err != nil {
+			return nil, err
+		}
+	}
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// isTextFormat reports whether format is unset or plain text
+func isTextFormat(format TextFormatParam) bool {
+	if format == nil {
+		return true
+	}
+	return typeOf(format) == "text"
+}
+
+// typeOf returns the "type" field of a JSON object or typed param, or "unknown"
+func typeOf(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "unknown"
+	}
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(data, &typed) != nil || typed.Type == "" {
+		return "unknown"
+	}
+	return typed.Type
+}
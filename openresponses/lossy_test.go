@@ -0,0 +1,56 @@
+package openresponses
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConverter_DroppedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "plain text",
+			body: `{"model":"gpt-4","input":"Hello","text":{"format":{"type":"text"}}}`,
+		},
+		{
+			name: "function tools and text messages",
+			body: `{"model":"gpt-4","input":[{"type":"message","role":"user","content":[{"type":"input_text","text":"Hi"}]}],"tools":[{"type":"function","name":"get_weather"}]}`,
+		},
+		{
+			name: "reasoning",
+			body: `{"model":"gpt-4","input":"Hello","reasoning":{"effort":"high"}}`,
+			want: []string{"reasoning"},
+		},
+		{
+			name: "structured input",
+			body: `{"model":"gpt-4","instructions":"Be brief","input":[{"type":"message","role":"user","content":[{"type":"input_text","text":"What is this?"},{"type":"input_image","image_url":"https://example.com/a.png"}]},{"type":"reasoning","summary":[]}],"tools":[{"type":"web_search"}]}`,
+			want: []string{"instructions", "tools[0] (web_search)", "input[0].content[1] (input_image)", "input[1] (reasoning)"},
+		},
+		{
+			name: "structured output",
+			body: `{"model":"gpt-4","input":"Hello","text":{"format":{"type":"json_schema","name":"answer","schema":{}}}}`,
+		},
+		{
+			name: "unknown text format",
+			body: `{"model":"gpt-4","input":"Hello","text":{"format":{"type":"grammar"}}}`,
+			want: []string{"text.format"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateRequest
+			if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+				t.Fatalf("failed to decode request: %v", err)
+			}
+
+			got := NewConverter().DroppedFields(&req)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected dropped fields %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package openresponses
+
+import (
+	"encoding/json"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// textFormat is the union of the text format params, as sent on the wire
+type textFormat struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Schema      map[string]any `json:"schema"`
+	Strict      *bool          `json:"strict"`
+}
+
+// ResponseFormatFromText returns the Chat Completions response_format
+// equivalent to text's format. It returns nil for plain text and for formats
+// Chat Completions can't express.
+func ResponseFormatFromText(text *TextParam) *openai.ResponseFormat {
+	if text == nil || text.Format == nil {
+		return nil
+	}
+	data, err := json.Marshal(text.Format)
+	if err != nil {
+		return nil
+	}
+	var format textFormat
+	if json.Unmarshal(data, &format) != nil {
+		return nil
+	}
+
+	switch format.Type {
+	case "json_object":
+		return &openai.ResponseFormat{Type: "json_object"}
+	case "json_schema":
+		return &openai.ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openai.JSONSchema{
+				Name:        format.Name,
+				Description: format.Description,
+				Schema:      format.Schema,
+				Strict:      format.Strict,
+			},
+		}
+	}
+	return nil
+}
+
+// TextFromResponseFormat returns the text param equivalent to a Chat
+// Completions response_format, or nil if format is nil
+func TextFromResponseFormat(format *openai.ResponseFormat) *TextParam {
+	if format == nil {
+		return nil
+	}
+	switch format.Type {
+	case "json_object":
+		return &TextParam{Format: &JsonObjectResponseFormat{Type: "json_object"}}
+	case "json_schema":
+		if format.JSONSchema == nil {
+			return nil
+		}
+		return &TextParam{Format: &JsonSchemaResponseFormatParam{
+			Type:        "json_schema",
+			Name:        format.JSONSchema.Name,
+			Description: format.JSONSchema.Description,
+			Schema:      format.JSONSchema.Schema,
+			Strict:      format.JSONSchema.Strict,
+		}}
+	}
+	return &TextParam{Format: &TextResponseFormat{Type: "text"}}
+}
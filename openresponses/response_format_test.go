@@ -0,0 +1,43 @@
+package openresponses
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResponseFormatFromText(t *testing.T) {
+	var req CreateRequest
+	body := `{"model":"gpt-4","input":"Hello","text":{"format":{"type":"json_schema","name":"answer","description":"The answer","schema":{"type":"object","required":["answer"]},"strict":true}}}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	chatReq, err := NewConverter().RequestToChatCompletion(&req)
+	if err != nil {
+		t.Fatalf("RequestToChatCompletion failed: %v", err)
+	}
+	format := chatReq.ResponseFormat
+	if format == nil || format.Type != "json_schema" || format.JSONSchema == nil {
+		t.Fatalf("expected a json_schema response format, got %+v", format)
+	}
+	schema := format.JSONSchema
+	if schema.Name != "answer" || schema.Description != "The answer" || schema.Strict == nil || !*schema.Strict || schema.Schema["type"] != "object" {
+		t.Errorf("unexpected json schema: %+v", schema)
+	}
+
+	// Mapping back gives the original format
+	text, _ := json.Marshal(TextFromResponseFormat(format))
+	want := `{"format":{"type":"json_schema","name":"answer","description":"The answer","schema":{"required":["answer"],"type":"object"},"strict":true}}`
+	if string(text) != want {
+		t.Errorf("unexpected text param:\n got %s\nwant %s", text, want)
+	}
+
+	for _, format := range []TextFormatParam{nil, &TextResponseFormat{Type: "text"}, map[string]any{"type": "grammar"}} {
+		if got := ResponseFormatFromText(&TextParam{Format: format}); got != nil {
+			t.Errorf("%v: expected no response format, got %+v", format, got)
+		}
+	}
+	if got := ResponseFormatFromText(&TextParam{Format: &JsonObjectResponseFormat{Type: "json_object"}}); got == nil || got.Type != "json_object" {
+		t.Errorf("expected a json_object response format, got %+v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package openresponses
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	openai "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// ErrResponseNotFound is returned when a stored response doesn't exist
+var ErrResponseNotFound = errors.New("response not found")
+
+// StoredResponse is a response saved for conversation continuation
+type StoredResponse struct {
+	// Response is the response returned to the client
+	Response *Response
+	// TenantID is the tenant that created the response, if authenticated
+	TenantID string
+	// Messages is the conversation sent upstream to produce the response
+	Messages []openai.Message
+}
+
+// ResponseStore persists responses so later requests can continue them
+// via previous_response_id
+type ResponseStore interface {
+	// Save stores a response under its ID
+	Save(ctx context.Context, stored *StoredResponse) error
+	// Get returns the stored response or ErrResponseNotFound
+	Get(ctx context.Context, responseID string) (*StoredResponse, error)
+	// Delete removes the stored response or returns ErrResponseNotFound
+	Delete(ctx context.Context, responseID string) error
+}
+
+// ResponseUpdater is implemented by stores that can replace a response only
+// while it is stored, so a response deleted during an update stays deleted
+type ResponseUpdater interface {
+	// Update replaces the stored response with the same ID, or returns
+	// ErrResponseNotFound without saving if there is none
+	Update(ctx context.Context, stored *StoredResponse) error
+}
+
+// MemoryResponseStore is an in-memory ResponseStore
+type MemoryResponseStore struct {
+	mu        sync.RWMutex
+	responses map[string]*StoredResponse
+}
+
+// NewMemoryResponseStore creates a new in-memory response store
+func NewMemoryResponseStore() *MemoryResponseStore {
+	return &MemoryResponseStore{
+		responses: make(map[string]*StoredResponse),
+	}
+}
+
+// Save implements ResponseStore
+func (s *MemoryResponseStore) Save(ctx context.Context, stored *StoredResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[stored.Response.ID] = stored
+	return nil
+}
+
+// Update implements ResponseUpdater
+func (s *MemoryResponseStore) Update(ctx context.Context, stored *StoredResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.responses[stored.Response.ID]; !ok {
+		return ErrResponseNotFound
+	}
+	s.responses[stored.Response.ID] = stored
+	return nil
+}
+
+// Get implements ResponseStore
+func (s *MemoryResponseStore) Get(ctx context.Context, responseID string) (*StoredResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stored, ok := s.responses[responseID]
+	if !ok {
+		return nil, ErrResponseNotFound
+	}
+	return stored, nil
+}
+
+// Delete implements ResponseStore
+func (s *MemoryResponseStore) Delete(ctx context.Context, responseID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.responses[responseID]; !ok {
+		return ErrResponseNotFound
+	}
+	delete(s.responses, responseID)
+	return nil
+}
+
+// History returns the full conversation of a stored response: the messages sent
+// upstream followed by the assistant output
+func (c *Converter) History(stored *StoredResponse) []openai.Message {
+	history := make([]openai.Message, 0, len(stored.Messages)+1)
+	history = append(history, stored.Messages...)
+	if chatResp := c.ResponseToChatCompletion(stored.Response); chatResp != nil {
+		history = append(history, chatResp.Choices[0].Message)
+	}
+	return history
+}
@@ -0,0 +1,87 @@
+package openresponses
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openai "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestMemoryResponseStore(t *testing.T) {
+	store := NewMemoryResponseStore()
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "resp_1"); !errors.Is(err, ErrResponseNotFound) {
+		t.Fatalf("Expected ErrResponseNotFound, got %v", err)
+	}
+
+	stored := &StoredResponse{
+		Response: &Response{
+			ID:     "resp_1",
+			Output: []ItemField{newOutputMessage("msg_1", MessageStatusCompleted, "Hi Alice")},
+		},
+		Messages: []openai.Message{{Role: "user", Content: "I'm Alice"}},
+	}
+	if err := store.Save(ctx, stored); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := store.Get(ctx, "resp_1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	history := NewConverter().History(got)
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history messages, got %d", len(history))
+	}
+	if history[0].Content != "I'm Alice" || history[1].Role != "assistant" || history[1].Content != "Hi Alice" {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestMemoryResponseStore_Delete(t *testing.T) {
+	store := NewMemoryResponseStore()
+	ctx := context.Background()
+
+	if err := store.Delete(ctx, "resp_1"); !errors.Is(err, ErrResponseNotFound) {
+		t.Fatalf("Expected ErrResponseNotFound, got %v", err)
+	}
+
+	if err := store.Save(ctx, &StoredResponse{Response: &Response{ID: "resp_1"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Delete(ctx, "resp_1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(ctx, "resp_1"); !errors.Is(err, ErrResponseNotFound) {
+		t.Errorf("Expected ErrResponseNotFound after delete, got %v", err)
+	}
+}
+
+func TestMemoryResponseStore_Update(t *testing.T) {
+	store := NewMemoryResponseStore()
+	ctx := context.Background()
+
+	if err := store.Update(ctx, &StoredResponse{Response: &Response{ID: "resp_1"}}); !errors.Is(err, ErrResponseNotFound) {
+		t.Fatalf("Expected ErrResponseNotFound, got %v", err)
+	}
+	if _, err := store.Get(ctx, "resp_1"); !errors.Is(err, ErrResponseNotFound) {
+		t.Errorf("Expected Update not to save a missing response, got %v", err)
+	}
+
+	if err := store.Save(ctx, &StoredResponse{Response: &Response{ID: "resp_1", Status: ResponseStatusQueued}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Update(ctx, &StoredResponse{Response: &Response{ID: "resp_1", Status: ResponseStatusCompleted}}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	stored, err := store.Get(ctx, "resp_1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Response.Status != ResponseStatusCompleted {
+		t.Errorf("Expected status completed, got %s", stored.Response.Status)
+	}
+}
@@ -12,6 +12,7 @@ type StreamWriter struct {
 	writer   io.Writer
 	flusher  http.Flusher
 	sequence int
+	newline  string
 }
 
 // NewStreamWriter creates a new StreamWriter
@@ -19,15 +20,22 @@ func NewStreamWriter(w io.Writer, flusher http.Flusher) *StreamWriter {
 	return &StreamWriter{
 		writer:  w,
 		flusher: flusher,
+		newline: "\n",
 	}
 }
 
-// WriteEvent writes a single streaming event
+// SetLineTerminator sets the terminator written after each line ("\n" by default).
+// Events are separated by an empty line, e.g. "\r\n\r\n" for "\r\n".
+func (w *StreamWriter) SetLineTerminator(newline string) {
+	w.newline = newline
+}
+
+// WriteEvent writes a single streaming event. The writer numbers every event
+// it writes, replacing the sequence number the event was created with, so
+// sequence numbers stay strictly increasing whatever produced the events.
 func (w *StreamWriter) WriteEvent(event StreamingEvent) error {
-	// Set sequence number if not already set
-	if event.GetSequenceNumber() == 0 {
-		event.SetSequenceNumber(w.NextSequence())
-	}
+	w.sequence++
+	event.SetSequenceNumber(w.sequence)
 
 	// Marshal event to JSON
 	data, err := json.Marshal(event)
@@ -38,12 +46,12 @@ func (w *StreamWriter) WriteEvent(event StreamingEvent) error {
 	// Write SSE format: event: <type>\ndata: <json>\n\n
 	eventType := event.GetType()
 	if eventType != "" {
-		if _, err := fmt.Fprintf(w.writer, "event: %s\n", eventType); err != nil {
+		if _, err := fmt.Fprintf(w.writer, "event: %s%s", eventType, w.newline); err != nil {
 			return fmt.Errorf("write event type: %w", err)
 		}
 	}
 
-	if _, err := fmt.Fprintf(w.writer, "data: %s\n\n", data); err != nil {
+	if _, err := fmt.Fprintf(w.writer, "data: %s%s%s", data, w.newline, w.newline); err != nil {
 		return fmt.Errorf("write event data: %w", err)
 	}
 
@@ -57,7 +65,7 @@ func (w *StreamWriter) WriteEvent(event StreamingEvent) error {
 
 // WriteDone writes the [DONE] marker to end the stream
 func (w *StreamWriter) WriteDone() error {
-	if _, err := fmt.Fprint(w.writer, "data: [DONE]\n\n"); err != nil {
+	if _, err := fmt.Fprint(w.writer, "data: [DONE]", w.newline, w.newline); err != nil {
 		return fmt.Errorf("write done marker: %w", err)
 	}
 	if w.flusher != nil {
@@ -66,20 +74,29 @@ func (w *StreamWriter) WriteDone() error {
 	return nil
 }
 
+// WriteComment writes an SSE comment line, which clients ignore
+func (w *StreamWriter) WriteComment(text string) error {
+	if _, err := fmt.Fprint(w.writer, ":", text, w.newline, w.newline); err != nil {
+		return fmt.Errorf("write comment: %w", err)
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
 // WriteError writes an error event and terminates the stream
 func (w *StreamWriter) WriteError(err *Error) error {
-	seq := w.NextSequence()
-	event := NewErrorStreamingEvent(seq, err)
+	event := NewErrorStreamingEvent(w.NextSequence(), err)
 	if writeErr := w.WriteEvent(event); writeErr != nil {
 		return writeErr
 	}
 	return w.WriteDone()
 }
 
-// NextSequence returns the next sequence number
+// NextSequence returns the sequence number WriteEvent assigns to the next event
 func (w *StreamWriter) NextSequence() int {
-	w.sequence++
-	return w.sequence
+	return w.sequence + 1
 }
 
 // WriteRaw writes raw SSE data (for compatibility with existing providers)
@@ -2,7 +2,9 @@ package openresponses
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -35,7 +37,7 @@ func TestStreamWriter_WriteEvent(t *testing.T) {
 			event: NewResponseInProgressEvent(2, NewResponse("resp_123", "gpt-4o")),
 			contains: []string{
 				"event: response.in_progress",
-				`"sequence_number":2`,
+				`"sequence_number":1`, // renumbered by the writer
 				`"response":{`,
 			},
 		},
@@ -50,7 +52,7 @@ func TestStreamWriter_WriteEvent(t *testing.T) {
 			}),
 			contains: []string{
 				"event: response.output_item.added",
-				`"sequence_number":3`,
+				`"sequence_number":1`, // renumbered by the writer
 				`"output_index":0`,
 				`"type":"message"`,
 			},
@@ -60,7 +62,7 @@ func TestStreamWriter_WriteEvent(t *testing.T) {
 			event: NewResponseOutputTextDeltaEvent(4, "msg_123", 0, 0, "Hello"),
 			contains: []string{
 				"event: response.output_text.delta",
-				`"sequence_number":4`,
+				`"sequence_number":1`, // renumbered by the writer
 				`"item_id":"msg_123"`,
 				`"delta":"Hello"`,
 			},
@@ -174,6 +176,53 @@ func TestStreamWriter_AutoSequenceNumber(t *testing.T) {
 	}
 }
 
+func TestStreamWriter_SequenceContinuity(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewStreamWriter(&buf, &mockFlusher{})
+	converter := NewConverter()
+	items := NewStreamItems("resp_123")
+	seq := 0
+
+	// Mirror the responses handler: writer-numbered lifecycle events around
+	// converter events numbered from their own counter
+	resp := NewResponse("resp_123", "gpt-4o")
+	writer.WriteEvent(NewResponseCreatedEvent(writer.NextSequence(), resp))
+	writer.WriteEvent(NewResponseInProgressEvent(writer.NextSequence(), resp))
+	for _, chunk := range []string{
+		`{"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`{"choices":[{"index":0,"delta":{"content":" there"},"finish_reason":"stop"}]}`,
+	} {
+		for _, event := range converter.StreamingChunkToEvents([]byte(chunk), &seq, items) {
+			writer.WriteEvent(event)
+		}
+	}
+	writer.WriteEvent(&BaseStreamingEvent{Type: "test.event"})
+	writer.WriteEvent(NewResponseCompletedEvent(0, resp))
+	writer.WriteError(NewError("server_error", "test", "late error", ""))
+
+	var got []int
+	for _, line := range strings.Split(buf.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var event BaseStreamingEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		got = append(got, event.SequenceNumber)
+	}
+
+	if len(got) < 10 {
+		t.Fatalf("expected a full event stream, got %d events", len(got))
+	}
+	for i, n := range got {
+		if n != i+1 {
+			t.Fatalf("expected sequence numbers 1..%d, got %v", len(got), got)
+		}
+	}
+}
+
 func TestStreamWriter_WriteRaw(t *testing.T) {
 	var buf bytes.Buffer
 	flusher := &mockFlusher{}
@@ -256,3 +305,21 @@ func TestStreamingEvent_SequenceNumber(t *testing.T) {
 		t.Errorf("Expected sequence number 42, got %d", event.GetSequenceNumber())
 	}
 }
+
+func TestStreamWriter_LineTerminator(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewStreamWriter(&buf, nil)
+	writer.SetLineTerminator("\r\n")
+
+	writer.WriteComment("ok")
+	writer.WriteEvent(NewResponseCreatedEvent(1, NewResponse("resp_123", "gpt-4o")))
+	writer.WriteDone()
+
+	output := buf.String()
+	if !strings.HasPrefix(output, ":ok\r\n\r\nevent: response.created\r\ndata: {") {
+		t.Errorf("unexpected framing: %q", output)
+	}
+	if !strings.HasSuffix(output, "}\r\n\r\ndata: [DONE]\r\n\r\n") {
+		t.Errorf("unexpected framing: %q", output)
+	}
+}
@@ -301,6 +301,17 @@ func NewResponseCompletedEvent(seq int, response *Response) *ResponseCompletedEv
 	}
 }
 
+// NewResponseIncompleteEvent creates a new ResponseIncompleteEvent
+func NewResponseIncompleteEvent(seq int, response *Response) *ResponseIncompleteEvent {
+	return &ResponseIncompleteEvent{
+		BaseStreamingEvent: BaseStreamingEvent{
+			Type:          "response.incomplete",
+			SequenceNumber: seq,
+		},
+		Response: response,
+	}
+}
+
 // NewResponseFailedEvent creates a new ResponseFailedEvent
 func NewResponseFailedEvent(seq int, responseID string, err *Error) *ResponseFailedEvent {
 	return &ResponseFailedEvent{
@@ -351,6 +362,20 @@ func NewResponseContentPartAddedEvent(seq int, itemID string, outputIndex, conte
 	}
 }
 
+// NewResponseContentPartDoneEvent creates a new ResponseContentPartDoneEvent
+func NewResponseContentPartDoneEvent(seq int, itemID string, outputIndex, contentIndex int, part ContentPart) *ResponseContentPartDoneEvent {
+	return &ResponseContentPartDoneEvent{
+		BaseStreamingEvent: BaseStreamingEvent{
+			Type:          "response.content_part.done",
+			SequenceNumber: seq,
+		},
+		ItemID:       itemID,
+		OutputIndex:  outputIndex,
+		ContentIndex: contentIndex,
+		Part:         part,
+	}
+}
+
 // NewResponseOutputTextDeltaEvent creates a new ResponseOutputTextDeltaEvent
 func NewResponseOutputTextDeltaEvent(seq int, itemID string, outputIndex, contentIndex int, delta string) *ResponseOutputTextDeltaEvent {
 	return &ResponseOutputTextDeltaEvent{
@@ -381,6 +406,32 @@ func NewResponseOutputTextDoneEvent(seq int, itemID string, outputIndex, content
 	}
 }
 
+// NewResponseFunctionCallArgumentsDeltaEvent creates a new ResponseFunctionCallArgumentsDeltaEvent
+func NewResponseFunctionCallArgumentsDeltaEvent(seq int, itemID string, outputIndex int, delta string) *ResponseFunctionCallArgumentsDeltaEvent {
+	return &ResponseFunctionCallArgumentsDeltaEvent{
+		BaseStreamingEvent: BaseStreamingEvent{
+			Type:           "response.function_call_arguments.delta",
+			SequenceNumber: seq,
+		},
+		ItemID:      itemID,
+		OutputIndex: outputIndex,
+		Delta:       delta,
+	}
+}
+
+// NewResponseFunctionCallArgumentsDoneEvent creates a new ResponseFunctionCallArgumentsDoneEvent
+func NewResponseFunctionCallArgumentsDoneEvent(seq int, itemID string, outputIndex int, arguments string) *ResponseFunctionCallArgumentsDoneEvent {
+	return &ResponseFunctionCallArgumentsDoneEvent{
+		BaseStreamingEvent: BaseStreamingEvent{
+			Type:           "response.function_call_arguments.done",
+			SequenceNumber: seq,
+		},
+		ItemID:      itemID,
+		OutputIndex: outputIndex,
+		Arguments:   arguments,
+	}
+}
+
 // NewErrorStreamingEvent creates a new ErrorStreamingEvent
 func NewErrorStreamingEvent(seq int, err *Error) *ErrorStreamingEvent {
 	return &ErrorStreamingEvent{
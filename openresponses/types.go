@@ -30,6 +30,10 @@ type CreateRequest struct {
 	Store              *bool             `json:"store,omitempty"`
 	ServiceTier        ServiceTierEnum  `json:"service_tier,omitempty"`
 	TopLogprobs        *int              `json:"top_logprobs,omitempty"`
+
+	// N requests multiple choices from Chat Completions upstreams (gateway extension).
+	// Each choice becomes its own output item.
+	N *int `json:"n,omitempty"`
 }
 
 // InputParam represents the input which can be a string or array of items
@@ -0,0 +1,259 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// DefaultMaxTokens is used when the OpenAI request doesn't set max_tokens,
+// which Anthropic requires
+const DefaultMaxTokens = 4096
+
+// NewBodySerializer returns a serializer that encodes OpenAI-format chat
+// requests as Anthropic messages request bodies
+func NewBodySerializer() provider.BodySerializer {
+	return provider.BodySerializerFunc(func(req *provider.Request, body any) ([]byte, string, error) {
+		chatReq, ok := body.(*openai.ChatCompletionRequest)
+		if !ok {
+			return nil, "", fmt.Errorf("anthropic: unsupported request body %T", body)
+		}
+		if err := checkImages(chatReq); err != nil {
+			return nil, "", err
+		}
+		data, err := json.Marshal(OpenAIToAnthropic(chatReq, req.Model))
+		return data, "application/json", err
+	})
+}
+
+// checkImages rejects images Anthropic can't take: it only accepts images
+// in user messages
+func checkImages(req *openai.ChatCompletionRequest) error {
+	for i := range req.Messages {
+		msg := &req.Messages[i]
+		if msg.Role != "user" && len(msg.Images()) > 0 {
+			return fmt.Errorf("anthropic: %s messages cannot contain images", msg.Role)
+		}
+	}
+	return nil
+}
+
+// OpenAIToAnthropic converts an OpenAI request to Anthropic format
+func OpenAIToAnthropic(req *openai.ChatCompletionRequest, model string) *MessagesRequest {
+	anthropicReq := &MessagesRequest{
+		Model:       model,
+		Messages:    make([]Message, 0, len(req.Messages)),
+		MaxTokens:   DefaultMaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stream:      req.Stream,
+	}
+	if maxTokens := req.OutputTokenLimit(); maxTokens != nil && *maxTokens > 0 {
+		anthropicReq.MaxTokens = *maxTokens
+	}
+
+	// Anthropic takes the system prompt as a separate field
+	systemMessages, messages := provider.ApplySystemMessageMode(provider.SystemTopLevel, req.Messages)
+	var system []ContentBlock
+	for _, msg := range systemMessages {
+		system = append(system, ContentBlock{Type: "text", Text: msg.Content, CacheControl: msg.CacheControl})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case "assistant":
+			var blocks []ContentBlock
+			if msg.Content != "" {
+				blocks = append(blocks, ContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := json.RawMessage(tc.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, ContentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: input,
+				})
+			}
+			// The hint covers the whole message, so it ends at its last block
+			if len(blocks) > 0 {
+				blocks[len(blocks)-1].CacheControl = msg.CacheControl
+			}
+			anthropicReq.appendBlocks("assistant", blocks)
+		case "tool":
+			// Tool results are sent back as user content
+			anthropicReq.appendBlocks("user", []ContentBlock{{
+				Type:         "tool_result",
+				ToolUseID:    msg.ToolCallID,
+				Content:      msg.Content,
+				CacheControl: msg.CacheControl,
+			}})
+		default:
+			blocks := userBlocks(&msg)
+			blocks[len(blocks)-1].CacheControl = msg.CacheControl
+			anthropicReq.appendBlocks("user", blocks)
+		}
+	}
+	anthropicReq.System = systemPrompt(system)
+
+	// Handle Stop which can be string or []string
+	switch stop := req.Stop.(type) {
+	case string:
+		if stop != "" {
+			anthropicReq.StopSequences = []string{stop}
+		}
+	case []string:
+		anthropicReq.StopSequences = stop
+	case []any:
+		for _, s := range stop {
+			if str, ok := s.(string); ok {
+				anthropicReq.StopSequences = append(anthropicReq.StopSequences, str)
+			}
+		}
+	}
+
+	for _, tool := range req.Tools {
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		anthropicReq.Tools = append(anthropicReq.Tools, Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	anthropicReq.ToolChoice = convertToolChoice(req.ToolChoice)
+
+	return anthropicReq
+}
+
+// userBlocks converts the content of a user message to text and image blocks
+func userBlocks(msg *openai.Message) []ContentBlock {
+	parts := msg.ContentParts()
+	blocks := make([]ContentBlock, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == openai.ContentPartImageURL && part.ImageURL != nil {
+			blocks = append(blocks, ContentBlock{Type: "image", Source: imageSource(part.ImageURL.URL)})
+		} else {
+			blocks = append(blocks, ContentBlock{Type: "text", Text: part.Text})
+		}
+	}
+	return blocks
+}
+
+// imageSource returns the source of an image given as a data: URL or a link
+func imageSource(url string) *ImageSource {
+	if mediaType, data, ok := openai.ParseDataURL(url); ok {
+		return &ImageSource{Type: "base64", MediaType: mediaType, Data: data}
+	}
+	return &ImageSource{Type: "url", URL: url}
+}
+
+// systemPrompt returns the system prompt as a string, or as text blocks if any
+// carries a cache hint, which only blocks can
+func systemPrompt(blocks []ContentBlock) any {
+	if len(blocks) == 0 {
+		return nil
+	}
+	texts := make([]string, len(blocks))
+	for i, block := range blocks {
+		if block.CacheControl != nil {
+			return blocks
+		}
+		texts[i] = block.Text
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// appendBlocks appends content blocks, merging consecutive messages of the
+// same role since Anthropic requires alternating roles
+func (r *MessagesRequest) appendBlocks(role string, blocks []ContentBlock) {
+	if len(blocks) == 0 {
+		return
+	}
+	if n := len(r.Messages); n > 0 && r.Messages[n-1].Role == role {
+		r.Messages[n-1].Content = append(r.Messages[n-1].Content, blocks...)
+		return
+	}
+	r.Messages = append(r.Messages, Message{Role: role, Content: blocks})
+}
+
+// convertToolChoice converts an OpenAI tool_choice to Anthropic format
+func convertToolChoice(choice any) *ToolChoice {
+	switch c := choice.(type) {
+	case string:
+		switch c {
+		case "auto":
+			return &ToolChoice{Type: "auto"}
+		case "required":
+			return &ToolChoice{Type: "any"}
+		case "none":
+			return &ToolChoice{Type: "none"}
+		}
+	case map[string]any:
+		if fn, ok := c["function"].(map[string]any); ok {
+			if name, ok := fn["name"].(string); ok {
+				return &ToolChoice{Type: "tool", Name: name}
+			}
+		}
+	}
+	return nil
+}
+
+// AnthropicToOpenAI converts an Anthropic response to OpenAI format
+func AnthropicToOpenAI(resp *MessagesResponse, model string) *openai.ChatCompletionResponse {
+	message := openai.Message{Role: "assistant"}
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			message.Content += block.Text
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: openai.FunctionCall{
+					Name:      block.Name,
+					Arguments: string(block.Input),
+				},
+			})
+		}
+	}
+
+	return &openai.ChatCompletionResponse{
+		ID:      resp.ID,
+		Object:  "chat.completion",
+		Created: 0, // Anthropic doesn't provide timestamp
+		Model:   model,
+		Choices: []openai.Choice{{
+			Index:        0,
+			Message:      message,
+			FinishReason: mapStopReason(resp.StopReason),
+		}},
+		Usage: openai.Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}
+}
+
+// mapStopReason maps Anthropic stop reasons to OpenAI format
+func mapStopReason(reason string) string {
+	switch reason {
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "refusal":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
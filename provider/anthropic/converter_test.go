@@ -0,0 +1,248 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestOpenAIToAnthropic(t *testing.T) {
+	temp := 0.7
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "system", Content: "You are helpful"},
+			{Role: "user", Content: "Hello"},
+		},
+		Temperature: &temp,
+	}
+
+	anthropicReq := OpenAIToAnthropic(openaiReq, "claude-sonnet")
+
+	if anthropicReq.Model != "claude-sonnet" {
+		t.Errorf("expected model claude-sonnet, got %s", anthropicReq.Model)
+	}
+	if anthropicReq.System != "You are helpful" {
+		t.Errorf("expected system prompt, got %s", anthropicReq.System)
+	}
+	if len(anthropicReq.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(anthropicReq.Messages))
+	}
+	if anthropicReq.Messages[0].Role != "user" || anthropicReq.Messages[0].Content[0].Text != "Hello" {
+		t.Errorf("unexpected message %+v", anthropicReq.Messages[0])
+	}
+	if anthropicReq.MaxTokens != DefaultMaxTokens {
+		t.Errorf("expected default max tokens %d, got %d", DefaultMaxTokens, anthropicReq.MaxTokens)
+	}
+	if anthropicReq.Temperature == nil || *anthropicReq.Temperature != 0.7 {
+		t.Errorf("expected temperature 0.7, got %v", anthropicReq.Temperature)
+	}
+}
+
+func TestOpenAIToAnthropicImages(t *testing.T) {
+	msg := openai.Message{Role: "user", Content: "Compare these"}
+	msg.AppendImage("data:image/png;base64,iVBORw0KGgo=")
+	msg.AppendImage("https://example.com/cat.png")
+	req := &openai.ChatCompletionRequest{Model: "gpt-4o", Messages: []openai.Message{msg}}
+
+	blocks := OpenAIToAnthropic(req, "claude-sonnet").Messages[0].Content
+	if len(blocks) != 3 || blocks[0].Text != "Compare these" {
+		t.Fatalf("expected the text and two images, got %+v", blocks)
+	}
+	if src := blocks[1].Source; blocks[1].Type != "image" || src == nil || src.Type != "base64" || src.MediaType != "image/png" || src.Data != "iVBORw0KGgo=" {
+		t.Errorf("expected a base64 image block, got %+v", blocks[1])
+	}
+	if src := blocks[2].Source; src == nil || src.Type != "url" || src.URL != "https://example.com/cat.png" {
+		t.Errorf("expected a url image block, got %+v", blocks[2])
+	}
+
+	// Anthropic only takes images from the user
+	answer := openai.Message{Role: "assistant"}
+	answer.AppendImage("https://example.com/dog.png")
+	req.Messages = append(req.Messages, answer)
+	if _, _, err := NewBodySerializer().Serialize(&provider.Request{Model: "claude-sonnet"}, req); err == nil {
+		t.Error("expected an error for an assistant image")
+	}
+}
+
+func TestOpenAIToAnthropicCacheControl(t *testing.T) {
+	hint := &openai.CacheControl{Type: openai.CacheControlEphemeral}
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "system", Content: "You are helpful", CacheControl: hint},
+			{Role: "system", Content: "Answer briefly"},
+			{Role: "user", Content: "Hello", CacheControl: hint},
+		},
+	}
+
+	data, err := json.Marshal(OpenAIToAnthropic(openaiReq, "claude-sonnet"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var body struct {
+		System   []ContentBlock `json:"system"`
+		Messages []Message      `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("expected system as content blocks: %v\n%s", err, data)
+	}
+
+	if len(body.System) != 2 || body.System[0].Text != "You are helpful" || body.System[1].Text != "Answer briefly" {
+		t.Fatalf("unexpected system blocks %+v", body.System)
+	}
+	if body.System[0].CacheControl == nil || body.System[0].CacheControl.Type != "ephemeral" {
+		t.Errorf("expected cache_control on the hinted system block, got %+v", body.System[0])
+	}
+	if body.System[1].CacheControl != nil {
+		t.Errorf("expected no cache_control on the unhinted system block, got %+v", body.System[1])
+	}
+	if cc := body.Messages[0].Content[0].CacheControl; cc == nil || cc.Type != "ephemeral" {
+		t.Errorf("expected cache_control on the user block, got %+v", body.Messages[0].Content[0])
+	}
+}
+
+func TestOpenAIToAnthropicMaxTokensAndStop(t *testing.T) {
+	maxTokens := 256
+	openaiReq := &openai.ChatCompletionRequest{
+		Messages:  []openai.Message{{Role: "user", Content: "Hello"}},
+		MaxTokens: &maxTokens,
+		Stop:      "END",
+	}
+
+	anthropicReq := OpenAIToAnthropic(openaiReq, "claude-sonnet")
+
+	if anthropicReq.MaxTokens != 256 {
+		t.Errorf("expected max tokens 256, got %d", anthropicReq.MaxTokens)
+	}
+	if len(anthropicReq.StopSequences) != 1 || anthropicReq.StopSequences[0] != "END" {
+		t.Errorf("expected stop sequences [END], got %v", anthropicReq.StopSequences)
+	}
+
+	// max_completion_tokens takes precedence
+	maxCompletionTokens := 1024
+	openaiReq.MaxCompletionTokens = &maxCompletionTokens
+	if anthropicReq := OpenAIToAnthropic(openaiReq, "claude-sonnet"); anthropicReq.MaxTokens != 1024 {
+		t.Errorf("expected max tokens 1024, got %d", anthropicReq.MaxTokens)
+	}
+}
+
+func TestOpenAIToAnthropicTools(t *testing.T) {
+	openaiReq := &openai.ChatCompletionRequest{
+		Messages: []openai.Message{
+			{Role: "user", Content: "What's the weather in Paris?"},
+			{
+				Role: "assistant",
+				ToolCalls: []openai.ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+				}},
+			},
+			{Role: "tool", ToolCallID: "call_1", Content: "Sunny"},
+		},
+		Tools: []openai.Tool{{
+			Type: "function",
+			Function: openai.FunctionDefinition{
+				Name:       "get_weather",
+				Parameters: map[string]any{"type": "object"},
+			},
+		}},
+		ToolChoice: "required",
+	}
+
+	anthropicReq := OpenAIToAnthropic(openaiReq, "claude-sonnet")
+
+	if len(anthropicReq.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(anthropicReq.Messages))
+	}
+
+	toolUse := anthropicReq.Messages[1].Content[0]
+	if toolUse.Type != "tool_use" || toolUse.ID != "call_1" || toolUse.Name != "get_weather" {
+		t.Errorf("unexpected tool_use block %+v", toolUse)
+	}
+	if string(toolUse.Input) != `{"city":"Paris"}` {
+		t.Errorf("expected input {\"city\":\"Paris\"}, got %s", toolUse.Input)
+	}
+
+	toolResult := anthropicReq.Messages[2]
+	if toolResult.Role != "user" || toolResult.Content[0].Type != "tool_result" || toolResult.Content[0].ToolUseID != "call_1" {
+		t.Errorf("unexpected tool_result message %+v", toolResult)
+	}
+
+	if len(anthropicReq.Tools) != 1 || anthropicReq.Tools[0].Name != "get_weather" {
+		t.Errorf("unexpected tools %+v", anthropicReq.Tools)
+	}
+	if anthropicReq.ToolChoice == nil || anthropicReq.ToolChoice.Type != "any" {
+		t.Errorf("expected tool choice any, got %+v", anthropicReq.ToolChoice)
+	}
+}
+
+func TestAnthropicToOpenAI(t *testing.T) {
+	anthropicResp := &MessagesResponse{
+		ID:   "msg_1",
+		Role: "assistant",
+		Content: []ContentBlock{
+			{Type: "text", Text: "Hi there"},
+		},
+		StopReason: "end_turn",
+		Usage:      Usage{InputTokens: 10, OutputTokens: 5},
+	}
+
+	openaiResp := AnthropicToOpenAI(anthropicResp, "claude-sonnet")
+
+	if openaiResp.Object != "chat.completion" {
+		t.Errorf("expected object chat.completion, got %s", openaiResp.Object)
+	}
+	if len(openaiResp.Choices) != 1 {
+		t.Fatalf("expected 1 choice, got %d", len(openaiResp.Choices))
+	}
+	if openaiResp.Choices[0].Message.Content != "Hi there" {
+		t.Errorf("expected content 'Hi there', got %s", openaiResp.Choices[0].Message.Content)
+	}
+	if openaiResp.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected finish reason stop, got %s", openaiResp.Choices[0].FinishReason)
+	}
+	if openaiResp.Usage.TotalTokens != 15 {
+		t.Errorf("expected total tokens 15, got %d", openaiResp.Usage.TotalTokens)
+	}
+}
+
+func TestAnthropicToOpenAIToolUse(t *testing.T) {
+	anthropicResp := &MessagesResponse{
+		Content: []ContentBlock{
+			{Type: "tool_use", ID: "toolu_1", Name: "get_weather", Input: json.RawMessage(`{"city":"Paris"}`)},
+		},
+		StopReason: "tool_use",
+	}
+
+	openaiResp := AnthropicToOpenAI(anthropicResp, "claude-sonnet")
+
+	choice := openaiResp.Choices[0]
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got %s", choice.FinishReason)
+	}
+	if len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(choice.Message.ToolCalls))
+	}
+	tc := choice.Message.ToolCalls[0]
+	if tc.ID != "toolu_1" || tc.Function.Name != "get_weather" || tc.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call %+v", tc)
+	}
+}
+
+func TestMapStopReason(t *testing.T) {
+	tests := map[string]string{
+		"end_turn":      "stop",
+		"stop_sequence": "stop",
+		"max_tokens":    "length",
+		"tool_use":      "tool_calls",
+	}
+	for reason, want := range tests {
+		if got := mapStopReason(reason); got != want {
+			t.Errorf("mapStopReason(%s) = %s, want %s", reason, got, want)
+		}
+	}
+}
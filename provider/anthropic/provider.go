@@ -0,0 +1,174 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+const (
+	// DefaultBaseURL is the base URL of the Anthropic API
+	DefaultBaseURL = "https://api.anthropic.com"
+
+	// DefaultVersion is the anthropic-version header sent with each request
+	DefaultVersion = "2023-06-01"
+)
+
+// APIError is an error returned by the Anthropic API
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Type is the Anthropic error type (e.g., "invalid_request_error")
+	Type string
+	// Message is the error message
+	Message string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Type != "" {
+		return fmt.Sprintf("anthropic error %d (%s): %s", e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("anthropic error %d: %s", e.StatusCode, e.Message)
+}
+
+// Provider sends requests to the Anthropic messages API
+type Provider struct {
+	*provider.BaseProvider
+	client *http.Client
+}
+
+// NewProvider creates a new Anthropic provider with a copy of the given
+// configuration. BaseURL defaults to DefaultBaseURL and Name defaults to "anthropic".
+func NewProvider(config *provider.ProviderConfig) *Provider {
+	if config == nil {
+		config = provider.DefaultConfig()
+	} else {
+		config = config.Clone()
+	}
+	if config.Name == "" {
+		config.Name = "anthropic"
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	config.SupportedAPIs = provider.APITypeChatCompletions
+	// The messages API has no equivalent of n
+	config.SingleChoice = true
+	config.PromptCaching = true
+	config.SystemMessages = provider.SystemTopLevel
+	if config.BodySerializer == nil {
+		config.BodySerializer = NewBodySerializer()
+	}
+
+	return &Provider{
+		BaseProvider: provider.NewBaseProvider(config),
+		client:       config.GetHTTPClient(),
+	}
+}
+
+// NewProviderWithAPIKey creates a new Anthropic provider for the public API
+func NewProviderWithAPIKey(apiKey string) *Provider {
+	return NewProvider(provider.NewProviderConfig("anthropic").WithAPIKey(apiKey))
+}
+
+// SendRequest implements provider.Provider.SendRequest
+func (p *Provider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	// Responses requests are served through Chat Completions
+	if req.APIType == provider.APITypeResponses {
+		if err := p.ConvertRequestIfNeeded(req); err != nil {
+			return nil, fmt.Errorf("convert request: %w", err)
+		}
+	}
+
+	if req.APIType != provider.APITypeChatCompletions {
+		return nil, fmt.Errorf("API type %v not supported by provider %s", req.APIType, p.Name())
+	}
+	if req.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	p.ForwardClientHeaders(ctx, req)
+
+	chatReq, err := p.ParseChatCompletionRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse chat completion request: %w", err)
+	}
+
+	body, headers, err := p.SerializeBody(req, chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.do(ctx, body, headers, req.Stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, p.StatusError(resp, respBody, parseError(resp.StatusCode, respBody))
+	}
+
+	if req.Stream {
+		return p.streamResponse(ctx, resp, req.Model), nil
+	}
+	defer resp.Body.Close()
+
+	var anthropicResp MessagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
+		return nil, provider.DecodeError(err)
+	}
+
+	return provider.NewChatCompletionResponse(AnthropicToOpenAI(&anthropicResp, req.Model)), nil
+}
+
+// do sends a messages request and returns the raw HTTP response
+func (p *Provider) do(ctx context.Context, body []byte, headers map[string]string, stream bool) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.Config().BaseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", DefaultVersion)
+	if apiKey := p.Config().APIKey; apiKey != "" {
+		httpReq.Header.Set("x-api-key", apiKey)
+	}
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(httpReq)
+	provider.LogAttempt(ctx, p.Name(), start, resp, err)
+	if err != nil {
+		return nil, provider.TransportError(err)
+	}
+	return resp, nil
+}
+
+// parseError converts an error response body to an *APIError
+func parseError(statusCode int, body []byte) error {
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error.Message == "" {
+		return &APIError{StatusCode: statusCode, Message: string(body)}
+	}
+	return &APIError{
+		StatusCode: statusCode,
+		Type:       errResp.Error.Type,
+		Message:    errResp.Error.Message,
+	}
+}
+
+// Ensure Provider implements provider.Provider
+var _ provider.Provider = (*Provider)(nil)
@@ -0,0 +1,200 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestProvider_SendRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("expected x-api-key 'test-key', got '%s'", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") != DefaultVersion {
+			t.Errorf("expected anthropic-version %s, got '%s'", DefaultVersion, r.Header.Get("anthropic-version"))
+		}
+
+		var req MessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Model != "claude-sonnet" || req.MaxTokens == 0 {
+			t.Errorf("unexpected request %+v", req)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"content": [{"type": "text", "text": "Hi there"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 3, "output_tokens": 2}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("anthropic").WithBaseURL(server.URL).WithAPIKey("test-key"))
+
+	req := provider.NewChatCompletionsRequest("claude-sonnet", []openai.Message{{Role: "user", Content: "Hello"}})
+	resp, err := p.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chatResp, err := resp.GetChatCompletion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chatResp.Choices[0].Message.Content != "Hi there" {
+		t.Errorf("expected content 'Hi there', got '%s'", chatResp.Choices[0].Message.Content)
+	}
+	if chatResp.Usage.TotalTokens != 5 {
+		t.Errorf("expected 5 total tokens, got %d", chatResp.Usage.TotalTokens)
+	}
+}
+
+func TestProvider_SendRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("anthropic").WithBaseURL(server.URL))
+
+	req := provider.NewChatCompletionsRequest("claude-sonnet", []openai.Message{{Role: "user", Content: "Hello"}})
+	_, err := p.SendRequest(context.Background(), req)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Type != "authentication_error" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if kind, ok := provider.ErrorKindOf(err); !ok || kind != provider.ErrorKindUpstream {
+		t.Errorf("expected an upstream provider.Error, got %v", err)
+	}
+}
+
+func TestProvider_SendRequestRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"type": "error", "error": {"type": "rate_limit_error", "message": "slow down"}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("anthropic").WithBaseURL(server.URL))
+	req := provider.NewChatCompletionsRequest("claude-sonnet", []openai.Message{{Role: "user", Content: "Hello"}})
+	_, err := p.SendRequest(context.Background(), req)
+
+	var provErr *provider.Error
+	if !errors.As(err, &provErr) || provErr.Kind != provider.ErrorKindRateLimited {
+		t.Fatalf("expected a rate limited provider.Error, got %v", err)
+	}
+	if got := provErr.Headers.Get("Retry-After"); got != "7" {
+		t.Errorf("expected Retry-After 7, got %q", got)
+	}
+}
+
+func TestProvider_SendRequestStream(t *testing.T) {
+	events := []string{
+		`{"type": "message_start", "message": {"id": "msg_1", "role": "assistant", "content": []}}`,
+		`{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`,
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Let me "}}`,
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "check."}}`,
+		`{"type": "content_block_stop", "index": 0}`,
+		`{"type": "content_block_start", "index": 1, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {}}}`,
+		`{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "{\"city\":"}}`,
+		`{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "\"Paris\"}"}}`,
+		`{"type": "content_block_stop", "index": 1}`,
+		`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 12}}`,
+		`{"type": "message_stop"}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			w.Write([]byte("event: x\ndata: " + event + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("anthropic").WithBaseURL(server.URL))
+
+	req := provider.NewChatCompletionsRequest("claude-sonnet", []openai.Message{{Role: "user", Content: "Weather?"}})
+	req.Stream = true
+
+	resp, err := p.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Close()
+
+	var content, finishReason, toolName, toolArgs string
+	var done bool
+	for chunk := range resp.Chunks {
+		if chunk.Done {
+			done = true
+			continue
+		}
+
+		var streamResp openai.ChatCompletionStreamResponse
+		if err := json.Unmarshal(chunk.OpenAI.Data, &streamResp); err != nil {
+			t.Fatalf("decode chunk: %v", err)
+		}
+		if streamResp.ID != "msg_1" {
+			t.Errorf("expected id msg_1, got %s", streamResp.ID)
+		}
+		for _, choice := range streamResp.Choices {
+			content += choice.Delta.Content
+			for _, tc := range choice.Delta.ToolCalls {
+				toolName += tc.Function.Name
+				toolArgs += tc.Function.Arguments
+			}
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+	if err := <-resp.Errors; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if content != "Let me check." {
+		t.Errorf("expected content 'Let me check.', got '%s'", content)
+	}
+	if toolName != "get_weather" || toolArgs != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call %s(%s)", toolName, toolArgs)
+	}
+	if finishReason != "tool_calls" {
+		t.Errorf("expected finish reason tool_calls, got '%s'", finishReason)
+	}
+	if !done {
+		t.Error("expected done chunk")
+	}
+}
+
+func TestNewProviderCopiesConfig(t *testing.T) {
+	config := provider.NewProviderConfig("").WithAPIKey("test-key")
+	p := NewProvider(config)
+
+	if config.Name != "" || config.BaseURL != "" || config.SingleChoice || config.BodySerializer != nil {
+		t.Errorf("expected the caller's config unchanged, got %+v", config)
+	}
+	if p.Name() != "anthropic" || p.Config().BaseURL != DefaultBaseURL || !p.Config().SingleChoice {
+		t.Errorf("expected the defaults on the provider's copy, got %s and %s", p.Name(), p.Config().BaseURL)
+	}
+}
@@ -0,0 +1,172 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// streamResponse converts an Anthropic SSE stream to OpenAI chat.completion.chunk events
+func (p *Provider) streamResponse(ctx context.Context, resp *http.Response, model string) *provider.Response {
+	chunkChan := make(chan *provider.Chunk, 16)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+		defer resp.Body.Close()
+
+		// Close the upstream body as soon as the caller goes away
+		stop := context.AfterFunc(ctx, func() {
+			resp.Body.Close()
+		})
+		defer stop()
+
+		conv := newStreamConverter(model)
+		decoder := provider.NewSSEDecoder(resp.Body)
+		for {
+			sse, readErr := decoder.Next()
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				if ctx.Err() == nil {
+					errChan <- provider.TransportError(readErr)
+				}
+				return
+			}
+
+			if sse.Data != "" {
+				var event StreamEvent
+				if err := json.Unmarshal([]byte(sse.Data), &event); err != nil {
+					errChan <- provider.DecodeError(err)
+					return
+				}
+
+				if event.Type == "error" && event.Error != nil {
+					errChan <- &APIError{StatusCode: http.StatusOK, Type: event.Error.Type, Message: event.Error.Message}
+					return
+				}
+
+				if chunk := conv.convert(&event); chunk != nil {
+					data, err := json.Marshal(chunk)
+					if err != nil {
+						errChan <- fmt.Errorf("marshal chunk: %w", err)
+						return
+					}
+					select {
+					case chunkChan <- provider.NewOpenAIChunk(data):
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				if event.Type == "message_stop" {
+					break
+				}
+			}
+		}
+
+		select {
+		case chunkChan <- provider.NewOpenAIChunkDone():
+		case <-ctx.Done():
+		}
+	}()
+
+	closeFn := func() error {
+		return resp.Body.Close()
+	}
+
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, closeFn)
+}
+
+// streamConverter tracks state while converting Anthropic stream events
+type streamConverter struct {
+	id      string
+	model   string
+	created int64
+
+	// toolIndexes maps content block indexes to OpenAI tool call indexes
+	toolIndexes map[int]int
+}
+
+// newStreamConverter creates a new stream converter
+func newStreamConverter(model string) *streamConverter {
+	return &streamConverter{
+		id:          fmt.Sprintf("anthropic-%d", time.Now().UnixNano()),
+		model:       model,
+		created:     time.Now().Unix(),
+		toolIndexes: make(map[int]int),
+	}
+}
+
+// convert converts a stream event to an OpenAI chunk, or nil if the event
+// carries nothing to forward
+func (c *streamConverter) convert(event *StreamEvent) *openai.ChatCompletionStreamResponse {
+	switch event.Type {
+	case "message_start":
+		if event.Message != nil && event.Message.ID != "" {
+			c.id = event.Message.ID
+		}
+		return c.chunk(&openai.Delta{Role: "assistant"}, "")
+
+	case "content_block_start":
+		if event.ContentBlock == nil || event.ContentBlock.Type != "tool_use" {
+			return nil
+		}
+		index := len(c.toolIndexes)
+		c.toolIndexes[event.Index] = index
+		return c.chunk(&openai.Delta{ToolCalls: []openai.ToolCall{{
+			Index:    &index,
+			ID:       event.ContentBlock.ID,
+			Type:     "function",
+			Function: openai.FunctionCall{Name: event.ContentBlock.Name},
+		}}}, "")
+
+	case "content_block_delta":
+		if event.Delta == nil {
+			return nil
+		}
+		switch event.Delta.Type {
+		case "text_delta":
+			return c.chunk(&openai.Delta{Content: event.Delta.Text}, "")
+		case "input_json_delta":
+			index, ok := c.toolIndexes[event.Index]
+			if !ok {
+				return nil
+			}
+			return c.chunk(&openai.Delta{ToolCalls: []openai.ToolCall{{
+				Index:    &index,
+				Function: openai.FunctionCall{Arguments: event.Delta.PartialJSON},
+			}}}, "")
+		}
+
+	case "message_delta":
+		if event.Delta != nil && event.Delta.StopReason != "" {
+			return c.chunk(&openai.Delta{}, mapStopReason(event.Delta.StopReason))
+		}
+	}
+
+	return nil
+}
+
+// chunk builds an OpenAI stream chunk with a single choice
+func (c *streamConverter) chunk(delta *openai.Delta, finishReason string) *openai.ChatCompletionStreamResponse {
+	return &openai.ChatCompletionStreamResponse{
+		ID:      c.id,
+		Object:  "chat.completion.chunk",
+		Created: c.created,
+		Model:   c.model,
+		Choices: []openai.Choice{{
+			Index:        0,
+			Delta:        delta,
+			FinishReason: finishReason,
+		}},
+	}
+}
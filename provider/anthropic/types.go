@@ -0,0 +1,120 @@
+package anthropic
+
+import (
+	"encoding/json"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// MessagesRequest represents an Anthropic messages request
+type MessagesRequest struct {
+	Model         string      `json:"model"`
+	Messages      []Message   `json:"messages"`
+	System        any         `json:"system,omitempty"` // string, or []ContentBlock when blocks carry cache hints
+	MaxTokens     int         `json:"max_tokens"`
+	Temperature   *float64    `json:"temperature,omitempty"`
+	TopP          *float64    `json:"top_p,omitempty"`
+	StopSequences []string    `json:"stop_sequences,omitempty"`
+	Stream        bool        `json:"stream,omitempty"`
+	Tools         []Tool      `json:"tools,omitempty"`
+	ToolChoice    *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// Message represents a single message with role and content blocks
+type Message struct {
+	Role    string         `json:"role"` // "user" or "assistant"
+	Content []ContentBlock `json:"content"`
+}
+
+// ContentBlock represents a block of message content
+type ContentBlock struct {
+	Type string `json:"type"` // "text", "image", "tool_use", or "tool_result"
+
+	// Text is set for "text" blocks
+	Text string `json:"text,omitempty"`
+
+	// Source is set for "image" blocks
+	Source *ImageSource `json:"source,omitempty"`
+
+	// ID, Name and Input are set for "tool_use" blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// ToolUseID and Content are set for "tool_result" blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+
+	// CacheControl marks the end of a cacheable prompt prefix
+	CacheControl *openai.CacheControl `json:"cache_control,omitempty"`
+}
+
+// ImageSource is the image of an "image" block, given inline or by URL
+type ImageSource struct {
+	Type      string `json:"type"`                 // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"` // base64 only, e.g. "image/png"
+	Data      string `json:"data,omitempty"`       // base64 only
+	URL       string `json:"url,omitempty"`        // url only
+}
+
+// Tool represents a tool declaration
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// ToolChoice controls how the model uses tools
+type ToolChoice struct {
+	Type string `json:"type"` // "auto", "any", "tool", or "none"
+	Name string `json:"name,omitempty"`
+}
+
+// MessagesResponse represents an Anthropic messages response
+type MessagesResponse struct {
+	ID           string         `json:"id"`
+	Type         string         `json:"type"`
+	Role         string         `json:"role"`
+	Model        string         `json:"model"`
+	Content      []ContentBlock `json:"content"`
+	StopReason   string         `json:"stop_reason"`
+	StopSequence string         `json:"stop_sequence,omitempty"`
+	Usage        Usage          `json:"usage"`
+}
+
+// Usage represents token usage
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// StreamEvent represents a server-sent event of a streaming response
+type StreamEvent struct {
+	Type         string            `json:"type"`
+	Index        int               `json:"index"`
+	Message      *MessagesResponse `json:"message,omitempty"`       // message_start
+	ContentBlock *ContentBlock     `json:"content_block,omitempty"` // content_block_start
+	Delta        *StreamDelta      `json:"delta,omitempty"`         // content_block_delta, message_delta
+	Usage        *Usage            `json:"usage,omitempty"`         // message_delta
+	Error        *ErrorDetail      `json:"error,omitempty"`         // error
+}
+
+// StreamDelta represents the delta of a streaming event
+type StreamDelta struct {
+	Type        string `json:"type,omitempty"` // "text_delta" or "input_json_delta"
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+// ErrorResponse represents an Anthropic error envelope
+type ErrorResponse struct {
+	Type  string      `json:"type"`
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail represents the details of an Anthropic error
+type ErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
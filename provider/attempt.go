@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+)
+
+type attemptLogKey struct{}
+
+// attemptLog numbers and logs the upstream attempts made for one request
+type attemptLog struct {
+	logger *slog.Logger
+	count  atomic.Int32
+}
+
+// WithAttemptLogging returns a copy of ctx in which every upstream HTTP
+// attempt, including retries and fallbacks to other providers, is logged to
+// logger with its provider, attempt number, latency and outcome
+func WithAttemptLogging(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, attemptLogKey{}, &attemptLog{logger: logger})
+}
+
+// LogAttempt logs an upstream attempt by providerName that started at start
+// and ended with resp or err, if attempt logging is enabled in ctx. Providers
+// sending their own HTTP requests call it after each one.
+func LogAttempt(ctx context.Context, providerName string, start time.Time, resp *http.Response, err error) {
+	log, ok := ctx.Value(attemptLogKey{}).(*attemptLog)
+	if !ok {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("provider", providerName),
+		slog.Int("attempt", int(log.count.Add(1))),
+		slog.Duration("latency", time.Since(start)),
+	}
+	switch {
+	case err != nil:
+		attrs = append(attrs, slog.String("outcome", "error"), slog.String("error", err.Error()))
+	case resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices:
+		attrs = append(attrs, slog.String("outcome", "success"), slog.Int("status", resp.StatusCode))
+	default:
+		attrs = append(attrs, slog.String("outcome", "error"), slog.Int("status", resp.StatusCode))
+	}
+	if id := ai_gateway.RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	log.logger.LogAttrs(ctx, slog.LevelInfo, "upstream attempt", attrs...)
+}
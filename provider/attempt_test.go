@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	ai_gateway "github.com/deeplooplabs/ai-gateway"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// retryOnce resends a failed request once, like a retry or fallback layer
+type retryOnce struct {
+	Provider
+}
+
+func (p *retryOnce) SendRequest(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := p.Provider.SendRequest(ctx, req)
+	if err != nil {
+		return p.Provider.SendRequest(ctx, req)
+	}
+	return resp, nil
+}
+
+func TestLogAttempt_RetriedRequest(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	ctx := ai_gateway.WithRequestID(context.Background(), "req-1")
+	ctx = WithAttemptLogging(ctx, slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	p := &retryOnce{Provider: NewHTTPProvider(NewProviderConfig("openai").WithBaseURL(server.URL))}
+	if _, err := p.SendRequest(ctx, NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 attempt log lines, got %d: %s", len(lines), buf.String())
+	}
+	want := []struct {
+		outcome string
+		status  int
+	}{
+		{outcome: "error", status: http.StatusServiceUnavailable},
+		{outcome: "success", status: http.StatusOK},
+	}
+	for i, line := range lines {
+		var entry struct {
+			Msg       string `json:"msg"`
+			Provider  string `json:"provider"`
+			Attempt   int    `json:"attempt"`
+			Latency   int64  `json:"latency"`
+			Outcome   string `json:"outcome"`
+			Status    int    `json:"status"`
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if entry.Msg != "upstream attempt" || entry.Provider != "openai" || entry.RequestID != "req-1" {
+			t.Errorf("attempt %d: unexpected entry %+v", i+1, entry)
+		}
+		if entry.Attempt != i+1 || entry.Outcome != want[i].outcome || entry.Status != want[i].status {
+			t.Errorf("attempt %d: expected %s with status %d, got %+v", i+1, want[i].outcome, want[i].status, entry)
+		}
+		if entry.Latency <= 0 {
+			t.Errorf("attempt %d: expected a latency, got %d", i+1, entry.Latency)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// DefaultAPIVersion is the Azure OpenAI API version sent when none is configured
+const DefaultAPIVersion = "2024-10-21"
+
+// Option configures a Provider
+type Option func(*options)
+
+type options struct {
+	apiVersion  string
+	deployments map[string]string
+}
+
+// WithAPIVersion sets the api-version query parameter (default DefaultAPIVersion)
+func WithAPIVersion(version string) Option {
+	return func(o *options) {
+		o.apiVersion = version
+	}
+}
+
+// WithDeployment routes requests for model to the named deployment. Models
+// without a deployment are sent to the deployment of the same name.
+func WithDeployment(model, deployment string) Option {
+	return func(o *options) {
+		o.deployments[model] = deployment
+	}
+}
+
+// Provider sends requests to Azure OpenAI deployments, e.g.
+// {BaseURL}/openai/deployments/{deployment}/chat/completions?api-version=...
+type Provider struct {
+	*provider.BaseProvider
+	deployments map[string]string
+}
+
+// NewProvider creates a new Azure OpenAI provider with a copy of the given
+// configuration. BaseURL is the resource endpoint (e.g.
+// https://my-resource.openai.azure.com) and APIKey is sent in the api-key
+// header. Name defaults to "azure".
+func NewProvider(config *provider.ProviderConfig, opts ...Option) *Provider {
+	o := &options{
+		apiVersion:  DefaultAPIVersion,
+		deployments: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if config == nil {
+		config = provider.DefaultConfig()
+	} else {
+		config = config.Clone()
+	}
+	if config.Name == "" {
+		config.Name = "azure"
+	}
+	if config.APIKeyHeader == "" {
+		config.APIKeyHeader = "api-key"
+	}
+	config.SupportedAPIs = provider.APITypeChatCompletions | provider.APITypeEmbeddings
+	// The {model} placeholder is replaced with the deployment name
+	query := "?api-version=" + url.QueryEscape(o.apiVersion)
+	config.WithEndpoint(provider.APITypeChatCompletions, http.MethodPost, "/openai/deployments/{model}/chat/completions"+query)
+	config.WithEndpoint(provider.APITypeEmbeddings, http.MethodPost, "/openai/deployments/{model}/embeddings"+query)
+
+	return &Provider{
+		BaseProvider: provider.NewBaseProvider(config),
+		deployments:  o.deployments,
+	}
+}
+
+// SendRequest implements provider.Provider.SendRequest
+func (p *Provider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	// Responses requests are served through Chat Completions
+	if !p.SupportedAPIs().Supports(req.APIType) {
+		if err := p.ConvertRequestIfNeeded(req); err != nil {
+			return nil, fmt.Errorf("API type %v not supported by provider %s: %w", req.APIType, p.Name(), err)
+		}
+	}
+	if req.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+
+	upstream := *req
+	upstream.Model = p.Deployment(req.Model)
+	return p.SendRequestToOpenAIProvider(ctx, &upstream)
+}
+
+// Deployment returns the deployment serving model
+func (p *Provider) Deployment(model string) string {
+	if deployment, ok := p.deployments[model]; ok {
+		return deployment
+	}
+	return model
+}
+
+// Ensure Provider implements provider.Provider
+var _ provider.Provider = (*Provider)(nil)
@@ -0,0 +1,123 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestProvider_SendRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      func() *provider.Request
+		wantPath string
+	}{
+		{
+			name: "chat",
+			req: func() *provider.Request {
+				return provider.NewChatCompletionsRequest("gpt-4o", []openai.Message{{Role: "user", Content: "Hello"}})
+			},
+			wantPath: "/openai/deployments/prod-gpt4o/chat/completions",
+		},
+		{
+			name: "stream",
+			req: func() *provider.Request {
+				req := provider.NewChatCompletionsRequest("gpt-4o", []openai.Message{{Role: "user", Content: "Hello"}})
+				req.Stream = true
+				return req
+			},
+			wantPath: "/openai/deployments/prod-gpt4o/chat/completions",
+		},
+		{
+			name: "embeddings",
+			req: func() *provider.Request {
+				return provider.NewEmbeddingsRequest("text-embedding-3-small", "Hello")
+			},
+			wantPath: "/openai/deployments/text-embedding-3-small/embeddings",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				switch {
+				case tt.name == "stream":
+					w.Header().Set("Content-Type", "text/event-stream")
+					w.Write([]byte("data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n"))
+				case tt.name == "embeddings":
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}]}`))
+				default:
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+				}
+			}))
+			defer server.Close()
+
+			p := NewProvider(
+				provider.NewProviderConfig("").WithBaseURL(server.URL).WithAPIKey("azure-key"),
+				WithDeployment("gpt-4o", "prod-gpt4o"),
+				WithAPIVersion("2024-06-01"),
+			)
+			resp, err := p.SendRequest(context.Background(), tt.req())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.Stream {
+				for chunk := range resp.Chunks {
+					if chunk.Done {
+						break
+					}
+				}
+				resp.Close()
+			}
+
+			if got.URL.Path != tt.wantPath {
+				t.Errorf("expected path %s, got %s", tt.wantPath, got.URL.Path)
+			}
+			if v := got.URL.Query().Get("api-version"); v != "2024-06-01" {
+				t.Errorf("expected api-version 2024-06-01, got %q", v)
+			}
+			if key := got.Header.Get("api-key"); key != "azure-key" {
+				t.Errorf("expected api-key header, got %q", key)
+			}
+			if auth := got.Header.Get("Authorization"); auth != "" {
+				t.Errorf("expected no Authorization header, got %q", auth)
+			}
+		})
+	}
+}
+
+func TestNewProvider_Defaults(t *testing.T) {
+	p := NewProvider(nil)
+
+	if p.Name() != "azure" {
+		t.Errorf("expected name 'azure', got '%s'", p.Name())
+	}
+	if p.Deployment("gpt-4o") != "gpt-4o" {
+		t.Errorf("expected models without a deployment to map to themselves, got %s", p.Deployment("gpt-4o"))
+	}
+	if !p.SupportedAPIs().Supports(provider.APITypeEmbeddings) || p.SupportedAPIs().Supports(provider.APITypeImages) {
+		t.Errorf("unexpected supported APIs: %s", p.SupportedAPIs())
+	}
+	if path := p.Config().Endpoints[provider.APITypeChatCompletions].Path; path != "/openai/deployments/{model}/chat/completions?api-version="+DefaultAPIVersion {
+		t.Errorf("unexpected chat endpoint %s", path)
+	}
+}
+
+func TestNewProviderCopiesConfig(t *testing.T) {
+	config := provider.NewProviderConfig("").WithAPIKey("test-api-key")
+	p := NewProvider(config)
+
+	if config.Name != "" || config.APIKeyHeader != "" || config.Endpoints[provider.APITypeChatCompletions].Path != "" {
+		t.Errorf("expected the caller's config unchanged, got %+v", config)
+	}
+	if p.Name() != "azure" || p.Config().APIKeyHeader != "api-key" {
+		t.Errorf("expected the defaults on the provider's copy, got %s and %s", p.Name(), p.Config().APIKeyHeader)
+	}
+}
@@ -8,7 +8,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
 )
@@ -26,10 +33,12 @@ func NewBaseProvider(config *ProviderConfig) *BaseProvider {
 		config = DefaultConfig()
 	}
 
+	converter := NewConverter(config.SupportedAPIs)
+	converter.SetMessageIDFunc(config.MessageIDFunc)
 	return &BaseProvider{
 		config:    config,
 		client:    config.GetHTTPClient(),
-		converter: NewConverter(config.SupportedAPIs),
+		converter: converter,
 	}
 }
 
@@ -46,6 +55,16 @@ func (p *BaseProvider) SupportedAPIs() APIType {
 	return p.config.SupportedAPIs
 }
 
+// SingleChoice reports whether the upstream ignores n > 1
+func (p *BaseProvider) SingleChoice() bool {
+	return p.config.SingleChoice
+}
+
+// SupportsTools reports whether the upstream accepts tools
+func (p *BaseProvider) SupportsTools() bool {
+	return !p.config.NoTools
+}
+
 // Config returns the provider configuration
 func (p *BaseProvider) Config() *ProviderConfig {
 	return p.config
@@ -56,47 +75,102 @@ func (p *BaseProvider) Converter() *Converter {
 	return p.converter
 }
 
+// Close releases the idle connections of the provider's own HTTP client. A
+// client passed in with WithHTTPClient is left to its owner. Requests in
+// flight are unaffected.
+func (p *BaseProvider) Close() error {
+	if p.config.HTTPClient == nil {
+		p.client.CloseIdleConnections()
+	}
+	return nil
+}
+
 // SendRequest implements Provider.SendRequest
 func (p *BaseProvider) SendRequest(ctx context.Context, req *Request) (*Response, error) {
 	return p.SendRequestToOpenAIProvider(ctx, req)
 }
 
 // sendHTTP sends an HTTP request with common headers
-func (p *BaseProvider) sendHTTP(ctx context.Context, url string, body []byte, headers map[string]string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+func (p *BaseProvider) sendHTTP(ctx context.Context, method, url string, body []byte, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, requestBody(method, body))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
 
-	// Set Authorization header if API key is configured
-	if p.config.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
-	}
+	p.setAuthHeader(req)
 
 	// Set additional headers
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
-	return p.client.Do(req)
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	LogAttempt(ctx, p.Name(), start, resp, err)
+	if err != nil {
+		return nil, TransportError(err)
+	}
+	if err := decodeBody(resp); err != nil {
+		return nil, DecodeError(err)
+	}
+	return resp, nil
+}
+
+// setAuthHeader sets the API key on req, if configured, as a bearer token or
+// in the configured APIKeyHeader
+func (p *BaseProvider) setAuthHeader(req *http.Request) {
+	if p.config.APIKey == "" {
+		return
+	}
+	if p.config.APIKeyHeader != "" {
+		req.Header.Set(p.config.APIKeyHeader, p.config.APIKey)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
 }
 
-// sendHTTPNonStreaming sends a non-streaming HTTP request
-func (p *BaseProvider) sendHTTPNonStreaming(ctx context.Context, url string, body []byte, headers map[string]string) ([]byte, error) {
-	resp, err := p.sendHTTP(ctx, url, body, headers)
+// sendHTTPNonStreaming sends a non-streaming HTTP request and returns the
+// response body with the allowlisted response headers
+func (p *BaseProvider) sendHTTPNonStreaming(ctx context.Context, method, url string, body []byte, headers map[string]string) ([]byte, http.Header, error) {
+	resp, err := p.sendHTTP(ctx, method, url, body, headers)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		return nil, nil, p.upstreamError(resp, respBody)
 	}
 
-	return io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, TransportError(err)
+	}
+	if err := p.embeddedError(resp, respBody); err != nil {
+		return nil, nil, err
+	}
+	return respBody, p.responseHeaders(resp.Header), nil
+}
+
+// sendHTTPPassthrough sends an HTTP request and returns the response with its body unread.
+// The caller must close the body.
+func (p *BaseProvider) sendHTTPPassthrough(ctx context.Context, method, url string, body []byte, headers map[string]string) (*http.Response, error) {
+	resp, err := p.sendHTTP(ctx, method, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, p.upstreamError(resp, respBody)
+	}
+
+	return resp, nil
 }
 
 // ConvertRequestIfNeeded converts the request to a supported API format if needed
@@ -123,7 +197,27 @@ func (p *BaseProvider) ConvertResponseIfNeeded(resp *Response, requestedAPIType
 
 // ParseChatCompletionRequest parses the unified request as a Chat Completions request
 func (p *BaseProvider) ParseChatCompletionRequest(req *Request) (*openai.ChatCompletionRequest, error) {
-	return req.ToChatCompletionRequest()
+	chatReq, err := req.ToChatCompletionRequest()
+	if err != nil {
+		return nil, err
+	}
+	if p.config.UserTransform != nil && chatReq.User != "" {
+		chatReq.User = p.config.UserTransform(chatReq.User)
+	}
+	if !p.config.PromptCaching {
+		chatReq.Messages = stripCacheControl(chatReq.Messages)
+	}
+	// Top-level system prompts are extracted by the provider's converter
+	if p.config.SingleSystemMessage {
+		chatReq.Messages = ConsolidateSystemMessages(chatReq.Messages)
+	}
+	if p.config.SystemMessages == SystemFirstUserPrefix {
+		_, chatReq.Messages = ApplySystemMessageMode(SystemFirstUserPrefix, chatReq.Messages)
+	}
+	if p.config.AlternatingRoles {
+		chatReq.Messages = MergeConsecutiveRoles(chatReq.Messages)
+	}
+	return chatReq, nil
 }
 
 // ParseEmbeddingRequest parses the unified request as an Embedding request
@@ -136,9 +230,61 @@ func (p *BaseProvider) ParseImageRequest(req *Request) (*openai.ImageRequest, er
 	return req.ToImageRequest()
 }
 
+// ParseModerationRequest parses the unified request as a Moderation request
+func (p *BaseProvider) ParseModerationRequest(req *Request) (*openai.ModerationRequest, error) {
+	return req.ToModerationRequest()
+}
+
+// ParseTranscriptionRequest parses the unified request as an Audio Transcription request
+func (p *BaseProvider) ParseTranscriptionRequest(req *Request) (*openai.TranscriptionRequest, error) {
+	return req.ToTranscriptionRequest()
+}
+
+// ParseSpeechRequest parses the unified request as an Audio Speech request
+func (p *BaseProvider) ParseSpeechRequest(req *Request) (*openai.SpeechRequest, error) {
+	return req.ToSpeechRequest()
+}
+
 // SendRequestToOpenAIProvider sends a request to an OpenAI-compatible provider
 // This is a helper method for providers that use the OpenAI API format
 func (p *BaseProvider) SendRequestToOpenAIProvider(ctx context.Context, req *Request) (*Response, error) {
+	p.ForwardClientHeaders(ctx, req)
+	method, url := p.endpointURL(req)
+
+	// Handle different API types. Only chat requests honor req.Stream; the
+	// other APIs are always sent non-streaming.
+	switch req.APIType {
+	case APITypeEmbeddings:
+		return p.sendEmbeddingRequest(ctx, method, url, req)
+	case APITypeImages:
+		return p.sendImageRequest(ctx, method, url, req)
+	case APITypeModerations:
+		return p.sendModerationRequest(ctx, method, url, req)
+	case APITypeAudioTranscription:
+		return p.sendTranscriptionRequest(ctx, method, url, req)
+	case APITypeAudioSpeech:
+		return p.sendSpeechRequest(ctx, method, url, req)
+	default:
+		// ChatCompletions and Responses use streaming support
+		return p.sendChatRequest(ctx, method, url, req)
+	}
+}
+
+// endpointURL returns the HTTP method and URL for req, applying any
+// endpoint override configured for its API type
+func (p *BaseProvider) endpointURL(req *Request) (string, string) {
+	override := p.config.Endpoints[req.APIType]
+
+	method := override.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	// Custom paths are used as-is, without BasePath stripping
+	if override.Path != "" {
+		return method, p.config.BaseURL + strings.ReplaceAll(override.Path, "{model}", url.PathEscape(req.Model))
+	}
+
 	// Build URL
 	endpoint := req.Endpoint
 	if endpoint == "" {
@@ -148,6 +294,12 @@ func (p *BaseProvider) SendRequestToOpenAIProvider(ctx context.Context, req *Req
 			endpoint = "/v1/embeddings"
 		case APITypeImages:
 			endpoint = "/v1/images/generations"
+		case APITypeModerations:
+			endpoint = "/v1/moderations"
+		case APITypeAudioTranscription:
+			endpoint = "/v1/audio/transcriptions"
+		case APITypeAudioSpeech:
+			endpoint = "/v1/audio/speech"
 		case APITypeResponses:
 			endpoint = "/v1/responses"
 		default:
@@ -166,28 +318,20 @@ func (p *BaseProvider) SendRequestToOpenAIProvider(ctx context.Context, req *Req
 		}
 	}
 
-	url := p.config.BaseURL + endpoint
-
-	// Set headers
-	headers := req.Headers
-	if headers == nil {
-		headers = make(map[string]string)
-	}
+	return method, p.config.BaseURL + endpoint
+}
 
-	// Handle different API types
-	switch req.APIType {
-	case APITypeEmbeddings:
-		return p.sendEmbeddingRequest(ctx, url, req, headers)
-	case APITypeImages:
-		return p.sendImageRequest(ctx, url, req, headers)
-	default:
-		// ChatCompletions and Responses use streaming support
-		return p.sendChatRequest(ctx, url, req, headers)
+// requestBody returns the body to send for method. GET, HEAD and DELETE requests are sent without a body.
+func requestBody(method string, body []byte) io.Reader {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return http.NoBody
 	}
+	return bytes.NewReader(body)
 }
 
 // sendChatRequest sends a chat completions or responses request
-func (p *BaseProvider) sendChatRequest(ctx context.Context, url string, req *Request, headers map[string]string) (*Response, error) {
+func (p *BaseProvider) sendChatRequest(ctx context.Context, method, url string, req *Request) (*Response, error) {
 	// Convert to Chat Completions format if needed
 	if req.APIType != APITypeChatCompletions {
 		if err := p.ConvertRequestIfNeeded(req); err != nil {
@@ -202,27 +346,27 @@ func (p *BaseProvider) sendChatRequest(ctx context.Context, url string, req *Req
 	}
 
 	// Marshal request
-	body, err := json.Marshal(chatReq)
+	body, headers, err := p.SerializeBody(req, chatReq)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	// Handle streaming vs non-streaming
 	if req.Stream {
-		return p.sendStreamingRequest(ctx, url, body, headers, req.APIType)
+		return p.sendStreamingRequest(ctx, method, url, body, headers, req.APIType)
 	}
 
-	return p.sendNonStreamingRequest(ctx, url, body, headers)
+	return p.sendNonStreamingRequest(ctx, method, url, body, headers)
 }
 
 // sendEmbeddingRequest sends an embedding request
-func (p *BaseProvider) sendEmbeddingRequest(ctx context.Context, url string, req *Request, headers map[string]string) (*Response, error) {
+func (p *BaseProvider) sendEmbeddingRequest(ctx context.Context, method, url string, req *Request) (*Response, error) {
 	embeddingReq, err := p.ParseEmbeddingRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("parse embedding request: %w", err)
 	}
 
-	body, err := json.Marshal(embeddingReq)
+	body, headers, err := p.SerializeBody(req, embeddingReq)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
@@ -233,7 +377,7 @@ func (p *BaseProvider) sendEmbeddingRequest(ctx context.Context, url string, req
 		"body", string(body),
 	)
 
-	respBody, err := p.sendHTTPNonStreaming(ctx, url, body, headers)
+	respBody, respHeaders, err := p.sendHTTPNonStreaming(ctx, method, url, body, headers)
 	if err != nil {
 		slog.ErrorContext(ctx, "Upstream embedding request failed",
 			"url", url,
@@ -248,78 +392,222 @@ func (p *BaseProvider) sendEmbeddingRequest(ctx context.Context, url string, req
 			"response", string(respBody),
 			"error", err.Error(),
 		)
-		return nil, fmt.Errorf("decode response: %w", err)
+		return nil, DecodeError(err)
 	}
 
-	return NewEmbeddingResponse(&embeddingResp), nil
+	resp := NewEmbeddingResponse(&embeddingResp)
+	resp.Headers = respHeaders
+	return resp, nil
 }
 
 // sendImageRequest sends an image generation request
-func (p *BaseProvider) sendImageRequest(ctx context.Context, url string, req *Request, headers map[string]string) (*Response, error) {
+func (p *BaseProvider) sendImageRequest(ctx context.Context, method, url string, req *Request) (*Response, error) {
 	imageReq, err := p.ParseImageRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("parse image request: %w", err)
 	}
 
-	body, err := json.Marshal(imageReq)
+	body, headers, err := p.SerializeBody(req, imageReq)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	respBody, err := p.sendHTTPNonStreaming(ctx, url, body, headers)
+	respBody, respHeaders, err := p.sendHTTPNonStreaming(ctx, method, url, body, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	var imageResp openai.ImageResponse
 	if err := json.Unmarshal(respBody, &imageResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+		return nil, DecodeError(err)
+	}
+
+	resp := NewImageResponse(&imageResp)
+	resp.Headers = respHeaders
+	return resp, nil
+}
+
+// sendModerationRequest sends a moderation request
+func (p *BaseProvider) sendModerationRequest(ctx context.Context, method, url string, req *Request) (*Response, error) {
+	moderationReq, err := p.ParseModerationRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse moderation request: %w", err)
+	}
+
+	body, headers, err := p.SerializeBody(req, moderationReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	respBody, respHeaders, err := p.sendHTTPNonStreaming(ctx, method, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var moderationResp openai.ModerationResponse
+	if err := json.Unmarshal(respBody, &moderationResp); err != nil {
+		return nil, DecodeError(err)
 	}
 
-	return NewImageResponse(&imageResp), nil
+	resp := NewModerationResponse(&moderationResp)
+	resp.Headers = respHeaders
+	return resp, nil
+}
+
+// sendTranscriptionRequest sends an audio transcription request as multipart/form-data
+func (p *BaseProvider) sendTranscriptionRequest(ctx context.Context, method, url string, req *Request) (*Response, error) {
+	transcriptionReq, err := p.ParseTranscriptionRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse transcription request: %w", err)
+	}
+
+	body, contentType, err := encodeTranscriptionRequest(transcriptionReq)
+	if err != nil {
+		return nil, fmt.Errorf("encode multipart request: %w", err)
+	}
+
+	headers := maps.Clone(req.Headers)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	headers["Content-Type"] = contentType
+
+	respBody, respHeaders, err := p.sendHTTPNonStreaming(ctx, method, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plain text formats are returned as-is rather than as JSON
+	var transcriptionResp openai.TranscriptionResponse
+	switch transcriptionReq.ResponseFormat {
+	case "text", "srt", "vtt":
+		transcriptionResp.Text = string(respBody)
+	default:
+		if err := json.Unmarshal(respBody, &transcriptionResp); err != nil {
+			return nil, DecodeError(err)
+		}
+	}
+
+	resp := NewTranscriptionResponse(&transcriptionResp)
+	resp.Headers = respHeaders
+	return resp, nil
+}
+
+// sendSpeechRequest sends an audio speech request. The audio body is passed
+// through unread rather than decoded as JSON.
+func (p *BaseProvider) sendSpeechRequest(ctx context.Context, method, url string, req *Request) (*Response, error) {
+	speechReq, err := p.ParseSpeechRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse speech request: %w", err)
+	}
+
+	body, headers, err := p.SerializeBody(req, speechReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.sendHTTPPassthrough(ctx, method, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	speechResp := NewAudioSpeechResponse(&BinaryBody{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	})
+	speechResp.Headers = p.responseHeaders(resp.Header)
+	return speechResp, nil
+}
+
+// encodeTranscriptionRequest encodes req as a multipart/form-data body and returns it with its content type
+func encodeTranscriptionRequest(req *openai.TranscriptionRequest) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	filename := req.Filename
+	if filename == "" {
+		filename = "audio"
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(req.File); err != nil {
+		return nil, "", err
+	}
+
+	fields := [][2]string{
+		{"model", req.Model},
+		{"language", req.Language},
+		{"prompt", req.Prompt},
+		{"response_format", req.ResponseFormat},
+	}
+	if req.Temperature != nil {
+		fields = append(fields, [2]string{"temperature", strconv.FormatFloat(*req.Temperature, 'f', -1, 64)})
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
 }
 
 // sendNonStreamingRequest sends a non-streaming request
-func (p *BaseProvider) sendNonStreamingRequest(ctx context.Context, url string, body []byte, headers map[string]string) (*Response, error) {
-	respBody, err := p.sendHTTPNonStreaming(ctx, url, body, headers)
+func (p *BaseProvider) sendNonStreamingRequest(ctx context.Context, method, url string, body []byte, headers map[string]string) (*Response, error) {
+	respBody, respHeaders, err := p.sendHTTPNonStreaming(ctx, method, url, body, headers)
 	if err != nil {
 		return nil, err
 	}
 
 	var chatResp openai.ChatCompletionResponse
 	if err := json.Unmarshal(respBody, &chatResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+		return nil, DecodeError(err)
 	}
 
-	return NewChatCompletionResponse(&chatResp), nil
+	resp := NewChatCompletionResponse(&chatResp)
+	resp.Headers = respHeaders
+	return resp, nil
 }
 
 // sendStreamingRequest sends a streaming request
-func (p *BaseProvider) sendStreamingRequest(ctx context.Context, url string, body []byte, headers map[string]string, apiType APIType) (*Response, error) {
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+func (p *BaseProvider) sendStreamingRequest(ctx context.Context, method, url string, body []byte, headers map[string]string, apiType APIType) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, requestBody(method, body))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	if p.config.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
-	}
+	p.setAuthHeader(req)
 	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
 
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
 
+	start := time.Now()
 	resp, err := p.client.Do(req)
+	LogAttempt(ctx, p.Name(), start, resp, err)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, TransportError(err)
+	}
+	if err := decodeBody(resp); err != nil {
+		return nil, DecodeError(err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		return nil, p.upstreamError(resp, respBody)
 	}
 
 	chunkChan := make(chan *Chunk, 16)
@@ -330,7 +618,17 @@ func (p *BaseProvider) sendStreamingRequest(ctx context.Context, url string, bod
 		defer close(errChan)
 		defer resp.Body.Close()
 
-		// Read SSE line by line
+		// Stop sending once the caller is gone so an abandoned stream can't block forever
+		send := func(chunk *Chunk) bool {
+			select {
+			case chunkChan <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// Read SSE event by event
 		decoder := NewSSEDecoder(resp.Body)
 		for {
 			// Check for context cancellation before reading
@@ -338,29 +636,22 @@ func (p *BaseProvider) sendStreamingRequest(ctx context.Context, url string, bod
 				return
 			}
 
-			line, err := decoder.NextLine()
+			event, err := decoder.Next()
 			if err != nil {
 				if err != io.EOF {
-					errChan <- fmt.Errorf("read stream: %w", err)
+					errChan <- TransportError(err)
 				}
 				return
 			}
 
 			// Check for [DONE]
-			if openai.IsDoneMarker(line) {
-				chunkChan <- NewOpenAIChunkDone()
+			if openai.IsDoneData(event.Data) {
+				send(NewOpenAIChunkDone())
 				return
 			}
-
-			// Extract data: content
-			_, data, isDone := openai.ParseSSELine(line)
-			if isDone {
-				chunkChan <- NewOpenAIChunkDone()
+			if event.Data != "" && !send(NewOpenAIChunk([]byte(event.Data))) {
 				return
 			}
-			if data != "" {
-				chunkChan <- NewOpenAIChunk([]byte(data))
-			}
 		}
 	}()
 
@@ -369,7 +660,9 @@ func (p *BaseProvider) sendStreamingRequest(ctx context.Context, url string, bod
 		return nil
 	}
 
-	return NewStreamingResponse(apiType, chunkChan, errChan, closeFn), nil
+	streamResp := NewStreamingResponse(apiType, chunkChan, errChan, closeFn)
+	streamResp.Headers = p.responseHeaders(resp.Header)
+	return streamResp, nil
 }
 
 // SSEDecoder helps decode SSE streams
@@ -388,3 +681,78 @@ func NewSSEDecoder(r io.Reader) *SSEDecoder {
 func (d *SSEDecoder) NextLine() ([]byte, error) {
 	return d.reader.ReadBytes('\n')
 }
+
+// SSEEvent is a single event of an SSE stream
+type SSEEvent struct {
+	// Event is the name set by an event: field, empty for unnamed events
+	Event string
+	// Data is the payload, with the values of multiple data: lines joined by newlines
+	Data string
+}
+
+// Next reads the next event from the SSE stream. data: lines are accumulated
+// until a blank line, comment lines (starting with ':') are skipped, and
+// events without data are not dispatched. A final event missing its blank
+// line is still returned; io.EOF is returned once the stream is exhausted.
+func (d *SSEDecoder) Next() (*SSEEvent, error) {
+	var event SSEEvent
+	var data []string
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		if len(line) == 0 {
+			if data != nil {
+				event.Data = strings.Join(data, "\n")
+				return &event, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			// A blank line ends an event without data, which is dropped
+			event = SSEEvent{}
+			continue
+		}
+
+		if line[0] != ':' {
+			field, value, _ := bytes.Cut(line, []byte(":"))
+			value = bytes.TrimPrefix(value, []byte(" "))
+			switch string(field) {
+			case "event":
+				event.Event = string(value)
+			case "data":
+				data = append(data, string(value))
+			}
+		}
+
+		if err != nil {
+			if data != nil {
+				event.Data = strings.Join(data, "\n")
+				return &event, nil
+			}
+			return nil, err
+		}
+	}
+}
+
+// stripCacheControl returns messages without prompt caching hints, copying
+// them only if any are hinted
+func stripCacheControl(messages []openai.Message) []openai.Message {
+	var stripped []openai.Message
+	for i, msg := range messages {
+		if msg.CacheControl == nil {
+			continue
+		}
+		if stripped == nil {
+			stripped = slices.Clone(messages)
+		}
+		stripped[i].CacheControl = nil
+	}
+	if stripped == nil {
+		return messages
+	}
+	return stripped
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Capabilities describes the features a provider was found to support
+type Capabilities struct {
+	// Tools reports whether chat requests may carry tools
+	Tools bool
+	// JSONMode reports whether the upstream honors JSON response formats
+	JSONMode bool
+	// Streaming reports whether chat requests may be streamed
+	Streaming bool
+}
+
+// PermissiveCapabilities assumes every feature is supported. It is used for
+// providers that can't be probed or whose probe failed.
+var PermissiveCapabilities = Capabilities{Tools: true, JSONMode: true, Streaming: true}
+
+// CapabilityProber is implemented by providers that can detect their
+// capabilities, e.g. from the upstream's models endpoint or a tiny request
+type CapabilityProber interface {
+	// ProbeCapabilities queries the upstream for the features it supports
+	ProbeCapabilities(ctx context.Context) (Capabilities, error)
+}
+
+// ProbeCapabilities implements CapabilityProber by listing the upstream's
+// models. Upstreams that report each model's supported_parameters, such as
+// OpenRouter, are checked for tools and response_format; a feature counts as
+// supported if any model lists it. Other upstreams are assumed permissive once
+// their models endpoint answers. Streaming is probed with a one-token
+// stream:true request to the first listed model, and counts as unsupported
+// if the upstream answers it without an event stream. Providers with their own
+// wire format (a BodySerializer or a custom chat endpoint) have no OpenAI
+// models endpoint and are assumed permissive without a request.
+func (p *BaseProvider) ProbeCapabilities(ctx context.Context) (Capabilities, error) {
+	caps := PermissiveCapabilities
+	if _, custom := p.config.Endpoints[APITypeChatCompletions]; custom || p.config.BodySerializer != nil || p.config.BaseURL == "" {
+		return caps, nil
+	}
+
+	_, url := p.endpointURL(&Request{Endpoint: "/v1/models"})
+	body, _, err := p.sendHTTPNonStreaming(ctx, http.MethodGet, url, nil, nil)
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("list models: %w", err)
+	}
+	var models struct {
+		Data []struct {
+			ID                  string   `json:"id"`
+			SupportedParameters []string `json:"supported_parameters"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &models); err != nil {
+		return Capabilities{}, DecodeError(err)
+	}
+
+	reported := false
+	tools, jsonMode := false, false
+	for _, m := range models.Data {
+		if m.SupportedParameters == nil {
+			continue
+		}
+		reported = true
+		tools = tools || slices.Contains(m.SupportedParameters, "tools")
+		jsonMode = jsonMode || slices.Contains(m.SupportedParameters, "response_format")
+	}
+	if reported {
+		caps.Tools, caps.JSONMode = tools, jsonMode
+	}
+
+	if len(models.Data) > 0 && models.Data[0].ID != "" {
+		streaming, err := p.probeStreaming(ctx, models.Data[0].ID)
+		if err != nil {
+			// A failed request says nothing about streaming
+			slog.WarnContext(ctx, "streaming probe failed, assuming streaming is supported",
+				"provider", p.Name(), "model", models.Data[0].ID, "error", err)
+		} else {
+			caps.Streaming = streaming
+		}
+	}
+	return caps, nil
+}
+
+// probeStreaming sends a one-token stream:true chat request for model and
+// reports whether the upstream answered with an event stream
+func (p *BaseProvider) probeStreaming(ctx context.Context, model string) (bool, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":      model,
+		"messages":   []map[string]string{{"role": "user", "content": "Hi"}},
+		"max_tokens": 1,
+		"stream":     true,
+	})
+	if err != nil {
+		return false, err
+	}
+	method, url := p.endpointURL(&Request{APIType: APITypeChatCompletions, Model: model})
+	resp, err := p.sendHTTPPassthrough(ctx, method, url, body, nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"), nil
+}
+
+// CapabilityCache probes providers once and caches their capabilities
+type CapabilityCache struct {
+	mu      sync.RWMutex
+	entries map[Provider]Capabilities
+}
+
+// NewCapabilityCache creates an empty capability cache
+func NewCapabilityCache() *CapabilityCache {
+	return &CapabilityCache{entries: make(map[Provider]Capabilities)}
+}
+
+// Probe returns the cached capabilities of prov, probing it on first use.
+// Providers that don't implement CapabilityProber (looking through wrapped
+// providers) and failed probes get PermissiveCapabilities.
+func (c *CapabilityCache) Probe(ctx context.Context, prov Provider) Capabilities {
+	c.mu.RLock()
+	caps, ok := c.entries[prov]
+	c.mu.RUnlock()
+	if ok {
+		return caps
+	}
+
+	caps = PermissiveCapabilities
+	if prober := findProber(prov); prober != nil {
+		probed, err := prober.ProbeCapabilities(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "capability probe failed, assuming all features are supported",
+				"provider", prov.Name(), "error", err)
+		} else {
+			caps = probed
+		}
+	}
+
+	c.mu.Lock()
+	c.entries[prov] = caps
+	c.mu.Unlock()
+	return caps
+}
+
+// Lookup returns the cached capabilities of prov, or of the first provider
+// it wraps that was probed, without probing. Unprobed providers, and lookups
+// on a nil cache, get PermissiveCapabilities.
+func (c *CapabilityCache) Lookup(prov Provider) Capabilities {
+	if c == nil {
+		return PermissiveCapabilities
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for prov != nil {
+		if caps, ok := c.entries[prov]; ok {
+			return caps
+		}
+		w, ok := prov.(interface{ Unwrap() Provider })
+		if !ok {
+			break
+		}
+		prov = w.Unwrap()
+	}
+	return PermissiveCapabilities
+}
+
+// findProber returns the first CapabilityProber found on prov or the providers it wraps
+func findProber(prov Provider) CapabilityProber {
+	if p, ok := prov.(CapabilityProber); ok {
+		return p
+	}
+	if w, ok := prov.(interface{ Unwrap() Provider }); ok {
+		return findProber(w.Unwrap())
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// probingProvider reports fixed capabilities through a probe
+type probingProvider struct {
+	BaseProvider
+	caps   Capabilities
+	probes int
+}
+
+func (p *probingProvider) ProbeCapabilities(ctx context.Context) (Capabilities, error) {
+	p.probes++
+	return p.caps, nil
+}
+
+func TestCapabilityCache(t *testing.T) {
+	prov := &probingProvider{BaseProvider: *NewBaseProvider(nil), caps: Capabilities{Streaming: true}}
+	wrapped := Wrap(prov)
+	cache := NewCapabilityCache()
+
+	if got := cache.Lookup(wrapped); got != PermissiveCapabilities {
+		t.Errorf("expected unprobed providers to be permissive, got %+v", got)
+	}
+	if got := cache.Probe(context.Background(), wrapped); got != prov.caps {
+		t.Errorf("expected probed capabilities through the wrapper, got %+v", got)
+	}
+	cache.Probe(context.Background(), wrapped)
+	if prov.probes != 1 {
+		t.Errorf("expected the result to be cached, got %d probes", prov.probes)
+	}
+	if got := cache.Lookup(observed{wrapped}); got != prov.caps {
+		t.Errorf("expected lookups to look through wrappers, got %+v", got)
+	}
+	if got := cache.Probe(context.Background(), NewBaseProvider(nil)); got != PermissiveCapabilities {
+		t.Errorf("expected providers without a probe to be permissive, got %+v", got)
+	}
+	if got := (*CapabilityCache)(nil).Lookup(prov); got != PermissiveCapabilities {
+		t.Errorf("expected a nil cache to be permissive, got %+v", got)
+	}
+}
+
+// observed wraps a provider like the handler's metrics wrapper
+type observed struct {
+	Provider
+}
+
+func (o observed) Unwrap() Provider {
+	return o.Provider
+}
+
+func TestBaseProvider_ProbeCapabilities(t *testing.T) {
+	models := `{"data":[{"id":"a","supported_parameters":["tools","temperature"]},{"id":"b","supported_parameters":["temperature"]}]}`
+	streams := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("expected the API key, got %q", got)
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/models":
+			w.Write([]byte(models))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/chat/completions":
+			var req struct {
+				Model  string `json:"model"`
+				Stream bool   `json:"stream"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if !req.Stream || req.Model == "" {
+				t.Errorf("expected a streamed request for a listed model, got %+v", req)
+			}
+			if streams {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte("data: [DONE]\n\n"))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"choices":[]}`))
+		default:
+			t.Errorf("unexpected probe %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	prov := NewHTTPProvider(NewProviderConfig("openrouter").WithBaseURL(server.URL).WithAPIKey("sk-test"))
+	caps, err := prov.ProbeCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if want := (Capabilities{Tools: true, Streaming: true}); caps != want {
+		t.Errorf("expected %+v from the supported parameters, got %+v", want, caps)
+	}
+
+	// Upstreams that don't report parameters are permissive
+	models = `{"data":[{"id":"gpt-4o"}]}`
+	if caps, err := prov.ProbeCapabilities(context.Background()); err != nil || caps != PermissiveCapabilities {
+		t.Errorf("expected permissive capabilities, got %+v and %v", caps, err)
+	}
+
+	// Upstreams that answer a streamed request in one piece can't stream
+	streams = false
+	if caps, err := prov.ProbeCapabilities(context.Background()); err != nil || caps.Streaming {
+		t.Errorf("expected streaming to be unsupported, got %+v and %v", caps, err)
+	}
+
+	// A failing models endpoint is an error
+	server.Close()
+	if _, err := prov.ProbeCapabilities(context.Background()); err == nil {
+		t.Error("expected an error when the models endpoint is unreachable")
+	}
+}
@@ -1,9 +1,14 @@
 package provider
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/deeplooplabs/ai-gateway/openresponses"
 )
 
 // APIType represents the API format a provider supports
@@ -18,26 +23,76 @@ const (
 	APITypeEmbeddings
 	// APITypeImages is OpenAI Images API
 	APITypeImages
+	// APITypeModerations is OpenAI Moderations API
+	APITypeModerations
+	// APITypeAudioTranscription is OpenAI Audio Transcriptions API
+	APITypeAudioTranscription
+	// APITypeAudioSpeech is OpenAI Audio Speech (text-to-speech) API
+	APITypeAudioSpeech
 	// APITypeAll supports all APIs
-	APITypeAll = APITypeChatCompletions | APITypeResponses | APITypeEmbeddings | APITypeImages
+	APITypeAll = APITypeChatCompletions | APITypeResponses | APITypeEmbeddings | APITypeImages | APITypeModerations | APITypeAudioTranscription | APITypeAudioSpeech
 )
 
-// String returns the string representation of APIType
+// apiTypeNames lists the single API types in bit order with their names
+var apiTypeNames = []struct {
+	apiType APIType
+	name    string
+}{
+	{APITypeChatCompletions, "chat_completions"},
+	{APITypeResponses, "responses"},
+	{APITypeEmbeddings, "embeddings"},
+	{APITypeImages, "images"},
+	{APITypeModerations, "moderations"},
+	{APITypeAudioTranscription, "audio_transcription"},
+	{APITypeAudioSpeech, "audio_speech"},
+}
+
+// String returns the string representation of APIType.
+// Combined flags are joined with "|", e.g. "chat_completions|embeddings".
 func (a APIType) String() string {
-	switch a {
-	case APITypeChatCompletions:
-		return "chat_completions"
-	case APITypeResponses:
-		return "responses"
-	case APITypeEmbeddings:
-		return "embeddings"
-	case APITypeImages:
-		return "images"
-	case APITypeAll:
+	if a == APITypeAll {
 		return "all"
-	default:
-		return fmt.Sprintf("unknown(%d)", a)
 	}
+	if a == 0 {
+		return "none"
+	}
+
+	var parts []string
+	remaining := a
+	for _, n := range apiTypeNames {
+		if a&n.apiType != 0 {
+			parts = append(parts, n.name)
+			remaining &^= n.apiType
+		}
+	}
+	if remaining != 0 {
+		parts = append(parts, fmt.Sprintf("unknown(%d)", int(remaining)))
+	}
+	return strings.Join(parts, "|")
+}
+
+// ParseAPIType parses the names produced by String, e.g. "chat_completions",
+// "chat_completions|embeddings" or "all"
+func ParseAPIType(s string) (APIType, error) {
+	if s == "all" {
+		return APITypeAll, nil
+	}
+
+	var a APIType
+	for _, part := range strings.Split(s, "|") {
+		found := false
+		for _, n := range apiTypeNames {
+			if n.name == part {
+				a |= n.apiType
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown API type %q", part)
+		}
+	}
+	return a, nil
 }
 
 // Supports checks if the provider supports the given API type
@@ -63,6 +118,10 @@ type ProviderConfig struct {
 	// APIKey is the authentication key
 	APIKey string
 
+	// APIKeyHeader is the header carrying APIKey as-is (e.g. "api-key").
+	// Default is empty, sending "Authorization: Bearer <APIKey>".
+	APIKeyHeader string
+
 	// SupportedAPIs is the API type(s) this provider supports
 	SupportedAPIs APIType
 
@@ -84,6 +143,10 @@ type ProviderConfig struct {
 	IdleConnTimeout     time.Duration // Idle connection timeout (default: 90s)
 	MaxIdleConnsPerHost int           // Maximum idle connections per host (default: 10)
 
+	// TLSConfig configures TLS to the upstream, e.g. private root CAs or client
+	// certificates for mTLS (optional). Ignored when HTTPClient is set.
+	TLSConfig *tls.Config
+
 	// Retry configuration
 	RetryConfig *RetryConfig
 
@@ -92,6 +155,75 @@ type ProviderConfig struct {
 
 	// ResponseConverter is an optional custom response converter
 	ResponseConverter ResponseConverterFunc
+
+	// UserTransform optionally rewrites the end-user identifier before it is sent upstream
+	UserTransform UserTransformFunc
+
+	// Endpoints overrides the HTTP method and path per API type (optional)
+	Endpoints map[APIType]Endpoint
+
+	// BodySerializer encodes outbound request bodies (optional, default: JSONSerializer)
+	BodySerializer BodySerializer
+
+	// SingleChoice marks an upstream that ignores n > 1 and always returns one choice.
+	// The chat handler then fans such requests out or rejects them.
+	SingleChoice bool
+
+	// PromptCaching marks an upstream that understands cache_control hints on
+	// messages. Without it the hints are dropped from outbound chat requests.
+	PromptCaching bool
+
+	// AlternatingRoles marks an upstream that rejects consecutive messages of the
+	// same role. Such messages are merged before chat requests are sent.
+	AlternatingRoles bool
+
+	// SystemMessages declares how the upstream takes system instructions
+	// (default SystemInMessages)
+	SystemMessages SystemMessageMode
+
+	// SingleSystemMessage marks an upstream accepting only one system
+	// instruction. Multiple system messages are consolidated into one.
+	SingleSystemMessage bool
+
+	// NoTools marks an upstream without function calling. The chat handler
+	// strips tools from such requests or rejects them.
+	NoTools bool
+
+	// IgnoreErrorBodies accepts 200 responses whose body is an error object.
+	// By default such responses are returned as an Error carrying the
+	// embedded message and type.
+	IgnoreErrorBodies bool
+
+	// ResponseHeaders lists the upstream response headers surfaced on
+	// Response.Headers and Error.Headers (default DefaultResponseHeaders)
+	ResponseHeaders []string
+
+	// BodyTemplate holds fixed fields merged into every outbound JSON request
+	// body after conversion, e.g. a routing key required by the upstream
+	BodyTemplate map[string]any
+
+	// OverrideBodyFields lets BodyTemplate replace fields the converter produced.
+	// By default the converted body wins.
+	OverrideBodyFields bool
+
+	// ForwardHeaders lists client request headers copied into outbound
+	// requests (e.g. "OpenAI-Organization"). Authorization is never forwarded.
+	ForwardHeaders []string
+
+	// MessageIDFunc generates the message item IDs of Chat Completions
+	// responses converted to Responses format (default openresponses.MessageID)
+	MessageIDFunc openresponses.MessageIDFunc
+}
+
+// Endpoint overrides how requests of one API type are sent upstream
+type Endpoint struct {
+	// Method is the HTTP method (default: POST)
+	Method string
+
+	// Path is appended to BaseURL instead of the request endpoint (optional).
+	// "{model}" is replaced with the request model,
+	// e.g. "/openai/deployments/{model}/chat/completions".
+	Path string
 }
 
 // RequestConverterFunc is a function that converts a request to a supported format
@@ -100,6 +232,9 @@ type RequestConverterFunc func(*Request) error
 // ResponseConverterFunc is a function that converts a response from a supported format
 type ResponseConverterFunc func(*Response) error
 
+// UserTransformFunc rewrites the end-user identifier; returning "" strips it
+type UserTransformFunc func(user string) string
+
 // DefaultConfig returns a default provider configuration
 func DefaultConfig() *ProviderConfig {
 	return &ProviderConfig{
@@ -131,6 +266,19 @@ func NewProviderConfig(name string) *ProviderConfig {
 	}
 }
 
+// Clone returns a copy of c that can be changed without affecting c, e.g. by
+// provider constructors filling in defaults
+func (c *ProviderConfig) Clone() *ProviderConfig {
+	clone := *c
+	if c.Endpoints != nil {
+		clone.Endpoints = make(map[APIType]Endpoint, len(c.Endpoints))
+		for apiType, endpoint := range c.Endpoints {
+			clone.Endpoints[apiType] = endpoint
+		}
+	}
+	return &clone
+}
+
 // WithAPIType sets the supported API types
 func (c *ProviderConfig) WithAPIType(apiType APIType) *ProviderConfig {
 	c.SupportedAPIs = apiType
@@ -155,6 +303,12 @@ func (c *ProviderConfig) WithAPIKey(apiKey string) *ProviderConfig {
 	return c
 }
 
+// WithAPIKeyHeader sends the API key as-is in the named header instead of as a bearer token
+func (c *ProviderConfig) WithAPIKeyHeader(header string) *ProviderConfig {
+	c.APIKeyHeader = header
+	return c
+}
+
 // WithTimeout sets the timeout
 func (c *ProviderConfig) WithTimeout(timeout time.Duration) *ProviderConfig {
 	c.Timeout = timeout
@@ -182,6 +336,36 @@ func (c *ProviderConfig) WithConnectionPool(maxIdleConns, maxConnsPerHost, maxId
 	return c
 }
 
+// WithTLSConfig sets the TLS configuration used to connect to the upstream
+func (c *ProviderConfig) WithTLSConfig(tlsConfig *tls.Config) *ProviderConfig {
+	c.TLSConfig = tlsConfig
+	return c
+}
+
+// WithClientCert adds a client certificate presented to upstreams requiring mTLS
+func (c *ProviderConfig) WithClientCert(cert tls.Certificate) *ProviderConfig {
+	tlsConfig := c.editTLSConfig()
+	tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	return c
+}
+
+// WithRootCAs sets the certificate authorities trusted for the upstream's certificate
+func (c *ProviderConfig) WithRootCAs(pool *x509.CertPool) *ProviderConfig {
+	c.editTLSConfig().RootCAs = pool
+	return c
+}
+
+// editTLSConfig returns a copy of the TLS configuration to modify, creating
+// one if needed, so a *tls.Config shared with other code is left untouched
+func (c *ProviderConfig) editTLSConfig() *tls.Config {
+	if c.TLSConfig == nil {
+		c.TLSConfig = &tls.Config{}
+	} else {
+		c.TLSConfig = c.TLSConfig.Clone()
+	}
+	return c.TLSConfig
+}
+
 // WithRetryConfig sets the retry configuration
 func (c *ProviderConfig) WithRetryConfig(retryConfig *RetryConfig) *ProviderConfig {
 	c.RetryConfig = retryConfig
@@ -206,6 +390,103 @@ func (c *ProviderConfig) WithResponseConverter(fn ResponseConverterFunc) *Provid
 	return c
 }
 
+// WithUserTransform sets the end-user identifier transform (see HMACUser and StripUser)
+func (c *ProviderConfig) WithUserTransform(fn UserTransformFunc) *ProviderConfig {
+	c.UserTransform = fn
+	return c
+}
+
+// WithEndpoint overrides the HTTP method and path template used for apiType.
+// An empty method defaults to POST; an empty path keeps the default endpoint.
+func (c *ProviderConfig) WithEndpoint(apiType APIType, method, path string) *ProviderConfig {
+	if c.Endpoints == nil {
+		c.Endpoints = make(map[APIType]Endpoint)
+	}
+	c.Endpoints[apiType] = Endpoint{Method: method, Path: path}
+	return c
+}
+
+// WithBodySerializer sets the serializer used for outbound request bodies
+func (c *ProviderConfig) WithBodySerializer(serializer BodySerializer) *ProviderConfig {
+	c.BodySerializer = serializer
+	return c
+}
+
+// WithSingleChoice marks the upstream as returning a single choice regardless of n
+func (c *ProviderConfig) WithSingleChoice() *ProviderConfig {
+	c.SingleChoice = true
+	return c
+}
+
+// WithPromptCaching marks the upstream as accepting cache_control hints on messages
+func (c *ProviderConfig) WithPromptCaching() *ProviderConfig {
+	c.PromptCaching = true
+	return c
+}
+
+// WithAlternatingRoles merges consecutive same-role messages for upstreams
+// requiring alternating user and assistant turns
+func (c *ProviderConfig) WithAlternatingRoles() *ProviderConfig {
+	c.AlternatingRoles = true
+	return c
+}
+
+// WithSystemMessageMode sets how system messages are sent upstream
+func (c *ProviderConfig) WithSystemMessageMode(mode SystemMessageMode) *ProviderConfig {
+	c.SystemMessages = mode
+	return c
+}
+
+// WithSingleSystemMessage consolidates system messages into one for upstreams
+// accepting a single system instruction
+func (c *ProviderConfig) WithSingleSystemMessage() *ProviderConfig {
+	c.SingleSystemMessage = true
+	return c
+}
+
+// WithoutTools marks the upstream as not supporting function calling
+func (c *ProviderConfig) WithoutTools() *ProviderConfig {
+	c.NoTools = true
+	return c
+}
+
+// WithIgnoreErrorBodies treats 200 responses with an error body as successful
+func (c *ProviderConfig) WithIgnoreErrorBodies() *ProviderConfig {
+	c.IgnoreErrorBodies = true
+	return c
+}
+
+// WithResponseHeaders sets the upstream response headers passed back to clients
+func (c *ProviderConfig) WithResponseHeaders(names ...string) *ProviderConfig {
+	c.ResponseHeaders = names
+	return c
+}
+
+// WithBodyTemplate merges template into every outbound JSON request body
+func (c *ProviderConfig) WithBodyTemplate(template map[string]any) *ProviderConfig {
+	c.BodyTemplate = template
+	return c
+}
+
+// WithMessageIDFunc sets the function generating the message item IDs of
+// responses converted to Responses format
+func (c *ProviderConfig) WithMessageIDFunc(fn openresponses.MessageIDFunc) *ProviderConfig {
+	c.MessageIDFunc = fn
+	return c
+}
+
+// WithOverrideBodyFields lets the body template replace converted fields
+func (c *ProviderConfig) WithOverrideBodyFields() *ProviderConfig {
+	c.OverrideBodyFields = true
+	return c
+}
+
+// WithForwardHeaders sets the client request headers forwarded upstream
+func (c *ProviderConfig) WithForwardHeaders(headers []string) *ProviderConfig {
+	c.ForwardHeaders = headers
+	return c
+}
+
 // GetHTTPClient returns the HTTP client, creating a default one if not set
 func (c *ProviderConfig) GetHTTPClient() *http.Client {
 	if c.HTTPClient != nil {
@@ -219,6 +500,7 @@ func (c *ProviderConfig) GetHTTPClient() *http.Client {
 		MaxIdleConnsPerHost: c.MaxIdleConnsPerHost,
 		IdleConnTimeout:     c.IdleConnTimeout,
 		DisableKeepAlives:   false,
+		TLSClientConfig:     c.TLSConfig.Clone(),
 	}
 	
 	// Set timeouts if configured
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// newMTLSServer starts a TLS upstream requiring a client certificate
+func newMTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "client certificate required", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	// Rejected handshakes are expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProviderConfig_TLS(t *testing.T) {
+	server := newMTLSServer(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	// The test server's certificate doubles as the client certificate
+	clientCert := server.TLS.Certificates[0]
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+	tests := []struct {
+		name    string
+		config  *ProviderConfig
+		wantErr bool
+	}{
+		{
+			name:    "unknown CA",
+			config:  NewProviderConfig("vllm").WithClientCert(clientCert),
+			wantErr: true,
+		},
+		{
+			name:    "missing client certificate",
+			config:  NewProviderConfig("vllm").WithRootCAs(roots),
+			wantErr: true,
+		},
+		{
+			name:   "private CA and client certificate",
+			config: NewProviderConfig("vllm").WithRootCAs(roots).WithClientCert(clientCert),
+		},
+		{
+			name: "TLS config",
+			config: NewProviderConfig("vllm").WithTLSConfig(&tls.Config{
+				RootCAs:      roots,
+				Certificates: []tls.Certificate{clientCert},
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPProvider(tt.config.WithBaseURL(server.URL)).SendRequest(context.Background(), req)
+			if tt.wantErr && err == nil {
+				t.Fatal("expected the request to fail")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProviderConfig_WithClientCertCopiesTLSConfig(t *testing.T) {
+	shared := &tls.Config{ServerName: "vllm.internal"}
+	config := NewProviderConfig("vllm").WithTLSConfig(shared).WithClientCert(tls.Certificate{})
+
+	if len(shared.Certificates) != 0 {
+		t.Error("expected the shared TLS config to be left untouched")
+	}
+	if config.TLSConfig.ServerName != "vllm.internal" || len(config.TLSConfig.Certificates) != 1 {
+		t.Errorf("unexpected TLS config: %+v", config.TLSConfig)
+	}
+	transport := config.GetHTTPClient().Transport.(*http.Transport)
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.ServerName != "vllm.internal" {
+		t.Errorf("expected the TLS config on the transport, got %+v", transport.TLSClientConfig)
+	}
+	if transport.MaxIdleConnsPerHost != config.MaxIdleConnsPerHost {
+		t.Error("expected connection pool settings to be kept")
+	}
+}
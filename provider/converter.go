@@ -11,6 +11,7 @@ import (
 // Converter handles conversion between different API formats
 type Converter struct {
 	supportedAPIs APIType
+	messageID     openresponses.MessageIDFunc
 }
 
 // NewConverter creates a new Converter for the given supported API types
@@ -20,6 +21,13 @@ func NewConverter(supportedAPIs APIType) *Converter {
 	}
 }
 
+// SetMessageIDFunc sets the function generating the IDs of message items in
+// Chat Completions responses converted to Responses format. nil restores
+// openresponses.MessageID.
+func (c *Converter) SetMessageIDFunc(fn openresponses.MessageIDFunc) {
+	c.messageID = fn
+}
+
 // ConvertRequest converts a request to the supported API format if needed
 func (c *Converter) ConvertRequest(req *Request) error {
 	// If the request API type is already supported, no conversion needed
@@ -27,6 +35,11 @@ func (c *Converter) ConvertRequest(req *Request) error {
 		return nil
 	}
 
+	// Only Chat Completions and Responses can be converted into each other
+	if req.APIType != APITypeChatCompletions && req.APIType != APITypeResponses {
+		return fmt.Errorf("%v requests cannot be converted to %v", req.APIType, c.supportedAPIs)
+	}
+
 	// Convert to the first supported API type
 	switch c.supportedAPIs {
 	case APITypeChatCompletions:
@@ -174,20 +187,30 @@ func (c *Converter) responsesToChatCompletion(orResp *openresponses.Response) *o
 	choices := make([]openai.Choice, 0, len(orResp.Output))
 
 	for _, item := range orResp.Output {
-		if msg, ok := item.(*openresponses.MessageItem); ok {
+		switch it := item.(type) {
+		case *openresponses.MessageItem:
 			content := ""
-			for _, c := range msg.Content {
+			for _, c := range it.Content {
 				content += c.Text
 			}
 
 			choices = append(choices, openai.Choice{
 				Index: len(choices),
 				Message: openai.Message{
-					Role:    string(msg.Role),
+					Role:    string(it.Role),
 					Content: content,
 				},
 				FinishReason: "stop",
 			})
+		case *openresponses.ImageGenerationCallItem:
+			// Images attach to the preceding message as image parts
+			if len(choices) == 0 {
+				choices = append(choices, openai.Choice{
+					Message:      openai.Message{Role: "assistant"},
+					FinishReason: "stop",
+				})
+			}
+			choices[len(choices)-1].Message.AppendImage(it.ImageURL())
 		}
 	}
 
@@ -215,10 +238,14 @@ func (c *Converter) chatCompletionToResponses(chatResp *openai.ChatCompletionRes
 	}
 
 	output := make([]openresponses.ItemField, 0, len(chatResp.Choices))
+	messageID := c.messageID
+	if messageID == nil {
+		messageID = openresponses.MessageID
+	}
 
 	for _, choice := range chatResp.Choices {
 		messageItem := &openresponses.MessageItem{
-			ID:     generateMessageID(responseID, choice.Index),
+			ID:     messageID(responseID, choice.Index),
 			Type:   "message",
 			Status: openresponses.MessageStatusCompleted,
 			Role:   openresponses.MessageRoleEnum(choice.Message.Role),
@@ -227,6 +254,7 @@ func (c *Converter) chatCompletionToResponses(chatResp *openai.ChatCompletionRes
 			},
 		}
 		output = append(output, messageItem)
+		output = append(output, openresponses.ImageItems(responseID, choice.Index, &choice.Message)...)
 	}
 
 	usage := &openresponses.Usage{}
@@ -316,8 +344,3 @@ func (c *Converter) openaiChunkToOREvent(chunk *openai.StreamChunk) *Chunk {
 
 	return nil
 }
-
-// Helper function to generate message IDs
-func generateMessageID(responseID string, index int) string {
-	return fmt.Sprintf("msg_%s_%d", responseID, index)
-}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/openresponses"
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestConverter_ConvertResponseMessageIDs(t *testing.T) {
+	chatResponse := func() *Response {
+		return &Response{
+			APIType: APITypeChatCompletions,
+			ChatCompletion: &openai2.ChatCompletionResponse{
+				ID:      "chatcmpl-1",
+				Choices: []openai2.Choice{{Message: openai2.Message{Role: "assistant", Content: "Hi"}}},
+			},
+		}
+	}
+	messageID := func(resp *Response) string {
+		t.Helper()
+		if resp.ORResponse == nil || len(resp.ORResponse.Output) == 0 {
+			t.Fatalf("expected a converted response, got %+v", resp)
+		}
+		item, ok := resp.ORResponse.Output[0].(*openresponses.MessageItem)
+		if !ok {
+			t.Fatalf("expected a message item, got %T", resp.ORResponse.Output[0])
+		}
+		return item.ID
+	}
+
+	resp := chatResponse()
+	if err := NewConverter(APITypeChatCompletions).ConvertResponse(resp, APITypeResponses); err != nil {
+		t.Fatalf("ConvertResponse failed: %v", err)
+	}
+	if got, want := messageID(resp), openresponses.MessageID("resp_chatcmpl-1", 0); got != want {
+		t.Errorf("expected the default message ID %s, got %s", want, got)
+	}
+
+	prov := NewHTTPProvider(NewProviderConfig("test").WithMessageIDFunc(func(responseID string, index int) string {
+		return fmt.Sprintf("custom_%d", index)
+	}))
+	resp = chatResponse()
+	if err := prov.ConvertResponseIfNeeded(resp, APITypeResponses); err != nil {
+		t.Fatalf("ConvertResponseIfNeeded failed: %v", err)
+	}
+	if got := messageID(resp); got != "custom_0" {
+		t.Errorf("expected the configured message ID, got %s", got)
+	}
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding is sent on every upstream request. Setting it ourselves turns
+// off the transport's transparent gzip handling, so decodeBody must run on
+// every response.
+const acceptEncoding = "gzip, deflate"
+
+// decodeBody replaces resp.Body with a reader decompressing it according to
+// its Content-Encoding. Unknown encodings are left as-is.
+func decodeBody(resp *http.Response) error {
+	var reader io.Reader
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			// An empty body, e.g. on an error status, has no gzip header
+			if err == io.EOF {
+				break
+			}
+			resp.Body.Close()
+			return fmt.Errorf("decode gzip response: %w", err)
+		}
+		reader = zr
+	case "deflate":
+		dr, err := newDeflateReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return fmt.Errorf("decode deflate response: %w", err)
+		}
+		reader = dr
+	default:
+		return nil
+	}
+
+	if reader != nil {
+		resp.Body = &decodedBody{Reader: reader, body: resp.Body}
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// newDeflateReader reads a "deflate" body. The encoding is meant to be zlib
+// wrapped, but some servers send raw deflate data, so the zlib header is
+// checked first.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == io.EOF || len(header) < 2 {
+		return br, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decodedBody reads the decompressed stream and closes the underlying body
+type decodedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+// Close closes the decompressor, if it needs closing, and the underlying body
+func (b *decodedBody) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		c.Close()
+	}
+	return b.body.Close()
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// compress encodes data with the named Content-Encoding
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestBaseProvider_DecodesCompressedResponses(t *testing.T) {
+	body := []byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Accept-Encoding") != acceptEncoding {
+					t.Errorf("expected Accept-Encoding %q, got %q", acceptEncoding, r.Header.Get("Accept-Encoding"))
+				}
+				header := encoding
+				if encoding == "raw-deflate" {
+					header = "deflate"
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", header)
+				w.Write(compress(t, encoding, body))
+			}))
+			defer server.Close()
+
+			req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+			resp, err := NewHTTPProviderWithBaseURL(server.URL, "test-key").SendRequest(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			chatResp, err := resp.GetChatCompletion()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if chatResp.Choices[0].Message.Content != "hi" {
+				t.Errorf("unexpected response: %+v", chatResp)
+			}
+		})
+	}
+}
+
+func TestBaseProvider_DecodesCompressedStream(t *testing.T) {
+	stream := []byte("data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}]}\n\ndata: [DONE]\n\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compress(t, "gzip", stream))
+	}))
+	defer server.Close()
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+	req.Stream = true
+	resp, err := NewHTTPProviderWithBaseURL(server.URL, "test-key").SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Close()
+
+	var chunks []*Chunk
+	for chunk := range resp.Chunks {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 2 || !bytes.Contains(chunks[0].OpenAI.Data, []byte("Hello")) || !chunks[1].Done {
+		t.Errorf("expected the decompressed chunk and done, got %d chunks", len(chunks))
+	}
+	if err := <-resp.Errors; err != nil {
+		t.Errorf("unexpected stream error: %v", err)
+	}
+}
+
+func TestBaseProvider_DecodesCompressedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(compress(t, "gzip", []byte(`{"error":{"message":"bad model"}}`)))
+	}))
+	defer server.Close()
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+	_, err := NewHTTPProviderWithBaseURL(server.URL, "test-key").SendRequest(context.Background(), req)
+	if err == nil || !bytes.Contains([]byte(err.Error()), []byte("bad model")) {
+		t.Errorf("expected the decompressed error body, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrorKind classifies why a provider call failed
+type ErrorKind int
+
+const (
+	// ErrorKindUpstream is an error status returned by the upstream
+	ErrorKindUpstream ErrorKind = iota
+	// ErrorKindRateLimited is a 429 returned by the upstream
+	ErrorKindRateLimited
+	// ErrorKindTimeout is a call that ran out of time, or a 408 or 504 from the upstream
+	ErrorKindTimeout
+	// ErrorKindNetwork is a call that failed before a response was read
+	ErrorKindNetwork
+	// ErrorKindDecode is a response body that couldn't be decoded
+	ErrorKindDecode
+)
+
+// String returns the name of the kind
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindUpstream:
+		return "upstream"
+	case ErrorKindRateLimited:
+		return "rate_limited"
+	case ErrorKindTimeout:
+		return "timeout"
+	case ErrorKindNetwork:
+		return "network"
+	case ErrorKindDecode:
+		return "decode"
+	default:
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+}
+
+// Error is returned when a call to the upstream fails. Use errors.As to
+// branch on its Kind.
+type Error struct {
+	// Kind classifies the failure
+	Kind ErrorKind
+	// StatusCode is the HTTP status code of the upstream response, or 0 if
+	// none was received
+	StatusCode int
+	// Body is the raw upstream response body
+	Body string
+	// Message and Type are the error an upstream embedded in the body of a
+	// 200 response
+	Message string
+	Type    string
+	// Headers are the allowlisted upstream response headers, e.g. Retry-After
+	Headers http.Header
+	// Err is the underlying error, if any
+	Err error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	switch {
+	case e.StatusCode == http.StatusOK && e.Type != "":
+		return fmt.Sprintf("upstream error (%s): %s", e.Type, e.Message)
+	case e.StatusCode == http.StatusOK:
+		return fmt.Sprintf("upstream error: %s", e.Message)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+	case e.Kind == ErrorKindDecode:
+		return fmt.Sprintf("decode response: %v", e.Err)
+	case e.Kind == ErrorKindTimeout:
+		return fmt.Sprintf("request timed out: %v", e.Err)
+	default:
+		return fmt.Sprintf("send request: %v", e.Err)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKindOf returns the kind of the first Error in err's chain, and false
+// if there is none
+func ErrorKindOf(err error) (ErrorKind, bool) {
+	var provErr *Error
+	if !errors.As(err, &provErr) {
+		return 0, false
+	}
+	return provErr.Kind, true
+}
+
+// statusErrorKind classifies an upstream error status
+func statusErrorKind(statusCode int) ErrorKind {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrorKindTimeout
+	default:
+		return ErrorKindUpstream
+	}
+}
+
+// TransportError classifies an error sending a request or reading its
+// response, for providers that make their own HTTP calls. Cancellation by the caller isn't the upstream's fault and is
+// returned unclassified.
+func TransportError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("send request: %w", err)
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &Error{Kind: ErrorKindTimeout, Err: err}
+	}
+	return &Error{Kind: ErrorKindNetwork, Err: err}
+}
+
+// DecodeError wraps an error decoding an upstream response body
+func DecodeError(err error) *Error {
+	return &Error{Kind: ErrorKindDecode, Err: err}
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// stall blocks until the client gives up on r. The body is read first so the
+// server notices the client disconnecting.
+func stall(r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	<-r.Context().Done()
+}
+
+func TestBaseProvider_ErrorKinds(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		timeout    time.Duration
+		closed     bool
+		wantKind   ErrorKind
+		wantStatus int
+	}{
+		{
+			name: "upstream",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error":{"message":"boom"}}`))
+			},
+			wantKind:   ErrorKindUpstream,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantKind:   ErrorKindRateLimited,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "upstream timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusGatewayTimeout)
+			},
+			wantKind:   ErrorKindTimeout,
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name: "deadline",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				stall(r)
+			},
+			timeout:  20 * time.Millisecond,
+			wantKind: ErrorKindTimeout,
+		},
+		{
+			name:     "network",
+			handler:  func(w http.ResponseWriter, r *http.Request) {},
+			closed:   true,
+			wantKind: ErrorKindNetwork,
+		},
+		{
+			name: "decode",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":`))
+			},
+			wantKind: ErrorKindDecode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			if tt.closed {
+				server.Close()
+			}
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			p := NewHTTPProvider(NewProviderConfig("openai").WithBaseURL(server.URL))
+			_, err := p.SendRequest(ctx, NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}}))
+
+			var provErr *Error
+			if !errors.As(err, &provErr) {
+				t.Fatalf("expected a provider Error, got %v", err)
+			}
+			if provErr.Kind != tt.wantKind {
+				t.Errorf("expected kind %s, got %s", tt.wantKind, provErr.Kind)
+			}
+			if provErr.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, provErr.StatusCode)
+			}
+			if kind, ok := ErrorKindOf(err); !ok || kind != tt.wantKind {
+				t.Errorf("expected ErrorKindOf to report %s, got %s", tt.wantKind, kind)
+			}
+		})
+	}
+}
+
+func TestBaseProvider_CancelledIsUnclassified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stall(r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	p := NewHTTPProvider(NewProviderConfig("openai").WithBaseURL(server.URL))
+	_, err := p.SendRequest(ctx, NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}}))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancellation, got %v", err)
+	}
+	if _, ok := ErrorKindOf(err); ok {
+		t.Errorf("expected cancellations not to be classified, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+)
+
+// neverForwarded lists client headers that are never sent upstream, whatever
+// the allowlist says. The gateway sets its own credentials and framing.
+var neverForwarded = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Api-Key":             true,
+	"X-Api-Key":           true,
+	"X-Goog-Api-Key":      true,
+	"Cookie":              true,
+	"Host":                true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+}
+
+type clientHeadersKey struct{}
+
+// WithClientHeaders attaches the headers of the incoming client request to ctx
+func WithClientHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, clientHeadersKey{}, header)
+}
+
+// ClientHeadersFromContext returns the client headers attached with WithClientHeaders
+func ClientHeadersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(clientHeadersKey{}).(http.Header)
+	return header
+}
+
+// ForwardClientHeaders copies the client headers in ctx named in the
+// ForwardHeaders allowlist into req.Headers. Headers already set on req win,
+// and credentials are never copied.
+func (p *BaseProvider) ForwardClientHeaders(ctx context.Context, req *Request) {
+	client := ClientHeadersFromContext(ctx)
+	if len(p.config.ForwardHeaders) == 0 || client == nil {
+		return
+	}
+	for _, name := range p.config.ForwardHeaders {
+		name = http.CanonicalHeaderKey(name)
+		if neverForwarded[name] || name == http.CanonicalHeaderKey(p.config.APIKeyHeader) {
+			continue
+		}
+		value := client.Get(name)
+		if value == "" {
+			continue
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		if _, ok := req.Headers[name]; !ok {
+			req.Headers[name] = value
+		}
+	}
+}
@@ -1,161 +1,70 @@
 package gemini
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/gemini/wire"
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
 )
 
-// OpenAIToGemini converts an OpenAI request to Gemini format
-func OpenAIToGemini(req *openai.ChatCompletionRequest, model string) *GenerateContentRequest {
-	geminiReq := &GenerateContentRequest{
-		Contents:         make([]Content, 0, len(req.Messages)),
-		GenerationConfig: GenerationConfig{},
-	}
-
-	// Convert messages to contents
-	for _, msg := range req.Messages {
-		role := msg.Role
-		if role == "assistant" {
-			role = "model"
-		}
-		if role == "system" {
-			role = "user" // Gemini treats system as user
-		}
-
-		content := Content{
-			Role: role,
-			Parts: []Part{
-				{Text: msg.Content},
-			},
+// NewBodySerializer returns a serializer that encodes OpenAI-format chat and
+// embedding requests as Gemini request bodies
+func NewBodySerializer() provider.BodySerializer {
+	return provider.BodySerializerFunc(func(req *provider.Request, body any) ([]byte, string, error) {
+		var geminiBody any
+		switch b := body.(type) {
+		case *openai.ChatCompletionRequest:
+			if err := checkImages(b); err != nil {
+				return nil, "", err
+			}
+			geminiBody = OpenAIToGemini(b, req.Model)
+		case *openai.EmbeddingRequest:
+			geminiBody = EmbeddingsOpenAIToGemini(b)
+		default:
+			return nil, "", fmt.Errorf("gemini: unsupported request body %T", body)
 		}
-		geminiReq.Contents = append(geminiReq.Contents, content)
-	}
+		data, err := json.Marshal(geminiBody)
+		return data, "application/json", err
+	})
+}
 
-	// Convert generation config
-	if req.Temperature != nil && *req.Temperature > 0 {
-		geminiReq.GenerationConfig.Temperature = *req.Temperature
-	}
-	if req.TopP != nil && *req.TopP > 0 {
-		geminiReq.GenerationConfig.TopP = *req.TopP
-	}
-	if req.MaxTokens != nil && *req.MaxTokens > 0 {
-		geminiReq.GenerationConfig.MaxOutputTokens = *req.MaxTokens
-	}
-	if req.Stop != nil {
-		// Handle Stop which can be string or []string
-		switch stop := req.Stop.(type) {
-		case string:
-			if stop != "" {
-				geminiReq.GenerationConfig.StopSequences = []string{stop}
-			}
-		case []string:
-			if len(stop) > 0 {
-				geminiReq.GenerationConfig.StopSequences = stop
+// checkImages rejects images Gemini can't take inline: only data: URLs can be
+// sent, as Gemini doesn't fetch image links
+func checkImages(req *openai.ChatCompletionRequest) error {
+	for i := range req.Messages {
+		for _, url := range req.Messages[i].Images() {
+			if _, _, ok := openai.ParseDataURL(url); !ok {
+				return fmt.Errorf("gemini: images must be given as data: URLs")
 			}
 		}
 	}
+	return nil
+}
 
-	return geminiReq
+// OpenAIToGemini converts an OpenAI request to Gemini format
+func OpenAIToGemini(req *openai.ChatCompletionRequest, model string) *GenerateContentRequest {
+	return wire.OpenAIToGemini(req, model)
 }
 
 // GeminiToOpenAI converts a Gemini response to OpenAI format
 func GeminiToOpenAI(resp *GenerateContentResponse, model string) *openai.ChatCompletionResponse {
-	openaiResp := &openai.ChatCompletionResponse{
-		ID:      "gemini-" + model,
-		Object:  "chat.completion",
-		Created: 0, // Gemini doesn't provide timestamp
-		Model:   model,
-		Choices: make([]openai.Choice, 0, len(resp.Candidates)),
-		Usage: openai.Usage{
-			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
-			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
-			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
-		},
-	}
-
-	for _, candidate := range resp.Candidates {
-		// Extract text from parts
-		var content string
-		for _, part := range candidate.Content.Parts {
-			if content != "" && part.Text != "" {
-				content += " "
-			}
-			content += part.Text
-		}
-
-		// Map finish reason
-		finishReason := mapFinishReason(candidate.FinishReason)
-
-		choice := openai.Choice{
-			Index: candidate.Index,
-			Message: openai.Message{
-				Role:    "assistant",
-				Content: content,
-			},
-			FinishReason: finishReason,
-		}
-		openaiResp.Choices = append(openaiResp.Choices, choice)
-	}
-
-	return openaiResp
+	return wire.GeminiToOpenAI(resp, model)
 }
 
-// mapFinishReason maps Gemini finish reasons to OpenAI format
-func mapFinishReason(reason string) string {
-	switch reason {
-	case "STOP":
-		return "stop"
-	case "MAX_TOKENS":
-		return "length"
-	case "SAFETY":
-		return "content_filter"
-	case "RECITATION":
-		return "content_filter"
-	default:
-		return "stop"
-	}
+// GeminiToOpenAIChunk converts a Gemini streaming response to an OpenAI
+// chat.completion.chunk. Candidates without a finish reason leave it empty.
+func GeminiToOpenAIChunk(resp *GenerateContentResponse, id, model string, created int64) *openai.ChatCompletionStreamResponse {
+	return wire.GeminiToOpenAIChunk(resp, id, model, created)
 }
 
 // EmbeddingsOpenAIToGemini converts OpenAI embedding request to Gemini format
 func EmbeddingsOpenAIToGemini(req *openai.EmbeddingRequest) *EmbedContentRequest {
-	// Extract input text
-	var text string
-	switch v := req.Input.(type) {
-	case string:
-		text = v
-	case []string:
-		if len(v) > 0 {
-			text = v[0] // Gemini API only supports single input
-		}
-	case []interface{}:
-		if len(v) > 0 {
-			if s, ok := v[0].(string); ok {
-				text = s
-			}
-		}
-	}
-
-	return &EmbedContentRequest{
-		Content: Content{
-			Parts: []Part{{Text: text}},
-		},
-		TaskType: "RETRIEVAL_DOCUMENT",
-	}
+	return wire.EmbeddingsOpenAIToGemini(req)
 }
 
 // EmbeddingsGeminiToOpenAI converts Gemini embedding response to OpenAI format
 func EmbeddingsGeminiToOpenAI(resp *EmbedContentResponse, model string) *openai.EmbeddingResponse {
-	return &openai.EmbeddingResponse{
-		Object: "list",
-		Data: []openai.Embedding{
-			{
-				Object:    "embedding",
-				Embedding: resp.Embedding.Values,
-				Index:     0,
-			},
-		},
-		Model: model,
-		Usage: openai.Usage{
-			TotalTokens: len(resp.Embedding.Values), // Approximate
-		},
-	}
+	return wire.EmbeddingsGeminiToOpenAI(resp, model)
 }
@@ -0,0 +1,227 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+)
+
+// DefaultBaseURL is the base URL of the Gemini API
+const DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// APIError is an error returned by the Gemini API
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Status is the Gemini status string (e.g., "INVALID_ARGUMENT")
+	Status string
+	// Message is the error message
+	Message string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("gemini error %d (%s): %s", e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("gemini error %d: %s", e.StatusCode, e.Message)
+}
+
+// Provider sends requests to the Gemini API
+type Provider struct {
+	*provider.BaseProvider
+	client *http.Client
+}
+
+// NewProvider creates a new Gemini provider with a copy of the given
+// configuration. BaseURL defaults to DefaultBaseURL and Name defaults to "gemini".
+func NewProvider(config *provider.ProviderConfig) *Provider {
+	if config == nil {
+		config = provider.DefaultConfig()
+	} else {
+		config = config.Clone()
+	}
+	if config.Name == "" {
+		config.Name = "gemini"
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultBaseURL
+	}
+	config.SupportedAPIs = provider.APITypeChatCompletions | provider.APITypeEmbeddings
+	// System messages become systemInstruction unless configured otherwise
+	if config.SystemMessages == provider.SystemInMessages {
+		config.SystemMessages = provider.SystemTopLevel
+	}
+	if config.BodySerializer == nil {
+		config.BodySerializer = NewBodySerializer()
+	}
+
+	return &Provider{
+		BaseProvider: provider.NewBaseProvider(config),
+		client:       config.GetHTTPClient(),
+	}
+}
+
+// NewProviderWithAPIKey creates a new Gemini provider for the public API
+func NewProviderWithAPIKey(apiKey string) *Provider {
+	return NewProvider(provider.NewProviderConfig("gemini").WithAPIKey(apiKey))
+}
+
+// SendRequest implements provider.Provider.SendRequest
+func (p *Provider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	// Responses requests are served through Chat Completions
+	if req.APIType == provider.APITypeResponses {
+		if err := p.ConvertRequestIfNeeded(req); err != nil {
+			return nil, fmt.Errorf("convert request: %w", err)
+		}
+	}
+
+	if req.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	p.ForwardClientHeaders(ctx, req)
+
+	switch req.APIType {
+	case provider.APITypeChatCompletions:
+		if req.Stream {
+			return p.sendStreamingChatRequest(ctx, req)
+		}
+		return p.sendChatRequest(ctx, req)
+	case provider.APITypeEmbeddings:
+		return p.sendEmbeddingRequest(ctx, req)
+	default:
+		return nil, fmt.Errorf("API type %v not supported by provider %s", req.APIType, p.Name())
+	}
+}
+
+// sendChatRequest sends a generateContent request
+func (p *Provider) sendChatRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	chatReq, err := p.ParseChatCompletionRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse chat completion request: %w", err)
+	}
+
+	body, headers, err := p.SerializeBody(req, chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	respBody, err := p.post(ctx, p.modelURL(req.Model, "generateContent"), body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var geminiResp GenerateContentResponse
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
+		return nil, provider.DecodeError(err)
+	}
+
+	return provider.NewChatCompletionResponse(GeminiToOpenAI(&geminiResp, req.Model)), nil
+}
+
+// sendEmbeddingRequest sends an embedContent request
+func (p *Provider) sendEmbeddingRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	embeddingReq, err := p.ParseEmbeddingRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse embedding request: %w", err)
+	}
+
+	body, headers, err := p.SerializeBody(req, embeddingReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	respBody, err := p.post(ctx, p.modelURL(req.Model, "embedContent"), body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var geminiResp EmbedContentResponse
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
+		return nil, provider.DecodeError(err)
+	}
+
+	return provider.NewEmbeddingResponse(EmbeddingsGeminiToOpenAI(&geminiResp, req.Model)), nil
+}
+
+// modelURL builds the URL of a model method, e.g. models/gemini-pro:generateContent
+func (p *Provider) modelURL(model, method string) string {
+	query := url.Values{}
+	if apiKey := p.Config().APIKey; apiKey != "" {
+		query.Set("key", apiKey)
+	}
+	if method == "streamGenerateContent" {
+		query.Set("alt", "sse")
+	}
+
+	u := fmt.Sprintf("%s/models/%s:%s", p.Config().BaseURL, url.PathEscape(model), method)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// do sends a JSON POST request and returns the raw HTTP response
+func (p *Provider) do(ctx context.Context, url string, body []byte, headers map[string]string) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(httpReq)
+	provider.LogAttempt(ctx, p.Name(), start, resp, err)
+	if err != nil {
+		return nil, provider.TransportError(err)
+	}
+	return resp, nil
+}
+
+// post sends a JSON request and returns the response body, mapping
+// Gemini error envelopes to *APIError
+func (p *Provider) post(ctx context.Context, url string, body []byte, headers map[string]string) ([]byte, error) {
+	resp, err := p.do(ctx, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, provider.TransportError(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.StatusError(resp, respBody, parseError(resp.StatusCode, respBody))
+	}
+
+	return respBody, nil
+}
+
+// parseError converts an error response body to an *APIError
+func parseError(statusCode int, body []byte) error {
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error.Message == "" {
+		return &APIError{StatusCode: statusCode, Message: string(body)}
+	}
+	return &APIError{
+		StatusCode: statusCode,
+		Status:     errResp.Error.Status,
+		Message:    errResp.Error.Message,
+	}
+}
+
+// Ensure Provider implements provider.Provider
+var _ provider.Provider = (*Provider)(nil)
@@ -0,0 +1,244 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestNewProviderWithAPIKey(t *testing.T) {
+	p := NewProviderWithAPIKey("test-api-key")
+
+	if p.Name() != "gemini" {
+		t.Errorf("expected name 'gemini', got '%s'", p.Name())
+	}
+	if p.Config().APIKey != "test-api-key" {
+		t.Errorf("expected api key 'test-api-key', got '%s'", p.Config().APIKey)
+	}
+	if p.Config().BaseURL != DefaultBaseURL {
+		t.Errorf("expected base URL %s, got %s", DefaultBaseURL, p.Config().BaseURL)
+	}
+	if !p.SupportedAPIs().Supports(provider.APITypeChatCompletions) {
+		t.Error("expected chat completions to be supported")
+	}
+	if p.SupportedAPIs().Supports(provider.APITypeImages) {
+		t.Error("expected images to be unsupported")
+	}
+}
+
+func TestNewProviderCopiesConfig(t *testing.T) {
+	config := provider.NewProviderConfig("").WithAPIKey("test-api-key")
+	p := NewProvider(config)
+
+	if config.Name != "" || config.BaseURL != "" || config.BodySerializer != nil {
+		t.Errorf("expected the caller's config unchanged, got %+v", config)
+	}
+	if p.Name() != "gemini" || p.Config().BaseURL != DefaultBaseURL {
+		t.Errorf("expected the defaults on the provider's copy, got %s and %s", p.Name(), p.Config().BaseURL)
+	}
+}
+
+func TestProvider_SendRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-pro:generateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("key") != "test-key" {
+			t.Errorf("expected key 'test-key', got '%s'", r.URL.Query().Get("key"))
+		}
+
+		var req GenerateContentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(req.Contents) != 1 || req.Contents[0].Parts[0].Text != "Hello" {
+			t.Errorf("unexpected contents: %+v", req.Contents)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"text": "Hi there"}]},
+				"finishReason": "STOP",
+				"index": 0
+			}],
+			"usageMetadata": {"promptTokenCount": 3, "candidatesTokenCount": 2, "totalTokenCount": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("gemini").WithBaseURL(server.URL).WithAPIKey("test-key"))
+
+	req := provider.NewChatCompletionsRequest("gemini-pro", []openai.Message{{Role: "user", Content: "Hello"}})
+	resp, err := p.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	chatResp, err := resp.GetChatCompletion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chatResp.Choices) != 1 {
+		t.Fatalf("expected 1 choice, got %d", len(chatResp.Choices))
+	}
+	if chatResp.Choices[0].Message.Content != "Hi there" {
+		t.Errorf("expected content 'Hi there', got '%s'", chatResp.Choices[0].Message.Content)
+	}
+	if chatResp.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected finish reason 'stop', got '%s'", chatResp.Choices[0].FinishReason)
+	}
+	if chatResp.Usage.TotalTokens != 5 {
+		t.Errorf("expected 5 total tokens, got %d", chatResp.Usage.TotalTokens)
+	}
+}
+
+func TestProvider_SendRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": 400, "message": "API key not valid", "status": "INVALID_ARGUMENT"}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("gemini").WithBaseURL(server.URL).WithAPIKey("bad-key"))
+
+	req := provider.NewChatCompletionsRequest("gemini-pro", []openai.Message{{Role: "user", Content: "Hello"}})
+	_, err := p.SendRequest(context.Background(), req)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", apiErr.StatusCode)
+	}
+	if apiErr.Status != "INVALID_ARGUMENT" {
+		t.Errorf("expected status INVALID_ARGUMENT, got %s", apiErr.Status)
+	}
+	if apiErr.Message != "API key not valid" {
+		t.Errorf("expected message 'API key not valid', got '%s'", apiErr.Message)
+	}
+}
+
+func TestProvider_SendRequestErrorKinds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": 429, "message": "Resource exhausted", "status": "RESOURCE_EXHAUSTED"}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("gemini").WithBaseURL(server.URL))
+	for _, stream := range []bool{false, true} {
+		req := provider.NewChatCompletionsRequest("gemini-pro", []openai.Message{{Role: "user", Content: "Hello"}})
+		req.Stream = stream
+		_, err := p.SendRequest(context.Background(), req)
+
+		var provErr *provider.Error
+		if !errors.As(err, &provErr) || provErr.Kind != provider.ErrorKindRateLimited {
+			t.Fatalf("stream=%v: expected a rate limited provider.Error, got %v", stream, err)
+		}
+		if got := provErr.Headers.Get("Retry-After"); got != "3" {
+			t.Errorf("stream=%v: expected Retry-After 3, got %q", stream, got)
+		}
+	}
+
+	// Timeouts are classified too
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	p = NewProvider(provider.NewProviderConfig("gemini").WithBaseURL(slow.URL))
+	_, err := p.SendRequest(ctx, provider.NewChatCompletionsRequest("gemini-pro", []openai.Message{{Role: "user", Content: "Hello"}}))
+	if kind, ok := provider.ErrorKindOf(err); !ok || kind != provider.ErrorKindTimeout {
+		t.Errorf("expected a timeout provider.Error, got %v", err)
+	}
+}
+
+func TestProvider_SendRequestUnsupported(t *testing.T) {
+	p := NewProviderWithAPIKey("test-key")
+
+	_, err := p.SendRequest(context.Background(), provider.NewImagesRequest("gemini-pro", "a cat"))
+	if err == nil {
+		t.Error("expected error for images request, got nil")
+	}
+}
+
+func TestProvider_SendRequestStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-pro:streamGenerateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("expected alt=sse, got '%s'", r.URL.Query().Get("alt"))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"candidates\": [{\"content\": {\"role\": \"model\", \"parts\": [{\"text\": \"Hello\"}]}, \"index\": 0}]}\n\n"))
+		w.Write([]byte("data: {\"candidates\": [{\"content\": {\"role\": \"model\", \"parts\": [{\"text\": \", world\"}]}, \"index\": 0}]}\n\n"))
+		w.Write([]byte("data: {\"candidates\": [{\"content\": {\"role\": \"model\", \"parts\": [{\"text\": \"!\"}]}, \"finishReason\": \"MAX_TOKENS\", \"index\": 0}]}\n\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider(provider.NewProviderConfig("gemini").WithBaseURL(server.URL).WithAPIKey("test-key"))
+
+	req := provider.NewChatCompletionsRequest("gemini-pro", []openai.Message{{Role: "user", Content: "Hi"}})
+	req.Stream = true
+
+	resp, err := p.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Close()
+
+	var content, finishReason, role string
+	var done bool
+	for chunk := range resp.Chunks {
+		if chunk.Done {
+			done = true
+			continue
+		}
+
+		var streamResp openai.ChatCompletionStreamResponse
+		if err := json.Unmarshal(chunk.OpenAI.Data, &streamResp); err != nil {
+			t.Fatalf("decode chunk: %v", err)
+		}
+		if streamResp.Object != "chat.completion.chunk" {
+			t.Errorf("expected object chat.completion.chunk, got %s", streamResp.Object)
+		}
+		for _, choice := range streamResp.Choices {
+			if role == "" {
+				role = choice.Delta.Role
+			}
+			content += choice.Delta.Content
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+	if err := <-resp.Errors; err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	if content != "Hello, world!" {
+		t.Errorf("expected content 'Hello, world!', got '%s'", content)
+	}
+	if finishReason != "length" {
+		t.Errorf("expected finish reason 'length', got '%s'", finishReason)
+	}
+	if role != "assistant" {
+		t.Errorf("expected role 'assistant' on first chunk, got '%s'", role)
+	}
+	if !done {
+		t.Error("expected done chunk")
+	}
+}
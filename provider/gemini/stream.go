@@ -0,0 +1,138 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// sendStreamingChatRequest sends a streamGenerateContent request and converts
+// the Gemini SSE stream to OpenAI chat.completion.chunk events
+func (p *Provider) sendStreamingChatRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	chatReq, err := p.ParseChatCompletionRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("parse chat completion request: %w", err)
+	}
+
+	body, headers, err := p.SerializeBody(req, chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	if _, ok := headers["Accept"]; !ok {
+		headers["Accept"] = "text/event-stream"
+	}
+
+	resp, err := p.do(ctx, p.modelURL(req.Model, "streamGenerateContent"), body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, p.StatusError(resp, respBody, parseError(resp.StatusCode, respBody))
+	}
+
+	chunkChan := make(chan *provider.Chunk, 16)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errChan)
+		defer resp.Body.Close()
+
+		// Close the upstream body as soon as the caller goes away
+		stop := context.AfterFunc(ctx, func() {
+			resp.Body.Close()
+		})
+		defer stop()
+
+		send := func(chunk *provider.Chunk) bool {
+			select {
+			case chunkChan <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		id := fmt.Sprintf("gemini-%d", time.Now().UnixNano())
+		created := time.Now().Unix()
+		started := make(map[int]bool)
+		finished := false
+
+		decoder := provider.NewSSEDecoder(resp.Body)
+		for {
+			event, readErr := decoder.Next()
+			if readErr == io.EOF {
+				break
+			}
+			if readErr != nil {
+				if ctx.Err() == nil {
+					errChan <- provider.TransportError(readErr)
+				}
+				return
+			}
+
+			if event.Data != "" {
+				var geminiResp GenerateContentResponse
+				if err := json.Unmarshal([]byte(event.Data), &geminiResp); err != nil {
+					errChan <- provider.DecodeError(err)
+					return
+				}
+
+				chunk := GeminiToOpenAIChunk(&geminiResp, id, req.Model, created)
+				for i := range chunk.Choices {
+					if !started[chunk.Choices[i].Index] {
+						chunk.Choices[i].Delta.Role = "assistant"
+						started[chunk.Choices[i].Index] = true
+					}
+					if chunk.Choices[i].FinishReason != "" {
+						finished = true
+					}
+				}
+
+				if !sendStreamChunk(chunk, send) {
+					return
+				}
+			}
+		}
+
+		// Ensure the stream ends with a finish reason
+		if !finished {
+			final := &openai.ChatCompletionStreamResponse{
+				ID:      id,
+				Object:  "chat.completion.chunk",
+				Created: created,
+				Model:   req.Model,
+				Choices: []openai.Choice{{Delta: &openai.Delta{}, FinishReason: "stop"}},
+			}
+			if !sendStreamChunk(final, send) {
+				return
+			}
+		}
+
+		send(provider.NewOpenAIChunkDone())
+	}()
+
+	closeFn := func() error {
+		return resp.Body.Close()
+	}
+
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunkChan, errChan, closeFn), nil
+}
+
+// sendStreamChunk marshals an OpenAI stream chunk and sends it
+func sendStreamChunk(chunk *openai.ChatCompletionStreamResponse, send func(*provider.Chunk) bool) bool {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return false
+	}
+	return send(provider.NewOpenAIChunk(data))
+}
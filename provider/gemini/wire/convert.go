@@ -0,0 +1,275 @@
+// Package wire holds the Gemini API request and response types and their
+// conversion to and from OpenAI format. It doesn't depend on the provider
+// package, so both the gemini provider and provider's deprecated
+// GeminiHTTPProvider can use it.
+package wire
+
+import (
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// OpenAIToGemini converts an OpenAI request to Gemini format
+func OpenAIToGemini(req *openai.ChatCompletionRequest, model string) *GenerateContentRequest {
+	geminiReq := &GenerateContentRequest{
+		Contents:         make([]Content, 0, len(req.Messages)),
+		GenerationConfig: GenerationConfig{},
+	}
+
+	// Gemini takes the system prompt as systemInstruction
+	messages := make([]openai.Message, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		if !openai.IsSystemRole(msg.Role) {
+			messages = append(messages, msg)
+			continue
+		}
+		if geminiReq.SystemInstruction == nil {
+			geminiReq.SystemInstruction = &Content{}
+		}
+		geminiReq.SystemInstruction.Parts = append(geminiReq.SystemInstruction.Parts, Part{Text: msg.Content})
+	}
+
+	// Convert messages to contents
+	for _, msg := range messages {
+		role := msg.Role
+		if role == "assistant" {
+			role = "model"
+		}
+
+		// Gemini requires alternating roles, so consecutive messages of the same
+		// role become parts of one content
+		parts := messageParts(&msg)
+		if n := len(geminiReq.Contents); n > 0 && geminiReq.Contents[n-1].Role == role {
+			geminiReq.Contents[n-1].Parts = append(geminiReq.Contents[n-1].Parts, parts...)
+			continue
+		}
+		geminiReq.Contents = append(geminiReq.Contents, Content{Role: role, Parts: parts})
+	}
+
+	// Convert generation config
+	if req.Temperature != nil && *req.Temperature > 0 {
+		geminiReq.GenerationConfig.Temperature = *req.Temperature
+	}
+	if req.TopP != nil && *req.TopP > 0 {
+		geminiReq.GenerationConfig.TopP = *req.TopP
+	}
+	if maxTokens := req.OutputTokenLimit(); maxTokens != nil && *maxTokens > 0 {
+		geminiReq.GenerationConfig.MaxOutputTokens = *maxTokens
+	}
+	if req.N != nil && *req.N > 1 {
+		geminiReq.GenerationConfig.CandidateCount = *req.N
+	}
+	if req.Stop != nil {
+		// Handle Stop which can be string or []string
+		switch stop := req.Stop.(type) {
+		case string:
+			if stop != "" {
+				geminiReq.GenerationConfig.StopSequences = []string{stop}
+			}
+		case []string:
+			if len(stop) > 0 {
+				geminiReq.GenerationConfig.StopSequences = stop
+			}
+		}
+	}
+
+	return geminiReq
+}
+
+// GeminiToOpenAI converts a Gemini response to OpenAI format
+func GeminiToOpenAI(resp *GenerateContentResponse, model string) *openai.ChatCompletionResponse {
+	openaiResp := &openai.ChatCompletionResponse{
+		ID:      "gemini-" + model,
+		Object:  "chat.completion",
+		Created: 0, // Gemini doesn't provide timestamp
+		Model:   model,
+		Choices: make([]openai.Choice, 0, len(resp.Candidates)),
+		Usage: openai.Usage{
+			PromptTokens:     resp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+		},
+	}
+
+	for _, candidate := range resp.Candidates {
+		// Extract text from parts
+		var content string
+		for _, part := range candidate.Content.Parts {
+			if content != "" && part.Text != "" {
+				content += " "
+			}
+			content += part.Text
+		}
+
+		// Map finish reason
+		finishReason := mapFinishReason(candidate.FinishReason)
+
+		choice := openai.Choice{
+			Index: candidate.Index,
+			Message: openai.Message{
+				Role:    "assistant",
+				Content: content,
+			},
+			FinishReason: finishReason,
+		}
+		if hasImages(candidate.Content.Parts) {
+			choice.Message.Parts = contentParts(candidate.Content.Parts)
+		}
+		openaiResp.Choices = append(openaiResp.Choices, choice)
+	}
+
+	return openaiResp
+}
+
+// GeminiToOpenAIChunk converts a Gemini streaming response to an OpenAI
+// chat.completion.chunk. Candidates without a finish reason leave it empty.
+func GeminiToOpenAIChunk(resp *GenerateContentResponse, id, model string, created int64) *openai.ChatCompletionStreamResponse {
+	chunk := &openai.ChatCompletionStreamResponse{
+		ID:      id,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: make([]openai.Choice, 0, len(resp.Candidates)),
+	}
+
+	for _, candidate := range resp.Candidates {
+		// Stream deltas are concatenated as-is
+		var content string
+		for _, part := range candidate.Content.Parts {
+			content += part.Text
+		}
+
+		var finishReason string
+		if candidate.FinishReason != "" {
+			finishReason = mapFinishReason(candidate.FinishReason)
+		}
+
+		delta := &openai.Delta{Content: content}
+		if hasImages(candidate.Content.Parts) {
+			delta.Parts = contentParts(candidate.Content.Parts)
+		}
+
+		chunk.Choices = append(chunk.Choices, openai.Choice{
+			Index:        candidate.Index,
+			Delta:        delta,
+			FinishReason: finishReason,
+		})
+	}
+
+	return chunk
+}
+
+// mapFinishReason maps Gemini finish reasons to OpenAI format
+func mapFinishReason(reason string) string {
+	switch reason {
+	case "STOP":
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY":
+		return "content_filter"
+	case "RECITATION":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+// EmbeddingsOpenAIToGemini converts OpenAI embedding request to Gemini format
+func EmbeddingsOpenAIToGemini(req *openai.EmbeddingRequest) *EmbedContentRequest {
+	// Extract input text
+	var text string
+	switch v := req.Input.(type) {
+	case string:
+		text = v
+	case []string:
+		if len(v) > 0 {
+			text = v[0] // Gemini API only supports single input
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			if s, ok := v[0].(string); ok {
+				text = s
+			}
+		}
+	}
+
+	return &EmbedContentRequest{
+		Content: Content{
+			Parts: []Part{{Text: text}},
+		},
+		TaskType: "RETRIEVAL_DOCUMENT",
+	}
+}
+
+// EmbeddingsGeminiToOpenAI converts Gemini embedding response to OpenAI format
+func EmbeddingsGeminiToOpenAI(resp *EmbedContentResponse, model string) *openai.EmbeddingResponse {
+	return &openai.EmbeddingResponse{
+		Object: "list",
+		Data: []openai.Embedding{
+			{
+				Object:    "embedding",
+				Embedding: resp.Embedding.Values,
+				Index:     0,
+			},
+		},
+		Model: model,
+		Usage: openai.Usage{
+			TotalTokens: len(resp.Embedding.Values), // Approximate
+		},
+	}
+}
+
+// imageURL returns an inline image part as a data: URL
+func imageURL(part Part) (string, bool) {
+	if part.InlineData == nil || !strings.HasPrefix(part.InlineData.MIMEType, "image/") {
+		return "", false
+	}
+	return "data:" + part.InlineData.MIMEType + ";base64," + part.InlineData.Data, true
+}
+
+// hasImages reports whether parts include an inline image
+func hasImages(parts []Part) bool {
+	for _, part := range parts {
+		if _, ok := imageURL(part); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// messageParts converts the content of a message to text and inline image
+// parts. Images that aren't data: URLs are skipped.
+func messageParts(msg *openai.Message) []Part {
+	if len(msg.Parts) == 0 {
+		return []Part{{Text: msg.Content}}
+	}
+	var parts []Part
+	for _, part := range msg.ContentParts() {
+		if part.Type != openai.ContentPartImageURL {
+			parts = append(parts, Part{Text: part.Text})
+		} else if part.ImageURL != nil {
+			if mediaType, data, ok := openai.ParseDataURL(part.ImageURL.URL); ok {
+				parts = append(parts, Part{InlineData: &InlineData{MIMEType: mediaType, Data: data}})
+			}
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, Part{Text: msg.Content})
+	}
+	return parts
+}
+
+// contentParts returns the text and inline images of parts as OpenAI content parts
+func contentParts(parts []Part) []openai.ContentPart {
+	var content []openai.ContentPart
+	for _, part := range parts {
+		if url, ok := imageURL(part); ok {
+			content = append(content, openai.ImagePart(url))
+		} else if part.Text != "" {
+			content = append(content, openai.TextPart(part.Text))
+		}
+	}
+	return content
+}
@@ -0,0 +1,275 @@
+package wire
+
+import (
+	"testing"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestOpenAIToGemini(t *testing.T) {
+	temp := 0.7
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+		},
+		Temperature: &temp,
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if len(geminiReq.Contents) != 1 {
+		t.Errorf("expected 1 content, got %d", len(geminiReq.Contents))
+	}
+	if geminiReq.Contents[0].Role != "user" {
+		t.Errorf("expected role user, got %s", geminiReq.Contents[0].Role)
+	}
+	if geminiReq.GenerationConfig.Temperature != 0.7 {
+		t.Errorf("expected temperature 0.7, got %f", geminiReq.GenerationConfig.Temperature)
+	}
+}
+
+func TestOpenAIToGeminiAssistantRole(t *testing.T) {
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi there"},
+		},
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if len(geminiReq.Contents) != 2 {
+		t.Errorf("expected 2 contents, got %d", len(geminiReq.Contents))
+	}
+	if geminiReq.Contents[1].Role != "model" {
+		t.Errorf("expected role model for assistant, got %s", geminiReq.Contents[1].Role)
+	}
+}
+
+func TestOpenAIToGeminiSystemRole(t *testing.T) {
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "system", Content: "You are a helpful assistant"},
+			{Role: "user", Content: "Hello"},
+		},
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	// The system message is sent as systemInstruction
+	if geminiReq.SystemInstruction == nil || len(geminiReq.SystemInstruction.Parts) != 1 {
+		t.Fatalf("expected a system instruction, got %+v", geminiReq.SystemInstruction)
+	}
+	if geminiReq.SystemInstruction.Parts[0].Text != "You are a helpful assistant" {
+		t.Errorf("unexpected system instruction: %q", geminiReq.SystemInstruction.Parts[0].Text)
+	}
+	if len(geminiReq.Contents) != 1 || geminiReq.Contents[0].Role != "user" || geminiReq.Contents[0].Parts[0].Text != "Hello" {
+		t.Errorf("expected only the user message in contents, got %+v", geminiReq.Contents)
+	}
+}
+
+func TestOpenAIToGeminiMergesConsecutiveRoles(t *testing.T) {
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+			{Role: "user", Content: "Are you there?"},
+			{Role: "assistant", Content: "Yes"},
+			{Role: "assistant", Content: "How can I help?"},
+			{Role: "user", Content: "Thanks"},
+		},
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if len(geminiReq.Contents) != 3 {
+		t.Fatalf("expected 3 alternating contents, got %d", len(geminiReq.Contents))
+	}
+	wantRoles := []string{"user", "model", "user"}
+	wantParts := [][]string{{"Hello", "Are you there?"}, {"Yes", "How can I help?"}, {"Thanks"}}
+	for i, content := range geminiReq.Contents {
+		if content.Role != wantRoles[i] {
+			t.Errorf("content %d: expected role %s, got %s", i, wantRoles[i], content.Role)
+		}
+		if len(content.Parts) != len(wantParts[i]) {
+			t.Fatalf("content %d: expected %d parts, got %+v", i, len(wantParts[i]), content.Parts)
+		}
+		for j, part := range content.Parts {
+			if part.Text != wantParts[i][j] {
+				t.Errorf("content %d part %d: expected %q, got %q", i, j, wantParts[i][j], part.Text)
+			}
+		}
+	}
+}
+
+func TestOpenAIToGeminiWithTopP(t *testing.T) {
+	topP := 0.9
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+		},
+		TopP: &topP,
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if geminiReq.GenerationConfig.TopP != 0.9 {
+		t.Errorf("expected topP 0.9, got %f", geminiReq.GenerationConfig.TopP)
+	}
+}
+
+func TestOpenAIToGeminiWithMaxTokens(t *testing.T) {
+	maxTokens := 100
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+		},
+		MaxTokens: &maxTokens,
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if geminiReq.GenerationConfig.MaxOutputTokens != 100 {
+		t.Errorf("expected MaxOutputTokens 100, got %d", geminiReq.GenerationConfig.MaxOutputTokens)
+	}
+}
+
+func TestOpenAIToGeminiWithN(t *testing.T) {
+	n := 2
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+		},
+		N: &n,
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if geminiReq.GenerationConfig.CandidateCount != 2 {
+		t.Errorf("expected CandidateCount 2, got %d", geminiReq.GenerationConfig.CandidateCount)
+	}
+}
+
+func TestOpenAIToGeminiWithStopSequences(t *testing.T) {
+	openaiReq := &openai.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []openai.Message{
+			{Role: "user", Content: "Hello"},
+		},
+		Stop: []string{"\n", "END"},
+	}
+
+	geminiReq := OpenAIToGemini(openaiReq, "gemini-pro")
+
+	if len(geminiReq.GenerationConfig.StopSequences) != 2 {
+		t.Errorf("expected 2 stop sequences, got %d", len(geminiReq.GenerationConfig.StopSequences))
+	}
+}
+
+func TestGeminiToOpenAI(t *testing.T) {
+	geminiResp := &GenerateContentResponse{
+		Candidates: []Candidate{
+			{
+				Content: Content{
+					Role:  "model",
+					Parts: []Part{{Text: "Hello there!"}},
+				},
+				FinishReason: "STOP",
+				Index:        0,
+			},
+		},
+		UsageMetadata: UsageMetadata{
+			PromptTokenCount:     5,
+			CandidatesTokenCount: 3,
+			TotalTokenCount:      8,
+		},
+	}
+
+	openaiResp := GeminiToOpenAI(geminiResp, "gemini-pro")
+
+	if len(openaiResp.Choices) != 1 {
+		t.Errorf("expected 1 choice, got %d", len(openaiResp.Choices))
+	}
+	if openaiResp.Choices[0].Message.Content != "Hello there!" {
+		t.Errorf("expected content 'Hello there!', got '%s'", openaiResp.Choices[0].Message.Content)
+	}
+	if openaiResp.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected finish_reason 'stop', got '%s'", openaiResp.Choices[0].FinishReason)
+	}
+	if openaiResp.Usage.TotalTokens != 8 {
+		t.Errorf("expected total tokens 8, got %d", openaiResp.Usage.TotalTokens)
+	}
+}
+
+func TestGeminiToOpenAIInlineImage(t *testing.T) {
+	parts := []Part{
+		{Text: "A cat:"},
+		{InlineData: &InlineData{MIMEType: "image/png", Data: "iVBORw0KGgo="}},
+	}
+	geminiResp := &GenerateContentResponse{
+		Candidates: []Candidate{{Content: Content{Role: "model", Parts: parts}, FinishReason: "STOP"}},
+	}
+
+	message := GeminiToOpenAI(geminiResp, "gemini-2.0-flash").Choices[0].Message
+	if message.Content != "A cat:" {
+		t.Errorf("expected content 'A cat:', got '%s'", message.Content)
+	}
+	if images := message.Images(); len(images) != 1 || images[0] != "data:image/png;base64,iVBORw0KGgo=" {
+		t.Errorf("expected the inline image as a data URL, got %v", images)
+	}
+
+	chunk := GeminiToOpenAIChunk(geminiResp, "chunk-1", "gemini-2.0-flash", 0)
+	if delta := chunk.Choices[0].Delta; len(delta.Parts) != 2 || delta.Parts[1].Type != openai.ContentPartImageURL {
+		t.Errorf("expected the image in the stream delta, got %+v", delta)
+	}
+}
+
+func TestOpenAIToGeminiImages(t *testing.T) {
+	msg := openai.Message{Role: "user", Content: "What is this?"}
+	msg.AppendImage("data:image/jpeg;base64,/9j/4AAQ")
+	req := &openai.ChatCompletionRequest{Model: "gpt-4o", Messages: []openai.Message{msg}}
+
+	parts := OpenAIToGemini(req, "gemini-2.0-flash").Contents[0].Parts
+	if len(parts) != 2 || parts[0].Text != "What is this?" {
+		t.Fatalf("expected the text and the image, got %+v", parts)
+	}
+	if data := parts[1].InlineData; data == nil || data.MIMEType != "image/jpeg" || data.Data != "/9j/4AAQ" {
+		t.Errorf("expected the image as inline data, got %+v", data)
+	}
+}
+
+func TestEmbeddingsOpenAIToGemini(t *testing.T) {
+	openaiReq := &openai.EmbeddingRequest{
+		Input: "Hello world",
+		Model: "text-embedding-004",
+	}
+
+	geminiReq := EmbeddingsOpenAIToGemini(openaiReq)
+
+	if geminiReq.Content.Parts[0].Text != "Hello world" {
+		t.Errorf("expected text 'Hello world', got '%s'", geminiReq.Content.Parts[0].Text)
+	}
+}
+
+func TestEmbeddingsGeminiToOpenAI(t *testing.T) {
+	geminiResp := &EmbedContentResponse{
+		Embedding: EmbeddingValue{
+			Values: []float32{0.1, 0.2, 0.3},
+		},
+	}
+
+	openaiResp := EmbeddingsGeminiToOpenAI(geminiResp, "text-embedding-004")
+
+	if len(openaiResp.Data) != 1 {
+		t.Errorf("expected 1 embedding, got %d", len(openaiResp.Data))
+	}
+	if len(openaiResp.Data[0].Embedding) != 3 {
+		t.Errorf("expected embedding length 3, got %d", len(openaiResp.Data[0].Embedding))
+	}
+}
@@ -9,18 +9,24 @@ import (
 	"net/http"
 	"time"
 
-	gemini2 "github.com/deeplooplabs/ai-gateway/provider/gemini"
+	"github.com/deeplooplabs/ai-gateway/provider/gemini/wire"
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
 )
 
-// GeminiHTTPProvider sends requests to Gemini via HTTP
+// GeminiHTTPProvider sends chat requests to Gemini's native generateContent
+// API via HTTP.
+//
+// Deprecated: use gemini.NewProvider, which implements Provider on the
+// native Gemini API with streaming and embeddings.
 type GeminiHTTPProvider struct {
 	BaseURL string
 	APIKey  string
 	Client  *http.Client
 }
 
-// NewGeminiHTTPProvider creates a new Gemini HTTP provider
+// NewGeminiHTTPProvider creates a new Gemini HTTP provider.
+//
+// Deprecated: use gemini.NewProviderWithAPIKey.
 func NewGeminiHTTPProvider(apiKey string) *GeminiHTTPProvider {
 	return &GeminiHTTPProvider{
 		BaseURL: "https://generativelanguage.googleapis.com/v1beta",
@@ -36,68 +42,52 @@ func (p *GeminiHTTPProvider) Name() string {
 	return "gemini-http"
 }
 
-// SendRequest sends a non-streaming request via HTTP
+// SendRequest sends a non-streaming chat request via HTTP
 func (p *GeminiHTTPProvider) SendRequest(ctx context.Context, endpoint string, req *openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
-	// Handle unsupported endpoints
-	if endpoint == "/v1/images/generations" {
+	switch endpoint {
+	case "/v1/images/generations":
 		return nil, fmt.Errorf("image generation not supported for Gemini provider")
+	case "/v1/embeddings":
+		return nil, fmt.Errorf("embeddings are not supported by GeminiHTTPProvider, use gemini.NewProvider")
 	}
 
-	// Handle embeddings endpoint
-	if endpoint == "/v1/embeddings" {
-		return p.sendEmbeddingsRequest(ctx, req)
-	}
-
-	// Handle chat completions
-	return p.sendChatRequest(ctx, req)
-}
-
-func (p *GeminiHTTPProvider) sendChatRequest(ctx context.Context, req *openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
-	// Convert OpenAI request to Gemini format
 	model := req.Model
 	if model == "" {
 		model = "gemini-pro"
 	}
-	geminiReq := gemini2.OpenAIToGemini(req, model)
-
-	body, err := json.Marshal(geminiReq)
+	body, err := json.Marshal(wire.OpenAIToGemini(req, model))
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	// Build URL
 	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", p.BaseURL, model, p.APIKey)
-
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := p.Client.Do(httpReq)
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+		return nil, TransportError(err)
 	}
 	defer resp.Body.Close()
 
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, TransportError(err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		return nil, &Error{Kind: statusErrorKind(resp.StatusCode), StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
-	var geminiResp gemini2.GenerateContentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	var geminiResp wire.GenerateContentResponse
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
+		return nil, DecodeError(err)
 	}
-
-	// Convert back to OpenAI format
-	return gemini2.GeminiToOpenAI(&geminiResp, model), nil
-}
-
-func (p *GeminiHTTPProvider) sendEmbeddingsRequest(ctx context.Context, req *openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
-	// Parse as embedding request from the original request
-	// This is a workaround - ideally we'd have separate request types
-	// For now, return an error indicating this needs proper request handling
-	return nil, fmt.Errorf("embeddings endpoint requires separate EmbeddingRequest type")
+	return wire.GeminiToOpenAI(&geminiResp, model), nil
 }
@@ -2,8 +2,12 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/deeplooplabs/ai-gateway/provider/gemini/wire"
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
 )
 
@@ -43,3 +47,38 @@ func TestGeminiHTTPProvider_SendRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestGeminiHTTPProvider_SendChatRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-pro:generateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("key"); got != "test-key" {
+			t.Errorf("expected the API key in the query, got %q", got)
+		}
+		var req wire.GenerateContentRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Contents) != 1 || req.Contents[0].Parts[0].Text != "Hello" {
+			t.Errorf("expected a native Gemini body, got %+v", req)
+		}
+		json.NewEncoder(w).Encode(wire.GenerateContentResponse{
+			Candidates: []wire.Candidate{{Content: wire.Content{Role: "model", Parts: []wire.Part{{Text: "Hi"}}}, FinishReason: "STOP"}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewGeminiHTTPProvider("test-key")
+	p.BaseURL = server.URL + "/v1beta"
+	req := &openai.ChatCompletionRequest{Messages: []openai.Message{{Role: "user", Content: "Hello"}}}
+
+	resp, err := p.SendRequest(context.Background(), "/v1/chat/completions", req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "Hi" || resp.Model != "gemini-pro" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if req.Model != "" {
+		t.Errorf("expected the caller's request unchanged, got model %q", req.Model)
+	}
+}
@@ -1,9 +1,13 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
@@ -68,3 +72,371 @@ data: [DONE]
 		// No error
 	}
 }
+
+func TestHTTPProvider_SendRequestModeration(t *testing.T) {
+	var gotPath string
+	var gotBody openai2.ModerationRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,"categories":{"violence":true},"category_scores":{"violence":0.9}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProviderWithBaseURL(server.URL, "test-key")
+
+	resp, err := provider.SendRequest(context.Background(), NewModerationsRequest("omni-moderation-latest", "some text"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotPath != "/v1/moderations" {
+		t.Errorf("expected path /v1/moderations, got %s", gotPath)
+	}
+	if gotBody.Input != "some text" {
+		t.Errorf("expected input to be forwarded, got %v", gotBody.Input)
+	}
+
+	moderation, err := resp.GetModeration()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(moderation.Results) != 1 || !moderation.Results[0].Flagged {
+		t.Errorf("expected one flagged result, got %+v", moderation.Results)
+	}
+}
+
+func TestHTTPProvider_SendRequestModerationUnsupported(t *testing.T) {
+	provider := NewHTTPProviderChatOnly("http://127.0.0.1:0", "test-key")
+
+	_, err := provider.SendRequest(context.Background(), NewModerationsRequest("omni-moderation-latest", "some text"))
+	if err == nil {
+		t.Fatal("expected error for unsupported moderation request")
+	}
+}
+
+func TestHTTPProvider_CustomEndpoint(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBody openai2.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	config := NewProviderConfig("azure").
+		WithBaseURL(server.URL).
+		WithEndpoint(APITypeChatCompletions, "", "/openai/deployments/{model}/chat/completions")
+	provider := NewHTTPProvider(config)
+
+	req := NewChatCompletionsRequest("gpt-4o", []openai2.Message{{Role: "user", Content: "test"}})
+	if _, err := provider.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotMethod != http.MethodPost {
+		t.Errorf("expected default method POST, got %s", gotMethod)
+	}
+	if gotPath != "/openai/deployments/gpt-4o/chat/completions" {
+		t.Errorf("unexpected path %s", gotPath)
+	}
+	if len(gotBody.Messages) != 1 {
+		t.Errorf("expected request body to be sent, got %+v", gotBody)
+	}
+}
+
+func TestHTTPProvider_CustomEndpointMethod(t *testing.T) {
+	var gotMethod, gotPath string
+	var gotBodyLen int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotBodyLen = r.ContentLength
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"modr-1","results":[{"flagged":false}]}`))
+	}))
+	defer server.Close()
+
+	config := NewProviderConfig("custom").
+		WithBaseURL(server.URL).
+		WithAPIType(APITypeModerations).
+		WithEndpoint(APITypeModerations, http.MethodGet, "/moderate")
+	provider := NewHTTPProvider(config)
+
+	if _, err := provider.SendRequest(context.Background(), NewModerationsRequest("m", "text")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotMethod != http.MethodGet || gotPath != "/moderate" {
+		t.Errorf("expected GET /moderate, got %s %s", gotMethod, gotPath)
+	}
+	if gotBodyLen != 0 {
+		t.Errorf("expected GET request without body, got %d bytes", gotBodyLen)
+	}
+}
+
+func TestHTTPProvider_EndpointPerAPIType(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "embeddings"):
+			w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]}]}`))
+		case strings.Contains(r.URL.Path, "moderations"):
+			w.Write([]byte(`{"id":"modr-1","results":[{"flagged":false}]}`))
+		default:
+			w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	// Chat and embeddings live under different paths; moderations keeps the
+	// default endpoint with the base path stripped
+	config := NewProviderConfig("custom").
+		WithBaseURL(server.URL+"/api").
+		WithBasePath("/v1").
+		WithAPIType(APITypeAll).
+		WithEndpoint(APITypeChatCompletions, "", "/v1/chat/completions").
+		WithEndpoint(APITypeEmbeddings, "", "/embeddings/v1")
+	provider := NewHTTPProvider(config)
+
+	requests := []*Request{
+		NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "test"}}),
+		NewEmbeddingsRequest("text-embedding-3-small", "test"),
+		NewModerationsRequest("omni-moderation-latest", "test"),
+	}
+	for _, req := range requests {
+		if _, err := provider.SendRequest(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := []string{"/api/v1/chat/completions", "/api/embeddings/v1", "/api/moderations"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("expected paths %v, got %v", want, paths)
+	}
+}
+
+func TestHTTPProvider_SendRequestTranscription(t *testing.T) {
+	var gotModel, gotFilename string
+	var gotFile []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("expected multipart body: %v", err)
+			return
+		}
+		gotModel = r.FormValue("model")
+		file, header, err := r.FormFile("file")
+		if err == nil {
+			gotFilename = header.Filename
+			gotFile, _ = io.ReadAll(file)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"hello world"}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProviderWithBaseURL(server.URL, "test-key")
+
+	req := NewAudioTranscriptionRequest("whisper-1", "speech.wav", []byte("RIFF....WAVE"))
+	resp, err := provider.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotModel != "whisper-1" || gotFilename != "speech.wav" || string(gotFile) != "RIFF....WAVE" {
+		t.Errorf("unexpected multipart request: model=%s filename=%s file=%q", gotModel, gotFilename, gotFile)
+	}
+
+	transcription, err := resp.GetTranscription()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transcription.Text != "hello world" {
+		t.Errorf("expected transcription text, got %q", transcription.Text)
+	}
+}
+
+func TestHTTPProvider_SendRequestSpeech(t *testing.T) {
+	audio := []byte{0xff, 0xfb, 0x90, 0x00, 0x00, 0x01, 0x02}
+	var gotReq map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&gotReq)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write(audio)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProviderWithBaseURL(server.URL, "test-key")
+
+	req := NewAudioSpeechRequest("tts-1", "Hello there", "alloy")
+	req.SpeechResponseFormat = "mp3"
+	resp, err := provider.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Close()
+
+	if gotReq["model"] != "tts-1" || gotReq["input"] != "Hello there" || gotReq["voice"] != "alloy" || gotReq["response_format"] != "mp3" {
+		t.Errorf("unexpected upstream request: %v", gotReq)
+	}
+
+	speech, err := resp.GetAudio()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if speech.ContentType != "audio/mpeg" || speech.ContentLength != int64(len(audio)) {
+		t.Errorf("unexpected content type/length: %s/%d", speech.ContentType, speech.ContentLength)
+	}
+	body, _ := io.ReadAll(speech.Body)
+	if !bytes.Equal(body, audio) {
+		t.Errorf("expected audio to pass through unchanged, got %x", body)
+	}
+}
+
+func TestSSEDecoder_Next(t *testing.T) {
+	stream := ": keep-alive\n" +
+		"event: message\n" +
+		"data: {\"a\":\n" +
+		"data: 1}\n" +
+		"\n" +
+		": keep-alive\n" +
+		"\n" +
+		"event: ping\n" +
+		"\n" +
+		"data:{\"b\":2}\r\n" +
+		"\r\n" +
+		"data: [DONE]"
+
+	decoder := NewSSEDecoder(strings.NewReader(stream))
+	var events []SSEEvent
+	for {
+		event, err := decoder.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		events = append(events, *event)
+	}
+
+	want := []SSEEvent{
+		{Event: "message", Data: "{\"a\":\n1}"},
+		{Data: "{\"b\":2}"},
+		{Data: "[DONE]"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: expected %+v, got %+v", i, want[i], events[i])
+		}
+	}
+}
+
+func TestHTTPProvider_SendRequestStreamMultiLineData(t *testing.T) {
+	sseResponse := ": keep-alive\n\n" +
+		"data: {\"id\":\"chatcmpl-123\",\"object\":\"chat.completion.chunk\",\n" +
+		"data:  \"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hello\"}}]}\n\n" +
+		": keep-alive\n\n" +
+		"data: [DONE]\n\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(sseResponse))
+	}))
+	defer server.Close()
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "test"}})
+	req.Stream = true
+	resp, err := NewHTTPProviderWithBaseURL(server.URL, "test-key").SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Close()
+
+	var chunks []*Chunk
+	for chunk := range resp.Chunks {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 2 || !chunks[1].Done {
+		t.Fatalf("expected one data chunk and done, got %d chunks", len(chunks))
+	}
+	var parsed openai2.ChatCompletionStreamResponse
+	if err := json.Unmarshal(chunks[0].OpenAI.Data, &parsed); err != nil {
+		t.Fatalf("expected the joined data to be valid JSON: %v", err)
+	}
+	if parsed.Choices[0].Delta.Content != "Hello" {
+		t.Errorf("unexpected chunk: %+v", parsed)
+	}
+}
+
+func TestHTTPProvider_SendRequestEmbeddingsIgnoresStream(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1,0.2]}],"model":"text-embedding-3-small"}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProviderWithBaseURL(server.URL, "test-key")
+
+	req := NewEmbeddingsRequest("text-embedding-3-small", "test")
+	req.Stream = true
+
+	resp, err := provider.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Stream || resp.Chunks != nil {
+		t.Error("expected a non-streaming response")
+	}
+	if resp.Embedding == nil || len(resp.Embedding.Data) != 1 {
+		t.Fatalf("expected the embedding response, got %+v", resp.Embedding)
+	}
+	if _, ok := gotBody["stream"]; ok {
+		t.Errorf("expected no stream field upstream, got %v", gotBody)
+	}
+}
+
+// idleTransport records CloseIdleConnections calls
+type idleTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *idleTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestHTTPProvider_CloseLeavesCallerClient(t *testing.T) {
+	transport := &idleTransport{RoundTripper: http.DefaultTransport}
+	prov := NewHTTPProvider(NewProviderConfig("test").WithHTTPClient(&http.Client{Transport: transport}))
+	if err := prov.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if transport.closed != 0 {
+		t.Errorf("expected a caller's client left alone, closed %d times", transport.closed)
+	}
+
+	prov = NewHTTPProvider(NewProviderConfig("test"))
+	transport = &idleTransport{RoundTripper: http.DefaultTransport}
+	prov.client.Transport = transport
+	if err := prov.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if transport.closed != 1 {
+		t.Errorf("expected the provider's own idle connections closed, closed %d times", transport.closed)
+	}
+}
@@ -0,0 +1,324 @@
+// Package mock provides a provider that simulates upstream latency and failures
+// without making network calls. It is intended for benchmarking the gateway's
+// streaming and load-balancing behavior.
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+const (
+	// DefaultTTFT is the default time to first token
+	DefaultTTFT = 200 * time.Millisecond
+	// DefaultTokenRate is the default number of tokens generated per second
+	DefaultTokenRate = 50.0
+	// DefaultTimeout is how long a simulated timeout stalls before failing
+	DefaultTimeout = 30 * time.Second
+	// DefaultResponseText is the text returned when no response text is configured
+	DefaultResponseText = "This is a simulated response from the mock provider."
+)
+
+var (
+	// ErrSimulated is returned when a request fails due to the configured error rate
+	ErrSimulated = errors.New("mock: simulated upstream error")
+	// ErrSimulatedTimeout is returned when a request fails due to the configured timeout rate
+	ErrSimulatedTimeout = errors.New("mock: simulated upstream timeout")
+)
+
+// Provider simulates an OpenAI-compatible upstream with configurable
+// time to first token, token rate, jitter, errors and timeouts
+type Provider struct {
+	*provider.BaseProvider
+
+	ttft        time.Duration
+	tokenRate   float64
+	jitter      float64
+	errorRate   float64
+	timeoutRate float64
+	timeout     time.Duration
+	text        string
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithName sets the provider name (default: "mock")
+func WithName(name string) Option {
+	return func(p *Provider) {
+		p.Config().Name = name
+	}
+}
+
+// WithTTFT sets the time to first token
+func WithTTFT(d time.Duration) Option {
+	return func(p *Provider) {
+		p.ttft = d
+	}
+}
+
+// WithTokenRate sets the number of tokens generated per second.
+// A rate of zero or less streams all tokens without delay.
+func WithTokenRate(tokensPerSecond float64) Option {
+	return func(p *Provider) {
+		p.tokenRate = tokensPerSecond
+	}
+}
+
+// WithJitter sets the relative jitter applied to every delay.
+// A jitter of 0.2 draws each delay uniformly from [0.8d, 1.2d].
+func WithJitter(fraction float64) Option {
+	return func(p *Provider) {
+		p.jitter = fraction
+	}
+}
+
+// WithErrorRate sets the probability (0..1) that a request fails with ErrSimulated
+func WithErrorRate(rate float64) Option {
+	return func(p *Provider) {
+		p.errorRate = rate
+	}
+}
+
+// WithTimeoutRate sets the probability (0..1) that a request stalls for the
+// timeout duration and then fails with ErrSimulatedTimeout
+func WithTimeoutRate(rate float64) Option {
+	return func(p *Provider) {
+		p.timeoutRate = rate
+	}
+}
+
+// WithTimeout sets how long a simulated timeout stalls
+func WithTimeout(d time.Duration) Option {
+	return func(p *Provider) {
+		p.timeout = d
+	}
+}
+
+// WithResponseText sets the text returned by every completion
+func WithResponseText(text string) Option {
+	return func(p *Provider) {
+		p.text = text
+	}
+}
+
+// WithSeed makes jitter and failure sampling reproducible
+func WithSeed(seed uint64) Option {
+	return func(p *Provider) {
+		p.rng = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// NewProvider creates a new mock provider
+func NewProvider(opts ...Option) *Provider {
+	config := provider.NewProviderConfig("mock")
+	config.SupportedAPIs = provider.APITypeChatCompletions
+
+	p := &Provider{
+		BaseProvider: provider.NewBaseProvider(config),
+		ttft:         DefaultTTFT,
+		tokenRate:    DefaultTokenRate,
+		timeout:      DefaultTimeout,
+		text:         DefaultResponseText,
+		rng:          rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// SendRequest implements provider.Provider.SendRequest
+func (p *Provider) SendRequest(ctx context.Context, req *provider.Request) (*provider.Response, error) {
+	// Responses requests are served through Chat Completions
+	if req.APIType == provider.APITypeResponses {
+		if err := p.ConvertRequestIfNeeded(req); err != nil {
+			return nil, fmt.Errorf("convert request: %w", err)
+		}
+	}
+	if req.APIType != provider.APITypeChatCompletions {
+		return nil, fmt.Errorf("API type %v not supported by provider %s", req.APIType, p.Name())
+	}
+
+	if err := p.simulateFailure(ctx); err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("chatcmpl-mock-%d", time.Now().UnixNano())
+	tokens := tokenize(p.text)
+	usage := openai.Usage{
+		PromptTokens:     openai.EstimatePromptTokens(req.Messages),
+		CompletionTokens: len(tokens),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	if req.Stream {
+		includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+		return p.stream(ctx, id, req.Model, tokens, usage, includeUsage), nil
+	}
+
+	// Non-streaming requests wait for the whole generation
+	if err := sleep(ctx, p.delay(p.ttft)); err != nil {
+		return nil, err
+	}
+	for range len(tokens) - 1 {
+		if err := sleep(ctx, p.tokenDelay()); err != nil {
+			return nil, err
+		}
+	}
+
+	return provider.NewChatCompletionResponse(&openai.ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []openai.Choice{{
+			Index:        0,
+			Message:      openai.Message{Role: "assistant", Content: p.text},
+			FinishReason: "stop",
+		}},
+		Usage: usage,
+	}), nil
+}
+
+// stream emits one chunk per token, honoring TTFT and the token rate
+func (p *Provider) stream(ctx context.Context, id, model string, tokens []string, usage openai.Usage, includeUsage bool) *provider.Response {
+	chunks := make(chan *provider.Chunk, 1)
+	errs := make(chan error, 1)
+	created := time.Now().Unix()
+
+	send := func(chunk *openai.ChatCompletionStreamResponse) bool {
+		chunk.ID = id
+		chunk.Object = "chat.completion.chunk"
+		chunk.Created = created
+		chunk.Model = model
+		data, _ := json.Marshal(chunk)
+		select {
+		case chunks <- provider.NewOpenAIChunk(data):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(chunks)
+		defer close(errs)
+
+		if err := sleep(ctx, p.delay(p.ttft)); err != nil {
+			errs <- err
+			return
+		}
+		for i, token := range tokens {
+			if i > 0 {
+				if err := sleep(ctx, p.tokenDelay()); err != nil {
+					errs <- err
+					return
+				}
+			}
+			delta := &openai.Delta{Content: token}
+			if i == 0 {
+				delta.Role = "assistant"
+			}
+			if !send(&openai.ChatCompletionStreamResponse{Choices: []openai.Choice{{Index: 0, Delta: delta}}}) {
+				return
+			}
+		}
+
+		final := &openai.ChatCompletionStreamResponse{
+			Choices: []openai.Choice{{Index: 0, Delta: &openai.Delta{}, FinishReason: "stop"}},
+		}
+		if includeUsage {
+			final.Usage = &usage
+		}
+		if !send(final) {
+			return
+		}
+
+		select {
+		case chunks <- provider.NewOpenAIChunkDone():
+		case <-ctx.Done():
+		}
+	}()
+
+	return provider.NewStreamingResponse(provider.APITypeChatCompletions, chunks, errs, func() error { return nil })
+}
+
+// simulateFailure fails the request according to the configured error and timeout rates
+func (p *Provider) simulateFailure(ctx context.Context) error {
+	p.mu.Lock()
+	roll := p.rng.Float64()
+	p.mu.Unlock()
+
+	switch {
+	case roll < p.errorRate:
+		if err := sleep(ctx, p.delay(p.ttft)); err != nil {
+			return err
+		}
+		return ErrSimulated
+	case roll < p.errorRate+p.timeoutRate:
+		if err := sleep(ctx, p.timeout); err != nil {
+			return err
+		}
+		return ErrSimulatedTimeout
+	}
+	return nil
+}
+
+// tokenDelay returns the delay between two consecutive tokens
+func (p *Provider) tokenDelay() time.Duration {
+	if p.tokenRate <= 0 {
+		return 0
+	}
+	return p.delay(time.Duration(float64(time.Second) / p.tokenRate))
+}
+
+// delay applies jitter to d
+func (p *Provider) delay(d time.Duration) time.Duration {
+	if p.jitter <= 0 || d <= 0 {
+		return d
+	}
+	p.mu.Lock()
+	factor := 1 + p.jitter*(2*p.rng.Float64()-1)
+	p.mu.Unlock()
+	return time.Duration(float64(d) * max(factor, 0))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tokenize splits text into word tokens, keeping the leading spaces on each word
+func tokenize(text string) []string {
+	var tokens []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i] == ' ' && text[i-1] != ' ' {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+	}
+	return append(tokens, text[start:])
+}
@@ -0,0 +1,144 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deeplooplabs/ai-gateway/provider"
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func chatRequest(stream bool) *provider.Request {
+	return &provider.Request{
+		APIType:  provider.APITypeChatCompletions,
+		Model:    "mock-model",
+		Stream:   stream,
+		Messages: []openai.Message{{Role: "user", Content: "Hello"}},
+	}
+}
+
+// approx fails the test if got is not within tolerance of want
+func approx(t *testing.T, name string, got, want, tolerance time.Duration) {
+	t.Helper()
+	if got < want || got > want+tolerance {
+		t.Errorf("%s: expected ~%v, got %v", name, want, got)
+	}
+}
+
+func TestProvider_StreamLatency(t *testing.T) {
+	p := NewProvider(
+		WithTTFT(50*time.Millisecond),
+		WithTokenRate(100), // 10ms per token
+		WithResponseText("one two three four five six"),
+	)
+
+	start := time.Now()
+	resp, err := p.SendRequest(context.Background(), chatRequest(true))
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	defer resp.Close()
+
+	var firstToken time.Duration
+	var content strings.Builder
+	for chunk := range resp.Chunks {
+		if chunk.Done {
+			break
+		}
+		var streamResp openai.ChatCompletionStreamResponse
+		if err := json.Unmarshal(chunk.OpenAI.Data, &streamResp); err != nil {
+			t.Fatalf("Invalid chunk: %v", err)
+		}
+		token := streamResp.Choices[0].Delta.Content
+		if token != "" && firstToken == 0 {
+			firstToken = time.Since(start)
+		}
+		content.WriteString(token)
+	}
+	total := time.Since(start)
+
+	if content.String() != "one two three four five six" {
+		t.Errorf("Unexpected content %q", content.String())
+	}
+	approx(t, "time to first token", firstToken, 50*time.Millisecond, 40*time.Millisecond)
+	approx(t, "total time", total, 100*time.Millisecond, 60*time.Millisecond)
+}
+
+func TestProvider_NonStreamingLatency(t *testing.T) {
+	p := NewProvider(
+		WithTTFT(30*time.Millisecond),
+		WithTokenRate(200), // 5ms per token
+		WithResponseText("a b c d e"),
+	)
+
+	start := time.Now()
+	resp, err := p.SendRequest(context.Background(), chatRequest(false))
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	approx(t, "total time", time.Since(start), 50*time.Millisecond, 40*time.Millisecond)
+
+	if resp.ChatCompletion.Usage.CompletionTokens != 5 {
+		t.Errorf("Expected 5 completion tokens, got %d", resp.ChatCompletion.Usage.CompletionTokens)
+	}
+}
+
+func TestProvider_ErrorRate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+		min  int
+		max  int
+	}{
+		{"never", 0, 0, 0},
+		{"always", 1, 200, 200},
+		{"sometimes", 0.25, 30, 70},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(WithTTFT(0), WithTokenRate(0), WithErrorRate(tt.rate), WithSeed(42))
+			failures := 0
+			for range 200 {
+				_, err := p.SendRequest(context.Background(), chatRequest(false))
+				if errors.Is(err, ErrSimulated) {
+					failures++
+				} else if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			if failures < tt.min || failures > tt.max {
+				t.Errorf("Expected %d-%d failures, got %d", tt.min, tt.max, failures)
+			}
+		})
+	}
+}
+
+func TestProvider_Timeout(t *testing.T) {
+	p := NewProvider(WithTimeoutRate(1), WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	_, err := p.SendRequest(context.Background(), chatRequest(true))
+	if !errors.Is(err, ErrSimulatedTimeout) {
+		t.Fatalf("Expected ErrSimulatedTimeout, got %v", err)
+	}
+	approx(t, "timeout", time.Since(start), 20*time.Millisecond, 40*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	p = NewProvider(WithTimeoutRate(1))
+	if _, err := p.SendRequest(ctx, chatRequest(false)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline exceeded, got %v", err)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("Hello  big world")
+	want := []string{"Hello", "  big", " world"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
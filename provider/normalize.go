@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"slices"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// MergeConsecutiveRoles merges consecutive messages of the same role, for
+// upstreams that require user and assistant turns to alternate. Contents are
+// joined with a blank line and tool calls are concatenated. Tool messages are
+// kept apart since each answers its own call. messages is not modified.
+func MergeConsecutiveRoles(messages []openai.Message) []openai.Message {
+	merged := make([]openai.Message, 0, len(messages))
+	for _, msg := range messages {
+		n := len(merged)
+		if n == 0 || msg.Role == "tool" || merged[n-1].Role != msg.Role {
+			merged = append(merged, msg)
+			continue
+		}
+
+		prev := &merged[n-1]
+		switch {
+		case prev.Content == "":
+			prev.Content = msg.Content
+		case msg.Content != "":
+			prev.Content += "\n\n" + msg.Content
+		}
+		if len(msg.ToolCalls) > 0 {
+			prev.ToolCalls = slices.Concat(prev.ToolCalls, msg.ToolCalls)
+		}
+		// A cache hint marks the end of the prefix, which is now the merged message
+		if msg.CacheControl != nil {
+			prev.CacheControl = msg.CacheControl
+		}
+	}
+	return merged
+}
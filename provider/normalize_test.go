@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestMergeConsecutiveRoles(t *testing.T) {
+	hint := &openai2.CacheControl{Type: openai2.CacheControlEphemeral}
+	messages := []openai2.Message{
+		{Role: "system", Content: "Be brief"},
+		{Role: "user", Content: "Hello"},
+		{Role: "user", Content: "Are you there?", CacheControl: hint},
+		{Role: "assistant", ToolCalls: []openai2.ToolCall{{ID: "call_1"}}},
+		{Role: "assistant", ToolCalls: []openai2.ToolCall{{ID: "call_2"}}},
+		{Role: "tool", ToolCallID: "call_1", Content: "sunny"},
+		{Role: "tool", ToolCallID: "call_2", Content: "rainy"},
+	}
+
+	merged := MergeConsecutiveRoles(messages)
+
+	if len(merged) != 5 {
+		t.Fatalf("expected 5 messages, got %d: %+v", len(merged), merged)
+	}
+	if merged[1].Content != "Hello\n\nAre you there?" || merged[1].CacheControl != hint {
+		t.Errorf("expected user messages to be merged, got %+v", merged[1])
+	}
+	if len(merged[2].ToolCalls) != 2 || merged[2].ToolCalls[1].ID != "call_2" || merged[2].Content != "" {
+		t.Errorf("expected assistant tool calls to be concatenated, got %+v", merged[2])
+	}
+	if merged[3].ToolCallID != "call_1" || merged[4].ToolCallID != "call_2" {
+		t.Errorf("expected tool results to stay apart, got %+v", merged[3:])
+	}
+	if messages[1].Content != "Hello" || len(messages[3].ToolCalls) != 1 {
+		t.Error("expected input messages not to be modified")
+	}
+}
+
+func TestHTTPProvider_AlternatingRoles(t *testing.T) {
+	var got openai2.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(NewProviderConfig("strict").WithBaseURL(server.URL).WithAlternatingRoles())
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "user", Content: "Are you there?"},
+	})
+	if _, err := provider.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got.Messages) != 1 || got.Messages[0].Content != "Hello\n\nAre you there?" {
+		t.Errorf("expected consecutive user messages to be merged, got %+v", got.Messages)
+	}
+}
@@ -0,0 +1,279 @@
+package openai
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// StreamAccumulator assembles streaming chunks into a complete response
+type StreamAccumulator struct {
+	id          string
+	model       string
+	created     int64
+	choices     map[int]*accumulatedChoice
+	usage       *Usage
+	serviceTier string
+	fingerprint string
+}
+
+// accumulatedChoice holds the assembled state of a single choice
+type accumulatedChoice struct {
+	role         string
+	content      strings.Builder
+	refusal      strings.Builder
+	toolCalls    map[int]*ToolCall
+	finishReason string
+
+	// images are the streamed non-text parts, at their offset in content
+	images []offsetPart
+
+	// logprobs are the streamed content token log probabilities, if any
+	logprobs []json.RawMessage
+}
+
+// offsetPart is a non-text content part streamed after offset bytes of text
+type offsetPart struct {
+	offset int
+	part   ContentPart
+}
+
+// NewStreamAccumulator creates a new stream accumulator
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{
+		choices: make(map[int]*accumulatedChoice),
+	}
+}
+
+// Add adds a streaming chunk. Chunks without choices (e.g. the final usage
+// chunk) only contribute their usage.
+func (a *StreamAccumulator) Add(chunk *ChatCompletionStreamResponse) {
+	if chunk == nil {
+		return
+	}
+	if a.id == "" {
+		a.id = chunk.ID
+	}
+	if a.model == "" {
+		a.model = chunk.Model
+	}
+	if a.created == 0 {
+		a.created = chunk.Created
+	}
+	if chunk.Usage != nil {
+		usage := *chunk.Usage
+		a.usage = &usage
+	}
+	if chunk.ServiceTier != "" {
+		a.serviceTier = chunk.ServiceTier
+	}
+	if chunk.SystemFingerprint != "" {
+		a.fingerprint = chunk.SystemFingerprint
+	}
+
+	for _, c := range chunk.Choices {
+		choice, ok := a.choices[c.Index]
+		if !ok {
+			choice = &accumulatedChoice{toolCalls: make(map[int]*ToolCall)}
+			a.choices[c.Index] = choice
+		}
+		if c.FinishReason != "" {
+			choice.finishReason = c.FinishReason
+		}
+		choice.addLogprobs(c.Logprobs)
+		if c.Delta == nil {
+			continue
+		}
+		if c.Delta.Role != "" {
+			choice.role = c.Delta.Role
+		}
+		choice.addContent(c.Delta)
+		choice.refusal.WriteString(c.Delta.Refusal)
+
+		for i, tc := range c.Delta.ToolCalls {
+			// Deltas without an index belong to the call at their position
+			index := i
+			if tc.Index != nil {
+				index = *tc.Index
+			}
+			call, ok := choice.toolCalls[index]
+			if !ok {
+				call = &ToolCall{Type: "function"}
+				choice.toolCalls[index] = call
+			}
+			if tc.ID != "" {
+				call.ID = tc.ID
+			}
+			if tc.Type != "" {
+				call.Type = tc.Type
+			}
+			call.Function.Name += tc.Function.Name
+			call.Function.Arguments += tc.Function.Arguments
+		}
+	}
+}
+
+// ID returns the ID of the accumulated stream
+func (a *StreamAccumulator) ID() string {
+	return a.id
+}
+
+// Model returns the model of the accumulated stream
+func (a *StreamAccumulator) Model() string {
+	return a.model
+}
+
+// Created returns the creation timestamp of the accumulated stream
+func (a *StreamAccumulator) Created() int64 {
+	return a.created
+}
+
+// Usage returns the usage reported by the stream, or nil if none was reported
+func (a *StreamAccumulator) Usage() *Usage {
+	return a.usage
+}
+
+// Content returns the accumulated content of all choices
+func (a *StreamAccumulator) Content() string {
+	var sb strings.Builder
+	for _, index := range a.indexes() {
+		sb.WriteString(a.choices[index].content.String())
+	}
+	return sb.String()
+}
+
+// Response returns the accumulated stream as a complete response
+func (a *StreamAccumulator) Response() *ChatCompletionResponse {
+	resp := &ChatCompletionResponse{
+		ID:      a.id,
+		Object:  "chat.completion",
+		Created: a.created,
+		Model:   a.model,
+		Choices: make([]Choice, 0, len(a.choices)),
+
+		ServiceTier:       a.serviceTier,
+		SystemFingerprint: a.fingerprint,
+	}
+	if a.usage != nil {
+		resp.Usage = *a.usage
+	}
+
+	for _, index := range a.indexes() {
+		choice := a.choices[index]
+		role := choice.role
+		if role == "" {
+			role = "assistant"
+		}
+
+		message := Message{Role: role, Content: choice.content.String(), Refusal: choice.refusal.String()}
+		if len(choice.images) > 0 {
+			message.Parts = choice.parts()
+		}
+		toolIndexes := make([]int, 0, len(choice.toolCalls))
+		for i := range choice.toolCalls {
+			toolIndexes = append(toolIndexes, i)
+		}
+		sort.Ints(toolIndexes)
+		for _, i := range toolIndexes {
+			call := *choice.toolCalls[i]
+			call.Index = nil
+			message.ToolCalls = append(message.ToolCalls, call)
+		}
+
+		resp.Choices = append(resp.Choices, Choice{
+			Index:        index,
+			Message:      message,
+			FinishReason: choice.finishReason,
+			Logprobs:     choice.logprobsJSON(),
+		})
+	}
+
+	return resp
+}
+
+// addLogprobs appends the content token log probabilities of a chunk
+func (c *accumulatedChoice) addLogprobs(logprobs json.RawMessage) {
+	if len(logprobs) == 0 {
+		return
+	}
+	var chunk struct {
+		Content []json.RawMessage `json:"content"`
+	}
+	if json.Unmarshal(logprobs, &chunk) == nil {
+		c.logprobs = append(c.logprobs, chunk.Content...)
+	}
+}
+
+// logprobsJSON returns the accumulated log probabilities, or nil if none were streamed
+func (c *accumulatedChoice) logprobsJSON() json.RawMessage {
+	if len(c.logprobs) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(map[string][]json.RawMessage{"content": c.logprobs})
+	return data
+}
+
+// addContent appends the content of delta, keeping where its non-text parts fall
+func (c *accumulatedChoice) addContent(delta *Delta) {
+	if len(delta.Parts) == 0 {
+		c.content.WriteString(delta.Content)
+		return
+	}
+	for _, part := range delta.Parts {
+		if part.Type == ContentPartText {
+			c.content.WriteString(part.Text)
+			continue
+		}
+		c.images = append(c.images, offsetPart{offset: c.content.Len(), part: part})
+	}
+}
+
+// parts returns the accumulated content as parts, with the text between
+// non-text parts as text parts
+func (c *accumulatedChoice) parts() []ContentPart {
+	content := c.content.String()
+	parts := make([]ContentPart, 0, 2*len(c.images)+1)
+	start := 0
+	for _, image := range c.images {
+		if image.offset > start {
+			parts = append(parts, TextPart(content[start:image.offset]))
+			start = image.offset
+		}
+		parts = append(parts, image.part)
+	}
+	if start < len(content) {
+		parts = append(parts, TextPart(content[start:]))
+	}
+	return parts
+}
+
+// indexes returns the choice indexes in order
+func (a *StreamAccumulator) indexes() []int {
+	indexes := make([]int, 0, len(a.choices))
+	for i := range a.choices {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// EstimateTokens returns a rough token count for text, for use when the
+// provider doesn't report usage (about four characters per token)
+func EstimateTokens(text string) int {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		return 0
+	}
+	return (n + 3) / 4
+}
+
+// EstimatePromptTokens returns a rough token count for a list of messages
+func EstimatePromptTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		// Per-message overhead for role and separators
+		total += 4 + EstimateTokens(msg.Content)
+	}
+	return total
+}
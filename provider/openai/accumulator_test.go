@@ -0,0 +1,167 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	chunks := []string{
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":123,"model":"gpt-4","choices":[{"index":0,"delta":{"role":"assistant"}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":123,"model":"gpt-4","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":123,"model":"gpt-4","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":"}}]}}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":123,"model":"gpt-4","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`,
+		`{"id":"chatcmpl-1","object":"chat.completion.chunk","created":123,"model":"gpt-4","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+	}
+
+	acc := NewStreamAccumulator()
+	for _, data := range chunks {
+		var chunk ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to unmarshal chunk: %v", err)
+		}
+		acc.Add(&chunk)
+	}
+
+	usage := acc.Usage()
+	if usage == nil || usage.TotalTokens != 15 {
+		t.Fatalf("expected total tokens 15, got %+v", usage)
+	}
+
+	resp := acc.Response()
+	if resp.ID != "chatcmpl-1" || resp.Model != "gpt-4" {
+		t.Errorf("unexpected id/model: %s/%s", resp.ID, resp.Model)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("expected 1 choice, got %d", len(resp.Choices))
+	}
+	choice := resp.Choices[0]
+	if choice.Message.Content != "Hello" {
+		t.Errorf("expected content 'Hello', got '%s'", choice.Message.Content)
+	}
+	if choice.FinishReason != "tool_calls" {
+		t.Errorf("expected finish reason 'tool_calls', got '%s'", choice.FinishReason)
+	}
+	if len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(choice.Message.ToolCalls))
+	}
+	call := choice.Message.ToolCalls[0]
+	if call.ID != "call_1" || call.Function.Name != "get_weather" {
+		t.Errorf("unexpected tool call: %+v", call)
+	}
+	if call.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected arguments: %s", call.Function.Arguments)
+	}
+}
+
+func TestStreamAccumulator_UsageOnlyChunk(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(&ChatCompletionStreamResponse{
+		ID:      "chatcmpl-1",
+		Model:   "gpt-4",
+		Choices: []Choice{{Index: 0, Delta: &Delta{Role: "assistant", Content: "Hi there"}, FinishReason: "stop"}},
+	})
+	// The final chunk of a stream with include_usage carries usage and no choices
+	acc.Add(&ChatCompletionStreamResponse{
+		ID:      "chatcmpl-1",
+		Model:   "gpt-4",
+		Choices: []Choice{},
+		Usage:   &Usage{PromptTokens: 7, CompletionTokens: 2, TotalTokens: 9},
+	})
+
+	resp := acc.Response()
+	if len(resp.Choices) != 1 || resp.Choices[0].Message.Content != "Hi there" {
+		t.Fatalf("expected the content chunk's choice, got %+v", resp.Choices)
+	}
+	if resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("expected finish reason stop, got %q", resp.Choices[0].FinishReason)
+	}
+	if resp.Usage != (Usage{PromptTokens: 7, CompletionTokens: 2, TotalTokens: 9}) {
+		t.Errorf("expected usage from the usage-only chunk, got %+v", resp.Usage)
+	}
+}
+
+func TestStreamAccumulator_NoUsage(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(&ChatCompletionStreamResponse{ID: "chatcmpl-1"})
+	acc.Add(nil)
+
+	if acc.Usage() != nil {
+		t.Error("expected nil usage")
+	}
+	if len(acc.Response().Choices) != 0 {
+		t.Error("expected no choices")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Errorf("expected 0, got %d", got)
+	}
+	if got := EstimateTokens("Hello, world"); got != 3 {
+		t.Errorf("expected 3, got %d", got)
+	}
+}
+
+func TestStreamAccumulator_ImageParts(t *testing.T) {
+	acc := NewStreamAccumulator()
+	for _, delta := range []*Delta{
+		{Role: "assistant", Content: "Here "},
+		{Content: "is a cat:"},
+		{Parts: []ContentPart{ImagePart("data:image/png;base64,AAAA")}},
+		{Parts: []ContentPart{TextPart(" and "), ImagePart("data:image/png;base64,BBBB")}},
+		{Content: "!"},
+	} {
+		acc.Add(&ChatCompletionStreamResponse{ID: "chatcmpl-1", Choices: []Choice{{Delta: delta}}})
+	}
+
+	message := acc.Response().Choices[0].Message
+	if message.Content != "Here is a cat: and !" {
+		t.Errorf("expected the text of all deltas, got %q", message.Content)
+	}
+	want := []ContentPart{
+		TextPart("Here is a cat:"),
+		ImagePart("data:image/png;base64,AAAA"),
+		TextPart(" and "),
+		ImagePart("data:image/png;base64,BBBB"),
+		TextPart("!"),
+	}
+	got, _ := json.Marshal(message.Parts)
+	wantJSON, _ := json.Marshal(want)
+	if string(got) != string(wantJSON) {
+		t.Errorf("unexpected parts:\n got %s\nwant %s", got, wantJSON)
+	}
+}
+
+func TestStreamAccumulator_Logprobs(t *testing.T) {
+	chunks := []string{
+		`{"id":"chatcmpl-1","service_tier":"default","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"},"logprobs":{"content":[{"token":"Hel","logprob":-0.1}]}}]}`,
+		`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"lo"},"logprobs":{"content":[{"token":"lo","logprob":-0.2}]},"finish_reason":"stop"}]}`,
+	}
+
+	acc := NewStreamAccumulator()
+	for _, data := range chunks {
+		var chunk ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("failed to unmarshal chunk: %v", err)
+		}
+		acc.Add(&chunk)
+	}
+
+	resp := acc.Response()
+	if resp.ServiceTier != "default" || resp.SystemFingerprint != "fp_1" {
+		t.Errorf("unexpected service_tier/system_fingerprint: %q/%q", resp.ServiceTier, resp.SystemFingerprint)
+	}
+	want := `{"content":[{"token":"Hel","logprob":-0.1},{"token":"lo","logprob":-0.2}]}`
+	if got := string(resp.Choices[0].Logprobs); got != want {
+		t.Errorf("expected logprobs %s, got %s", want, got)
+	}
+}
+
+func TestStreamAccumulator_NoLogprobs(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(&ChatCompletionStreamResponse{Choices: []Choice{{Delta: &Delta{Content: "hi"}}}})
+	if logprobs := acc.Response().Choices[0].Logprobs; logprobs != nil {
+		t.Errorf("expected no logprobs, got %s", logprobs)
+	}
+}
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Content part types
+const (
+	ContentPartText     = "text"
+	ContentPartImageURL = "image_url"
+)
+
+// ContentPart is one part of a message whose content is an array, such as
+// an image returned by the assistant alongside its text
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL is an image given by URL, or inline as a data: URL
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// TextPart returns a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// ImagePart returns an image content part for url
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: ContentPartImageURL, ImageURL: &ImageURL{URL: url}}
+}
+
+// AppendImage adds an image part to m. The message's text, if any, becomes
+// its first part.
+func (m *Message) AppendImage(url string) {
+	if len(m.Parts) == 0 && m.Content != "" {
+		m.Parts = append(m.Parts, TextPart(m.Content))
+	}
+	m.Parts = append(m.Parts, ImagePart(url))
+}
+
+// Images returns the URLs of the image parts of m
+func (m *Message) Images() []string {
+	var urls []string
+	for _, part := range m.Parts {
+		if part.Type == ContentPartImageURL && part.ImageURL != nil {
+			urls = append(urls, part.ImageURL.URL)
+		}
+	}
+	return urls
+}
+
+// ContentParts returns the content of m as parts, in order: its text parts
+// (taken from Content) and images, or a single text part for plain content
+func (m *Message) ContentParts() []ContentPart {
+	if len(m.Parts) == 0 {
+		return []ContentPart{TextPart(m.Content)}
+	}
+	return contentParts(m.Content, m.Parts)
+}
+
+// ParseDataURL splits a base64 data: URL into its media type, e.g.
+// "image/png", and data
+func ParseDataURL(url string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", "", false
+	}
+	mediaType, data, ok := strings.Cut(rest, ";base64,")
+	if !ok || mediaType == "" || strings.ContainsAny(mediaType, ";,") {
+		return "", "", false
+	}
+	return mediaType, data, true
+}
+
+// contentParts returns parts with their text replaced by content, which is
+// the source of truth for the text of a message. Parts whose text still joins
+// to content are returned as they are; otherwise their text parts are
+// collapsed into one part holding content, where the first one was.
+func contentParts(content string, parts []ContentPart) []ContentPart {
+	var text strings.Builder
+	first := -1
+	for i, part := range parts {
+		if part.Type == ContentPartText {
+			text.WriteString(part.Text)
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if text.String() == content {
+		return parts
+	}
+
+	out := make([]ContentPart, 0, len(parts)+1)
+	if first < 0 {
+		out = append(out, TextPart(content))
+	}
+	for i, part := range parts {
+		switch {
+		case part.Type != ContentPartText:
+			out = append(out, part)
+		case i == first && content != "":
+			out = append(out, TextPart(content))
+		}
+	}
+	return out
+}
+
+// decodeContent decodes message content given as a string or an array of
+// parts. It returns the text of the content, and the parts if any of them
+// isn't text; text-only arrays are collapsed into the returned string.
+func decodeContent(raw json.RawMessage) (string, []ContentPart, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	switch raw[0] {
+	case '"':
+		var content string
+		err := json.Unmarshal(raw, &content)
+		return content, nil, err
+	case '[':
+		var parts []ContentPart
+		if err := json.Unmarshal(raw, &parts); err != nil {
+			return "", nil, err
+		}
+		var text strings.Builder
+		textOnly := true
+		for _, part := range parts {
+			if part.Type == ContentPartText {
+				text.WriteString(part.Text)
+			} else {
+				textOnly = false
+			}
+		}
+		if textOnly {
+			parts = nil
+		}
+		return text.String(), parts, nil
+	}
+	return "", nil, errors.New("content must be a string or an array of parts")
+}
+
+// IsSystemRole reports whether role carries system instructions. "developer"
+// is OpenAI's name for system messages on newer models.
+func IsSystemRole(role string) bool {
+	return role == "system" || role == "developer"
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"bytes"
+	"strings"
 )
 
 // ParseSSELine parses a single SSE line, returning (event, data, isDone)
@@ -39,6 +40,11 @@ func IsDoneMarker(line []byte) bool {
 	return bytes.HasPrefix(line, []byte("data: [DONE]"))
 }
 
+// IsDoneData checks if the data of an SSE event is the [DONE] marker
+func IsDoneData(data string) bool {
+	return strings.TrimSpace(data) == "[DONE]"
+}
+
 // ExtractData extracts the data portion from a "data: xxx" line
 func ExtractData(line []byte) string {
 	// Remove "data:" prefix
@@ -92,3 +92,12 @@ func TestSSEParser_ExtractData(t *testing.T) {
 		})
 	}
 }
+
+func TestIsDoneData(t *testing.T) {
+	if !IsDoneData("[DONE]") || !IsDoneData(" [DONE]\n") {
+		t.Error("expected the [DONE] marker to be detected")
+	}
+	if IsDoneData(`{"done":"[DONE]"}`) {
+		t.Error("expected JSON data not to be the marker")
+	}
+}
@@ -8,8 +8,65 @@ import (
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	Refusal    string     `json:"refusal,omitempty"`      // Set when the assistant declines to answer
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Tool calls made by the assistant
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Tool call answered by a "tool" message
+
+	// CacheControl marks the end of a prompt prefix the upstream may cache.
+	// Providers without prompt caching drop it.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+
+	// Parts holds content sent as an array with non-text parts, e.g. images
+	// in an assistant answer. Content then holds the joined text parts and
+	// stays the source of truth for the text: when set, Parts is marshaled
+	// as the content with its text taken from Content.
+	Parts []ContentPart `json:"-"`
+}
+
+// CacheControlEphemeral is the cache control type for short-lived prompt caching
+const CacheControlEphemeral = "ephemeral"
+
+// CacheControl is a prompt caching hint
+type CacheControl struct {
+	Type string `json:"type"` // "ephemeral"
+}
+
+// MarshalJSON implements json.Marshaler for Message. An assistant turn with
+// only tool calls has null content, as the OpenAI API returns it.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if len(m.Parts) > 0 {
+		return json.Marshal(struct {
+			plain
+			Content []ContentPart `json:"content"`
+		}{plain: plain(m), Content: contentParts(m.Content, m.Parts)})
+	}
+	if m.Content != "" || len(m.ToolCalls) == 0 {
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		plain
+		Content *string `json:"content"`
+	}{plain: plain(m)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Message. The content may be
+// a string or an array of parts.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type plain Message
+	var msg struct {
+		plain
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	*m = Message(msg.plain)
+	var err error
+	m.Content, m.Parts, err = decodeContent(msg.Content)
+	return err
 }
 
 // Choice represents a completion choice
@@ -18,12 +75,52 @@ type Choice struct {
 	Message      Message `json:"message,omitempty"`
 	Delta        *Delta  `json:"delta,omitempty"`
 	FinishReason string  `json:"finish_reason"`
+
+	// Logprobs are the token log probabilities requested with logprobs,
+	// passed through as the upstream sent them
+	Logprobs json.RawMessage `json:"logprobs,omitempty"`
 }
 
 // Delta represents streaming message delta
 type Delta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role      string     `json:"role,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	Refusal   string     `json:"refusal,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// Parts holds content streamed as an array with non-text parts, as in
+	// Message. When set, Parts is marshaled as the content with its text
+	// taken from Content.
+	Parts []ContentPart `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler for Delta
+func (d Delta) MarshalJSON() ([]byte, error) {
+	type plain Delta
+	if len(d.Parts) == 0 {
+		return json.Marshal(plain(d))
+	}
+	return json.Marshal(struct {
+		plain
+		Content []ContentPart `json:"content"`
+	}{plain: plain(d), Content: contentParts(d.Content, d.Parts)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Delta. The content may be a
+// string or an array of parts.
+func (d *Delta) UnmarshalJSON(data []byte) error {
+	type plain Delta
+	var delta struct {
+		plain
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return err
+	}
+	*d = Delta(delta.plain)
+	var err error
+	d.Content, d.Parts, err = decodeContent(delta.Content)
+	return err
 }
 
 // Usage represents token usage
@@ -42,30 +139,74 @@ type ChatCompletionRequest struct {
 	N                *int      `json:"n,omitempty"`
 	Stream           bool      `json:"stream,omitempty"`
 	MaxTokens        *int      `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int   `json:"max_completion_tokens,omitempty"` // Supersedes max_tokens; required by reasoning models
 	Stop             any       `json:"stop,omitempty"`
 	PresencePenalty  *float64  `json:"presence_penalty,omitempty"`
 	FrequencyPenalty *float64  `json:"frequency_penalty,omitempty"`
 	Tools            []Tool    `json:"tools,omitempty"`
 	ToolChoice       any       `json:"tool_choice,omitempty"`
+	User             string    `json:"user,omitempty"`
+	Store            *bool     `json:"store,omitempty"` // Ask the upstream to retain the completion; never served from the gateway cache
+	Seed             *int      `json:"seed,omitempty"` // Best-effort deterministic sampling
+	LogProbs         *bool     `json:"logprobs,omitempty"`
+	TopLogProbs      *int      `json:"top_logprobs,omitempty"`
+	ServiceTier      string    `json:"service_tier,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// OutputTokenLimit returns max_completion_tokens if set, otherwise max_tokens
+func (r *ChatCompletionRequest) OutputTokenLimit() *int {
+	if r.MaxCompletionTokens != nil {
+		return r.MaxCompletionTokens
+	}
+	return r.MaxTokens
+}
+
+// ResponseFormat constrains the format of the model's output
+type ResponseFormat struct {
+	Type       string      `json:"type"`                  // "text", "json_object" or "json_schema"
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"` // Set for "json_schema"
+}
+
+// JSONSchema is the schema a "json_schema" response must follow
+type JSONSchema struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Schema      map[string]any `json:"schema,omitempty"`
+	Strict      *bool          `json:"strict,omitempty"` // Require the output to match the schema exactly
+}
+
+// StreamOptions controls streaming behavior
+type StreamOptions struct {
+	// IncludeUsage requests a final chunk carrying token usage
+	IncludeUsage bool `json:"include_usage,omitempty"`
 }
 
 // ChatCompletionResponse represents a chat completion response
 type ChatCompletionResponse struct {
-	ID      string   `json:"id"`
-	Object  string   `json:"object"`
-	Created int64    `json:"created"`
-	Model   string   `json:"model"`
-	Choices []Choice `json:"choices"`
-	Usage   Usage    `json:"usage"`
+	ID                string   `json:"id"`
+	Object            string   `json:"object"`
+	Created           int64    `json:"created"`
+	Model             string   `json:"model"`
+	Choices           []Choice `json:"choices"`
+	Usage             Usage    `json:"usage"`
+	ServiceTier       string   `json:"service_tier,omitempty"`       // Tier that served the request
+	SystemFingerprint string   `json:"system_fingerprint,omitempty"` // Backend configuration the model ran with
 }
 
 // ChatCompletionStreamResponse represents a streaming chunk
 type ChatCompletionStreamResponse struct {
-	ID      string   `json:"id"`
-	Object  string   `json:"object"`
-	Created int64    `json:"created"`
-	Model   string   `json:"model"`
-	Choices []Choice `json:"choices"`
+	ID                string   `json:"id"`
+	Object            string   `json:"object"`
+	Created           int64    `json:"created"`
+	Model             string   `json:"model"`
+	Choices           []Choice `json:"choices"`
+	Usage             *Usage   `json:"usage,omitempty"` // Set on the final chunk when usage is requested
+	ServiceTier       string   `json:"service_tier,omitempty"`
+	SystemFingerprint string   `json:"system_fingerprint,omitempty"`
 }
 
 // EmbeddingRequest represents an embedding request
@@ -151,6 +292,64 @@ type Image struct {
 	RevisedPrompt string `json:"revised_prompt,omitempty"`
 }
 
+// ModerationRequest represents a moderation request
+type ModerationRequest struct {
+	Input any    `json:"input"` // string, []string, or multi-modal input items
+	Model string `json:"model,omitempty"`
+}
+
+// ModerationResponse represents a moderation response
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
+
+// ModerationResult represents the moderation result for a single input
+type ModerationResult struct {
+	Flagged                   bool                `json:"flagged"`
+	Categories                map[string]bool     `json:"categories"`
+	CategoryScores            map[string]float64  `json:"category_scores"`
+	CategoryAppliedInputTypes map[string][]string `json:"category_applied_input_types,omitempty"`
+}
+
+// TranscriptionRequest represents an audio transcription request.
+// It is sent as multipart/form-data, so the audio file is not part of the JSON form.
+type TranscriptionRequest struct {
+	File           []byte   `json:"-"`
+	Filename       string   `json:"-"`
+	Model          string   `json:"model"`
+	Language       string   `json:"language,omitempty"`
+	Prompt         string   `json:"prompt,omitempty"`
+	ResponseFormat string   `json:"response_format,omitempty"` // "json", "text", "srt", "verbose_json" or "vtt"
+	Temperature    *float64 `json:"temperature,omitempty"`
+}
+
+// TranscriptionResponse represents an audio transcription response
+type TranscriptionResponse struct {
+	Text     string                 `json:"text"`
+	Language string                 `json:"language,omitempty"`
+	Duration float64                `json:"duration,omitempty"`
+	Segments []TranscriptionSegment `json:"segments,omitempty"`
+}
+
+// TranscriptionSegment represents a timed segment of a transcription
+type TranscriptionSegment struct {
+	ID    int     `json:"id"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// SpeechRequest represents a text-to-speech request. The response is raw audio, not JSON.
+type SpeechRequest struct {
+	Model          string   `json:"model"`
+	Input          string   `json:"input"`
+	Voice          string   `json:"voice"`
+	ResponseFormat string   `json:"response_format,omitempty"` // "mp3", "opus", "aac", "flac", "wav" or "pcm"
+	Speed          *float64 `json:"speed,omitempty"`
+}
+
 // Tool represents a tool that can be called by the model
 type Tool struct {
 	Type     string             `json:"type"`     // "function"
@@ -166,12 +365,16 @@ type FunctionDefinition struct {
 
 // ToolCall represents a tool call in a response
 type ToolCall struct {
-	ID       string `json:"id,omitempty"`
-	Type     string `json:"type"` // "function"
-	Function struct {
-		Name      string `json:"name"`
-		Arguments string `json:"arguments"`
-	} `json:"function"`
+	Index    *int         `json:"index,omitempty"` // Position of the call, set on streaming deltas
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type"` // "function"
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function invoked by a tool call
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON-encoded arguments
 }
 
 // ToolCallChoice controls tool calling behavior
@@ -322,3 +322,95 @@ func TestImageResponse_MarshalJSON(t *testing.T) {
 		t.Error("created timestamp mismatch")
 	}
 }
+
+func TestMessage_MarshalJSON_ToolCallsOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		message Message
+		want    any
+	}{
+		{"tool calls only", Message{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function"}}}, nil},
+		{"text and tool calls", Message{Role: "assistant", Content: "Checking", ToolCalls: []ToolCall{{ID: "call_1", Type: "function"}}}, "Checking"},
+		{"empty text", Message{Role: "assistant"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.message)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+
+			var decoded map[string]any
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("failed to decode: %v", err)
+			}
+			content, exists := decoded["content"]
+			if !exists || content != tt.want {
+				t.Errorf("expected content %#v, got %s", tt.want, data)
+			}
+		})
+	}
+}
+
+func TestMessage_ContentParts(t *testing.T) {
+	body := `{"role":"assistant","content":[{"type":"text","text":"Here is a cat: "},{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}},{"type":"text","text":"Cute!"}]}`
+
+	var msg Message
+	if err := json.Unmarshal([]byte(body), &msg); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if msg.Content != "Here is a cat: Cute!" {
+		t.Errorf("expected the joined text as content, got %q", msg.Content)
+	}
+	if images := msg.Images(); len(images) != 1 || images[0] != "data:image/png;base64,iVBORw0KGgo=" {
+		t.Errorf("expected the image part, got %v", images)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("expected the parts to round-trip:\n got %s\nwant %s", data, body)
+	}
+
+	// Edits to Content replace the text parts, keeping the image
+	msg.Content = "Here is a [REDACTED]"
+	data, _ = json.Marshal(msg)
+	want := `{"role":"assistant","content":[{"type":"text","text":"Here is a [REDACTED]"},{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}]}`
+	if string(data) != want {
+		t.Errorf("expected the edited content in the parts:\n got %s\nwant %s", data, want)
+	}
+
+	// Text-only arrays collapse into a string
+	if err := json.Unmarshal([]byte(`{"role":"user","content":[{"type":"text","text":"Hi "},{"type":"text","text":"there"}]}`), &msg); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if msg.Content != "Hi there" || msg.Parts != nil {
+		t.Errorf("expected plain text content, got %q and %v", msg.Content, msg.Parts)
+	}
+
+	if err := json.Unmarshal([]byte(`{"role":"user","content":42}`), &msg); err == nil {
+		t.Error("expected an error for non-string, non-array content")
+	}
+}
+
+func TestDelta_ContentParts(t *testing.T) {
+	body := `{"content":[{"type":"image_url","image_url":{"url":"https://example.com/cat.png"}}]}`
+
+	var delta Delta
+	if err := json.Unmarshal([]byte(body), &delta); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(delta.Parts) != 1 || delta.Parts[0].ImageURL.URL != "https://example.com/cat.png" {
+		t.Fatalf("expected the image part, got %+v", delta.Parts)
+	}
+
+	data, _ := json.Marshal(delta)
+	if string(data) != body {
+		t.Errorf("expected the parts to round-trip:\n got %s\nwant %s", data, body)
+	}
+	if data, _ := json.Marshal(Delta{Content: "Hi"}); string(data) != `{"content":"Hi"}` {
+		t.Errorf("expected plain text deltas unchanged, got %s", data)
+	}
+}
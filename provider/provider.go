@@ -17,5 +17,42 @@ type Provider interface {
 	SendRequest(ctx context.Context, req *Request) (*Response, error)
 }
 
+// SingleChoiceProvider is implemented by providers that may be unable to return
+// multiple choices (n > 1) for one chat request
+type SingleChoiceProvider interface {
+	// SingleChoice reports whether the provider always returns a single choice
+	SingleChoice() bool
+}
+
+// IsSingleChoice reports whether prov always returns a single choice, looking
+// through wrapped providers
+func IsSingleChoice(prov Provider) bool {
+	if p, ok := prov.(SingleChoiceProvider); ok {
+		return p.SingleChoice()
+	}
+	if w, ok := prov.(interface{ Unwrap() Provider }); ok {
+		return IsSingleChoice(w.Unwrap())
+	}
+	return false
+}
+
+// ToolsProvider is implemented by providers that may not support function calling
+type ToolsProvider interface {
+	// SupportsTools reports whether the provider accepts tools in chat requests
+	SupportsTools() bool
+}
+
+// SupportsTools reports whether prov accepts tools, looking through wrapped
+// providers. Providers that don't declare it are assumed to.
+func SupportsTools(prov Provider) bool {
+	if p, ok := prov.(ToolsProvider); ok {
+		return p.SupportsTools()
+	}
+	if w, ok := prov.(interface{ Unwrap() Provider }); ok {
+		return SupportsTools(w.Unwrap())
+	}
+	return true
+}
+
 // Ensure BaseProvider implements Provider
 var _ Provider = (*BaseProvider)(nil)
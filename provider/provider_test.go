@@ -172,3 +172,86 @@ func (m *mockStreamProvider) SendRequest(ctx context.Context, req *Request) (*Re
 	}
 	return NewChatCompletionResponse(chatResp), nil
 }
+
+func TestAPITypeSupports(t *testing.T) {
+	single := []APIType{APITypeChatCompletions, APITypeResponses, APITypeEmbeddings, APITypeImages, APITypeModerations, APITypeAudioTranscription, APITypeAudioSpeech}
+
+	// Each single type supports only itself
+	for _, a := range single {
+		for _, b := range single {
+			if got, want := a.Supports(b), a == b; got != want {
+				t.Errorf("%v.Supports(%v) = %v, want %v", a, b, got, want)
+			}
+		}
+	}
+
+	// APITypeAll supports every type
+	for _, a := range single {
+		if !APITypeAll.Supports(a) {
+			t.Errorf("expected APITypeAll to support %v", a)
+		}
+	}
+
+	// Bits are distinct
+	seen := APIType(0)
+	for _, a := range single {
+		if seen&a != 0 {
+			t.Errorf("APIType %v overlaps with another type", a)
+		}
+		seen |= a
+	}
+	if seen != APITypeAll {
+		t.Errorf("expected APITypeAll to be the union of all types, got %d", APITypeAll)
+	}
+}
+
+func TestAPITypeString(t *testing.T) {
+	tests := map[APIType]string{
+		APITypeChatCompletions:    "chat_completions",
+		APITypeResponses:          "responses",
+		APITypeEmbeddings:         "embeddings",
+		APITypeImages:             "images",
+		APITypeModerations:        "moderations",
+		APITypeAudioTranscription: "audio_transcription",
+		APITypeAudioSpeech:        "audio_speech",
+		APITypeAll:                "all",
+	}
+	for apiType, want := range tests {
+		if got := apiType.String(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestParseAPIType(t *testing.T) {
+	for _, want := range []APIType{APITypeChatCompletions, APITypeChatCompletions | APITypeEmbeddings, APITypeAll} {
+		got, err := ParseAPIType(want.String())
+		if err != nil || got != want {
+			t.Errorf("ParseAPIType(%q) = %v, %v, want %v", want.String(), got, err, want)
+		}
+	}
+	for _, s := range []string{"", "chat", "embeddings|none"} {
+		if _, err := ParseAPIType(s); err == nil {
+			t.Errorf("ParseAPIType(%q): expected an error", s)
+		}
+	}
+}
+
+func TestAPITypeStringCombined(t *testing.T) {
+	tests := []struct {
+		apiType APIType
+		want    string
+	}{
+		{APITypeChatCompletions | APITypeEmbeddings, "chat_completions|embeddings"},
+		{APITypeChatCompletions | APITypeResponses, "chat_completions|responses"},
+		{APITypeEmbeddings | APITypeImages, "embeddings|images"},
+		{APITypeChatCompletions | APITypeResponses | APITypeImages, "chat_completions|responses|images"},
+		{0, "none"},
+		{APITypeImages | 1<<10, "images|unknown(1024)"},
+	}
+	for _, tt := range tests {
+		if got := tt.apiType.String(); got != tt.want {
+			t.Errorf("APIType(%d).String() = %q, want %q", int(tt.apiType), got, tt.want)
+		}
+	}
+}
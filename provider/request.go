@@ -13,7 +13,8 @@ type Request struct {
 	// APIType specifies which API format to use (ChatCompletions, Responses, Embeddings, or Images)
 	APIType APIType
 
-	// Stream indicates whether to use streaming
+	// Stream indicates whether to use streaming. Only Chat Completions and
+	// Responses stream; other API types ignore it.
 	Stream bool
 
 	// Model is the model identifier
@@ -66,6 +67,42 @@ type Request struct {
 	// ImageStyle is the image style ("vivid" or "natural")
 	ImageStyle string
 
+	// === Moderations fields ===
+
+	// ModerationInput is the input to classify (string, []string, or multi-modal input items)
+	ModerationInput any
+
+	// === Audio transcription fields ===
+
+	// AudioFile is the raw audio file to transcribe
+	AudioFile []byte
+
+	// AudioFilename is the original file name, used by upstreams to detect the format
+	AudioFilename string
+
+	// AudioLanguage is the ISO-639-1 language of the audio (optional)
+	AudioLanguage string
+
+	// AudioPrompt is text to guide the transcription style (optional)
+	AudioPrompt string
+
+	// AudioResponseFormat is the transcription format ("json", "text", "srt", "verbose_json" or "vtt")
+	AudioResponseFormat string
+
+	// === Audio speech fields ===
+
+	// SpeechInput is the text to synthesize
+	SpeechInput string
+
+	// SpeechVoice is the voice to synthesize with
+	SpeechVoice string
+
+	// SpeechResponseFormat is the audio format ("mp3", "opus", "aac", "flac", "wav" or "pcm")
+	SpeechResponseFormat string
+
+	// SpeechSpeed is the playback speed of the generated audio (optional)
+	SpeechSpeed *float64
+
 	// === Common parameters (shared by both APIs) ===
 
 	// Temperature controls randomness
@@ -80,9 +117,16 @@ type Request struct {
 	// MaxOutputTokens is the maximum output tokens (Responses API naming)
 	MaxOutputTokens *int
 
+	// MaxCompletionTokens is the maximum output tokens (Chat Completions
+	// max_completion_tokens). It takes precedence over MaxTokens.
+	MaxCompletionTokens *int
+
 	// Stop sequences
 	Stop any
 
+	// N is the number of choices to generate (Chat Completions)
+	N *int
+
 	// Presence penalty
 	PresencePenalty *float64
 
@@ -95,6 +139,30 @@ type Request struct {
 	// ToolChoice controls tool calling behavior
 	ToolChoice any
 
+	// ResponseFormat constrains the output to JSON, optionally following a schema
+	ResponseFormat *openai.ResponseFormat
+
+	// User is the end-user identifier for provider-side abuse detection
+	User string
+
+	// Seed requests best-effort deterministic sampling (Chat Completions)
+	Seed *int
+
+	// LogProbs requests the log probabilities of the output tokens (Chat Completions)
+	LogProbs *bool
+
+	// TopLogProbs is the number of most likely tokens returned per position with LogProbs
+	TopLogProbs *int
+
+	// ServiceTier selects the upstream processing tier, e.g. "auto" or "flex"
+	ServiceTier string
+
+	// Metadata is the key-value metadata stored with the completion
+	Metadata map[string]string
+
+	// StreamOptions controls streaming behavior (Chat Completions)
+	StreamOptions *openai.StreamOptions
+
 	// Original request body for passthrough
 	OriginalBody []byte
 
@@ -142,6 +210,38 @@ func NewImagesRequest(model, prompt string) *Request {
 	}
 }
 
+// NewModerationsRequest creates a new request for Moderations API
+func NewModerationsRequest(model string, input any) *Request {
+	return &Request{
+		APIType:         APITypeModerations,
+		Model:           model,
+		ModerationInput: input,
+		Endpoint:        "/v1/moderations",
+	}
+}
+
+// NewAudioTranscriptionRequest creates a new request for Audio Transcriptions API
+func NewAudioTranscriptionRequest(model, filename string, file []byte) *Request {
+	return &Request{
+		APIType:       APITypeAudioTranscription,
+		Model:         model,
+		AudioFile:     file,
+		AudioFilename: filename,
+		Endpoint:      "/v1/audio/transcriptions",
+	}
+}
+
+// NewAudioSpeechRequest creates a new request for Audio Speech API
+func NewAudioSpeechRequest(model, input, voice string) *Request {
+	return &Request{
+		APIType:     APITypeAudioSpeech,
+		Model:       model,
+		SpeechInput: input,
+		SpeechVoice: voice,
+		Endpoint:    "/v1/audio/speech",
+	}
+}
+
 // GetMaxTokens returns the max tokens value, checking both field names
 func (r *Request) GetMaxTokens() *int {
 	if r.MaxOutputTokens != nil {
@@ -165,11 +265,27 @@ func (r *Request) ToChatCompletionRequest() (*openai.ChatCompletionRequest, erro
 		TopP:             r.TopP,
 		MaxTokens:        r.GetMaxTokens(),
 		Stop:             r.Stop,
+		N:                r.N,
 		PresencePenalty:  r.PresencePenalty,
 		FrequencyPenalty: r.FrequencyPenalty,
 		Tools:            r.Tools,
 		ToolChoice:       r.ToolChoice,
+		User:             r.User,
 		Stream:           r.Stream,
+		ResponseFormat:   r.ResponseFormat,
+		Seed:             r.Seed,
+		LogProbs:         r.LogProbs,
+		TopLogProbs:      r.TopLogProbs,
+		ServiceTier:      r.ServiceTier,
+		Metadata:         r.Metadata,
+	}
+	// Reasoning models reject max_tokens, so it isn't sent alongside max_completion_tokens
+	if r.MaxCompletionTokens != nil {
+		req.MaxCompletionTokens = r.MaxCompletionTokens
+		req.MaxTokens = nil
+	}
+	if r.Stream {
+		req.StreamOptions = r.StreamOptions
 	}
 	return req, nil
 }
@@ -186,6 +302,7 @@ func (r *Request) ToResponsesRequest() (*openresponses.CreateRequest, error) {
 		PresencePenalty:  r.PresencePenalty,
 		FrequencyPenalty: r.FrequencyPenalty,
 		Truncation:       r.Truncation,
+		Text:             openresponses.TextFromResponseFormat(r.ResponseFormat),
 	}
 
 	// Convert tools
@@ -232,6 +349,41 @@ func (r *Request) ToImageRequest() (*openai.ImageRequest, error) {
 	return req, nil
 }
 
+// ToModerationRequest converts the unified request to OpenAI ModerationRequest
+func (r *Request) ToModerationRequest() (*openai.ModerationRequest, error) {
+	req := &openai.ModerationRequest{
+		Input: r.ModerationInput,
+		Model: r.Model,
+	}
+	return req, nil
+}
+
+// ToTranscriptionRequest converts the unified request to OpenAI TranscriptionRequest
+func (r *Request) ToTranscriptionRequest() (*openai.TranscriptionRequest, error) {
+	req := &openai.TranscriptionRequest{
+		File:           r.AudioFile,
+		Filename:       r.AudioFilename,
+		Model:          r.Model,
+		Language:       r.AudioLanguage,
+		Prompt:         r.AudioPrompt,
+		ResponseFormat: r.AudioResponseFormat,
+		Temperature:    r.Temperature,
+	}
+	return req, nil
+}
+
+// ToSpeechRequest converts the unified request to OpenAI SpeechRequest
+func (r *Request) ToSpeechRequest() (*openai.SpeechRequest, error) {
+	req := &openai.SpeechRequest{
+		Model:          r.Model,
+		Input:          r.SpeechInput,
+		Voice:          r.SpeechVoice,
+		ResponseFormat: r.SpeechResponseFormat,
+		Speed:          r.SpeechSpeed,
+	}
+	return req, nil
+}
+
 // Clone creates a deep copy of the request
 func (r *Request) Clone() (*Request, error) {
 	data, err := json.Marshal(r)
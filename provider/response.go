@@ -2,6 +2,8 @@ package provider
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 
 	"github.com/deeplooplabs/ai-gateway/provider/openai"
 	openresponses "github.com/deeplooplabs/ai-gateway/openresponses"
@@ -79,6 +81,15 @@ type Response struct {
 	// Image is the OpenAI Images response
 	Image *openai.ImageResponse
 
+	// Moderation is the OpenAI Moderations response
+	Moderation *openai.ModerationResponse
+
+	// Transcription is the OpenAI Audio Transcriptions response
+	Transcription *openai.TranscriptionResponse
+
+	// Audio is the raw audio body of an Audio Speech response
+	Audio *BinaryBody
+
 	// === Streaming responses (when Stream=true) ===
 
 	// Chunks is the channel for streaming chunks
@@ -89,6 +100,10 @@ type Response struct {
 
 	// CloseFunc is called to close the streaming response
 	CloseFunc func() error
+
+	// Headers are the allowlisted upstream response headers, such as rate limits,
+	// to pass back to the client
+	Headers http.Header
 }
 
 // NewChatCompletionResponse creates a new non-streaming Chat Completions response
@@ -127,6 +142,46 @@ func NewImageResponse(resp *openai.ImageResponse) *Response {
 	}
 }
 
+// NewModerationResponse creates a new non-streaming Moderation response
+func NewModerationResponse(resp *openai.ModerationResponse) *Response {
+	return &Response{
+		APIType:    APITypeModerations,
+		Stream:     false,
+		Moderation: resp,
+	}
+}
+
+// NewTranscriptionResponse creates a new non-streaming Audio Transcription response
+func NewTranscriptionResponse(resp *openai.TranscriptionResponse) *Response {
+	return &Response{
+		APIType:       APITypeAudioTranscription,
+		Stream:        false,
+		Transcription: resp,
+	}
+}
+
+// BinaryBody is a raw response body passed through without decoding
+type BinaryBody struct {
+	// Body is the upstream response body; it is closed by Response.Close
+	Body io.ReadCloser
+
+	// ContentType is the upstream content type (e.g. "audio/mpeg")
+	ContentType string
+
+	// ContentLength is the body size in bytes, or -1 if unknown
+	ContentLength int64
+}
+
+// NewAudioSpeechResponse creates a new Audio Speech response that passes the body through
+func NewAudioSpeechResponse(body *BinaryBody) *Response {
+	return &Response{
+		APIType:   APITypeAudioSpeech,
+		Stream:    false,
+		Audio:     body,
+		CloseFunc: body.Body.Close,
+	}
+}
+
 // NewStreamingResponse creates a new streaming response
 func NewStreamingResponse(apiType APIType, chunks <-chan *Chunk, errors <-chan error, closeFn func() error) *Response {
 	return &Response{
@@ -193,6 +248,30 @@ func (r *Response) GetImage() (*openai.ImageResponse, error) {
 	return nil, fmt.Errorf("no image response data available")
 }
 
+// GetModeration returns the Moderation response
+func (r *Response) GetModeration() (*openai.ModerationResponse, error) {
+	if r.Moderation != nil {
+		return r.Moderation, nil
+	}
+	return nil, fmt.Errorf("no moderation response data available")
+}
+
+// GetTranscription returns the Audio Transcription response
+func (r *Response) GetTranscription() (*openai.TranscriptionResponse, error) {
+	if r.Transcription != nil {
+		return r.Transcription, nil
+	}
+	return nil, fmt.Errorf("no transcription response data available")
+}
+
+// GetAudio returns the raw audio body of an Audio Speech response
+func (r *Response) GetAudio() (*BinaryBody, error) {
+	if r.Audio != nil {
+		return r.Audio, nil
+	}
+	return nil, fmt.Errorf("no audio response data available")
+}
+
 // IsStreaming returns true if this is a streaming response
 func (r *Response) IsStreaming() bool {
 	return r.Stream
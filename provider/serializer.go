@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+)
+
+// BodySerializer encodes the outbound request body for a provider
+type BodySerializer interface {
+	// Serialize encodes body, the OpenAI-format request parsed from req
+	// (*openai.ChatCompletionRequest, *openai.EmbeddingRequest, *openai.ImageRequest
+	// or *openai.ModerationRequest), and returns the encoded body and its content type
+	Serialize(req *Request, body any) ([]byte, string, error)
+}
+
+// BodySerializerFunc is a function that implements BodySerializer
+type BodySerializerFunc func(req *Request, body any) ([]byte, string, error)
+
+// Serialize implements BodySerializer
+func (f BodySerializerFunc) Serialize(req *Request, body any) ([]byte, string, error) {
+	return f(req, body)
+}
+
+// JSONSerializer encodes bodies as OpenAI JSON
+type JSONSerializer struct{}
+
+// Serialize implements BodySerializer
+func (JSONSerializer) Serialize(req *Request, body any) ([]byte, string, error) {
+	data, err := json.Marshal(body)
+	return data, "application/json", err
+}
+
+// SerializeBody encodes body with the configured BodySerializer (JSONSerializer by default).
+// It returns a copy of req.Headers with Content-Type set to the serializer's content type.
+func (p *BaseProvider) SerializeBody(req *Request, body any) ([]byte, map[string]string, error) {
+	serializer := p.config.BodySerializer
+	if serializer == nil {
+		serializer = JSONSerializer{}
+	}
+
+	data, contentType, err := serializer.Serialize(req, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(p.config.BodyTemplate) > 0 && isJSONContentType(contentType) {
+		if data, err = mergeBodyTemplate(data, p.config.BodyTemplate, p.config.OverrideBodyFields); err != nil {
+			return nil, nil, fmt.Errorf("apply body template: %w", err)
+		}
+	}
+
+	headers := maps.Clone(req.Headers)
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	return data, headers, nil
+}
+
+// isJSONContentType reports whether contentType is JSON, or unset
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// mergeBodyTemplate merges template into the JSON object data. Objects present
+// on both sides are merged recursively; other fields the body already has are
+// kept unless override is set.
+func mergeBodyTemplate(data []byte, template map[string]any, override bool) ([]byte, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	if body == nil {
+		body = make(map[string]json.RawMessage)
+	}
+	if err := mergeFields(body, template, override); err != nil {
+		return nil, err
+	}
+	return json.Marshal(body)
+}
+
+// mergeFields merges template into body, as described by mergeBodyTemplate
+func mergeFields(body map[string]json.RawMessage, template map[string]any, override bool) error {
+	for key, value := range template {
+		existing, ok := body[key]
+		if nested, isObject := value.(map[string]any); ok && isObject {
+			var inner map[string]json.RawMessage
+			if json.Unmarshal(existing, &inner) == nil && inner != nil {
+				if err := mergeFields(inner, nested, override); err != nil {
+					return err
+				}
+				merged, err := json.Marshal(inner)
+				if err != nil {
+					return err
+				}
+				body[key] = merged
+				continue
+			}
+		}
+		if ok && !override {
+			continue
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", key, err)
+		}
+		body[key] = encoded
+	}
+	return nil
+}
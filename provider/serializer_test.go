@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestHTTPProvider_BodySerializer(t *testing.T) {
+	var gotContentType string
+	var gotForm url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotForm, _ = url.ParseQuery(string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	// Form-encode the model and the last user message
+	formSerializer := BodySerializerFunc(func(req *Request, body any) ([]byte, string, error) {
+		chatReq := body.(*openai2.ChatCompletionRequest)
+		form := url.Values{}
+		form.Set("model_name", chatReq.Model)
+		form.Set("prompt", chatReq.Messages[len(chatReq.Messages)-1].Content)
+		return []byte(form.Encode()), "application/x-www-form-urlencoded", nil
+	})
+
+	config := NewProviderConfig("form").
+		WithBaseURL(server.URL).
+		WithBodySerializer(formSerializer)
+	provider := NewHTTPProvider(config)
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "hello"}})
+	req.Headers = map[string]string{"X-Trace": "1"}
+	if _, err := provider.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotContentType != "application/x-www-form-urlencoded" {
+		t.Errorf("expected form content type, got %s", gotContentType)
+	}
+	if gotForm.Get("model_name") != "gpt-4" || gotForm.Get("prompt") != "hello" {
+		t.Errorf("unexpected form body: %v", gotForm)
+	}
+	if _, ok := req.Headers["Content-Type"]; ok {
+		t.Error("expected request headers not to be modified")
+	}
+}
+
+func TestHTTPProvider_DropsCacheControl(t *testing.T) {
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	hint := &openai2.CacheControl{Type: openai2.CacheControlEphemeral}
+	messages := []openai2.Message{{Role: "system", Content: "You are helpful", CacheControl: hint}, {Role: "user", Content: "hello"}}
+
+	tests := []struct {
+		name   string
+		config *ProviderConfig
+		want   bool
+	}{
+		{"unsupported", NewProviderConfig("plain").WithBaseURL(server.URL), false},
+		{"supported", NewProviderConfig("caching").WithBaseURL(server.URL).WithPromptCaching(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewHTTPProvider(tt.config)
+			if _, err := provider.SendRequest(context.Background(), NewChatCompletionsRequest("gpt-4", messages)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Contains(string(gotBody), "cache_control"); got != tt.want {
+				t.Errorf("expected cache_control sent = %v, got body %s", tt.want, gotBody)
+			}
+			if messages[0].CacheControl == nil {
+				t.Error("expected request messages not to be modified")
+			}
+		})
+	}
+}
+
+func TestJSONSerializer(t *testing.T) {
+	data, contentType, err := JSONSerializer{}.Serialize(&Request{}, map[string]string{"model": "gpt-4"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "application/json" || string(data) != `{"model":"gpt-4"}` {
+		t.Errorf("unexpected serialization %s (%s)", data, contentType)
+	}
+}
+
+func TestHTTPProvider_BodyTemplate(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	config := NewProviderConfig("routed").
+		WithBaseURL(server.URL).
+		WithBodyTemplate(map[string]any{
+			"provider": map[string]any{"order": []string{"azure"}},
+			"route":    "fallback",
+			"model":    "template-model",
+		})
+	provider := NewHTTPProvider(config)
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "hello"}})
+	if _, err := provider.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got["route"] != "fallback" {
+		t.Errorf("expected template field route, got %v", got["route"])
+	}
+	if routing, ok := got["provider"].(map[string]any); !ok || len(routing["order"].([]any)) != 1 {
+		t.Errorf("expected template field provider, got %v", got["provider"])
+	}
+	if got["model"] != "gpt-4" {
+		t.Errorf("expected the converted model to win, got %v", got["model"])
+	}
+	if messages, ok := got["messages"].([]any); !ok || len(messages) != 1 {
+		t.Errorf("expected messages to be kept, got %v", got["messages"])
+	}
+}
+
+func TestMergeBodyTemplate(t *testing.T) {
+	data := []byte(`{"model":"gpt-4","metadata":{"user":"u1"},"stream":false}`)
+	template := map[string]any{
+		"model":    "override",
+		"metadata": map[string]any{"user": "template", "team": "search"},
+	}
+
+	tests := []struct {
+		name     string
+		override bool
+		want     string
+	}{
+		{"keep converted fields", false, `{"metadata":{"team":"search","user":"u1"},"model":"gpt-4","stream":false}`},
+		{"override", true, `{"metadata":{"team":"search","user":"template"},"model":"override","stream":false}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeBodyTemplate(data, template, tt.override)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(merged) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, merged)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// SystemMessageMode declares how an upstream takes system instructions
+type SystemMessageMode int
+
+const (
+	// SystemInMessages keeps system messages in the message list, as OpenAI does
+	SystemInMessages SystemMessageMode = iota
+
+	// SystemTopLevel moves system messages to a separate request field, such as
+	// Anthropic's system or Gemini's systemInstruction. The provider's converter
+	// fills the field from the messages returned by ApplySystemMessageMode.
+	SystemTopLevel
+
+	// SystemFirstUserPrefix prepends system content to the first user message,
+	// for upstreams with no notion of a system prompt
+	SystemFirstUserPrefix
+)
+
+// IsSystemRole reports whether role carries system instructions. "developer"
+// is OpenAI's name for system messages on newer models.
+func IsSystemRole(role string) bool {
+	return openai.IsSystemRole(role)
+}
+
+// ApplySystemMessageMode relocates system messages as mode requires. It returns
+// the system messages to send in a top-level field (SystemTopLevel only) and the
+// conversation to send as messages. messages is not modified.
+func ApplySystemMessageMode(mode SystemMessageMode, messages []openai.Message) (system, rest []openai.Message) {
+	if mode == SystemInMessages {
+		return nil, messages
+	}
+
+	rest = make([]openai.Message, 0, len(messages))
+	for _, msg := range messages {
+		if IsSystemRole(msg.Role) {
+			system = append(system, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	if mode == SystemTopLevel || len(system) == 0 {
+		return system, rest
+	}
+
+	// SystemFirstUserPrefix: the instructions lead the first user message, or a
+	// new one when the conversation has none
+	prefix := joinContents(system)
+	for i := range rest {
+		if rest[i].Role == "user" {
+			if rest[i].Content == "" {
+				rest[i].Content = prefix
+			} else {
+				rest[i].Content = prefix + "\n\n" + rest[i].Content
+			}
+			return nil, rest
+		}
+	}
+	return nil, append([]openai.Message{{Role: "user", Content: prefix}}, rest...)
+}
+
+// joinContents joins the non-empty contents of messages with a blank line
+func joinContents(messages []openai.Message) string {
+	contents := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Content != "" {
+			contents = append(contents, msg.Content)
+		}
+	}
+	return strings.Join(contents, "\n\n")
+}
+
+// ConsolidateSystemMessages merges all system messages into one at the position
+// of the first, joined with a blank line, for upstreams taking a single system
+// instruction. messages is not modified.
+func ConsolidateSystemMessages(messages []openai.Message) []openai.Message {
+	first := -1
+	var system []openai.Message
+	for i, msg := range messages {
+		if IsSystemRole(msg.Role) {
+			if first < 0 {
+				first = i
+			}
+			system = append(system, msg)
+		}
+	}
+	if len(system) < 2 {
+		return messages
+	}
+
+	merged := system[0]
+	merged.Content = joinContents(system)
+	// A cache hint marks the end of the prefix, which is now the merged message
+	for _, msg := range system {
+		if msg.CacheControl != nil {
+			merged.CacheControl = msg.CacheControl
+		}
+	}
+
+	consolidated := make([]openai.Message, 0, len(messages)-len(system)+1)
+	for i, msg := range messages {
+		switch {
+		case i == first:
+			consolidated = append(consolidated, merged)
+		case !IsSystemRole(msg.Role):
+			consolidated = append(consolidated, msg)
+		}
+	}
+	return consolidated
+}
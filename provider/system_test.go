@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestApplySystemMessageMode(t *testing.T) {
+	messages := []openai2.Message{
+		{Role: "system", Content: "Be brief"},
+		{Role: "assistant", Content: "Hi"},
+		{Role: "user", Content: "Hello"},
+		{Role: "developer", Content: "Answer in French"},
+	}
+
+	tests := []struct {
+		name       string
+		mode       SystemMessageMode
+		wantSystem []string
+		wantRest   []openai2.Message
+	}{
+		{
+			name:     "in messages",
+			mode:     SystemInMessages,
+			wantRest: messages,
+		},
+		{
+			name:       "top level",
+			mode:       SystemTopLevel,
+			wantSystem: []string{"Be brief", "Answer in French"},
+			wantRest: []openai2.Message{
+				{Role: "assistant", Content: "Hi"},
+				{Role: "user", Content: "Hello"},
+			},
+		},
+		{
+			name: "first user prefix",
+			mode: SystemFirstUserPrefix,
+			wantRest: []openai2.Message{
+				{Role: "assistant", Content: "Hi"},
+				{Role: "user", Content: "Be brief\n\nAnswer in French\n\nHello"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system, rest := ApplySystemMessageMode(tt.mode, messages)
+
+			if len(system) != len(tt.wantSystem) {
+				t.Fatalf("expected %d system messages, got %+v", len(tt.wantSystem), system)
+			}
+			for i, msg := range system {
+				if msg.Content != tt.wantSystem[i] {
+					t.Errorf("system %d: expected %q, got %q", i, tt.wantSystem[i], msg.Content)
+				}
+			}
+			if len(rest) != len(tt.wantRest) {
+				t.Fatalf("expected %d messages, got %+v", len(tt.wantRest), rest)
+			}
+			for i, msg := range rest {
+				if msg.Role != tt.wantRest[i].Role || msg.Content != tt.wantRest[i].Content {
+					t.Errorf("message %d: expected %+v, got %+v", i, tt.wantRest[i], msg)
+				}
+			}
+		})
+	}
+
+	if messages[2].Content != "Hello" {
+		t.Errorf("expected the input to be left unmodified, got %q", messages[2].Content)
+	}
+}
+
+func TestApplySystemMessageMode_NoUserMessage(t *testing.T) {
+	_, rest := ApplySystemMessageMode(SystemFirstUserPrefix, []openai2.Message{
+		{Role: "system", Content: "Be brief"},
+		{Role: "assistant", Content: "Hi"},
+	})
+
+	if len(rest) != 2 || rest[0].Role != "user" || rest[0].Content != "Be brief" {
+		t.Errorf("expected the instructions in a new leading user message, got %+v", rest)
+	}
+}
+
+func TestHTTPProvider_SystemFirstUserPrefix(t *testing.T) {
+	var got openai2.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(NewProviderConfig("no-system").WithBaseURL(server.URL).WithSystemMessageMode(SystemFirstUserPrefix))
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{
+		{Role: "system", Content: "Be brief"},
+		{Role: "user", Content: "Hello"},
+	})
+	if _, err := provider.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content != "Be brief\n\nHello" {
+		t.Errorf("expected the system prompt to prefix the user message, got %+v", got.Messages)
+	}
+}
+
+func TestConsolidateSystemMessages(t *testing.T) {
+	hint := &openai2.CacheControl{Type: openai2.CacheControlEphemeral}
+	messages := []openai2.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "system", Content: "Be brief"},
+		{Role: "assistant", Content: "Hi"},
+		{Role: "developer", Content: "Answer in French", CacheControl: hint},
+		{Role: "user", Content: "Bonjour"},
+	}
+
+	got := ConsolidateSystemMessages(messages)
+
+	want := []openai2.Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "system", Content: "Be brief\n\nAnswer in French"},
+		{Role: "assistant", Content: "Hi"},
+		{Role: "user", Content: "Bonjour"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d messages, got %+v", len(want), got)
+	}
+	for i, msg := range got {
+		if msg.Role != want[i].Role || msg.Content != want[i].Content {
+			t.Errorf("message %d: expected %+v, got %+v", i, want[i], msg)
+		}
+	}
+	if got[1].CacheControl != hint {
+		t.Errorf("expected the cache hint to move to the consolidated message")
+	}
+	if messages[1].Content != "Be brief" {
+		t.Errorf("expected the input to be left unmodified, got %q", messages[1].Content)
+	}
+}
+
+func TestHTTPProvider_SingleSystemMessage(t *testing.T) {
+	var got openai2.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(NewProviderConfig("single-system").WithBaseURL(server.URL).WithSingleSystemMessage())
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{
+		{Role: "system", Content: "Be brief"},
+		{Role: "system", Content: "Answer in French"},
+		{Role: "user", Content: "Hello"},
+	})
+	if _, err := provider.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[0].Content != "Be brief\n\nAnswer in French" {
+		t.Errorf("expected one consolidated system message, got %+v", got.Messages)
+	}
+}
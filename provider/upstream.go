@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// DefaultResponseHeaders are the upstream response headers passed back to
+// clients unless configured otherwise: OpenAI's request ID and rate limits
+var DefaultResponseHeaders = []string{
+	"X-Request-Id",
+	"X-Ratelimit-Limit-Requests",
+	"X-Ratelimit-Limit-Tokens",
+	"X-Ratelimit-Remaining-Requests",
+	"X-Ratelimit-Remaining-Tokens",
+	"X-Ratelimit-Reset-Requests",
+	"X-Ratelimit-Reset-Tokens",
+	"Retry-After",
+}
+
+// responseHeaders returns the allowlisted headers of an upstream response, or nil if there are none
+func (p *BaseProvider) responseHeaders(header http.Header) http.Header {
+	names := p.config.ResponseHeaders
+	if names == nil {
+		names = DefaultResponseHeaders
+	}
+
+	var headers http.Header
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			if headers == nil {
+				headers = make(http.Header)
+			}
+			headers[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return headers
+}
+
+// StatusError returns the error for an upstream error status, for providers
+// that make their own HTTP calls. detail is the provider's decoded error body,
+// if any, and is kept as Err.
+func (p *BaseProvider) StatusError(resp *http.Response, body []byte, detail error) *Error {
+	err := p.upstreamError(resp, body)
+	err.Err = detail
+	return err
+}
+
+// upstreamError returns the error for an upstream response with status code and body
+func (p *BaseProvider) upstreamError(resp *http.Response, body []byte) *Error {
+	return &Error{
+		Kind:       statusErrorKind(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Headers:    p.responseHeaders(resp.Header),
+	}
+}
+
+// embeddedError returns the error for a 200 response whose body is an error
+// object, as some OpenAI-compatible upstreams send instead of an error status,
+// or nil if the body isn't one. A null error field, as in successful Responses
+// API bodies, isn't an error.
+func (p *BaseProvider) embeddedError(resp *http.Response, body []byte) *Error {
+	if p.config.IgnoreErrorBodies || !bytes.Contains(body, []byte(`"error"`)) {
+		return nil
+	}
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) != nil || len(envelope.Error) == 0 {
+		return nil
+	}
+
+	err := p.upstreamError(resp, body)
+	switch envelope.Error[0] {
+	case '{':
+		var detail struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		}
+		json.Unmarshal(envelope.Error, &detail)
+		err.Message, err.Type = detail.Message, detail.Type
+	case '"':
+		// Some upstreams send the message alone
+		json.Unmarshal(envelope.Error, &err.Message)
+	default:
+		return nil
+	}
+	if err.Message == "" {
+		err.Message = string(envelope.Error)
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+func TestHTTPProvider_ResponseHeaders(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_1")
+		w.Header().Set("x-custom-quota", "5")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[]}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(NewProviderConfig("custom").WithBaseURL(server.URL).WithResponseHeaders("X-Custom-Quota"))
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+
+	resp, err := provider.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Headers.Get("X-Custom-Quota"); got != "5" {
+		t.Errorf("expected the configured header, got %q", got)
+	}
+	if got := resp.Headers.Get("X-Request-Id"); got != "" {
+		t.Errorf("expected headers outside the allowlist to be dropped, got %q", got)
+	}
+
+	status = http.StatusBadRequest
+	_, err = provider.SendRequest(context.Background(), req)
+	var upstreamErr *Error
+	if !errors.As(err, &upstreamErr) {
+		t.Fatalf("expected an Error, got %v", err)
+	}
+	if upstreamErr.StatusCode != http.StatusBadRequest || upstreamErr.Headers.Get("X-Custom-Quota") != "5" {
+		t.Errorf("unexpected upstream error: %+v", upstreamErr)
+	}
+}
+
+func TestHTTPProvider_ErrorBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		ignore      bool
+		wantMessage string
+		wantType    string
+		wantErr     string
+	}{
+		{
+			name:        "error object",
+			body:        `{"error":{"message":"model overloaded","type":"server_error","code":null}}`,
+			wantMessage: "model overloaded",
+			wantType:    "server_error",
+			wantErr:     "upstream error (server_error): model overloaded",
+		},
+		{
+			name:        "error string",
+			body:        `{"error":"invalid api key"}`,
+			wantMessage: "invalid api key",
+			wantErr:     "upstream error: invalid api key",
+		},
+		{
+			name: "null error",
+			body: `{"id":"chatcmpl-1","object":"chat.completion","choices":[],"error":null}`,
+		},
+		{
+			name:   "ignored",
+			body:   `{"error":{"message":"model overloaded","type":"server_error"}}`,
+			ignore: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := NewProviderConfig("custom").WithBaseURL(server.URL)
+			if tt.ignore {
+				config.WithIgnoreErrorBodies()
+			}
+			req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+
+			_, err := NewHTTPProvider(config).SendRequest(context.Background(), req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var provErr *Error
+			if !errors.As(err, &provErr) {
+				t.Fatalf("expected an Error, got %v", err)
+			}
+			if provErr.Kind != ErrorKindUpstream || provErr.StatusCode != http.StatusOK {
+				t.Errorf("expected an upstream error with status 200, got %v and %d", provErr.Kind, provErr.StatusCode)
+			}
+			if provErr.Message != tt.wantMessage || provErr.Type != tt.wantType {
+				t.Errorf("expected message %q and type %q, got %q and %q", tt.wantMessage, tt.wantType, provErr.Message, provErr.Type)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("expected %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HMACUser returns a UserTransformFunc that replaces the end-user identifier with
+// its hex-encoded HMAC-SHA256 under secret. The result is stable for a given user,
+// so providers can still correlate abuse without seeing the raw identifier.
+func HMACUser(secret []byte) UserTransformFunc {
+	return func(user string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(user))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// StripUser is a UserTransformFunc that removes the end-user identifier
+func StripUser(string) string {
+	return ""
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// sendUser sends a chat request for user and returns the outbound user field
+func sendUser(t *testing.T, transform UserTransformFunc, user string) (string, bool) {
+	t.Helper()
+
+	var outbound map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&outbound); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	config := NewProviderConfig("test").WithBaseURL(server.URL).WithUserTransform(transform)
+	prov := NewHTTPProvider(config)
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "Hello"}})
+	req.User = user
+	resp, err := prov.SendRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	resp.Close()
+
+	value, ok := outbound["user"].(string)
+	return value, ok
+}
+
+func TestHMACUser(t *testing.T) {
+	secret := []byte("secret")
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("alice@example.com"))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	got, ok := sendUser(t, HMACUser(secret), "alice@example.com")
+	if !ok {
+		t.Fatal("expected outbound user field")
+	}
+	if got != want {
+		t.Errorf("expected hashed user %s, got %s", want, got)
+	}
+	if again := HMACUser(secret)("alice@example.com"); again != got {
+		t.Error("expected hashing to be stable")
+	}
+}
+
+func TestStripUser(t *testing.T) {
+	if got, ok := sendUser(t, StripUser, "alice@example.com"); ok {
+		t.Errorf("expected user to be stripped, got %s", got)
+	}
+}
+
+func TestUserTransformUnset(t *testing.T) {
+	got, _ := sendUser(t, nil, "alice@example.com")
+	if got != "alice@example.com" {
+		t.Errorf("expected user to pass through, got %s", got)
+	}
+}
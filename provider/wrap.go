@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+)
+
+// BeforeRequestFunc is called before the request is sent to the wrapped provider.
+// Returning an error aborts the request.
+type BeforeRequestFunc func(ctx context.Context, req *Request) error
+
+// AfterResponseFunc is called after the wrapped provider returns a response.
+// Returning an error discards the response.
+type AfterResponseFunc func(ctx context.Context, req *Request, resp *Response) error
+
+// ErrorMapperFunc maps an error returned while handling a request
+type ErrorMapperFunc func(ctx context.Context, req *Request, err error) error
+
+// WrapOption configures a WrappedProvider
+type WrapOption func(*WrappedProvider)
+
+// WithBeforeRequest adds a function called before each request
+func WithBeforeRequest(fn BeforeRequestFunc) WrapOption {
+	return func(w *WrappedProvider) {
+		w.before = append(w.before, fn)
+	}
+}
+
+// WithAfterResponse adds a function called after each successful response
+func WithAfterResponse(fn AfterResponseFunc) WrapOption {
+	return func(w *WrappedProvider) {
+		w.after = append(w.after, fn)
+	}
+}
+
+// WithErrorMapper sets the function used to map errors
+func WithErrorMapper(fn ErrorMapperFunc) WrapOption {
+	return func(w *WrappedProvider) {
+		w.mapError = fn
+	}
+}
+
+// WithWrappedName overrides the name reported by the wrapped provider
+func WithWrappedName(name string) WrapOption {
+	return func(w *WrappedProvider) {
+		w.name = name
+	}
+}
+
+// WrappedProvider decorates a provider with request/response transformations
+type WrappedProvider struct {
+	inner    Provider
+	name     string
+	before   []BeforeRequestFunc
+	after    []AfterResponseFunc
+	mapError ErrorMapperFunc
+}
+
+// Wrap decorates a provider with the given options
+func Wrap(inner Provider, opts ...WrapOption) *WrappedProvider {
+	w := &WrappedProvider{inner: inner}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Name returns the provider name
+func (w *WrappedProvider) Name() string {
+	if w.name != "" {
+		return w.name
+	}
+	return w.inner.Name()
+}
+
+// SupportedAPIs returns the APIs supported by the wrapped provider
+func (w *WrappedProvider) SupportedAPIs() APIType {
+	return w.inner.SupportedAPIs()
+}
+
+// Unwrap returns the wrapped provider
+func (w *WrappedProvider) Unwrap() Provider {
+	return w.inner
+}
+
+// SendRequest runs the before functions, sends the request to the wrapped
+// provider and runs the after functions. Errors are passed through the error mapper.
+func (w *WrappedProvider) SendRequest(ctx context.Context, req *Request) (*Response, error) {
+	for _, fn := range w.before {
+		if err := fn(ctx, req); err != nil {
+			return nil, w.handleError(ctx, req, err)
+		}
+	}
+
+	resp, err := w.inner.SendRequest(ctx, req)
+	if err != nil {
+		return nil, w.handleError(ctx, req, err)
+	}
+
+	for _, fn := range w.after {
+		if err := fn(ctx, req, resp); err != nil {
+			resp.Close()
+			return nil, w.handleError(ctx, req, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// handleError applies the error mapper if configured
+func (w *WrappedProvider) handleError(ctx context.Context, req *Request, err error) error {
+	if w.mapError != nil {
+		return w.mapError(ctx, req, err)
+	}
+	return err
+}
+
+// Ensure WrappedProvider implements Provider
+var _ Provider = (*WrappedProvider)(nil)
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openai2 "github.com/deeplooplabs/ai-gateway/provider/openai"
+)
+
+// recordingProvider records the last request it received
+type recordingProvider struct {
+	lastRequest *Request
+	err         error
+}
+
+func (r *recordingProvider) Name() string {
+	return "recording"
+}
+
+func (r *recordingProvider) SupportedAPIs() APIType {
+	return APITypeChatCompletions
+}
+
+func (r *recordingProvider) SendRequest(ctx context.Context, req *Request) (*Response, error) {
+	r.lastRequest = req
+	if r.err != nil {
+		return nil, r.err
+	}
+	return NewChatCompletionResponse(&openai2.ChatCompletionResponse{Model: req.Model}), nil
+}
+
+func TestWrap_BeforeRequest(t *testing.T) {
+	inner := &recordingProvider{}
+	var afterCalled bool
+
+	p := Wrap(inner,
+		WithBeforeRequest(func(ctx context.Context, req *Request) error {
+			if req.Headers == nil {
+				req.Headers = make(map[string]string)
+			}
+			req.Headers["X-Tenant"] = "acme"
+			return nil
+		}),
+		WithAfterResponse(func(ctx context.Context, req *Request, resp *Response) error {
+			afterCalled = true
+			return nil
+		}),
+	)
+
+	if p.Name() != "recording" {
+		t.Errorf("expected name 'recording', got '%s'", p.Name())
+	}
+	if p.SupportedAPIs() != APITypeChatCompletions {
+		t.Errorf("expected APITypeChatCompletions, got %v", p.SupportedAPIs())
+	}
+
+	req := NewChatCompletionsRequest("gpt-4", []openai2.Message{{Role: "user", Content: "test"}})
+	if _, err := p.SendRequest(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if inner.lastRequest == nil || inner.lastRequest.Headers["X-Tenant"] != "acme" {
+		t.Errorf("expected inner provider to observe X-Tenant header, got %v", inner.lastRequest)
+	}
+	if !afterCalled {
+		t.Error("expected after response function to be called")
+	}
+}
+
+func TestWrap_ErrorMapper(t *testing.T) {
+	upstreamErr := errors.New("upstream failed")
+	mappedErr := errors.New("mapped")
+	inner := &recordingProvider{err: upstreamErr}
+
+	p := Wrap(inner,
+		WithWrappedName("wrapped"),
+		WithErrorMapper(func(ctx context.Context, req *Request, err error) error {
+			if errors.Is(err, upstreamErr) {
+				return mappedErr
+			}
+			return err
+		}),
+	)
+
+	if p.Name() != "wrapped" {
+		t.Errorf("expected name 'wrapped', got '%s'", p.Name())
+	}
+
+	_, err := p.SendRequest(context.Background(), NewChatCompletionsRequest("gpt-4", nil))
+	if !errors.Is(err, mappedErr) {
+		t.Errorf("expected mapped error, got %v", err)
+	}
+}
+
+func TestWrap_BeforeRequestAborts(t *testing.T) {
+	inner := &recordingProvider{}
+	abortErr := errors.New("blocked")
+
+	p := Wrap(inner, WithBeforeRequest(func(ctx context.Context, req *Request) error {
+		return abortErr
+	}))
+
+	_, err := p.SendRequest(context.Background(), NewChatCompletionsRequest("gpt-4", nil))
+	if !errors.Is(err, abortErr) {
+		t.Errorf("expected abort error, got %v", err)
+	}
+	if inner.lastRequest != nil {
+		t.Error("expected inner provider not to be called")
+	}
+}
+
+func TestIsSingleChoice(t *testing.T) {
+	if IsSingleChoice(&recordingProvider{}) {
+		t.Error("expected provider without SingleChoice to support n")
+	}
+
+	base := NewBaseProvider(NewProviderConfig("single").WithSingleChoice())
+	if !IsSingleChoice(base) {
+		t.Error("expected configured provider to be single-choice")
+	}
+	if !IsSingleChoice(Wrap(base)) {
+		t.Error("expected wrapped provider to be single-choice")
+	}
+}
+
+func TestSupportsTools(t *testing.T) {
+	if !SupportsTools(&recordingProvider{}) {
+		t.Error("expected provider without SupportsTools to accept tools")
+	}
+
+	base := NewBaseProvider(NewProviderConfig("no-tools").WithoutTools())
+	if SupportsTools(base) {
+		t.Error("expected configured provider not to support tools")
+	}
+	if SupportsTools(Wrap(base)) {
+		t.Error("expected wrapped provider not to support tools")
+	}
+}
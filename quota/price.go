@@ -0,0 +1,53 @@
+package quota
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Price is the cost of a model in dollars per 1K tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// PriceTable maps model names to their prices, for tracking spend in dollars
+type PriceTable map[string]Price
+
+// Cost returns the dollar cost of usage on model, or false if model has no price
+func (t PriceTable) Cost(model string, usage TokenUsage) (float64, bool) {
+	price, ok := t[model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(usage.InputTokens)*price.Input + float64(usage.OutputTokens)*price.Output) / 1000, true
+}
+
+// spend returns the dollar cost of usage on model for RecordCost. A model
+// missing from a configured table is logged, since its usage only counts
+// tokens against the tenant's limits.
+func (t PriceTable) spend(ctx context.Context, model string, usage TokenUsage) float64 {
+	cost, ok := t.Cost(model, usage)
+	if !ok && len(t) > 0 {
+		slog.WarnContext(ctx, "no price for model, spend not recorded", "model", model)
+	}
+	return cost
+}
+
+// TokenUsage is the token usage of a single request
+type TokenUsage struct {
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+}
+
+// withinLimits reports whether usage is below both its token and spend limits (0 = unlimited)
+func withinLimits(usage *Usage) bool {
+	if usage.QuotaLimit > 0 && usage.TotalTokens >= usage.QuotaLimit {
+		return false
+	}
+	if usage.SpendLimit > 0 && usage.Cost >= usage.SpendLimit {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package quota
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+var testPrices = PriceTable{
+	"gpt-4":       {Input: 0.03, Output: 0.06},
+	"gpt-4o-mini": {Input: 0.00015, Output: 0.0006},
+}
+
+func TestPriceTable_Cost(t *testing.T) {
+	cost, ok := testPrices.Cost("gpt-4", TokenUsage{InputTokens: 1000, OutputTokens: 500, TotalTokens: 1500})
+	if !ok || math.Abs(cost-0.06) > 1e-9 {
+		t.Errorf("Expected $0.06, got %v (ok=%v)", cost, ok)
+	}
+	if _, ok := testPrices.Cost("unknown", TokenUsage{InputTokens: 1000}); ok {
+		t.Error("Expected unpriced model to have no cost")
+	}
+}
+
+// testRecordCost records priced usage on two models and checks the dollar total against a spend limit
+func testRecordCost(t *testing.T, mgr Manager) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := mgr.RecordCost(ctx, "tenant1", "gpt-4", TokenUsage{InputTokens: 1000, OutputTokens: 500, TotalTokens: 1500}); err != nil {
+		t.Fatalf("Failed to record cost: %v", err)
+	}
+	if err := mgr.RecordCost(ctx, "tenant1", "gpt-4o-mini", TokenUsage{InputTokens: 2000, OutputTokens: 1000, TotalTokens: 3000}); err != nil {
+		t.Fatalf("Failed to record cost: %v", err)
+	}
+	// Unpriced models only count tokens
+	if err := mgr.RecordCost(ctx, "tenant1", "unknown", TokenUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}); err != nil {
+		t.Fatalf("Failed to record cost: %v", err)
+	}
+
+	hasQuota, usage, err := mgr.CheckQuota(ctx, "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to check quota: %v", err)
+	}
+	if math.Abs(usage.Cost-0.0609) > 1e-9 {
+		t.Errorf("Expected $0.0609 spent, got %v", usage.Cost)
+	}
+	if usage.TotalTokens != 4515 {
+		t.Errorf("Expected 4515 total tokens, got %d", usage.TotalTokens)
+	}
+	if hasQuota {
+		t.Error("Expected tenant to have exceeded the $0.05 spend limit")
+	}
+
+	if err := mgr.SetSpendLimit(ctx, "tenant1", 1); err != nil {
+		t.Fatalf("Failed to set spend limit: %v", err)
+	}
+	if hasQuota, _, _ := mgr.CheckQuota(ctx, "tenant1"); !hasQuota {
+		t.Error("Expected tenant to have quota after raising the spend limit")
+	}
+}
+
+func TestQuotaManager_RecordCost(t *testing.T) {
+	testRecordCost(t, NewMemoryManager(&Config{
+		ResetPeriod:       Never,
+		Enabled:           true,
+		Prices:            testPrices,
+		DefaultSpendLimit: 0.05,
+	}))
+}
+
+func TestRedisManager_RecordCost(t *testing.T) {
+	mgr, _ := newTestRedisManager(t, &Config{
+		ResetPeriod:       Never,
+		Enabled:           true,
+		Prices:            testPrices,
+		DefaultSpendLimit: 0.05,
+	})
+	testRecordCost(t, mgr)
+}
+
+func TestQuotaManager_RecordCostWithoutPrices(t *testing.T) {
+	// Without a price table token quotas work as before
+	mgr := NewMemoryManager(&Config{DefaultQuota: 100, ResetPeriod: Never, Enabled: true})
+	ctx := context.Background()
+
+	mgr.RecordCost(ctx, "tenant1", "gpt-4", TokenUsage{InputTokens: 100, OutputTokens: 50, TotalTokens: 150})
+
+	hasQuota, usage, err := mgr.CheckQuota(ctx, "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to check quota: %v", err)
+	}
+	if hasQuota || usage.TotalTokens != 150 || usage.Cost != 0 {
+		t.Errorf("Expected token quota to be exceeded with no cost, got hasQuota=%v usage=%+v", hasQuota, usage)
+	}
+}
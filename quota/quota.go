@@ -26,6 +26,10 @@ const (
 type Manager interface {
 	// RecordUsage records token usage for a tenant
 	RecordUsage(ctx context.Context, tenantID string, inputTokens, outputTokens, totalTokens int) error
+
+	// RecordCost records token usage for a tenant and its dollar cost on model,
+	// priced from the configured price table. Unpriced models only count tokens.
+	RecordCost(ctx context.Context, tenantID, model string, usage TokenUsage) error
 	
 	// CheckQuota checks if tenant has remaining quota
 	CheckQuota(ctx context.Context, tenantID string) (bool, *Usage, error)
@@ -35,6 +39,9 @@ type Manager interface {
 	
 	// SetQuota sets the quota limit for a tenant
 	SetQuota(ctx context.Context, tenantID string, limit int64) error
+
+	// SetSpendLimit sets the dollar spend limit for a tenant (0 = unlimited)
+	SetSpendLimit(ctx context.Context, tenantID string, limit float64) error
 	
 	// ResetUsage resets usage for a tenant
 	ResetUsage(ctx context.Context, tenantID string) error
@@ -49,7 +56,9 @@ type Usage struct {
 	InputTokens   int64
 	OutputTokens  int64
 	TotalTokens   int64
-	QuotaLimit    int64 // 0 means unlimited
+	QuotaLimit    int64   // 0 means unlimited
+	Cost          float64 // Dollars spent, when a price table is configured
+	SpendLimit    float64 // Dollar limit, 0 means unlimited
 	ResetAt       time.Time
 	LastUpdated   time.Time
 }
@@ -58,6 +67,12 @@ type Usage struct {
 type Config struct {
 	// DefaultQuota is the default quota for new tenants (0 = unlimited)
 	DefaultQuota int64
+
+	// Prices prices models for RecordCost. Without it only tokens are tracked.
+	Prices PriceTable
+
+	// DefaultSpendLimit is the default dollar limit for new tenants (0 = unlimited)
+	DefaultSpendLimit float64
 	
 	// ResetPeriod determines when quotas reset
 	ResetPeriod ResetPeriod
@@ -105,6 +120,17 @@ func NewMemoryManager(config *Config) Manager {
 
 // RecordUsage records token usage for a tenant
 func (m *memoryQuotaManager) RecordUsage(ctx context.Context, tenantID string, inputTokens, outputTokens, totalTokens int) error {
+	return m.record(tenantID, TokenUsage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: totalTokens}, 0)
+}
+
+// RecordCost records token usage for a tenant and its cost on model
+func (m *memoryQuotaManager) RecordCost(ctx context.Context, tenantID, model string, usage TokenUsage) error {
+	cost := m.config.Prices.spend(ctx, model, usage)
+	return m.record(tenantID, usage, cost)
+}
+
+// record adds tokens and cost to a tenant's usage
+func (m *memoryQuotaManager) record(tenantID string, tokens TokenUsage, cost float64) error {
 	if !m.config.Enabled {
 		return nil
 	}
@@ -117,23 +143,26 @@ func (m *memoryQuotaManager) RecordUsage(ctx context.Context, tenantID string, i
 		usage = &Usage{
 			TenantID:   tenantID,
 			QuotaLimit: m.config.DefaultQuota,
+			SpendLimit: m.config.DefaultSpendLimit,
 			ResetAt:    m.calculateResetTime(),
 		}
 		m.usages[tenantID] = usage
 	}
 	
-	// Check if reset is needed
-	if time.Now().After(usage.ResetAt) {
+	// Check if reset is needed (skip if reset time is zero)
+	if !usage.ResetAt.IsZero() && time.Now().After(usage.ResetAt) {
 		usage.InputTokens = 0
 		usage.OutputTokens = 0
 		usage.TotalTokens = 0
+		usage.Cost = 0
 		usage.ResetAt = m.calculateResetTime()
 	}
 	
 	// Record usage
-	usage.InputTokens += int64(inputTokens)
-	usage.OutputTokens += int64(outputTokens)
-	usage.TotalTokens += int64(totalTokens)
+	usage.InputTokens += int64(tokens.InputTokens)
+	usage.OutputTokens += int64(tokens.OutputTokens)
+	usage.TotalTokens += int64(tokens.TotalTokens)
+	usage.Cost += cost
 	usage.LastUpdated = time.Now()
 	
 	return nil
@@ -154,6 +183,7 @@ func (m *memoryQuotaManager) CheckQuota(ctx context.Context, tenantID string) (b
 		return true, &Usage{
 			TenantID:   tenantID,
 			QuotaLimit: m.config.DefaultQuota,
+			SpendLimit: m.config.DefaultSpendLimit,
 		}, nil
 	}
 	
@@ -162,13 +192,8 @@ func (m *memoryQuotaManager) CheckQuota(ctx context.Context, tenantID string) (b
 		return true, usage, nil
 	}
 	
-	// Check quota (0 = unlimited)
-	if usage.QuotaLimit == 0 {
-		return true, usage, nil
-	}
-	
-	hasQuota := usage.TotalTokens < usage.QuotaLimit
-	return hasQuota, usage, nil
+	// Check token and spend limits (0 = unlimited)
+	return withinLimits(usage), usage, nil
 }
 
 // GetUsage returns current usage for a tenant
@@ -181,6 +206,7 @@ func (m *memoryQuotaManager) GetUsage(ctx context.Context, tenantID string) (*Us
 		return &Usage{
 			TenantID:   tenantID,
 			QuotaLimit: m.config.DefaultQuota,
+			SpendLimit: m.config.DefaultSpendLimit,
 			ResetAt:    m.calculateResetTime(),
 		}, nil
 	}
@@ -200,6 +226,7 @@ func (m *memoryQuotaManager) SetQuota(ctx context.Context, tenantID string, limi
 		usage = &Usage{
 			TenantID:   tenantID,
 			QuotaLimit: limit,
+			SpendLimit: m.config.DefaultSpendLimit,
 			ResetAt:    m.calculateResetTime(),
 		}
 		m.usages[tenantID] = usage
@@ -210,6 +237,25 @@ func (m *memoryQuotaManager) SetQuota(ctx context.Context, tenantID string, limi
 	return nil
 }
 
+// SetSpendLimit sets the dollar spend limit for a tenant
+func (m *memoryQuotaManager) SetSpendLimit(ctx context.Context, tenantID string, limit float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	usage, exists := m.usages[tenantID]
+	if !exists {
+		usage = &Usage{
+			TenantID:   tenantID,
+			QuotaLimit: m.config.DefaultQuota,
+			ResetAt:    m.calculateResetTime(),
+		}
+		m.usages[tenantID] = usage
+	}
+	usage.SpendLimit = limit
+	
+	return nil
+}
+
 // ResetUsage resets usage for a tenant
 func (m *memoryQuotaManager) ResetUsage(ctx context.Context, tenantID string) error {
 	m.mu.Lock()
@@ -220,6 +266,7 @@ func (m *memoryQuotaManager) ResetUsage(ctx context.Context, tenantID string) er
 		usage.InputTokens = 0
 		usage.OutputTokens = 0
 		usage.TotalTokens = 0
+		usage.Cost = 0
 		usage.ResetAt = m.calculateResetTime()
 		usage.LastUpdated = time.Now()
 	}
@@ -239,6 +286,7 @@ func (m *memoryQuotaManager) ResetAll(ctx context.Context) error {
 		usage.InputTokens = 0
 		usage.OutputTokens = 0
 		usage.TotalTokens = 0
+		usage.Cost = 0
 		usage.ResetAt = resetAt
 		usage.LastUpdated = now
 	}
@@ -248,13 +296,24 @@ func (m *memoryQuotaManager) ResetAll(ctx context.Context) error {
 
 // calculateResetTime calculates the next reset time based on the reset period
 func (m *memoryQuotaManager) calculateResetTime() time.Time {
-	now := time.Now()
-	
-	switch m.config.ResetPeriod {
+	return nextReset(m.config.ResetPeriod, time.Now())
+}
+
+// nextReset returns the start of the period after the one containing now
+func nextReset(period ResetPeriod, now time.Time) time.Time {
+	switch period {
 	case Hourly:
 		return now.Add(1 * time.Hour).Truncate(time.Hour)
 	case Daily:
 		return now.Add(24 * time.Hour).Truncate(24 * time.Hour)
+	case Weekly:
+		// Reset on the next Monday
+		day := now.UTC().Truncate(24 * time.Hour)
+		days := (8 - int(day.Weekday())) % 7
+		if days == 0 {
+			days = 7
+		}
+		return day.Add(time.Duration(days) * 24 * time.Hour)
 	case Monthly:
 		// Reset on the first day of next month
 		year, month, _ := now.Date()
@@ -305,6 +364,7 @@ func (m *memoryQuotaManager) checkAndResetExpired() {
 			usage.InputTokens = 0
 			usage.OutputTokens = 0
 			usage.TotalTokens = 0
+			usage.Cost = 0
 			usage.ResetAt = resetAt
 			usage.LastUpdated = now
 		}
@@ -0,0 +1,195 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces the quota keys in Redis
+const redisKeyPrefix = "quota:"
+
+// redisQuotaManager implements quota management backed by Redis, so usage
+// survives restarts and is shared across gateway replicas
+type redisQuotaManager struct {
+	client redis.UniversalClient
+	config *Config
+}
+
+// NewRedisManager creates a quota manager storing per-tenant token counters in Redis.
+// Counters expire at the end of the reset period, so resets need no background goroutine.
+func NewRedisManager(client redis.UniversalClient, config *Config) Manager {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &redisQuotaManager{
+		client: client,
+		config: config,
+	}
+}
+
+// usageKey returns the key of one of a tenant's usage counters. The tenant is a
+// hash tag so all of its keys share a cluster slot and can be read with one MGET.
+func usageKey(tenantID, counter string) string {
+	return redisKeyPrefix + "{" + tenantID + "}:usage:" + counter
+}
+
+// limitKey returns the key of a tenant's quota limit, which never expires
+func limitKey(tenantID string) string {
+	return redisKeyPrefix + "{" + tenantID + "}:limit"
+}
+
+// spendLimitKey returns the key of a tenant's dollar spend limit, which never expires
+func spendLimitKey(tenantID string) string {
+	return redisKeyPrefix + "{" + tenantID + "}:spend_limit"
+}
+
+// usageKeys returns all usage keys of a tenant
+func usageKeys(tenantID string) []string {
+	return []string{
+		usageKey(tenantID, "input"),
+		usageKey(tenantID, "output"),
+		usageKey(tenantID, "total"),
+		usageKey(tenantID, "updated"),
+		usageKey(tenantID, "cost"),
+	}
+}
+
+// RecordUsage records token usage for a tenant
+func (m *redisQuotaManager) RecordUsage(ctx context.Context, tenantID string, inputTokens, outputTokens, totalTokens int) error {
+	return m.record(ctx, tenantID, TokenUsage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: totalTokens}, 0)
+}
+
+// RecordCost records token usage for a tenant and its cost on model
+func (m *redisQuotaManager) RecordCost(ctx context.Context, tenantID, model string, usage TokenUsage) error {
+	cost := m.config.Prices.spend(ctx, model, usage)
+	return m.record(ctx, tenantID, usage, cost)
+}
+
+// record adds tokens and cost to a tenant's counters
+func (m *redisQuotaManager) record(ctx context.Context, tenantID string, tokens TokenUsage, cost float64) error {
+	if !m.config.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	resetAt := nextReset(m.config.ResetPeriod, now)
+	_, err := m.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.IncrBy(ctx, usageKey(tenantID, "input"), int64(tokens.InputTokens))
+		pipe.IncrBy(ctx, usageKey(tenantID, "output"), int64(tokens.OutputTokens))
+		pipe.IncrBy(ctx, usageKey(tenantID, "total"), int64(tokens.TotalTokens))
+		pipe.IncrByFloat(ctx, usageKey(tenantID, "cost"), cost)
+		pipe.Set(ctx, usageKey(tenantID, "updated"), now.UnixNano(), 0)
+
+		// The reset time is the same for the whole period, so setting it on every
+		// update only starts the expiry of counters created in this period
+		if !resetAt.IsZero() {
+			for _, key := range usageKeys(tenantID) {
+				pipe.ExpireAt(ctx, key, resetAt)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("record usage: %w", err)
+	}
+	return nil
+}
+
+// CheckQuota checks if tenant has remaining quota
+func (m *redisQuotaManager) CheckQuota(ctx context.Context, tenantID string) (bool, *Usage, error) {
+	if !m.config.Enabled {
+		return true, nil, nil
+	}
+
+	usage, err := m.GetUsage(ctx, tenantID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Check token and spend limits (0 = unlimited)
+	return withinLimits(usage), usage, nil
+}
+
+// GetUsage returns current usage for a tenant
+func (m *redisQuotaManager) GetUsage(ctx context.Context, tenantID string) (*Usage, error) {
+	keys := append(usageKeys(tenantID), limitKey(tenantID), spendLimitKey(tenantID))
+	values, err := m.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get usage: %w", err)
+	}
+
+	usage := &Usage{
+		TenantID:   tenantID,
+		QuotaLimit: m.config.DefaultQuota,
+		SpendLimit: m.config.DefaultSpendLimit,
+		ResetAt:    nextReset(m.config.ResetPeriod, time.Now()),
+	}
+	targets := []*int64{&usage.InputTokens, &usage.OutputTokens, &usage.TotalTokens, nil, nil, &usage.QuotaLimit, nil}
+	dollars := map[int]*float64{4: &usage.Cost, 6: &usage.SpendLimit}
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if target, ok := dollars[i]; ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("get usage: invalid value for %s: %w", keys[i], err)
+			}
+			*target = f
+			continue
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("get usage: invalid value for %s: %w", keys[i], err)
+		}
+		if targets[i] != nil {
+			*targets[i] = n
+		} else {
+			usage.LastUpdated = time.Unix(0, n)
+		}
+	}
+	return usage, nil
+}
+
+// SetQuota sets the quota limit for a tenant
+func (m *redisQuotaManager) SetQuota(ctx context.Context, tenantID string, limit int64) error {
+	if err := m.client.Set(ctx, limitKey(tenantID), limit, 0).Err(); err != nil {
+		return fmt.Errorf("set quota: %w", err)
+	}
+	return nil
+}
+
+// SetSpendLimit sets the dollar spend limit for a tenant
+func (m *redisQuotaManager) SetSpendLimit(ctx context.Context, tenantID string, limit float64) error {
+	if err := m.client.Set(ctx, spendLimitKey(tenantID), limit, 0).Err(); err != nil {
+		return fmt.Errorf("set spend limit: %w", err)
+	}
+	return nil
+}
+
+// ResetUsage resets usage for a tenant
+func (m *redisQuotaManager) ResetUsage(ctx context.Context, tenantID string) error {
+	if err := m.client.Del(ctx, usageKeys(tenantID)...).Err(); err != nil {
+		return fmt.Errorf("reset usage: %w", err)
+	}
+	return nil
+}
+
+// ResetAll resets usage for all tenants, keeping their quota limits
+func (m *redisQuotaManager) ResetAll(ctx context.Context) error {
+	iter := m.client.Scan(ctx, 0, redisKeyPrefix+"{*}:usage:*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := m.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return fmt.Errorf("reset all: %w", err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("reset all: %w", err)
+	}
+	return nil
+}
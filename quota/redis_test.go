@@ -0,0 +1,165 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisManager(t *testing.T, config *Config) (Manager, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisManager(client, config), mr
+}
+
+func TestRedisManager_RecordAndCheck(t *testing.T) {
+	mgr, _ := newTestRedisManager(t, &Config{DefaultQuota: 1000, ResetPeriod: Never, Enabled: true})
+	ctx := context.Background()
+
+	if err := mgr.RecordUsage(ctx, "tenant1", 100, 50, 150); err != nil {
+		t.Fatalf("Failed to record usage: %v", err)
+	}
+	if err := mgr.RecordUsage(ctx, "tenant1", 10, 5, 15); err != nil {
+		t.Fatalf("Failed to record usage: %v", err)
+	}
+
+	hasQuota, usage, err := mgr.CheckQuota(ctx, "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to check quota: %v", err)
+	}
+	if !hasQuota {
+		t.Error("Expected tenant to have quota remaining")
+	}
+	if usage.InputTokens != 110 || usage.OutputTokens != 55 || usage.TotalTokens != 165 || usage.QuotaLimit != 1000 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+	if usage.LastUpdated.IsZero() {
+		t.Error("Expected LastUpdated to be set")
+	}
+}
+
+func TestRedisManager_ExceedQuota(t *testing.T) {
+	mgr, _ := newTestRedisManager(t, &Config{DefaultQuota: 100, ResetPeriod: Never, Enabled: true})
+	ctx := context.Background()
+
+	mgr.RecordUsage(ctx, "tenant1", 150, 0, 150)
+
+	hasQuota, usage, err := mgr.CheckQuota(ctx, "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to check quota: %v", err)
+	}
+	if hasQuota {
+		t.Error("Expected tenant to have exceeded quota (150 > 100)")
+	}
+	if usage.TotalTokens != 150 {
+		t.Errorf("Expected 150 total tokens, got %d", usage.TotalTokens)
+	}
+
+	// Other tenants are unaffected
+	if hasQuota, _, _ := mgr.CheckQuota(ctx, "tenant2"); !hasQuota {
+		t.Error("Expected tenant2 to have quota remaining")
+	}
+}
+
+func TestRedisManager_SetQuotaSurvivesReset(t *testing.T) {
+	mgr, _ := newTestRedisManager(t, &Config{DefaultQuota: 100, ResetPeriod: Never, Enabled: true})
+	ctx := context.Background()
+
+	mgr.SetQuota(ctx, "tenant1", 500)
+	mgr.RecordUsage(ctx, "tenant1", 150, 0, 150)
+	if hasQuota, _, _ := mgr.CheckQuota(ctx, "tenant1"); !hasQuota {
+		t.Error("Expected custom quota of 500 to apply")
+	}
+
+	mgr.ResetAll(ctx)
+	usage, err := mgr.GetUsage(ctx, "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to get usage: %v", err)
+	}
+	if usage.TotalTokens != 0 || usage.QuotaLimit != 500 {
+		t.Errorf("Expected usage reset with quota kept, got %+v", usage)
+	}
+}
+
+func TestRedisManager_ResetUsage(t *testing.T) {
+	mgr, _ := newTestRedisManager(t, &Config{DefaultQuota: 100, ResetPeriod: Never, Enabled: true})
+	ctx := context.Background()
+
+	mgr.RecordUsage(ctx, "tenant1", 150, 0, 150)
+	mgr.RecordUsage(ctx, "tenant2", 150, 0, 150)
+	if err := mgr.ResetUsage(ctx, "tenant1"); err != nil {
+		t.Fatalf("Failed to reset usage: %v", err)
+	}
+
+	if hasQuota, _, _ := mgr.CheckQuota(ctx, "tenant1"); !hasQuota {
+		t.Error("Expected tenant1 to have quota after reset")
+	}
+	if hasQuota, _, _ := mgr.CheckQuota(ctx, "tenant2"); hasQuota {
+		t.Error("Expected tenant2 to still be over quota")
+	}
+}
+
+func TestRedisManager_ResetOnExpiry(t *testing.T) {
+	mgr, mr := newTestRedisManager(t, &Config{DefaultQuota: 100, ResetPeriod: Hourly, Enabled: true})
+	ctx := context.Background()
+
+	mgr.RecordUsage(ctx, "tenant1", 150, 0, 150)
+	if hasQuota, _, _ := mgr.CheckQuota(ctx, "tenant1"); hasQuota {
+		t.Fatal("Expected tenant to be over quota")
+	}
+
+	// Counters expire at the start of the next hour
+	ttl := mr.TTL(usageKey("tenant1", "total"))
+	if ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("Expected counters to expire within the hour, got TTL %s", ttl)
+	}
+	mr.FastForward(ttl)
+
+	hasQuota, usage, err := mgr.CheckQuota(ctx, "tenant1")
+	if err != nil {
+		t.Fatalf("Failed to check quota: %v", err)
+	}
+	if !hasQuota || usage.TotalTokens != 0 {
+		t.Errorf("Expected usage to reset on expiry, got %+v", usage)
+	}
+}
+
+func TestRedisManager_Disabled(t *testing.T) {
+	mgr, mr := newTestRedisManager(t, &Config{DefaultQuota: 1, Enabled: false})
+	ctx := context.Background()
+
+	mgr.RecordUsage(ctx, "tenant1", 150, 0, 150)
+	hasQuota, _, err := mgr.CheckQuota(ctx, "tenant1")
+	if err != nil || !hasQuota {
+		t.Errorf("Expected quota check to pass when disabled, got %v, %v", hasQuota, err)
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("Expected nothing to be stored when disabled, got %v", keys)
+	}
+}
+
+func TestNextReset(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period ResetPeriod
+		want   time.Time
+	}{
+		{Hourly, time.Date(2026, 10, 14, 16, 0, 0, 0, time.UTC)},
+		{Daily, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{Weekly, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{Monthly, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{Never, time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := nextReset(tt.period, now); !got.Equal(tt.want) {
+			t.Errorf("period %d: expected %s, got %s", tt.period, tt.want, got)
+		}
+	}
+}
@@ -19,6 +19,28 @@ type Limiter interface {
 	Reset(ctx context.Context, key string)
 }
 
+// Status describes a key's rate limit after a request was checked
+type Status struct {
+	// Limit is the maximum number of requests allowed in a burst
+	Limit int
+
+	// Remaining is the number of requests that would be allowed right now
+	Remaining int
+
+	// Reset is the time until the full limit is available again
+	Reset time.Duration
+
+	// RetryAfter is the time until the next request is allowed, or 0 if one is allowed now
+	RetryAfter time.Duration
+}
+
+// Reporter is implemented by limiters that report a key's status, e.g. for rate limit headers
+type Reporter interface {
+	// Take checks and consumes one request for key, returning whether it was
+	// allowed and the key's status afterwards
+	Take(ctx context.Context, key string) (bool, Status)
+}
+
 // Config holds rate limiter configuration
 type Config struct {
 	// RequestsPerSecond is the number of requests allowed per second
@@ -31,6 +53,16 @@ type Config struct {
 	Enabled bool
 }
 
+// PerMinute returns a configuration allowing requests per minute per key,
+// refilled continuously, with the full minute's allowance usable as a burst
+func PerMinute(requests int) *Config {
+	return &Config{
+		RequestsPerSecond: float64(requests) / 60,
+		Burst:             requests,
+		Enabled:           true,
+	}
+}
+
 // DefaultConfig returns a default rate limiter configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -77,6 +109,9 @@ func NewTokenBucket(config *Config) Limiter {
 	return tb
 }
 
+// Ensure tokenBucket reports its status
+var _ Reporter = (*tokenBucket)(nil)
+
 // Allow checks if a request is allowed
 func (tb *tokenBucket) Allow(ctx context.Context, key string) bool {
 	return tb.AllowN(ctx, key, 1)
@@ -91,6 +126,42 @@ func (tb *tokenBucket) AllowN(ctx context.Context, key string, n int) bool {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 	
+	_, allowed := tb.takeLocked(key, n)
+	return allowed
+}
+
+// Take checks and consumes one request, reporting the bucket's status
+func (tb *tokenBucket) Take(ctx context.Context, key string) (bool, Status) {
+	if !tb.config.Enabled {
+		return true, Status{Limit: tb.config.Burst, Remaining: tb.config.Burst}
+	}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	b, allowed := tb.takeLocked(key, 1)
+	status := Status{
+		Limit:     tb.config.Burst,
+		Remaining: int(b.tokens),
+		Reset:     tb.refillTime(float64(tb.config.Burst) - b.tokens),
+	}
+	if !allowed {
+		status.RetryAfter = tb.refillTime(1 - b.tokens)
+	}
+	return allowed, status
+}
+
+// refillTime returns how long it takes to refill the given number of tokens
+func (tb *tokenBucket) refillTime(tokens float64) time.Duration {
+	if tokens <= 0 || tb.config.RequestsPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / tb.config.RequestsPerSecond * float64(time.Second))
+}
+
+// takeLocked refills key's bucket and takes n tokens if available, returning
+// the bucket and whether they were taken (must be called with lock held)
+func (tb *tokenBucket) takeLocked(key string, n int) (*bucket, bool) {
 	// Periodic cleanup of old buckets
 	if time.Since(tb.lastCleanup) > tb.cleanupInterval {
 		tb.cleanup()
@@ -116,10 +187,10 @@ func (tb *tokenBucket) AllowN(ctx context.Context, key string, n int) bool {
 	// Check if enough tokens available
 	if b.tokens >= float64(n) {
 		b.tokens -= float64(n)
-		return true
+		return b, true
 	}
 	
-	return false
+	return b, false
 }
 
 // Reset resets the rate limiter for a key
@@ -130,13 +201,14 @@ func (tb *tokenBucket) Reset(ctx context.Context, key string) {
 	delete(tb.buckets, key)
 }
 
-// cleanup removes buckets that haven't been used recently
+// cleanup removes idle buckets that have refilled completely, since a new
+// bucket starts full. Removing a bucket earlier would reset its limit.
 func (tb *tokenBucket) cleanup() {
 	now := time.Now()
-	threshold := 10 * time.Minute
 	
 	for key, b := range tb.buckets {
-		if now.Sub(b.lastRefillTime) > threshold {
+		idle := now.Sub(b.lastRefillTime).Seconds()
+		if b.tokens+idle*tb.config.RequestsPerSecond >= float64(tb.config.Burst) {
 			delete(tb.buckets, key)
 		}
 	}
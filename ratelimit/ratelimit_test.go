@@ -2,6 +2,8 @@ package ratelimit
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -130,3 +132,99 @@ func TestTokenBucket_Disabled(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenBucket_Take(t *testing.T) {
+	limiter := NewTokenBucket(PerMinute(2)).(Reporter)
+	ctx := context.Background()
+
+	allowed, status := limiter.Take(ctx, "tenant-a")
+	if !allowed || status.Limit != 2 || status.Remaining != 1 || status.RetryAfter != 0 {
+		t.Fatalf("unexpected first status: %v %+v", allowed, status)
+	}
+
+	limiter.Take(ctx, "tenant-a")
+	allowed, status = limiter.Take(ctx, "tenant-a")
+	if allowed {
+		t.Fatal("expected request over the limit to be denied")
+	}
+	if status.Remaining != 0 {
+		t.Errorf("expected 0 remaining, got %d", status.Remaining)
+	}
+	// One request per 30s refills
+	if status.RetryAfter <= 29*time.Second || status.RetryAfter > 30*time.Second {
+		t.Errorf("expected retry after ~30s, got %s", status.RetryAfter)
+	}
+	if status.Reset <= 59*time.Second || status.Reset > time.Minute {
+		t.Errorf("expected reset after ~60s, got %s", status.Reset)
+	}
+
+	// Other keys are unaffected
+	if allowed, _ := limiter.Take(ctx, "tenant-b"); !allowed {
+		t.Error("expected another key to be allowed")
+	}
+}
+
+func TestTokenBucket_SteadyState(t *testing.T) {
+	limiter := NewTokenBucket(&Config{RequestsPerSecond: 20, Burst: 1, Enabled: true})
+	ctx := context.Background()
+
+	// After the burst, requests are throttled to the refill rate
+	allowed := 0
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if limiter.Allow(ctx, "user1") {
+			allowed++
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 1 burst token plus ~10 refilled in 500ms at 20/s
+	if allowed < 8 || allowed > 13 {
+		t.Errorf("expected about 11 requests allowed, got %d", allowed)
+	}
+}
+
+func TestTokenBucket_ConcurrentSameKey(t *testing.T) {
+	limiter := NewTokenBucket(&Config{RequestsPerSecond: 0.001, Burst: 50, Enabled: true})
+	ctx := context.Background()
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				if limiter.Allow(ctx, "shared") {
+					allowed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 50 {
+		t.Errorf("expected exactly the burst of 50 to be allowed, got %d", got)
+	}
+}
+
+func TestTokenBucket_CleanupKeepsUnrefilledBuckets(t *testing.T) {
+	tb := NewTokenBucket(&Config{RequestsPerSecond: 0.001, Burst: 1, Enabled: true}).(*tokenBucket)
+	tb.cleanupInterval = 0
+	ctx := context.Background()
+
+	tb.Allow(ctx, "slow")
+	tb.Allow(ctx, "idle")
+
+	// Idle for 11 minutes refills "idle" but only part of a token for "slow"
+	tb.buckets["slow"].lastRefillTime = time.Now().Add(-11 * time.Minute)
+	tb.buckets["idle"].lastRefillTime = time.Now().Add(-time.Hour)
+	tb.Allow(ctx, "other")
+
+	if _, ok := tb.buckets["idle"]; ok {
+		t.Error("expected refilled idle bucket to be removed")
+	}
+	if tb.Allow(ctx, "slow") {
+		t.Error("expected cleanup not to reset a bucket that hasn't refilled")
+	}
+}